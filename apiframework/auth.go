@@ -2,15 +2,25 @@ package apiframework
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
 type ContextKey string
 
 const (
 	ContextTokenKey ContextKey = "token"
+	// ContextIdentityKey holds the identity of the API key a request was
+	// authenticated with; see EnforceKeys and IdentityFromContext.
+	ContextIdentityKey ContextKey = "identity"
+	// ContextScopesKey holds the scopes of the API key a request was
+	// authenticated with; see RequireScope.
+	ContextScopesKey ContextKey = "scopes"
 )
 
 func TokenMiddleware(next http.Handler) http.Handler {
@@ -27,6 +37,8 @@ func TokenMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// EnforceToken accepts only requests carrying expectedToken. It is the
+// single-key case of EnforceKeys and leaves no identity in the context.
 func EnforceToken(expectedToken string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestToken, ok := r.Context().Value(ContextTokenKey).(string)
@@ -37,3 +49,247 @@ func EnforceToken(expectedToken string, next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// APIKey is one accepted token and the identity requests presenting it are
+// attributed to. Several keys may share an identity, which is how a key is
+// rotated without the caller changing who it is.
+type APIKey struct {
+	Key string
+	// KeyHash stands in for Key when the secret is not kept, as for keys
+	// loaded from a store: it is HashAPIKey of the key.
+	KeyHash  string
+	Identity string
+	// ExpiresAt ends the key's validity; the zero time means it never
+	// expires. KeySet.Rotate sets it on the outgoing key.
+	ExpiresAt time.Time
+	// Scopes limits what the key may do. A key without scopes is unrestricted,
+	// which keeps a plain token meaning full access.
+	Scopes []Scope
+}
+
+// HashAPIKey returns the hex SHA-256 of key, the form APIKey.KeyHash holds.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Access is the level of access a Scope grants on a resource type.
+type Access string
+
+const (
+	// AccessRead allows reads (GET, HEAD, OPTIONS).
+	AccessRead Access = "read"
+	// AccessManage allows every method, reads included.
+	AccessManage Access = "manage"
+)
+
+// ScopeAllResources in Scope.Resource matches every resource type.
+const ScopeAllResources = "*"
+
+// Scope grants Access on one resource type, such as "files" or "backends".
+type Scope struct {
+	Resource string `json:"resource"`
+	Access   Access `json:"access"`
+}
+
+// Allows reports whether scopes grant access on resource. A nil scopes list
+// is unrestricted; an empty non-nil one grants nothing.
+func Allows(scopes []Scope, resource string, access Access) bool {
+	if scopes == nil {
+		return true
+	}
+	for _, sc := range scopes {
+		if sc.Resource != resource && sc.Resource != ScopeAllResources {
+			continue
+		}
+		if sc.Access == AccessManage || sc.Access == access {
+			return true
+		}
+	}
+	return false
+}
+
+// KeySet is the set of API keys EnforceKeys accepts. It is safe for
+// concurrent use and can be changed while the server runs, so keys loaded
+// from config at startup can later be replaced from the store.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []APIKey
+	now  func() time.Time
+}
+
+// NewKeySet returns a KeySet holding keys. Keys with neither Key nor KeyHash,
+// or without an Identity, are ignored: an empty key would match a request
+// without one, and an empty identity would leave downstream authorization
+// nothing to check.
+func NewKeySet(keys ...APIKey) *KeySet {
+	s := &KeySet{now: time.Now}
+	s.Replace(keys...)
+	return s
+}
+
+// Replace swaps the whole set for keys.
+func (s *KeySet) Replace(keys ...APIKey) {
+	valid := make([]APIKey, 0, len(keys))
+	for _, k := range keys {
+		if k.valid() {
+			valid = append(valid, k)
+		}
+	}
+	s.mu.Lock()
+	s.keys = valid
+	s.mu.Unlock()
+}
+
+// Rotate adds next and keeps oldKey valid for overlap, so clients can switch
+// to the new key without a window where neither works. A non-positive
+// overlap revokes oldKey immediately. Rotating a key that is not in the set
+// only adds next.
+func (s *KeySet) Rotate(oldKey string, next APIKey, overlap time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	keys := make([]APIKey, 0, len(s.keys)+1)
+	for _, k := range s.keys {
+		if k.Key == oldKey {
+			if overlap <= 0 {
+				continue
+			}
+			if k.ExpiresAt.IsZero() || k.ExpiresAt.After(now.Add(overlap)) {
+				k.ExpiresAt = now.Add(overlap)
+			}
+		}
+		keys = append(keys, k)
+	}
+	if next.valid() {
+		keys = append(keys, next)
+	}
+	s.keys = keys
+}
+
+func (k APIKey) valid() bool {
+	return (k.Key != "" || k.KeyHash != "") && k.Identity != ""
+}
+
+// Revoke removes key from the set.
+func (s *KeySet) Revoke(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := s.keys[:0:0]
+	for _, k := range s.keys {
+		if k.Key != key {
+			keys = append(keys, k)
+		}
+	}
+	s.keys = keys
+}
+
+// Resolve returns the identity of the unexpired key matching token. Every
+// key is compared in constant time, so the response time does not reveal
+// which key, if any, a token came close to.
+func (s *KeySet) Resolve(token string) (string, bool) {
+	k, ok := s.lookup(token)
+	return k.Identity, ok
+}
+
+func (s *KeySet) lookup(token string) (APIKey, bool) {
+	if token == "" {
+		return APIKey{}, false
+	}
+	tokenHash := HashAPIKey(token)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := s.now()
+	var found APIKey
+	ok := false
+	for _, k := range s.keys {
+		var match bool
+		if k.Key != "" {
+			match = subtle.ConstantTimeCompare([]byte(token), []byte(k.Key)) == 1
+		} else {
+			match = subtle.ConstantTimeCompare([]byte(tokenHash), []byte(k.KeyHash)) == 1
+		}
+		if match && !ok && (k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt)) {
+			found, ok = k, true
+		}
+	}
+	return found, ok
+}
+
+// List returns the keys in the set without their secrets, for a management
+// view of who holds access and with which scopes.
+func (s *KeySet) List() []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]APIKey, len(s.keys))
+	for i, k := range s.keys {
+		k.Key, k.KeyHash = "", ""
+		k.Scopes = append([]Scope(nil), k.Scopes...)
+		out[i] = k
+	}
+	return out
+}
+
+// EnforceKeys accepts requests whose token (see TokenMiddleware) resolves to
+// a key in keys and stores that key's identity under ContextIdentityKey, and
+// its scopes under ContextScopesKey, for downstream authorization.
+func EnforceKeys(keys *KeySet, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestToken, _ := r.Context().Value(ContextTokenKey).(string)
+		key, ok := keys.lookup(requestToken)
+		if !ok {
+			// GetOperation, not AuthorizeOperation: a failed credential is
+			// 401, and AuthorizeOperation would make every error a 403.
+			_ = Error(w, r, ErrUnauthorized, GetOperation)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), key.Identity, key.Scopes)))
+	})
+}
+
+// WithIdentity returns ctx carrying identity and scopes as EnforceKeys
+// stores them, for gates that authenticate a request some other way, such as
+// a session cookie. nil scopes is unrestricted.
+func WithIdentity(ctx context.Context, identity string, scopes []Scope) context.Context {
+	ctx = context.WithValue(ctx, ContextIdentityKey, identity)
+	ctx = context.WithValue(ctx, ContextScopesKey, scopes)
+	setRequestLogIdentity(ctx, identity)
+	return ctx
+}
+
+// RequireScope lets a request through only when the key EnforceKeys resolved
+// for it may access resource: read scope for GET, HEAD and OPTIONS, manage
+// scope for every other method. Mount it behind EnforceKeys; a request that
+// did not pass through EnforceKeys is rejected as unauthenticated.
+func RequireScope(resource string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := IdentityFromContext(r.Context()); !ok {
+			_ = Error(w, r, ErrUnauthorized, GetOperation)
+			return
+		}
+		access := AccessManage
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			access = AccessRead
+		}
+		if !Allows(ScopesFromContext(r.Context()), resource, access) {
+			_ = Error(w, r, Forbidden("API key lacks "+string(access)+" access to "+resource), AuthorizeOperation)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ScopesFromContext returns the scopes of the key EnforceKeys resolved for
+// the request; nil means unrestricted.
+func ScopesFromContext(ctx context.Context) []Scope {
+	scopes, _ := ctx.Value(ContextScopesKey).([]Scope)
+	return scopes
+}
+
+// IdentityFromContext returns the identity EnforceKeys resolved for the
+// request.
+func IdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(ContextIdentityKey).(string)
+	return identity, ok && identity != ""
+}
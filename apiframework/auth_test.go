@@ -0,0 +1,115 @@
+package apiframework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func enforceKeysRequest(t *testing.T, keys *KeySet, apiKey string) (int, string) {
+	t.Helper()
+	var identity string
+	h := TokenMiddleware(EnforceKeys(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = IdentityFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code, identity
+}
+
+func TestUnit_EnforceKeys_ResolvesIdentityPerKey(t *testing.T) {
+	keys := NewKeySet(
+		APIKey{Key: "alice-key", Identity: "alice"},
+		APIKey{Key: "bob-key", Identity: "bob"},
+		APIKey{Key: "", Identity: "nobody"},
+	)
+
+	code, identity := enforceKeysRequest(t, keys, "bob-key")
+	require.Equal(t, http.StatusNoContent, code)
+	require.Equal(t, "bob", identity)
+
+	code, _ = enforceKeysRequest(t, keys, "Bearer alice-key")
+	require.Equal(t, http.StatusNoContent, code)
+
+	code, _ = enforceKeysRequest(t, keys, "mallory-key")
+	require.Equal(t, http.StatusUnauthorized, code)
+	code, _ = enforceKeysRequest(t, keys, "")
+	require.Equal(t, http.StatusUnauthorized, code, "an empty key must not match")
+}
+
+func TestUnit_KeySet_RotateKeepsOldKeyForOverlap(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := NewKeySet(APIKey{Key: "old", Identity: "ci"})
+	keys.now = func() time.Time { return now }
+
+	keys.Rotate("old", APIKey{Key: "new", Identity: "ci"}, time.Hour)
+	for _, k := range []string{"old", "new"} {
+		identity, ok := keys.Resolve(k)
+		require.True(t, ok, k)
+		require.Equal(t, "ci", identity)
+	}
+
+	now = now.Add(time.Hour)
+	_, ok := keys.Resolve("old")
+	require.False(t, ok, "the old key must stop working when the overlap ends")
+	_, ok = keys.Resolve("new")
+	require.True(t, ok)
+
+	keys.Revoke("new")
+	_, ok = keys.Resolve("new")
+	require.False(t, ok)
+}
+
+func TestUnit_KeySet_MatchesStoredHash(t *testing.T) {
+	keys := NewKeySet(APIKey{KeyHash: HashAPIKey("stored-key"), Identity: "ci"})
+
+	identity, ok := keys.Resolve("stored-key")
+	require.True(t, ok)
+	require.Equal(t, "ci", identity)
+	_, ok = keys.Resolve(HashAPIKey("stored-key"))
+	require.False(t, ok, "the hash itself is not a credential")
+	require.Empty(t, keys.List()[0].KeyHash)
+}
+
+func TestUnit_RequireScope_EnforcesPerResourceAccess(t *testing.T) {
+	keys := NewKeySet(
+		APIKey{Key: "reader", Identity: "dash", Scopes: []Scope{{Resource: "files", Access: AccessRead}}},
+		APIKey{Key: "ops", Identity: "ops", Scopes: []Scope{{Resource: "backends", Access: AccessManage}}},
+		APIKey{Key: "admin", Identity: "admin"},
+	)
+	h := TokenMiddleware(EnforceKeys(keys, RequireScope("files", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))))
+	do := func(method, key string) int {
+		req := httptest.NewRequest(method, "/files", nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusNoContent, do(http.MethodGet, "reader"))
+	require.Equal(t, http.StatusForbidden, do(http.MethodPut, "reader"), "read scope must not allow writes")
+	require.Equal(t, http.StatusForbidden, do(http.MethodGet, "ops"), "a scope on another resource grants nothing here")
+	require.Equal(t, http.StatusNoContent, do(http.MethodDelete, "admin"), "a key without scopes is unrestricted")
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "revoked"), "an unknown key is unauthenticated, not forbidden")
+
+	unauthenticated := httptest.NewRecorder()
+	RequireScope("files", http.NotFoundHandler()).ServeHTTP(unauthenticated, httptest.NewRequest(http.MethodGet, "/files", nil))
+	require.Equal(t, http.StatusUnauthorized, unauthenticated.Code, "a request with no identity is 401")
+
+	listed := keys.List()
+	require.Len(t, listed, 3)
+	for _, k := range listed {
+		require.Empty(t, k.Key, "listing must not expose key secrets")
+	}
+	require.Equal(t, []Scope{{Resource: "files", Access: AccessRead}}, listed[0].Scopes)
+}
@@ -0,0 +1,62 @@
+package apiframework
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	// DefaultMaxBodyBytes caps API request bodies: JSON documents, including
+	// base64 file writes, stay well below it.
+	DefaultMaxBodyBytes int64 = 32 << 20
+	// DefaultMaxUploadBytes caps the bodies of upload routes, which stream
+	// model artifacts of several gigabytes.
+	DefaultMaxUploadBytes int64 = 64 << 30
+)
+
+// BodyLimits configures BodyLimitMiddleware.
+type BodyLimits struct {
+	// Default caps every request body. 0 or less disables the cap.
+	Default int64
+	// Upload replaces Default for requests matching UploadRoutes. 0 or less
+	// leaves upload bodies uncapped.
+	Upload int64
+	// UploadRoutes are http.ServeMux patterns, such as
+	// "POST /backends/{id}/models/push", of the routes that take Upload.
+	UploadRoutes []string
+}
+
+// BodyLimitMiddleware caps request bodies so one oversized payload cannot
+// exhaust the process: Decode and the handlers that read r.Body directly
+// all read through the cap. A request whose Content-Length already exceeds
+// its limit is refused with 413 before the handler runs; a body that only
+// turns out too large while being read (chunked transfer) fails that read
+// with an *http.MaxBytesError, which Decode and Error also turn into a 413.
+func BodyLimitMiddleware(limits BodyLimits, next http.Handler) http.Handler {
+	uploads := http.NewServeMux()
+	for _, pattern := range limits.UploadRoutes {
+		uploads.Handle(pattern, http.NotFoundHandler())
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := limits.Default
+		if len(limits.UploadRoutes) > 0 {
+			if _, pattern := uploads.Handler(r); pattern != "" {
+				limit = limits.Upload
+			}
+		}
+		if limit <= 0 || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.ContentLength > limit {
+			_ = Error(w, r, bodyTooLarge(limit), ServerOperation)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func bodyTooLarge(limit int64) *APIError {
+	return RequestBodyTooLarge(fmt.Sprintf("Request body exceeds the limit of %d bytes", limit))
+}
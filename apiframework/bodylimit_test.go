@@ -0,0 +1,71 @@
+package apiframework
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func bodyLimitHandler(t *testing.T) http.Handler {
+	t.Helper()
+	limits := BodyLimits{Default: 16, Upload: 64, UploadRoutes: []string{"POST /backends/{id}/models/push"}}
+	return BodyLimitMiddleware(limits, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			_ = Error(w, r, err, CreateOperation)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestUnit_BodyLimitMiddleware_RejectsOversizedBodies(t *testing.T) {
+	h := bodyLimitHandler(t)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(strings.Repeat("x", 16))))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(strings.Repeat("x", 17))))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	var body apiErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, "request_too_large", body.Error.Code)
+	require.Contains(t, body.Error.Message, "16 bytes")
+}
+
+func TestUnit_BodyLimitMiddleware_CatchesBodiesWithoutContentLength(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/tasks", io.NopCloser(strings.NewReader(strings.Repeat("x", 32))))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	bodyLimitHandler(t).ServeHTTP(rec, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestUnit_BodyLimitMiddleware_UploadRoutesTakeUploadLimit(t *testing.T) {
+	h := bodyLimitHandler(t)
+	body := strings.Repeat("x", 48)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/backends/b1/models/push", strings.NewReader(body)))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/backends/b1/models/push", strings.NewReader(body)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, "only the registered method gets the upload limit")
+}
+
+func TestUnit_Decode_ReportsBodyLimit(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"a long enough value"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Body = http.MaxBytesReader(httptest.NewRecorder(), req.Body, 8)
+
+	_, err := Decode[map[string]string](req)
+	require.ErrorIs(t, err, ErrRequestBodyTooLarge)
+	require.Equal(t, http.StatusRequestEntityTooLarge, ErrorToStatus(err))
+}
@@ -48,6 +48,10 @@ func Decode[T any](r *http.Request) (T, error) {
 
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return v, bodyTooLarge(maxBytesErr.Limit)
+		}
 		return v, fmt.Errorf("%w: %w", ErrReadingRequestBody, err)
 	}
 	defer r.Body.Close()
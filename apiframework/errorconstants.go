@@ -1,12 +1,16 @@
 package apiframework
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
 	"github.com/contenox/runtime/libauth"
 	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/messagestore"
 	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/contenox/runtime/runtime/taskengine"
 	"github.com/contenox/runtime/runtime/vfs"
 )
 
@@ -31,6 +35,7 @@ var (
 	ErrUnauthorized          = errors.New("serverops: unauthorized")
 	ErrFileSizeLimitExceeded = errors.New("serverops: file size limit exceeded")
 	ErrFileEmpty             = errors.New("serverops: file cannot be empty")
+	ErrRequestBodyTooLarge   = errors.New("serverops: request body too large")
 )
 
 var errorMappings = map[error]struct {
@@ -54,6 +59,7 @@ var errorMappings = map[error]struct {
 	ErrUnauthorized:          {"authentication_error", "unauthorized"},
 	ErrFileSizeLimitExceeded: {"invalid_request_error", "file_size_limit_exceeded"},
 	ErrFileEmpty:             {"invalid_request_error", "file_empty"},
+	ErrRequestBodyTooLarge:   {"invalid_request_error", "request_too_large"},
 	ErrInvalidChain:          {"invalid_request_error", "invalid_chain"},
 }
 
@@ -88,6 +94,10 @@ func getErrorTypeAndCode(status int) (string, string) {
 		return "rate_limit_error", "rate_limit_exceeded"
 	case http.StatusInternalServerError:
 		return "api_error", "internal_error"
+	case http.StatusBadGateway:
+		return "api_error", "upstream_error"
+	case http.StatusGatewayTimeout:
+		return "api_error", "timeout"
 	default:
 		return "api_error", "unknown_error"
 	}
@@ -107,11 +117,55 @@ const (
 )
 
 func mapErrorToStatus(op Operation, err error) int {
+	if status := requestStatus(err); status != 0 {
+		return status
+	}
+	if op == AuthorizeOperation {
+		return http.StatusForbidden
+	}
+	if status := causeStatus(err); status != 0 {
+		return status
+	}
+
+	switch op {
+	case CreateOperation, UpdateOperation:
+		return http.StatusUnprocessableEntity
+	case GetOperation, ListOperation, DeleteOperation:
+		return http.StatusNotFound
+	case AuthorizeOperation:
+		return http.StatusForbidden
+	case ServerOperation, ExecuteOperation:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ErrorToStatus returns the HTTP status for err from the causes it wraps —
+// the apiframework sentinels, auth and store errors, errdefs, and the
+// taskengine error categories — unwrapping as deep as the chain goes. An
+// error with no recognised cause is a 500. Handlers get the same mapping
+// through Error, which additionally falls back on the operation for
+// unrecognised errors.
+func ErrorToStatus(err error) int {
+	if status := requestStatus(err); status != 0 {
+		return status
+	}
+	if status := causeStatus(err); status != 0 {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// requestStatus maps failures of the request itself — its size, its
+// multipart shape, its credentials — which take precedence over everything
+// else, including the blanket 403 of AuthorizeOperation.
+func requestStatus(err error) int {
 	var maxBytesErr *http.MaxBytesError
 	if errors.As(err, &maxBytesErr) {
 		return http.StatusRequestEntityTooLarge
 	}
-	if errors.Is(err, ErrFileSizeLimitExceeded) {
+	if errors.Is(err, ErrFileSizeLimitExceeded) || errors.Is(err, ErrRequestBodyTooLarge) {
 		return http.StatusRequestEntityTooLarge
 	}
 	if errors.Is(err, http.ErrNotMultipart) {
@@ -126,9 +180,12 @@ func mapErrorToStatus(op Operation, err error) int {
 	if errors.Is(err, libauth.ErrNotAuthorized) {
 		return http.StatusUnauthorized
 	}
-	if op == AuthorizeOperation {
-		return http.StatusForbidden
-	}
+	return 0
+}
+
+// causeStatus maps the errors a service layer returns. It returns 0 for an
+// error it does not recognise.
+func causeStatus(err error) int {
 	if errors.Is(err, libauth.ErrTokenExpired) {
 		return http.StatusUnauthorized
 	}
@@ -217,18 +274,35 @@ func mapErrorToStatus(op Operation, err error) int {
 		return http.StatusForbidden
 	}
 
-	switch op {
-	case CreateOperation, UpdateOperation:
-		return http.StatusUnprocessableEntity
-	case GetOperation, ListOperation, DeleteOperation:
+	if errors.Is(err, messagestore.ErrNotFound) {
 		return http.StatusNotFound
-	case AuthorizeOperation:
+	}
+	if errors.Is(err, errdefs.ErrBadRequest) ||
+		errors.Is(err, errdefs.ErrEmptyRequest) ||
+		errors.Is(err, errdefs.ErrEmptyRequestBody) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, errdefs.ErrUnprocessableEntity) {
+		return http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, errdefs.ErrImmutableModel) {
 		return http.StatusForbidden
-	case ServerOperation, ExecuteOperation:
-		return http.StatusInternalServerError
-	default:
-		return http.StatusInternalServerError
 	}
+
+	// Chain execution failures, by taskengine category. A timeout wins over
+	// the provider or hook call it cut short (see taskengine.ErrorCategory).
+	switch taskengine.ErrorCategory(err) {
+	case taskengine.ErrTimeout:
+		return http.StatusGatewayTimeout
+	case taskengine.ErrProvider, taskengine.ErrHook:
+		return http.StatusBadGateway
+	case taskengine.ErrValidation:
+		return http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return 0
 }
 
 func NewAPIError(err error, message, param string) *APIError {
@@ -289,6 +363,10 @@ func FileSizeLimitExceeded(message ...string) *APIError {
 	return NewAPIError(ErrFileSizeLimitExceeded, messageOrDefault("File size limit exceeded", message), "")
 }
 
+func RequestBodyTooLarge(message ...string) *APIError {
+	return NewAPIError(ErrRequestBodyTooLarge, messageOrDefault("Request body too large", message), "")
+}
+
 func FileEmpty(message ...string) *APIError {
 	return NewAPIError(ErrFileEmpty, messageOrDefault("File cannot be empty", message), "")
 }
@@ -0,0 +1,85 @@
+package apiframework
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/runtime/libauth"
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/messagestore"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+// wrap buries err under two layers of context, the way a service call
+// stack usually returns it to a handler.
+func wrap(err error) error {
+	return fmt.Errorf("handler: %w", fmt.Errorf("service: %w", err))
+}
+
+func TestUnit_ErrorToStatus_WrappedChains(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"store not found", libdb.ErrNotFound, http.StatusNotFound},
+		{"message store not found", messagestore.ErrNotFound, http.StatusNotFound},
+		{"store conflict", libdb.ErrUniqueViolation, http.StatusConflict},
+		{"framework forbidden", ErrForbidden, http.StatusForbidden},
+		{"not authorized", libauth.ErrNotAuthorized, http.StatusUnauthorized},
+		{"errdefs bad request", errdefs.ErrBadRequest, http.StatusBadRequest},
+		{"errdefs empty request", errdefs.ErrEmptyRequestBody, http.StatusBadRequest},
+		{"errdefs unprocessable", errdefs.ErrUnprocessableEntity, http.StatusUnprocessableEntity},
+		{"task validation", fmt.Errorf("%w: bad template", taskengine.ErrValidation), http.StatusUnprocessableEntity},
+		{"task provider", fmt.Errorf("%w: 503 from backend", taskengine.ErrProvider), http.StatusBadGateway},
+		{"task hook", fmt.Errorf("%w: tool failed", taskengine.ErrHook), http.StatusBadGateway},
+		{"task timeout", fmt.Errorf("%w: %w", taskengine.ErrTimeout, context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{"timeout beats provider", fmt.Errorf("%w %w", taskengine.ErrProvider, taskengine.ErrTimeout), http.StatusGatewayTimeout},
+		{"bare deadline", context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, ErrorToStatus(tc.err), "unwrapped")
+			require.Equal(t, tc.want, ErrorToStatus(wrap(tc.err)), "wrapped")
+		})
+	}
+}
+
+// TestUnit_ErrorToStatus_MatchesErrorHelper pins that a handler returning a
+// recognised error through Error responds with the same status ErrorToStatus
+// reports, whatever operation it was tagged with.
+func TestUnit_ErrorToStatus_MatchesErrorHelper(t *testing.T) {
+	for _, err := range []error{
+		wrap(libdb.ErrNotFound),
+		wrap(errdefs.ErrBadRequest),
+		wrap(fmt.Errorf("%w: upstream", taskengine.ErrProvider)),
+		wrap(fmt.Errorf("%w: slow", taskengine.ErrTimeout)),
+	} {
+		for _, op := range []Operation{CreateOperation, GetOperation, ExecuteOperation} {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, Error(rr, req, err, op))
+			require.Equal(t, ErrorToStatus(err), rr.Code, "%v (%s)", err, op)
+
+			var body apiErrorResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, err.Error(), body.Error.Message)
+		}
+	}
+}
+
+func TestUnit_Error_UpstreamAndTimeoutCodes(t *testing.T) {
+	typ, code := getErrorTypeAndCode(http.StatusBadGateway)
+	require.Equal(t, "api_error", typ)
+	require.Equal(t, "upstream_error", code)
+
+	_, code = getErrorTypeAndCode(http.StatusGatewayTimeout)
+	require.Equal(t, "timeout", code)
+}
@@ -0,0 +1,90 @@
+package apiframework
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PageCursor is the position after which a keyset-paginated listing resumes:
+// the CreatedAt and ID of the last item of the previous page. Ordering on
+// both keeps pages stable when items share a creation time or are created
+// while the caller is paging.
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type pageCursorWire struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// EncodePageCursor returns c as the opaque, URL-safe string clients pass back
+// as `cursor`.
+func EncodePageCursor(c PageCursor) string {
+	raw, _ := json.Marshal(pageCursorWire{CreatedAt: c.CreatedAt.UTC(), ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodePageCursor parses a cursor EncodePageCursor produced. Anything else
+// yields ErrInvalidParameterValue.
+func DecodePageCursor(raw string) (*PageCursor, error) {
+	invalid := InvalidParameterValue("cursor", "invalid cursor, expected the nextCursor of a previous page")
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(raw, "="))
+	if err != nil {
+		return nil, invalid
+	}
+	var wire pageCursorWire
+	if err := json.Unmarshal(data, &wire); err != nil || wire.CreatedAt.IsZero() {
+		return nil, invalid
+	}
+	return &PageCursor{CreatedAt: wire.CreatedAt, ID: wire.ID}, nil
+}
+
+// PageParams is ListParams for endpoints that page with opaque cursors: it
+// parses the optional `cursor` (see DecodePageCursor) and `limit` query
+// parameters with the same absent-versus-invalid rules.
+func PageParams(r *http.Request, defaultLimit int) (*PageCursor, int, error) {
+	var cursor *PageCursor
+	if raw := GetQueryParam(r, "cursor", "", PageCursorParamDescription); raw != "" {
+		var err error
+		if cursor, err = DecodePageCursor(raw); err != nil {
+			return nil, 0, err
+		}
+	}
+	limit, err := LimitParam(r, defaultLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return cursor, limit, nil
+}
+
+// Page is one page of a cursor-paginated listing. NextCursor is set when
+// HasMore is; passing it back as `cursor` fetches the page that follows.
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// Paginate builds the page of at most limit items from items, which the
+// caller fetched with a limit of limit+1 in the store's (CreatedAt, ID)
+// order. The extra item only signals that another page exists, so a page
+// that ends exactly at the last item does not hand out a cursor leading to
+// an empty page. key returns an item's sort key; the last returned item's
+// key becomes NextCursor.
+func Paginate[T any](items []T, limit int, key func(T) PageCursor) Page[T] {
+	page := Page[T]{Data: items}
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+	if limit > 0 && len(page.Data) > limit {
+		page.Data = page.Data[:limit]
+		page.HasMore = true
+		page.NextCursor = EncodePageCursor(key(page.Data[limit-1]))
+	}
+	return page
+}
@@ -0,0 +1,72 @@
+package apiframework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type pageItem struct {
+	id      string
+	created time.Time
+}
+
+func pageItemKey(item pageItem) PageCursor {
+	return PageCursor{CreatedAt: item.created, ID: item.id}
+}
+
+func TestUnit_Paginate_EmptyPage(t *testing.T) {
+	page := Paginate[pageItem](nil, 10, pageItemKey)
+	require.NotNil(t, page.Data, "an empty page encodes as [], not null")
+	require.Empty(t, page.Data)
+	require.False(t, page.HasMore)
+	require.Empty(t, page.NextCursor)
+}
+
+func TestUnit_Paginate_ExactLimitPageHasNoNextCursor(t *testing.T) {
+	now := time.Now().UTC()
+	items := []pageItem{{"c", now}, {"b", now.Add(-time.Second)}}
+
+	page := Paginate(items, 2, pageItemKey)
+	require.Len(t, page.Data, 2)
+	require.False(t, page.HasMore)
+	require.Empty(t, page.NextCursor)
+}
+
+func TestUnit_Paginate_DuplicateTimestampsResumeByID(t *testing.T) {
+	same := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	items := []pageItem{{"c", same}, {"b", same}, {"a", same}}
+
+	page := Paginate(items, 2, pageItemKey)
+	require.Equal(t, []pageItem{{"c", same}, {"b", same}}, page.Data)
+	require.True(t, page.HasMore)
+
+	cursor, err := DecodePageCursor(page.NextCursor)
+	require.NoError(t, err)
+	require.True(t, cursor.CreatedAt.Equal(same))
+	require.Equal(t, "b", cursor.ID, "the ID breaks the tie between items created in the same instant")
+}
+
+func TestUnit_PageParams_ParsesOpaqueCursor(t *testing.T) {
+	want := PageCursor{CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC), ID: "model-1"}
+	req := httptest.NewRequest(http.MethodGet, "/things?limit=5&cursor="+EncodePageCursor(want), nil)
+
+	cursor, limit, err := PageParams(req, 100)
+	require.NoError(t, err)
+	require.Equal(t, 5, limit)
+	require.True(t, cursor.CreatedAt.Equal(want.CreatedAt))
+	require.Equal(t, want.ID, cursor.ID)
+
+	cursor, limit, err = PageParams(httptest.NewRequest(http.MethodGet, "/things", nil), 100)
+	require.NoError(t, err)
+	require.Nil(t, cursor)
+	require.Equal(t, 100, limit)
+
+	for _, raw := range []string{"garbage", "2024-03-01T12:00:00Z", EncodePageCursor(PageCursor{ID: "no-time"})} {
+		_, _, err = PageParams(httptest.NewRequest(http.MethodGet, "/things?cursor="+raw, nil), 100)
+		require.ErrorIs(t, err, ErrInvalidParameterValue, raw)
+	}
+}
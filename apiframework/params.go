@@ -39,10 +39,12 @@ func GetQueryParam(r *http.Request, name, defaultValue, description string) stri
 // CursorParamDescription and LimitParamDescription are the canonical
 // documentation for the two shared pagination query parameters. The OpenAPI
 // generator emits them verbatim for every CursorParam/LimitParam/ListParams
-// call site.
+// call site, and PageCursorParamDescription for the opaque cursor of every
+// PageParams call site.
 const (
-	CursorParamDescription = "An optional RFC3339Nano timestamp to fetch the next page of results."
-	LimitParamDescription  = "The maximum number of items to return per page."
+	CursorParamDescription     = "An optional RFC3339Nano timestamp to fetch the next page of results."
+	LimitParamDescription      = "The maximum number of items to return per page."
+	PageCursorParamDescription = "An optional opaque cursor, the nextCursor of the previous page, to fetch the next page of results."
 )
 
 // ListParams parses the two pagination query parameters shared by every
@@ -7,6 +7,7 @@ import (
 
 	"github.com/contenox/runtime/libtracker"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 func RequestIDMiddleware(next http.Handler) http.Handler {
@@ -22,10 +23,12 @@ func RequestIDMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// TracingMiddleware extracts or generates trace and span IDs.
+// TracingMiddleware extracts or generates trace and span IDs. An incoming W3C
+// traceparent also becomes the remote parent of the request context, so the
+// OpenTelemetry spans of work done for the request join the caller's trace.
 func TracingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 		traceID := ""
 		spanID := ""
 
@@ -0,0 +1,153 @@
+package apiframework
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+)
+
+// Log formats accepted by NewLogHandler.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// NewLogHandler returns the slog handler for format ("text" or "json"; empty
+// is text) writing to w at level ("debug", "info", "warn" or "error"; empty
+// is info). JSON output is meant for log shippers: every attribute, including
+// the request_id RequestIDMiddleware assigns, becomes a queryable field.
+func NewLogHandler(w io.Writer, format, level string) (slog.Handler, error) {
+	var lvl slog.Level
+	if level = strings.TrimSpace(level); level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q: use debug, info, warn or error", level)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", LogFormatText:
+		return slog.NewTextHandler(w, opts), nil
+	case LogFormatJSON:
+		return slog.NewJSONHandler(w, opts), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q: use %s or %s", format, LogFormatText, LogFormatJSON)
+	}
+}
+
+type requestLogKey struct{}
+
+// requestLogFields collects what inner middleware learns about a request
+// (the identity EnforceKeys resolves) for the line RequestLogMiddleware
+// writes once the request is done. Inner handlers only see a derived
+// context, so the fields travel as a pointer the outer middleware still holds.
+type requestLogFields struct {
+	identity string
+}
+
+// setRequestLogIdentity records identity on the request log line, if ctx
+// belongs to a request RequestLogMiddleware is logging.
+func setRequestLogIdentity(ctx context.Context, identity string) {
+	if fields, ok := ctx.Value(requestLogKey{}).(*requestLogFields); ok {
+		fields.identity = identity
+	}
+}
+
+// RequestLogMiddleware writes one log line per request with its method,
+// path, status, size and duration, the request ID, the tenant, and the
+// identity the request was authenticated as. Mount it inside
+// RequestIDMiddleware so the request ID is set; the same ID reaches the
+// activity tracker through the request context, which correlates the
+// operations a request caused with its log line. Server errors are logged at
+// error level, everything else at info.
+func RequestLogMiddleware(logger *slog.Logger, tenant string, next http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		fields := &requestLogFields{}
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestLogKey{}, fields)))
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("bytes", rec.bytes),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if requestID, ok := r.Context().Value(libtracker.ContextKeyRequestID).(string); ok && requestID != "" {
+			attrs = append(attrs, slog.String("request_id", requestID))
+		}
+		if tenant != "" {
+			attrs = append(attrs, slog.String("tenant", tenant))
+		}
+		if fields.identity != "" {
+			attrs = append(attrs, slog.String("identity", fields.identity))
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(r.Context(), level, "HTTP request", attrs...)
+	})
+}
+
+// statusRecorder captures the status and body size of a response. It passes
+// Flush and Hijack through so streaming (SSE) and WebSocket handlers keep
+// working behind the logger.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,73 @@
+package apiframework
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_RequestLogMiddleware_LogsCorrelationFields(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewLogHandler(&buf, LogFormatJSON, "info")
+	require.NoError(t, err)
+
+	keys := NewKeySet(APIKey{Key: "alice-key", Identity: "alice"})
+	h := RequestIDMiddleware(RequestLogMiddleware(slog.New(handler), "tenant-1",
+		TokenMiddleware(EnforceKeys(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("done"))
+		})))))
+
+	req := httptest.NewRequest(http.MethodPost, "/tasks", nil)
+	req.Header.Set("X-API-Key", "alice-key")
+	req.Header.Set("X-Request-ID", "req-42")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "HTTP request", line["msg"])
+	require.Equal(t, "POST", line["method"])
+	require.Equal(t, "/tasks", line["path"])
+	require.Equal(t, float64(http.StatusCreated), line["status"])
+	require.Equal(t, float64(4), line["bytes"])
+	require.Equal(t, "req-42", line["request_id"])
+	require.Equal(t, "tenant-1", line["tenant"])
+	require.Equal(t, "alice", line["identity"], "the identity resolved by inner auth middleware reaches the log line")
+	require.Contains(t, line, "duration")
+}
+
+func TestUnit_RequestLogMiddleware_ServerErrorsLogAtErrorLevel(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewLogHandler(&buf, LogFormatJSON, "error")
+	require.NoError(t, err)
+
+	h := RequestLogMiddleware(slog.New(handler), "", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	require.Empty(t, buf.String(), "successful requests log at info, below the configured level")
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "ERROR", line["level"])
+	require.NotContains(t, line, "identity")
+}
+
+func TestUnit_NewLogHandler_RejectsUnknownSettings(t *testing.T) {
+	_, err := NewLogHandler(&bytes.Buffer{}, "xml", "")
+	require.Error(t, err)
+	_, err = NewLogHandler(&bytes.Buffer{}, "", "loud")
+	require.Error(t, err)
+
+	handler, err := NewLogHandler(&bytes.Buffer{}, "", "")
+	require.NoError(t, err)
+	require.IsType(t, &slog.TextHandler{}, handler)
+}
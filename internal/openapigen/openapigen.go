@@ -17,7 +17,7 @@
 //	... // @response binary <description>           (200 application/octet-stream)
 //	... // @response sse <description>              (200 text/event-stream)
 //	... // @response redirect <description>         (302, no content, no 200)
-//	... // @param    name type description...       (escape-hatch parameter)
+//	... // @param    name type [required] description... (escape-hatch parameter)
 //	Field T `json:"x" openapi_include_type:"pkg.Type"`  // document a named type
 //
 // Annotations also bind when the registered handler is a function literal
@@ -33,6 +33,8 @@
 //   - apiframework.LimitParam(r, n) emits the integer `limit` query parameter,
 //     apiframework.CursorParam(r) the string `cursor` one, and
 //     apiframework.ListParams(r, n) both — each with its canonical description.
+//     apiframework.PageParams(r, n) emits both too, documenting `cursor` as
+//     the opaque nextCursor of a previous page.
 //   - apiframework.GetPathParam(r, "name", "description") attaches the
 //     description to the path parameter the route template already declares; a
 //     name that appears in no route template bound to that handler is a strict
@@ -41,13 +43,22 @@
 // The helper is matched by function name under any package qualifier (or bare
 // when dot-imported). Only string literals are consumed: a non-literal
 // parameter NAME is a strict error at the call site, while a non-literal
-// default or description is consumed as absent (counted in Stats). The
-// `@param name type description...` annotation (type one of string, integer,
-// number, boolean) remains the escape hatch for parameters the scan cannot
-// see. Helper-derived and @param-derived parameters merge first-wins by name,
-// helpers first; the emitted order is deterministic — path parameters in
-// template order, then query parameters sorted by name, all `required: false`
-// except path parameters.
+// default or description is consumed as absent (counted in Stats).
+//
+// Handlers that read the query string directly are seen too: a
+// `X.Query().Get("name")` or `X.Query()["name"]` expression with a literal
+// name emits an optional, description-less string query parameter. Reads
+// through an intermediate variable (`q := r.URL.Query(); q.Get("name")`) and
+// non-literal names are not followed.
+//
+// The `@param name type [required] description...` annotation (type one of
+// string, integer, number, boolean) remains the escape hatch for parameters
+// the scan cannot see, and the way to give a raw query read a type,
+// description, or `required: true`. Parameters merge first-wins by name:
+// helper-derived first, then @param, then raw query reads; the emitted order
+// is deterministic — path parameters in template order, then query parameters
+// sorted by name, all `required: false` except path parameters and @param
+// entries marked required.
 //
 // An operation's summary is the first sentence of the handler's GoDoc when it
 // has one, else "METHOD /path".
@@ -103,6 +114,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -128,11 +140,15 @@ var typeScanDirs = []string{
 	"runtime/taskengine/llmretry",
 	"runtime/agentinstance",
 	"runtime/agentservice",
+	"runtime/apikeyservice",
+	"runtime/auditservice",
+	"runtime/execservice",
 	"runtime/fleetservice",
 	"runtime/missionchanges",
 	"runtime/missionservice",
 	"runtime/operatorinbox",
 	"runtime/presence",
+	"runtime/promptservice",
 	"runtime/runtimetypes",
 	"runtime/stateservice",
 	"runtime/internal/setupcheck",
@@ -144,6 +160,7 @@ var typeScanDirs = []string{
 	"runtime/mcpserverservice",
 	"runtime/providerservice",
 	"runtime/statetype",
+	"runtime/taskchainservice",
 	"runtime/terminalstore",
 	"runtime/terminalservice",
 	"runtime/toolsproviderservice",
@@ -171,6 +188,7 @@ func Generate(root string) ([]byte, Stats, error) {
 		fset:    token.NewFileSet(),
 		structs: map[string]*structInfo{},
 		aliases: map[string]aliasInfo{},
+		enums:   map[string][]string{},
 		funcs:   map[string]map[string]*funcInfo{},
 		schemas: map[string]any{},
 		pending: map[string]bool{},
@@ -204,9 +222,10 @@ type aliasInfo struct {
 
 // annoParam is one typed `@param name type description...` escape-hatch entry.
 type annoParam struct {
-	name string
-	typ  string // string | integer | number | boolean (validated at collection)
-	desc string
+	name     string
+	typ      string // string | integer | number | boolean (validated at collection)
+	desc     string
+	required bool // `@param name type required ...`
 }
 
 // Annotation kinds. The zero value ("") means "not annotated" — the coverage
@@ -234,8 +253,8 @@ type handlerAnno struct {
 	params     []annoParam // @param entries, first-wins by name, in comment order
 }
 
-// derivedParam is one parameter derived from an apiframework helper call
-// inside a function body.
+// derivedParam is one parameter derived from an apiframework helper call (or
+// a raw query-string read) inside a function body.
 type derivedParam struct {
 	name string
 	typ  string // "string" or "integer"
@@ -244,6 +263,10 @@ type derivedParam struct {
 	// path marks a GetPathParam call: it attaches desc to the route template's
 	// path parameter instead of declaring a query parameter.
 	path bool
+	// raw marks a direct `X.Query().Get("name")` / `X.Query()["name"]` read
+	// rather than a helper call. It carries no description, so it yields to
+	// a same-named @param annotation instead of winning over it.
+	raw bool
 	// inFuncLit marks a call inside a nested function literal; such calls are
 	// consumed only when the function is used as a handler-returning wrapper
 	// (its returned closure IS the handler).
@@ -309,6 +332,7 @@ type generator struct {
 	fset    *token.FileSet
 	structs map[string]*structInfo // "pkg.Type" -> struct
 	aliases map[string]aliasInfo   // "pkg.Type" -> underlying type of a non-struct named type
+	enums   map[string][]string    // "pkg.Type" -> its typed string constants, declaration order
 	// per package: function key ("Recv.name" or bare "name") -> collected info
 	funcs          map[string]map[string]*funcInfo
 	routes         []route
@@ -389,6 +413,7 @@ func (g *generator) parseDir(dir string, isRoute bool) {
 		for _, fileName := range fileNames {
 			file := pkgs[pkgName].Files[fileName]
 			g.collectStructs(pkgName, file)
+			g.collectEnums(pkgName, file)
 			if isRoute {
 				g.collectFuncs(pkgName, file)
 				g.collectRoutes(pkgName, file)
@@ -426,6 +451,38 @@ func (g *generator) collectStructs(pkg string, file *ast.File) {
 	})
 }
 
+// collectEnums records the values of string constants declared with an
+// explicit named type (`const HandleNoop TaskHandler = "noop"`), so a field of
+// that type documents its closed value set as an `enum`. Constants that only
+// inherit a type implicitly, or whose value is not a string literal, are
+// ignored.
+func (g *generator) collectEnums(pkg string, file *ast.File) {
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok || len(vs.Values) != len(vs.Names) {
+				continue
+			}
+			typ, ok := vs.Type.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			key := pkg + "." + typ.Name
+			for _, v := range vs.Values {
+				val, ok := stringLit(v)
+				if !ok || slices.Contains(g.enums[key], val) {
+					continue
+				}
+				g.enums[key] = append(g.enums[key], val)
+			}
+		}
+	}
+}
+
 var (
 	reRequestLine  = regexp.MustCompile(`@request\s+(\S+)\s*(.*)$`)
 	reResponseLine = regexp.MustCompile(`@response\s+(\S+)\s*(.*)$`)
@@ -522,9 +579,13 @@ func (g *generator) collectAnnotations(file *ast.File, start, end token.Pos, own
 				g.problemf(cg.Pos(), "@param %s on %s: type %q must be one of string, integer, number, boolean", name, owner, typ)
 				continue
 			}
+			required := false
+			if word, rest, _ := strings.Cut(desc, " "); word == "required" {
+				required, desc = true, strings.TrimSpace(rest)
+			}
 			if !seenParam[name] {
 				seenParam[name] = true
-				fi.anno.params = append(fi.anno.params, annoParam{name: name, typ: typ, desc: desc})
+				fi.anno.params = append(fi.anno.params, annoParam{name: name, typ: typ, desc: desc, required: required})
 			}
 		}
 	}
@@ -535,11 +596,11 @@ func (g *generator) collectAnnotations(file *ast.File, start, end token.Pos, own
 // aliasing (or dot-importing) cannot hide a call.
 var paramHelperNames = map[string]bool{
 	"GetQueryParam": true, "LimitParam": true, "CursorParam": true,
-	"ListParams": true, "GetPathParam": true,
+	"ListParams": true, "PageParams": true, "GetPathParam": true,
 }
 
 // scanHelperCalls walks a function (or closure) body for apiframework
-// parameter-helper calls. Calls inside nested function literals are recorded
+// parameter-helper calls and raw query-string reads. Calls inside nested function literals are recorded
 // with inFuncLit set, so they only count when the function is consumed as a
 // handler-returning wrapper — for a plain handler, only its direct body is
 // the truth source.
@@ -551,6 +612,11 @@ func (g *generator) scanHelperCalls(body *ast.BlockStmt, fi *funcInfo) {
 				walk(fl.Body, true)
 				return false
 			}
+			if name, ok := rawQueryName(m); ok {
+				fi.derived = append(fi.derived, derivedParam{
+					name: name, typ: "string", raw: true, inFuncLit: inLit, pos: m.Pos(),
+				})
+			}
 			if call, ok := m.(*ast.CallExpr); ok {
 				g.recordHelperCall(call, inLit, fi)
 			}
@@ -560,6 +626,33 @@ func (g *generator) scanHelperCalls(body *ast.BlockStmt, fi *funcInfo) {
 	walk(body, false)
 }
 
+// rawQueryName recognizes a direct query-string read on the result of a
+// zero-argument Query() method call — `r.URL.Query().Get("name")` or
+// `r.URL.Query()["name"]` — and returns the literal parameter name.
+func rawQueryName(n ast.Node) (string, bool) {
+	var recv, key ast.Expr
+	switch v := n.(type) {
+	case *ast.CallExpr:
+		sel, ok := v.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Get" || len(v.Args) != 1 {
+			return "", false
+		}
+		recv, key = sel.X, v.Args[0]
+	case *ast.IndexExpr:
+		recv, key = v.X, v.Index
+	default:
+		return "", false
+	}
+	call, ok := recv.(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return "", false
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Query" {
+		return "", false
+	}
+	return stringLit(key)
+}
+
 func calleeName(fun ast.Expr) string {
 	switch v := fun.(type) {
 	case *ast.SelectorExpr:
@@ -643,6 +736,16 @@ func (g *generator) recordHelperCall(call *ast.CallExpr, inLit bool, fi *funcInf
 			derivedParam{name: "limit", typ: "integer", desc: apiframework.LimitParamDescription,
 				inFuncLit: inLit, pos: call.Pos()},
 		)
+	case "PageParams":
+		if len(call.Args) != 2 {
+			return
+		}
+		fi.derived = append(fi.derived,
+			derivedParam{name: "cursor", typ: "string", desc: apiframework.PageCursorParamDescription,
+				inFuncLit: inLit, pos: call.Pos()},
+			derivedParam{name: "limit", typ: "integer", desc: apiframework.LimitParamDescription,
+				inFuncLit: inLit, pos: call.Pos()},
+		)
 	case "GetPathParam":
 		if len(call.Args) != 3 {
 			return
@@ -1158,11 +1261,19 @@ func (g *generator) paramsFor(rt route, fi, wfi *funcInfo, pathOrder []string, p
 	pathDesc := map[string]string{}
 	pathType := map[string]string{}
 	pathSeen := map[string]bool{}
-	type queryParam struct{ typ, desc, def string }
+	type queryParam struct {
+		typ, desc, def string
+		required       bool
+	}
 	querySeen := map[string]queryParam{}
 	var queryNames []string
 
+	var raw []derivedParam
 	addDerived := func(dp derivedParam) {
+		if dp.raw {
+			raw = append(raw, dp)
+			return
+		}
 		if dp.path {
 			// Attaches only where this route's template has the name; a name in
 			// no bound template at all is reported by the deferred check.
@@ -1205,10 +1316,18 @@ func (g *generator) paramsFor(rt route, fi, wfi *funcInfo, pathOrder []string, p
 			if _, ok := querySeen[ap.name]; ok {
 				continue
 			}
-			querySeen[ap.name] = queryParam{typ: ap.typ, desc: ap.desc}
+			querySeen[ap.name] = queryParam{typ: ap.typ, desc: ap.desc, required: ap.required}
 			queryNames = append(queryNames, ap.name)
 		}
 	}
+	// Raw query reads come last: an @param of the same name documents them.
+	for _, dp := range raw {
+		if _, ok := querySeen[dp.name]; ok || pathNames[dp.name] {
+			continue
+		}
+		querySeen[dp.name] = queryParam{typ: dp.typ}
+		queryNames = append(queryNames, dp.name)
+	}
 
 	var params []any
 	for _, name := range pathOrder {
@@ -1226,7 +1345,7 @@ func (g *generator) paramsFor(rt route, fi, wfi *funcInfo, pathOrder []string, p
 	sort.Strings(queryNames)
 	for _, name := range queryNames {
 		q := querySeen[name]
-		p := map[string]any{"name": name, "in": "query", "required": false}
+		p := map[string]any{"name": name, "in": "query", "required": q.required}
 		if q.desc != "" {
 			p["description"] = q.desc
 		}
@@ -1289,7 +1408,11 @@ func (g *generator) refForNamed(pkg, name string, rc refCtx) map[string]any {
 	si := g.structs[key]
 	if si == nil {
 		if a, ok := g.aliases[key]; ok {
-			return g.schemaForExpr(a.underlying, a.pkg, rc)
+			s := g.schemaForExpr(a.underlying, a.pkg, rc)
+			if vals := g.enums[key]; len(vals) > 0 && s["type"] == "string" {
+				s["enum"] = vals
+			}
+			return s
 		}
 		g.problemf(rc.pos, "cannot resolve type %q to a known struct or named alias (%s); is its package in the generator's scan list?", key, rc.what)
 		return map[string]any{"type": "object"}
@@ -1304,10 +1427,21 @@ func (g *generator) refForNamed(pkg, name string, rc refCtx) map[string]any {
 	return map[string]any{"$ref": "#/components/schemas/" + compKey}
 }
 
+// structSchema renders a struct as an object schema. A property is listed in
+// `required` when encoding/json always writes it — its json tag carries
+// neither omitempty nor omitzero — or when the field is tagged
+// `required:"true"`. Fields of an embedded struct keep their requiredness,
+// except behind an embedded pointer, which json omits wholesale when nil.
 func (g *generator) structSchema(si *structInfo, key string) map[string]any {
 	props := map[string]any{}
+	var required []string
+	require := func(name string) {
+		if name != "" && !slices.Contains(required, name) {
+			required = append(required, name)
+		}
+	}
 	for _, field := range si.st.Fields.List {
-		jsonName, incType, skip := parseTag(field.Tag)
+		jsonName, incType, optional, skip := parseTag(field.Tag)
 		if skip {
 			continue
 		}
@@ -1317,6 +1451,12 @@ func (g *generator) structSchema(si *structInfo, key string) map[string]any {
 				if ep, ok := emb["properties"].(map[string]any); ok {
 					maps.Copy(props, ep)
 				}
+				if _, ptr := field.Type.(*ast.StarExpr); !ptr {
+					er, _ := emb["required"].([]string)
+					for _, name := range er {
+						require(name)
+					}
+				}
 			}
 			continue
 		}
@@ -1328,6 +1468,9 @@ func (g *generator) structSchema(si *structInfo, key string) map[string]any {
 			if pname == "" {
 				pname = nm.Name
 			}
+			if !optional {
+				require(pname)
+			}
 			rc := refCtx{pos: field.Pos(), what: fmt.Sprintf("field %q of %s", pname, key)}
 			if incType != "" {
 				s := g.schemaForRef(incType, rc)
@@ -1345,7 +1488,11 @@ func (g *generator) structSchema(si *structInfo, key string) map[string]any {
 			}
 		}
 	}
-	return map[string]any{"type": "object", "properties": props}
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
 }
 
 // isSliceType reports whether expr is a Go slice (unwrapping a leading pointer),
@@ -1362,6 +1509,10 @@ func isSliceType(expr ast.Expr) bool {
 
 func (g *generator) embeddedSchema(expr ast.Expr, pkg, key string) map[string]any {
 	switch v := expr.(type) {
+	case *ast.StarExpr:
+		// encoding/json promotes the fields of an embedded *T exactly as it
+		// does for an embedded T.
+		return g.embeddedSchema(v.X, pkg, key)
 	case *ast.Ident:
 		if si := g.structs[pkg+"."+v.Name]; si != nil {
 			return g.structSchema(si, pkg+"."+v.Name)
@@ -1414,20 +1565,31 @@ func (g *generator) schemaForExpr(expr ast.Expr, pkg string, rc refCtx) map[stri
 	return map[string]any{"type": "object"}
 }
 
-func parseTag(tag *ast.BasicLit) (jsonName, includeType string, skip bool) {
+// parseTag reads the struct tag of one field. optional reports whether the
+// field may be absent from the JSON encoding (omitempty/omitzero) and is not
+// overridden by `required:"true"`.
+func parseTag(tag *ast.BasicLit) (jsonName, includeType string, optional, skip bool) {
 	if tag == nil {
-		return "", "", false
+		return "", "", false, false
 	}
 	st := reflect.StructTag(strings.Trim(tag.Value, "`"))
 	if j, ok := st.Lookup("json"); ok {
-		name, _, _ := strings.Cut(j, ",")
+		name, opts, _ := strings.Cut(j, ",")
 		if name == "-" {
-			return "", "", true
+			return "", "", false, true
 		}
 		jsonName = name
+		for opt := range strings.SplitSeq(opts, ",") {
+			if opt == "omitempty" || opt == "omitzero" {
+				optional = true
+			}
+		}
+	}
+	if st.Get("required") == "true" {
+		optional = false
 	}
 	includeType = st.Get("openapi_include_type")
-	return jsonName, includeType, false
+	return jsonName, includeType, optional, false
 }
 
 // handlerDesc names a route's handler for coverage-gate error messages.
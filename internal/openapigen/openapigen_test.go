@@ -220,6 +220,170 @@ func AddRoutes(mux *http.ServeMux) {
 	}
 }
 
+func TestUnit_PointerAndNestedSliceFieldTypes(t *testing.T) {
+	root := writeFixtureTree(t, map[string]string{
+		"runtime/internal/testapi/routes.go": `package testapi
+
+import "net/http"
+
+type base struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type item struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type okResponse struct {
+	*base
+	One   *item            ` + "`json:\"one\"`" + `
+	Many  []*item          ` + "`json:\"many\"`" + `
+	Grid  [][]string       ` + "`json:\"grid\"`" + `
+	ByKey map[string]*item ` + "`json:\"byKey\"`" + `
+}
+
+type thingHandler struct{}
+
+func (h *thingHandler) get(w http.ResponseWriter, r *http.Request) {
+	// @response testapi.okResponse
+}
+
+func AddRoutes(mux *http.ServeMux) {
+	h := &thingHandler{}
+	mux.HandleFunc("GET /things", h.get)
+}
+`,
+	})
+	doc, _, _ := mustGenerate(t, root)
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	props := schemas["testapi_okResponse"].(map[string]any)["properties"].(map[string]any)
+	const itemRef = "#/components/schemas/testapi_item"
+
+	if got := props["one"].(map[string]any)["$ref"]; got != itemRef {
+		t.Errorf("*item must resolve to its component, got %v", props["one"])
+	}
+	many := props["many"].(map[string]any)
+	if many["type"] != "array" || many["items"].(map[string]any)["$ref"] != itemRef {
+		t.Errorf("[]*item must be an array of the component, got %v", many)
+	}
+	grid := props["grid"].(map[string]any)
+	inner, _ := grid["items"].(map[string]any)
+	if grid["type"] != "array" || inner["type"] != "array" || inner["items"].(map[string]any)["type"] != "string" {
+		t.Errorf("[][]string must nest arrays down to string, got %v", grid)
+	}
+	byKey := props["byKey"].(map[string]any)
+	if byKey["additionalProperties"].(map[string]any)["$ref"] != itemRef {
+		t.Errorf("map[string]*item values must resolve to the component, got %v", byKey)
+	}
+	if _, ok := props["id"]; !ok {
+		t.Errorf("fields of an embedded *base must be promoted, got %v", props)
+	}
+	if _, ok := schemas["testapi_item"]; !ok {
+		t.Errorf("item component must be emitted")
+	}
+}
+
+func TestUnit_StringConstEnum(t *testing.T) {
+	root := writeFixtureTree(t, map[string]string{
+		"runtime/internal/testapi/routes.go": `package testapi
+
+import "net/http"
+
+type Color string
+
+const (
+	Red   Color = "red"
+	Green Color = "green"
+	Blue        = "blue" // untyped: not part of the enum
+)
+
+const Crimson Color = "red" // duplicate value, listed once
+
+type Size string
+
+type okResponse struct {
+	Color   Color ` + "`json:\"color\"`" + `
+	Tagged  string ` + "`json:\"tagged\" openapi_include_type:\"testapi.Color\"`" + `
+	Size    Size  ` + "`json:\"size\"`" + `
+}
+
+type thingHandler struct{}
+
+func (h *thingHandler) get(w http.ResponseWriter, r *http.Request) {
+	// @response testapi.okResponse
+}
+
+func AddRoutes(mux *http.ServeMux) {
+	h := &thingHandler{}
+	mux.HandleFunc("GET /things", h.get)
+}
+`,
+	})
+	doc, _, _ := mustGenerate(t, root)
+	props := doc["components"].(map[string]any)["schemas"].(map[string]any)["testapi_okResponse"].(map[string]any)["properties"].(map[string]any)
+	for _, field := range []string{"color", "tagged"} {
+		p := props[field].(map[string]any)
+		got, _ := json.Marshal(p["enum"])
+		if p["type"] != "string" || string(got) != `["red","green"]` {
+			t.Errorf("%s must be a string enum of the typed constants, got %v", field, p)
+		}
+	}
+	if _, ok := props["size"].(map[string]any)["enum"]; ok {
+		t.Errorf("a named type without constants must stay a plain string, got %v", props["size"])
+	}
+}
+
+func TestUnit_RequiredFromOmitempty(t *testing.T) {
+	root := writeFixtureTree(t, map[string]string{
+		"runtime/internal/testapi/routes.go": `package testapi
+
+import "net/http"
+
+type base struct {
+	ID    string ` + "`json:\"id\"`" + `
+	Notes string ` + "`json:\"notes,omitempty\"`" + `
+}
+
+type extra struct {
+	Flag bool ` + "`json:\"flag\"`" + `
+}
+
+type okResponse struct {
+	base
+	*extra
+	Name     string ` + "`json:\"name\"`" + `
+	Optional string ` + "`json:\"optional,omitempty\"`" + `
+	Zero     int    ` + "`json:\"zero,omitzero\"`" + `
+	Forced   string ` + "`json:\"forced,omitempty\" required:\"true\"`" + `
+	Untagged string
+	Renamed  string ` + "`json:\",omitempty\"`" + `
+	Hidden   string ` + "`json:\"-\"`" + `
+	ID       string ` + "`json:\"id\"`" + `
+}
+
+type thingHandler struct{}
+
+func (h *thingHandler) get(w http.ResponseWriter, r *http.Request) {
+	// @response testapi.okResponse
+}
+
+func AddRoutes(mux *http.ServeMux) {
+	h := &thingHandler{}
+	mux.HandleFunc("GET /things", h.get)
+}
+`,
+	})
+	doc, _, _ := mustGenerate(t, root)
+	schema := doc["components"].(map[string]any)["schemas"].(map[string]any)["testapi_okResponse"].(map[string]any)
+	var required []string
+	for _, r := range schema["required"].([]any) {
+		required = append(required, r.(string))
+	}
+	if got := strings.Join(required, ","); got != "id,name,forced,Untagged" {
+		t.Errorf("required = %q; want non-omitempty fields plus the forced one, in field order, deduplicated, none from behind an embedded pointer", got)
+	}
+}
+
 func TestUnit_ReceiverQualifiedAnnotationBinding(t *testing.T) {
 	root := writeFixtureTree(t, map[string]string{
 		"runtime/internal/testapi/routes.go": `package testapi
@@ -473,6 +637,43 @@ func AddRoutes(mux *http.ServeMux) {
 	}
 }
 
+func TestUnit_PageParamsExpansion(t *testing.T) {
+	root := writeFixtureTree(t, map[string]string{
+		"runtime/internal/testapi/routes.go": `package testapi
+
+import (
+	"net/http"
+
+	"example.com/apiframework"
+)
+
+type thingHandler struct{}
+
+func (h *thingHandler) list(w http.ResponseWriter, r *http.Request) {
+	// @response string
+	cursor, limit, err := apiframework.PageParams(r, 100)
+	_, _, _ = cursor, limit, err
+}
+
+func AddRoutes(mux *http.ServeMux) {
+	h := &thingHandler{}
+	mux.HandleFunc("GET /things", h.list)
+}
+`,
+	})
+	doc, _, _ := mustGenerate(t, root)
+	params, order := opParams(t, doc, "/things", "get")
+	if len(order) != 2 {
+		t.Fatalf("want cursor+limit, got %v", order)
+	}
+	if c := params["cursor"]; c == nil || c["description"] != "An optional opaque cursor, the nextCursor of the previous page, to fetch the next page of results." {
+		t.Errorf("cursor param wrong: %v", c)
+	}
+	if l := params["limit"]; l == nil || l["schema"].(map[string]any)["type"] != "integer" {
+		t.Errorf("limit param wrong: %v", l)
+	}
+}
+
 func TestUnit_GetPathParamDescriptionAttach(t *testing.T) {
 	root := writeFixtureTree(t, map[string]string{
 		"runtime/internal/testapi/routes.go": `package testapi
@@ -646,6 +847,55 @@ func AddRoutes(mux *http.ServeMux) {
 	}
 }
 
+func TestUnit_RawQueryReadDerivation(t *testing.T) {
+	root := writeFixtureTree(t, map[string]string{
+		"runtime/internal/testapi/routes.go": `package testapi
+
+import "net/http"
+
+type thingHandler struct{}
+
+func (h *thingHandler) list(w http.ResponseWriter, r *http.Request) {
+	// @response string
+	// @param limit integer required Page size.
+	_ = r.URL.Query().Get("filter")
+	_ = r.URL.Query()["tag"]
+	_ = r.URL.Query().Get("limit")
+	name := "dynamic"
+	_ = r.URL.Query().Get(name)
+	q := r.URL.Query()
+	_ = q.Get("indirect")
+}
+
+func AddRoutes(mux *http.ServeMux) {
+	h := &thingHandler{}
+	mux.HandleFunc("GET /things", h.list)
+}
+`,
+	})
+	doc, _, _ := mustGenerate(t, root)
+	params, order := opParams(t, doc, "/things", "get")
+	if strings.Join(order, ",") != "filter,limit,tag" {
+		t.Fatalf("want literal raw reads plus the annotation, sorted; got %v", order)
+	}
+	for _, name := range []string{"filter", "tag"} {
+		p := params[name]
+		if p["in"] != "query" || p["required"] != false || p["schema"].(map[string]any)["type"] != "string" {
+			t.Errorf("%s must be an optional string query param, got %v", name, p)
+		}
+		if _, hasDesc := p["description"]; hasDesc {
+			t.Errorf("raw read %s must omit description, got %v", name, p)
+		}
+	}
+	limit := params["limit"]
+	if limit["schema"].(map[string]any)["type"] != "integer" || limit["required"] != true {
+		t.Errorf("@param must override the raw read's type and requiredness, got %v", limit)
+	}
+	if limit["description"] != "Page size." {
+		t.Errorf("the required keyword must not leak into the description, got %v", limit["description"])
+	}
+}
+
 func TestUnit_DocSummaryUsed(t *testing.T) {
 	root := writeFixtureTree(t, map[string]string{
 		"runtime/internal/testapi/routes.go": `package testapi
@@ -0,0 +1,260 @@
+package libtracker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ ActivityTracker = (*MetricsTracker)(nil)
+
+// metricsDurationBuckets are the upper bounds, in seconds, of the operation
+// duration histogram. They span a cache hit through a slow model download.
+var metricsDurationBuckets = []float64{0.005, 0.025, 0.1, 0.25, 1, 2.5, 10, 30, 120, 600}
+
+// MetricsTracker is an ActivityTracker that aggregates what every tracked
+// operation reports into Prometheus metrics, so the existing tracker.Start
+// call sites — reconciliation cycles, backend syncs, downloads, task handlers,
+// model calls — become scrapeable without touching them. Metrics are labelled
+// by the operation and subject passed to Start:
+//
+//   - contenox_operations_total: operations started
+//   - contenox_operation_errors_total: operations that reported an error
+//   - contenox_operation_duration_seconds: histogram of start-to-end time
+//   - contenox_tokens_total: the last "total_tokens" an operation reported
+//     through reportChange, added when it ends. For a task's model call this
+//     is the tokenizer's estimate of the prompt and tool tokens sent, after
+//     any history shift, not the provider's billed usage
+//   - contenox_cost_total: the estimated "cost" an operation reported through
+//     reportChange, as chain runs do when a model price table is configured
+//   - contenox_queue_depth: the last "queue_depth" an operation reported
+//     through reportChange, as rate-limited tools do
+//
+// It serves them in the Prometheus text format as an http.Handler. Chain it
+// next to a logging tracker with NewChainedTracker.
+type MetricsTracker struct {
+	mu     sync.Mutex
+	series map[metricsKey]*metricsSeries
+}
+
+type metricsKey struct {
+	operation string
+	subject   string
+}
+
+type metricsSeries struct {
+	count   uint64
+	ended   uint64
+	errors  uint64
+	tokens  uint64
+	cost    float64
+	depth   int64
+	queued  bool // depth was reported
+	buckets []uint64
+	sum     float64
+}
+
+// NewMetricsTracker returns an empty MetricsTracker.
+func NewMetricsTracker() *MetricsTracker {
+	return &MetricsTracker{series: map[metricsKey]*metricsSeries{}}
+}
+
+// Start implements ActivityTracker.
+func (t *MetricsTracker) Start(
+	ctx context.Context,
+	operation string,
+	subject string,
+	kvArgs ...any,
+) (func(error), func(string, any), func()) {
+	key := metricsKey{operation: operation, subject: subject}
+	t.mu.Lock()
+	s := t.seriesLocked(key)
+	s.count++
+	t.mu.Unlock()
+
+	started := time.Now()
+	var once sync.Once
+	// tokens is the operation's last token report: a model call may report
+	// its count again after shifting history, and only the final count was
+	// sent.
+	var tokens int64
+	reportErr := func(err error) {
+		if err == nil {
+			return
+		}
+		t.mu.Lock()
+		s.errors++
+		t.mu.Unlock()
+	}
+	reportChange := func(_ string, data any) {
+		if n, ok := reportedTokens(data); ok {
+			t.mu.Lock()
+			tokens = n
+			t.mu.Unlock()
+		}
+		if c, ok := reportedCost(data); ok {
+			t.mu.Lock()
+			s.cost += c
+			t.mu.Unlock()
+		}
+		if n, ok := reportedInt(data, "queue_depth"); ok {
+			t.mu.Lock()
+			s.depth, s.queued = n, true
+			t.mu.Unlock()
+		}
+	}
+	end := func() {
+		once.Do(func() {
+			d := time.Since(started).Seconds()
+			t.mu.Lock()
+			s.ended++
+			s.sum += d
+			s.tokens += uint64(tokens)
+			for i, le := range metricsDurationBuckets {
+				if d <= le {
+					s.buckets[i]++
+				}
+			}
+			t.mu.Unlock()
+		})
+	}
+	return reportErr, reportChange, end
+}
+
+func (t *MetricsTracker) seriesLocked(key metricsKey) *metricsSeries {
+	s, ok := t.series[key]
+	if !ok {
+		s = &metricsSeries{buckets: make([]uint64, len(metricsDurationBuckets))}
+		t.series[key] = s
+	}
+	return s
+}
+
+// reportedTokens extracts a non-negative "total_tokens" count from a
+// reportChange payload.
+func reportedTokens(data any) (int64, bool) {
+	return reportedInt(data, "total_tokens")
+}
+
+// reportedInt extracts a non-negative integer field from a reportChange
+// payload.
+func reportedInt(data any, field string) (int64, bool) {
+	m, ok := data.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	switch n := m[field].(type) {
+	case int:
+		return int64(n), n >= 0
+	case int64:
+		return n, n >= 0
+	default:
+		return 0, false
+	}
+}
+
+// reportedCost extracts a positive "cost" amount from a reportChange payload.
+func reportedCost(data any) (float64, bool) {
+	m, ok := data.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	c, ok := m["cost"].(float64)
+	return c, ok && c > 0
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (t *MetricsTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = t.Write(w)
+}
+
+// Write renders the metrics in the Prometheus text exposition format, series
+// sorted by operation and subject so scrapes are stable.
+func (t *MetricsTracker) Write(w io.Writer) error {
+	t.mu.Lock()
+	keys := make([]metricsKey, 0, len(t.series))
+	snapshot := make(map[metricsKey]metricsSeries, len(t.series))
+	for k, s := range t.series {
+		keys = append(keys, k)
+		cp := *s
+		cp.buckets = append([]uint64(nil), s.buckets...)
+		snapshot[k] = cp
+	}
+	t.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].subject < keys[j].subject
+	})
+
+	var b strings.Builder
+	counter := func(name, help string, value func(metricsSeries) uint64, skipZero bool) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, k := range keys {
+			v := value(snapshot[k])
+			if skipZero && v == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "%s{%s} %d\n", name, k.labels(), v)
+		}
+	}
+	counter("contenox_operations_total", "Tracked operations started.",
+		func(s metricsSeries) uint64 { return s.count }, false)
+	counter("contenox_operation_errors_total", "Tracked operations that reported an error.",
+		func(s metricsSeries) uint64 { return s.errors }, false)
+	counter("contenox_tokens_total", "Estimated LLM prompt and tool tokens reported by tracked operations.",
+		func(s metricsSeries) uint64 { return s.tokens }, true)
+
+	const cost = "contenox_cost_total"
+	fmt.Fprintf(&b, "# HELP %s Estimated model cost reported by tracked operations.\n# TYPE %s counter\n", cost, cost)
+	for _, k := range keys {
+		if c := snapshot[k].cost; c > 0 {
+			fmt.Fprintf(&b, "%s{%s} %s\n", cost, k.labels(), strconv.FormatFloat(c, 'g', -1, 64))
+		}
+	}
+
+	const depth = "contenox_queue_depth"
+	fmt.Fprintf(&b, "# HELP %s Calls waiting in a queue, as last reported by tracked operations.\n# TYPE %s gauge\n", depth, depth)
+	for _, k := range keys {
+		if s := snapshot[k]; s.queued {
+			fmt.Fprintf(&b, "%s{%s} %d\n", depth, k.labels(), s.depth)
+		}
+	}
+
+	const hist = "contenox_operation_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Duration of tracked operations.\n# TYPE %s histogram\n", hist, hist)
+	for _, k := range keys {
+		s := snapshot[k]
+		labels := k.labels()
+		for i, le := range metricsDurationBuckets {
+			fmt.Fprintf(&b, "%s_bucket{%s,le=%q} %d\n", hist, labels, strconv.FormatFloat(le, 'g', -1, 64), s.buckets[i])
+		}
+		// Operations still running have been counted but have no duration
+		// yet; the +Inf bucket and _count cover ended ones only.
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", hist, labels, s.ended)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", hist, labels, strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", hist, labels, s.ended)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (k metricsKey) labels() string {
+	return fmt.Sprintf("operation=%s,subject=%s", quoteLabel(k.operation), quoteLabel(k.subject))
+}
+
+// quoteLabel escapes a label value per the text exposition format.
+func quoteLabel(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
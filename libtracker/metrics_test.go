@@ -0,0 +1,65 @@
+package libtracker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_MetricsTracker_AggregatesTrackedOperations(t *testing.T) {
+	m := NewMetricsTracker()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, _, end := m.Start(ctx, "sync", "backend_cycle")
+		end()
+	}
+	reportErr, _, end := m.Start(ctx, "download", "model", "model", "qwen")
+	reportErr(errors.New("connection reset"))
+	end()
+	end() // a second end must not count the duration twice
+	_, reportChange, end := m.Start(ctx, "SimpleExec", "prompt_model")
+	reportChange("token_usage", map[string]any{"messages_tokens": 30, "total_tokens": 42})
+	end()
+	_, reportChange, end = m.Start(ctx, "SimpleExec", "prompt_model")
+	reportChange("token_usage", map[string]any{"total_tokens": 900})
+	reportChange("token_usage_post_shift", map[string]any{"total_tokens": 58})
+	end()
+	_, reportChange, end = m.Start(ctx, "chain_exec", "chat-chain")
+	reportChange("chain_cost", map[string]any{"cost": 0.25})
+	reportChange("chain_cost", map[string]any{"cost": 0.5})
+	end()
+	_, reportChange, end = m.Start(ctx, "rate_limit", "slack")
+	reportChange("queue_depth", map[string]any{"queue_depth": 3, "wait": "2s"})
+	reportChange("queue_depth", map[string]any{"queue_depth": 2})
+	end()
+	_, _, _ = m.Start(ctx, "sync", "still_running")
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	body := rec.Body.String()
+
+	require.Contains(t, body, "# TYPE contenox_operations_total counter\n")
+	require.Contains(t, body, `contenox_operations_total{operation="sync",subject="backend_cycle"} 2`)
+	require.Contains(t, body, `contenox_operation_errors_total{operation="download",subject="model"} 1`)
+	require.Contains(t, body, `contenox_tokens_total{operation="SimpleExec",subject="prompt_model"} 100`, "only the final report of a call counts")
+	require.NotContains(t, body, `contenox_tokens_total{operation="sync"`, "series without tokens are omitted")
+	require.Contains(t, body, `contenox_cost_total{operation="chain_exec",subject="chat-chain"} 0.75`)
+	require.NotContains(t, body, `contenox_cost_total{operation="sync"`, "series without cost are omitted")
+	require.Contains(t, body, "# TYPE contenox_queue_depth gauge\n")
+	require.Contains(t, body, `contenox_queue_depth{operation="rate_limit",subject="slack"} 2`)
+	require.NotContains(t, body, `contenox_queue_depth{operation="sync"`, "series without a queue are omitted")
+	require.Contains(t, body, `contenox_operation_duration_seconds_count{operation="download",subject="model"} 1`)
+	require.Contains(t, body, `contenox_operation_duration_seconds_bucket{operation="sync",subject="backend_cycle",le="+Inf"} 2`)
+	require.Contains(t, body, `contenox_operations_total{operation="sync",subject="still_running"} 1`)
+	require.Contains(t, body, `contenox_operation_duration_seconds_count{operation="sync",subject="still_running"} 0`)
+}
+
+func TestUnit_MetricsTracker_EscapesLabelValues(t *testing.T) {
+	require.Equal(t, `"a\"b\\c\nd"`, quoteLabel("a\"b\\c\nd"))
+}
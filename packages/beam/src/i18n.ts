@@ -1256,6 +1256,13 @@ const resources = {
           noop: 'No Operation',
           chat_completion: 'Model Execution',
           execute_tool_calls: 'Execute Tool Calls',
+          tool_loop: 'Tool Loop',
+          truncate_history: 'Truncate History',
+          json_extract: 'JSON Extract',
+          render_template: 'Render Template',
+          parse_json_array: 'Parse JSON Array',
+          parse_bool: 'Parse Boolean',
+          detect_language: 'Detect Language',
           raise_error: 'Raise Error',
         },
         operators: {
@@ -1,9 +1,12 @@
-import { apiFetch } from './fetch';
+import { apiFetch, apiFetchBinary } from './fetch';
 import {
   Agent,
   AuthenticatedUser,
   AuthStatus,
   Backend,
+  BackendConnectionTest,
+  BackendEnsureResult,
+  BackendImportResult,
   BackendRuntimeState,
   ChainDefinition,
   CLIConfig,
@@ -22,6 +25,8 @@ import {
   MissionChangesResponse,
   MissionFileDiff,
   MissionReport,
+  MockResponse,
+  Model,
   ModeldCapacityResponse,
   ModelDescriptor,
   ModeldLoadResponse,
@@ -29,10 +34,13 @@ import {
   ModeldStatusResponse,
   ModeldUnloadResponse,
   ModelRegistryEntry,
+  ModelReport,
   OperatorInboxItem,
+  PaginatedResponse,
   PushModelResult,
   RemoteHook,
   SetupStatus,
+  SimulationResult,
   StatusResponse,
   SupportedProvider,
   TaskExecutionRequest,
@@ -304,6 +312,36 @@ export const api = {
   updateBackend: (id: string, data: Partial<Backend>) =>
     apiFetch<Backend>(`/api/backends/${id}`, options('PUT', data)),
   deleteBackend: (id: string) => apiFetch<void>(`/api/backends/${id}`, options('DELETE')),
+  /** Probes an unsaved backend configuration; nothing is stored. */
+  testBackendConnection: (data: Partial<Backend>) =>
+    apiFetch<BackendConnectionTest>('/api/backends/test', options('POST', data)),
+  /**
+   * Creates the backend or updates the one matching it — by name, or with
+   * match 'url' by type and baseUrl. Safe to call repeatedly.
+   */
+  ensureBackend: (data: Partial<Backend>, match?: 'name' | 'url') =>
+    apiFetch<BackendEnsureResult>(
+      `/api/backends/ensure${match ? `?match=${match}` : ''}`,
+      options('POST', data),
+    ),
+  /** Downloads every backend as a YAML document that importBackends accepts. */
+  exportBackends: async () =>
+    new TextDecoder().decode(await apiFetchBinary('/api/backends/export')),
+  /**
+   * Applies a YAML backends document. mode 'replace' also deletes backends
+   * the document does not list; test probes each new or changed backend first.
+   */
+  importBackends: (yaml: string, params?: { mode?: 'merge' | 'replace'; test?: boolean }) => {
+    const search = new URLSearchParams();
+    if (params?.mode) search.set('mode', params.mode);
+    if (params?.test) search.set('test', 'true');
+    const qs = search.toString() ? `?${search.toString()}` : '';
+    return apiFetch<BackendImportResult[]>(`/api/backends/import${qs}`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/yaml' },
+      body: yaml,
+    });
+  },
   /**
    * Streams a local GGUF file to a modeld backend's model store (local or
    * remote) — the HTTP twin of `contenox model push`. The file is sent as
@@ -323,6 +361,25 @@ export const api = {
       },
     ),
 
+  /**
+   * Searches the stored model records, newest first. To page, pass the
+   * previous page's nextCursor as cursor.
+   */
+  getModelRecords: (params?: {
+    prefix?: string;
+    affinityGroup?: string;
+    limit?: number;
+    cursor?: string;
+  }) => {
+    const search = new URLSearchParams();
+    if (params?.prefix) search.set('prefix', params.prefix);
+    if (params?.affinityGroup) search.set('affinityGroup', params.affinityGroup);
+    if (params?.limit !== undefined) search.set('limit', params.limit.toString());
+    if (params?.cursor) search.set('cursor', params.cursor);
+    const qs = search.toString() ? `?${search.toString()}` : '';
+    return apiFetch<PaginatedResponse<Model>>(`/api/models/records${qs}`);
+  },
+
   getSetupStatus: async (): Promise<SetupStatus> =>
     normalizeSetupStatus(await apiFetch<SetupStatus>('/api/setup-status')),
   refreshSetupStatus: async (): Promise<SetupStatus> =>
@@ -350,6 +407,9 @@ export const api = {
 
   /** Runtime sync snapshot per backend (OSS backend refresh loop; not a managed download queue). */
   getRuntimeBackendState: () => apiFetch<BackendRuntimeState[]>('/api/state'),
+  getModelReport: () => apiFetch<ModelReport[]>('/api/state/models'),
+  /** Requests a backend reconcile without waiting for it; concurrent requests coalesce. */
+  triggerReconcile: () => apiFetch<string>('/api/state/reconcile', options('POST')),
 
   /** Local modeld daemon state exposed by contenox serve, not a direct browser daemon client. */
   getModeldStatus: () => apiFetch<ModeldStatusResponse>('/api/modeld/status'),
@@ -426,6 +486,29 @@ export const api = {
     ),
   deleteChain: (path: string) =>
     apiFetch<void>(`/api/taskchains?path=${encodeURIComponent(path)}`, options('DELETE')),
+  /** YAML of one chain (path or ID), or of every chain when ref is omitted. */
+  exportChains: async (ref?: string) =>
+    new TextDecoder().decode(
+      await apiFetchBinary(
+        ref ? `/api/taskchains/export?ref=${encodeURIComponent(ref)}` : '/api/taskchains/export',
+      ),
+    ),
+  /**
+   * Validates and stores a chain, refusing unknown tools; a stored chain with
+   * the same ID is only replaced when overwrite is set.
+   */
+  importChain: (data: ChainDefinition, overwrite = false) =>
+    apiFetch<{ path: string; chain: ChainDefinition }>(
+      `/api/taskchains/import?overwrite=${overwrite}`,
+      options('POST', data),
+    ),
+  /** Runs a chain (inline, or stored under ref) with scripted model replies keyed by task ID. */
+  simulateChain: (data: {
+    chain?: ChainDefinition;
+    ref?: string;
+    input: unknown;
+    responses: Record<string, MockResponse>;
+  }) => apiFetch<SimulationResult>('/api/taskchains/simulate', options('POST', data)),
 
   // ── Model Registry ───────────────────────────────────────────────
   listModelRegistry: () => apiFetch<ModelDescriptor[]>('/api/model-registry'),
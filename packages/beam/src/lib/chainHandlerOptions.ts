@@ -20,6 +20,41 @@ export const CHAIN_HANDLER_OPTIONS: Array<{
     label: 'Execute Tool Calls',
     hint: 'Run tools that previous model call asked for',
   },
+  {
+    value: 'tool_loop',
+    label: 'Tool Loop',
+    hint: 'Chat, run requested tools, and re-prompt until a final answer',
+  },
+  {
+    value: 'truncate_history',
+    label: 'Truncate History',
+    hint: 'Drop the oldest messages until the history fits a token budget',
+  },
+  {
+    value: 'json_extract',
+    label: 'JSON Extract',
+    hint: 'Pull one value out of JSON with a JSONPath expression',
+  },
+  {
+    value: 'render_template',
+    label: 'Render Template',
+    hint: 'Emit the rendered prompt template as output, no model call',
+  },
+  {
+    value: 'parse_json_array',
+    label: 'Parse JSON Array',
+    hint: 'Parse a model reply into a JSON array; branches on the item count',
+  },
+  {
+    value: 'parse_bool',
+    label: 'Parse Boolean',
+    hint: 'Read a yes/no model reply as true or false; branches on the answer',
+  },
+  {
+    value: 'detect_language',
+    label: 'Detect Language',
+    hint: 'Model names the input language as an ISO 639-1 code; branches on the code',
+  },
   {
     value: 'tools',
     label: 'Tools',
@@ -31,6 +31,26 @@ export type Backend = {
   updatedAt?: string;
 };
 
+/** POST /api/backends/test response (see backendapi.backendConnectionTest). */
+export type BackendConnectionTest = {
+  ok: boolean;
+  detail: string;
+};
+
+/** One entry of the POST /api/backends/import response (see backendservice.ImportResult). */
+export type BackendImportResult = {
+  name: string;
+  action: 'created' | 'updated' | 'unchanged' | 'deleted' | 'failed';
+  error?: string;
+  connectionDetail?: string;
+};
+
+/** POST /api/backends/ensure response (see backendapi.backendEnsureResult). */
+export type BackendEnsureResult = {
+  action: 'created' | 'updated' | 'unchanged';
+  backend: Backend;
+};
+
 /** POST /api/backends/{id}/models/push response (see backendapi.pushModelResponse). */
 export type PushModelResult = {
   name: string;
@@ -38,6 +58,28 @@ export type PushModelResult = {
   bytesWritten?: number;
 };
 
+/** GET /api/state/models — one backend's declared-versus-observed model diff (statetype.ModelReport). */
+export type ModelReport = {
+  backendId: string;
+  backendName: string;
+  declared: string[];
+  pulled: string[];
+  /** Declared but not reported by the backend. */
+  missing: string[];
+  /** Reported by the backend but not declared. */
+  orphaned: string[];
+  error?: string;
+  /** Context window of each pulled model and whether the backend reported it. */
+  contexts?: ModelContextWindow[];
+};
+
+/** Context window reconciliation settled on for one model (statetype.ModelContextWindow). */
+export type ModelContextWindow = {
+  model: string;
+  contextLength: number;
+  source: 'detected' | 'configured';
+};
+
 /** GET /api/state — runtime-observed backend state (same shape as statetype.BackendRuntimeState JSON). */
 export type BackendRuntimeState = {
   id: string;
@@ -189,10 +231,20 @@ export type ObservedModel = {
   size?: number;
   digest?: string;
   contextLength: number;
+  /** Context window the backend reported; absent when contextLength is the configured value. */
+  detectedContextLength?: number;
   canChat: boolean;
   canEmbed: boolean;
   canPrompt: boolean;
   canStream: boolean;
+  /** Last keep-alive outcome for a declared Ollama model; absent when warm-up is off. */
+  warmUp?: ModelWarmUp;
+};
+
+export type ModelWarmUp = {
+  status: 'loaded' | 'failed';
+  error?: string;
+  at: string;
 };
 
 export type LayoutDirection = 'horizontal' | 'vertical';
@@ -933,6 +985,8 @@ export type TaskExecutionResponse = {
   output: unknown;
   outputType: string;
   state: CapturedStateUnit[];
+  /** Estimated price of the run's model calls; omitted when no model is priced. */
+  cost?: number;
 };
 
 export interface HookCall {
@@ -974,6 +1028,10 @@ export interface ChainTask {
   system_instruction?: string;
   execute_config?: ExecuteConfig;
   tools?: HookCall;
+  // truncate: budget for truncate_history tasks. See taskengine.TruncateHistoryConfig.
+  truncate?: TruncateHistoryConfig;
+  // extract: path for json_extract tasks. See taskengine.JSONExtractConfig.
+  extract?: JSONExtractConfig;
   print?: string;
   prompt_template: string;
   output_template?: string;
@@ -981,6 +1039,22 @@ export interface ChainTask {
   transition: TaskTransition;
   timeout?: string;
   retry_on_failure?: number;
+  idempotent?: boolean;
+}
+
+export interface TruncateHistoryConfig {
+  // max_tokens: token budget; defaults to the chain's token_limit.
+  max_tokens?: number;
+  // keep_turns: most recent turns never dropped (default 1).
+  keep_turns?: number;
+}
+
+export interface JSONExtractConfig {
+  // path: JSONPath expression rooted at "$".
+  path: string;
+  // use_default: yield default instead of failing when the path matches nothing.
+  use_default?: boolean;
+  default?: unknown;
 }
 
 // FormTask keeps partial but requires keys we edit frequently
@@ -1021,6 +1095,10 @@ export interface ExecuteConfig {
   provider?: string;
   providers?: string[];
   temperature?: number;
+  // top_p, presence_penalty, stop: passed to the provider; unset = provider default.
+  top_p?: number;
+  presence_penalty?: number;
+  stop?: string[];
   tools?: string[];
   hooks?: string[];
   hide_tools?: string[];
@@ -1039,6 +1117,12 @@ export interface ExecuteConfig {
   // compact_policy: mid-run conversation compaction.
   // See taskengine/compact.Policy.
   compact_policy?: CompactPolicy;
+  // max_tool_iterations: model-turn cap for tool_loop tasks (default 10).
+  max_tool_iterations?: number;
+  // inline_attachments: read attached workspace files into the prompt,
+  // up to attachment_budget bytes of text (default 32768).
+  inline_attachments?: boolean;
+  attachment_budget?: number;
 }
 
 export interface ChainDefinition {
@@ -1047,8 +1131,45 @@ export interface ChainDefinition {
   tasks: ChainTask[];
   token_limit?: number;
   debug?: boolean;
+  // partials: named templates any task's prompt_template/print can include
+  // with {{template "name" .}}.
+  partials?: Record<string, string>;
+  // provider_preference: chain-wide provider order, used by tasks whose
+  // execute_config sets neither provider nor providers.
+  provider_preference?: string[];
 }
 
+/** Scripted reply for one task in POST /api/taskchains/simulate (see taskchainservice.MockResponse). */
+export type MockResponse = {
+  content?: string;
+  // sequence: the n-th call gets sequence[n]; later calls repeat the last entry.
+  sequence?: string[];
+  error?: string;
+};
+
+/** One executed step of a simulated chain (see taskengine.CapturedStateUnit). */
+export type SimulatedStep = {
+  taskID: string;
+  taskHandler: string;
+  inputType: string;
+  outputType: string;
+  transition: string;
+  duration: number;
+  error: { error: string };
+  input?: unknown;
+  output?: unknown;
+  retryIndex: number;
+  modelName?: string;
+};
+
+/** POST /api/taskchains/simulate response (see taskchainservice.SimulationResult). */
+export type SimulationResult = {
+  output: unknown;
+  outputType: string;
+  error?: string;
+  trace: SimulatedStep[];
+};
+
 export type ActivityLog = {
   id: string;
   operation: string;
@@ -1363,7 +1484,14 @@ export type TaskHandler =
   | 'chat_completion'
   | 'execute_tool_calls'
   | 'noop'
-  | 'tools';
+  | 'tools'
+  | 'tool_loop'
+  | 'truncate_history'
+  | 'json_extract'
+  | 'render_template'
+  | 'parse_json_array'
+  | 'parse_bool'
+  | 'detect_language';
 
 export const HandleRaiseError: TaskHandler = 'raise_error';
 export const HandleRoute: TaskHandler = 'route';
@@ -1371,6 +1499,13 @@ export const HandleChatCompletion: TaskHandler = 'chat_completion';
 export const HandleExecuteToolCalls: TaskHandler = 'execute_tool_calls';
 export const HandleNoop: TaskHandler = 'noop';
 export const HandleTools: TaskHandler = 'tools';
+export const HandleToolLoop: TaskHandler = 'tool_loop';
+export const HandleTruncateHistory: TaskHandler = 'truncate_history';
+export const HandleJSONExtract: TaskHandler = 'json_extract';
+export const HandleRenderTemplate: TaskHandler = 'render_template';
+export const HandleParseJSONArray: TaskHandler = 'parse_json_array';
+export const HandleParseBool: TaskHandler = 'parse_bool';
+export const HandleDetectLanguage: TaskHandler = 'detect_language';
 
 /**
  * One allowlisted workspace root reported by `GET /workspace/roots`. Mirrors
@@ -7,6 +7,7 @@ import {
   HandleNoop,
   HandleRaiseError,
   HandleRoute,
+  HandleToolLoop,
   HandleTools,
 } from '../../../../../../lib/types';
 import LLMConfigFields from './LLMConfigFields';
@@ -35,7 +36,11 @@ export default function HandlerSpecificFields({ task, onChange }: HandlerSpecifi
   );
 
   // LLM-like
-  if (task.handler === HandleChatCompletion || task.handler === HandleExecuteToolCalls) {
+  if (
+    task.handler === HandleChatCompletion ||
+    task.handler === HandleExecuteToolCalls ||
+    task.handler === HandleToolLoop
+  ) {
     return (
       <Panel variant="surface" className="p-4">
         <LLMConfigFields task={task} onChange={onChange} expanded />
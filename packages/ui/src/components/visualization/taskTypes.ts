@@ -80,4 +80,6 @@ export type CapturedStateUnit = {
   modelName?: string;
   toolNames?: string[];
   tokenUsage?: TokenUsage;
+  /** Estimated price of the step's model call; set only when a price table covers the model. */
+  cost?: number;
 };
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -91,6 +92,21 @@ func (t *Transport) handleCompact(ctx context.Context, _ libacp.SessionID, sess
 	return fmt.Sprintf("Compacted %d messages to %d (kept last %d).", len(history), len(compacted), keep), nil
 }
 
+// autoCompactChain returns the compaction chain when Deps.AutoCompact is on.
+// It is read per turn, like /compact reads it, so an edited chain applies
+// without a restart; when it cannot be loaded the turn runs uncompacted.
+func (t *Transport) autoCompactChain() *taskengine.TaskChainDefinition {
+	if !t.deps.AutoCompact.Enabled() {
+		return nil
+	}
+	chain, err := t.loadCompactChain()
+	if err != nil {
+		slog.Warn("acpsvc: auto-compact is on but the compaction chain is unavailable; history is not compacted", "error", err)
+		return nil
+	}
+	return chain
+}
+
 // loadCompactChain reads chain-compact.json from the active .contenox directory,
 // falling back to ~/.contenox. It does not import the CLI's resolver (that would
 // create an import cycle); this is a plain file lookup, not duplicated logic.
@@ -214,6 +214,8 @@ func (d *nativeDriver) Prompt(ctx context.Context, req libacp.PromptRequest, ses
 		TemplateVars:   templateVars,
 		ToolsAllowlist: toolsAllowlist,
 		ContextLength:  contextLen,
+		AutoCompact:    t.deps.AutoCompact,
+		CompactChain:   t.autoCompactChain(),
 	})
 	if err != nil {
 		// Distinguish a genuine user cancellation from an execution failure that
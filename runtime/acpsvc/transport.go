@@ -10,6 +10,7 @@ import (
 	libdb "github.com/contenox/runtime/libdbexec"
 	"github.com/contenox/runtime/libtracker"
 	"github.com/contenox/runtime/runtime/agentinstance"
+	"github.com/contenox/runtime/runtime/chatservice"
 	"github.com/contenox/runtime/runtime/enginesvc"
 	"github.com/contenox/runtime/runtime/internal/clikv"
 	"github.com/contenox/runtime/runtime/runtimetypes"
@@ -32,6 +33,10 @@ type Deps struct {
 	// ContenoxDir is the active .contenox directory, used to locate auxiliary
 	// chains (e.g. chain-compact.json for the /compact command).
 	ContenoxDir string
+	// AutoCompact, when enabled, compacts a session's history with
+	// chain-compact.json after each turn that crosses its threshold (the
+	// auto-compact config key). The zero value leaves histories alone.
+	AutoCompact chatservice.AutoCompactPolicy
 
 	// WorkspaceRoots is the allowlist of directories a client may choose as a
 	// session's workspace (its cwd). When nil, no allowlist is enforced and any
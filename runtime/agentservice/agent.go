@@ -12,7 +12,9 @@ import (
 	"github.com/contenox/runtime/libtracker"
 	"github.com/contenox/runtime/runtime/chatservice"
 	"github.com/contenox/runtime/runtime/enginesvc"
+	"github.com/contenox/runtime/runtime/internal/clikv"
 	"github.com/contenox/runtime/runtime/messagestore"
+	"github.com/contenox/runtime/runtime/promptservice"
 	"github.com/contenox/runtime/runtime/runtimetypes"
 	"github.com/contenox/runtime/runtime/sessionservice"
 	"github.com/contenox/runtime/runtime/taskengine"
@@ -142,6 +144,25 @@ func (a *agent) tracker() libtracker.ActivityTracker {
 	return libtracker.NoopTracker{}
 }
 
+// resolvePrompts returns chain with its prompt library references
+// ({{prompt "name"}}) replaced by the prompts' text. The caller's chain is
+// not modified, so a chain read from storage keeps its references.
+func (a *agent) resolvePrompts(ctx context.Context, chain *taskengine.TaskChainDefinition) (*taskengine.TaskChainDefinition, error) {
+	refs, err := taskengine.PromptRefs(chain)
+	if err != nil || len(refs) == 0 {
+		return chain, err
+	}
+	if a.deps.DB == nil {
+		return nil, fmt.Errorf("chain %q references the prompt library, which needs a database", chain.ID)
+	}
+	resolved := *chain
+	resolved.Tasks = append([]taskengine.TaskDefinition(nil), chain.Tasks...)
+	if err := promptservice.New(a.deps.DB, nil).Resolve(ctx, &resolved); err != nil {
+		return nil, fmt.Errorf("chain %q: %w", chain.ID, err)
+	}
+	return &resolved, nil
+}
+
 func (a *agent) Prompt(ctx context.Context, req PromptRequest) (*PromptResponse, error) {
 	promptReportErr, _, promptEnd := a.tracker().Start(ctx, "execute", "prompt", "sessionID", req.SessionID)
 	defer promptEnd()
@@ -151,7 +172,11 @@ func (a *agent) Prompt(ctx context.Context, req PromptRequest) (*PromptResponse,
 		promptReportErr(err)
 		return nil, err
 	}
-	chain := req.Chain
+	chain, err := a.resolvePrompts(ctx, req.Chain)
+	if err != nil {
+		promptReportErr(err)
+		return nil, err
+	}
 
 	templateVars := req.TemplateVars
 	if templateVars == nil {
@@ -163,10 +188,12 @@ func (a *agent) Prompt(ctx context.Context, req PromptRequest) (*PromptResponse,
 	if req.ToolsAllowlist != nil {
 		ctx = taskengine.WithRuntimeToolsAllowlist(ctx, req.ToolsAllowlist)
 	}
+	if prices := a.priceTable(ctx); prices != nil {
+		ctx = taskengine.WithPriceTable(ctx, prices)
+	}
 
 	var inputVal any
 	var inputType taskengine.DataType
-	var err error
 	if req.InputValue != nil {
 		inputVal = req.InputValue
 		inputType = req.InputType
@@ -206,6 +233,7 @@ func (a *agent) Prompt(ctx context.Context, req PromptRequest) (*PromptResponse,
 
 		if !isPoisonPill {
 			a.persistHistory(ctx, req.SessionID, inputVal, stateUnits, execErr, req.ChainRef)
+			a.autoCompact(ctx, req, templateVars)
 		}
 	}
 
@@ -221,6 +249,24 @@ func (a *agent) Prompt(ctx context.Context, req PromptRequest) (*PromptResponse,
 	return resp, nil
 }
 
+// priceTable loads the "model-prices" config value. A malformed table is
+// reported and ignored: a bad price list must not stop chains from running,
+// it only leaves their cost unestimated.
+func (a *agent) priceTable(ctx context.Context) taskengine.PriceTable {
+	if a.deps.DB == nil {
+		return nil
+	}
+	raw := clikv.Read(ctx, runtimetypes.New(a.deps.DB.WithoutTransaction()), "model-prices")
+	prices, err := taskengine.ParsePriceTable(raw)
+	if err != nil {
+		reportErr, _, end := a.tracker().Start(ctx, "load", "model_prices")
+		reportErr(err)
+		end()
+		return nil
+	}
+	return prices
+}
+
 func (a *agent) startObserving(ctx context.Context, obs Observer) func() {
 	bus := a.deps.Engine.Bus
 	if bus == nil {
@@ -381,6 +427,33 @@ func (a *agent) persistHistory(ctx context.Context, sessionID string, input any,
 	persistReportChange(sessionID, len(synthesized))
 }
 
+// autoCompact applies req.AutoCompact to the session after a turn. It runs the
+// compaction chain with the turn's template vars, pointed at CompactChain.
+func (a *agent) autoCompact(ctx context.Context, req PromptRequest, templateVars map[string]string) {
+	if !req.AutoCompact.Enabled() || req.CompactChain == nil {
+		return
+	}
+	cleanCtx := context.WithoutCancel(ctx)
+	reportErr, reportChange, end := a.tracker().Start(cleanCtx, "compact", "chat_history", "sessionID", req.SessionID)
+	defer end()
+
+	vars := make(map[string]string, len(templateVars))
+	for k, v := range templateVars {
+		vars[k] = v
+	}
+	vars["chain"] = req.CompactChain.ID
+	execCtx := taskengine.WithTemplateVars(cleanCtx, vars)
+
+	compacted, err := a.chatMgr().AutoCompact(execCtx, a.deps.DB, a.deps.Engine.TaskService, req.CompactChain, req.SessionID, req.AutoCompact)
+	if err != nil {
+		reportErr(fmt.Errorf("auto-compact: %w", err))
+		return
+	}
+	if compacted {
+		reportChange(req.SessionID, req.AutoCompact)
+	}
+}
+
 func AgentsMDMessage(content, path string) taskengine.Message {
 	return taskengine.Message{
 		ID:        uuid.NewString(),
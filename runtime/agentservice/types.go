@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/contenox/runtime/runtime/chatservice"
 	"github.com/contenox/runtime/runtime/taskengine"
 )
 
@@ -29,6 +30,14 @@ type PromptRequest struct {
 	// ChainRef is the chain path used for this turn (e.g. "default-chain.json").
 	// Stamped onto persisted messages as turn provenance; not used for execution.
 	ChainRef string
+
+	// AutoCompact, when enabled and CompactChain is set, compacts the
+	// session's stored history with CompactChain once the turn is persisted
+	// and the history crosses the policy's threshold. A failed compaction is
+	// reported to the tracker and leaves the history as it was; the turn
+	// itself still succeeds.
+	AutoCompact  chatservice.AutoCompactPolicy
+	CompactChain *taskengine.TaskChainDefinition
 }
 
 type PromptResponse struct {
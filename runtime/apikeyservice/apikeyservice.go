@@ -0,0 +1,327 @@
+// Package apikeyservice manages the API keys serve accepts next to its TOKEN.
+// Each key carries the identity requests presenting it are attributed to and,
+// optionally, per-resource scopes (see apiframework.Scope). Keys come from
+// two places: the API_KEYS setting, fixed for the life of the process, and
+// keys created over the API, which are stored and survive restarts. The
+// service keeps an apiframework.KeySet holding both, which serve's API gate
+// enforces.
+//
+// Created keys are stored as runtimetypes KV records under one prefix, one
+// record per key. The secret itself is not stored, only its SHA-256: it is
+// returned once, by Create.
+package apikeyservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contenox/runtime/apiframework"
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/google/uuid"
+)
+
+// keyKVPrefix namespaces stored keys in the KV store; each key is stored at
+// keyKVPrefix+id.
+const keyKVPrefix = "api_key:"
+
+// scanPageSize bounds one page of the key prefix scan.
+const scanPageSize = 200
+
+// Key sources, as Key.Source reports them.
+const (
+	SourceConfig = "config"
+	SourceStore  = "store"
+)
+
+// Key describes an API key without its secret.
+type Key struct {
+	ID       string `json:"id" example:"6f1c2b0e-7a51-4d0c-9f3e-2a8b1d4c5e6f"`
+	Identity string `json:"identity" example:"ci"`
+	// Scopes limits what the key may do; omitted, the key is unrestricted.
+	Scopes    []apiframework.Scope `json:"scopes,omitempty"`
+	ExpiresAt *time.Time           `json:"expiresAt,omitempty" example:"2027-01-01T00:00:00Z"`
+	// CreatedAt is zero, and omitted, for configured keys.
+	CreatedAt time.Time `json:"createdAt,omitzero" example:"2026-01-01T00:00:00Z"`
+	// Source is "config" for keys from API_KEYS, which cannot be revoked over
+	// the API, and "store" for keys created over it.
+	Source string `json:"source" example:"store"`
+}
+
+// CreateRequest describes a key to create.
+type CreateRequest struct {
+	Identity string `json:"identity" example:"ci"`
+	// Scopes limits what the key may do. Omitted, the key is unrestricted;
+	// an empty list grants nothing.
+	Scopes    []apiframework.Scope `json:"scopes,omitempty"`
+	ExpiresAt *time.Time           `json:"expiresAt,omitempty" example:"2027-01-01T00:00:00Z"`
+}
+
+// CreatedKey is a new key and its secret, which is not shown again.
+type CreatedKey struct {
+	Key
+	Secret string `json:"key" example:"3f9a0c..."`
+}
+
+type Service interface {
+	// Create stores a new key and returns it with its secret. A caller
+	// holding a scoped key may only create keys within its own scopes.
+	Create(ctx context.Context, req *CreateRequest) (*CreatedKey, error)
+	// List returns every key, configured and stored, without secrets,
+	// ordered by identity then ID.
+	List(ctx context.Context) ([]Key, error)
+	// Revoke deletes a stored key; it stops working at once. Configured
+	// keys cannot be revoked this way.
+	Revoke(ctx context.Context, id string) error
+	// Keys is the set the API gate enforces; the service keeps it current
+	// as keys are created and revoked.
+	Keys() *apiframework.KeySet
+}
+
+// keyRecord is the stored form of a created key.
+type keyRecord struct {
+	ID        string               `json:"id"`
+	Identity  string               `json:"identity"`
+	KeyHash   string               `json:"keyHash"`
+	Scopes    []apiframework.Scope `json:"scopes,omitempty"`
+	ExpiresAt *time.Time           `json:"expiresAt,omitempty"`
+	CreatedAt time.Time            `json:"createdAt"`
+}
+
+func (rec *keyRecord) apiKey() apiframework.APIKey {
+	k := apiframework.APIKey{KeyHash: rec.KeyHash, Identity: rec.Identity, Scopes: rec.Scopes}
+	if rec.ExpiresAt != nil {
+		k.ExpiresAt = *rec.ExpiresAt
+	}
+	return k
+}
+
+type service struct {
+	db     libdb.DBManager
+	static []ConfigKey
+	keys   *apiframework.KeySet
+	// mu serializes changes so the set is reloaded in the order they
+	// happen.
+	mu sync.Mutex
+}
+
+// New returns the key service over db, with static the keys API_KEYS
+// configures. It loads the stored keys into Keys before returning.
+func New(ctx context.Context, db libdb.DBManager, static []ConfigKey) (Service, error) {
+	s := &service{db: db, static: static, keys: apiframework.NewKeySet()}
+	if err := s.reload(ctx); err != nil {
+		return nil, fmt.Errorf("load API keys: %w", err)
+	}
+	return s, nil
+}
+
+func (s *service) store() runtimetypes.Store {
+	return runtimetypes.New(s.db.WithoutTransaction())
+}
+
+func (s *service) Keys() *apiframework.KeySet {
+	return s.keys
+}
+
+func (s *service) Create(ctx context.Context, req *CreateRequest) (*CreatedKey, error) {
+	if req == nil {
+		return nil, fmt.Errorf("key is required %w", errdefs.ErrBadRequest)
+	}
+	identity := strings.TrimSpace(req.Identity)
+	if identity == "" {
+		return nil, fmt.Errorf("key identity is required %w", errdefs.ErrBadRequest)
+	}
+	if err := validateScopes(req.Scopes); err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("key expiresAt must be in the future %w", errdefs.ErrBadRequest)
+	}
+	if err := checkGrant(ctx, req.Scopes); err != nil {
+		return nil, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	rec := &keyRecord{
+		ID:        uuid.NewString(),
+		Identity:  identity,
+		KeyHash:   apiframework.HashAPIKey(secret),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: time.Now().UTC(),
+	}
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal API key: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.store().SetKV(ctx, keyKVPrefix+rec.ID, raw); err != nil {
+		return nil, err
+	}
+	if err := s.reloadLocked(ctx); err != nil {
+		return nil, err
+	}
+	return &CreatedKey{Key: rec.view(), Secret: secret}, nil
+}
+
+func (s *service) List(ctx context.Context) ([]Key, error) {
+	recs, err := s.stored(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]Key, 0, len(s.static)+len(recs))
+	for _, k := range s.static {
+		keys = append(keys, k.view())
+	}
+	for _, rec := range recs {
+		keys = append(keys, rec.view())
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Identity != keys[j].Identity {
+			return keys[i].Identity < keys[j].Identity
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+func (s *service) Revoke(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return fmt.Errorf("key id is required %w", errdefs.ErrBadRequest)
+	}
+	for _, k := range s.static {
+		if k.ID == id {
+			return apiframework.Conflict(fmt.Sprintf("API key %q is configured by API_KEYS; remove it there", id))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rec keyRecord
+	if err := s.store().GetKV(ctx, keyKVPrefix+id, &rec); err != nil {
+		if errors.Is(err, libdb.ErrNotFound) {
+			return fmt.Errorf("API key %q: %w", id, libdb.ErrNotFound)
+		}
+		return err
+	}
+	if err := s.store().DeleteKV(ctx, keyKVPrefix+id); err != nil {
+		return err
+	}
+	return s.reloadLocked(ctx)
+}
+
+func (s *service) reload(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reloadLocked(ctx)
+}
+
+// reloadLocked replaces the enforced set with the configured keys and the
+// stored ones. s.mu must be held.
+func (s *service) reloadLocked(ctx context.Context) error {
+	recs, err := s.stored(ctx)
+	if err != nil {
+		return err
+	}
+	keys := make([]apiframework.APIKey, 0, len(s.static)+len(recs))
+	for _, k := range s.static {
+		keys = append(keys, k.APIKey)
+	}
+	for _, rec := range recs {
+		keys = append(keys, rec.apiKey())
+	}
+	s.keys.Replace(keys...)
+	return nil
+}
+
+func (s *service) stored(ctx context.Context) ([]*keyRecord, error) {
+	var recs []*keyRecord
+	var cursor *time.Time
+	for {
+		kvs, err := s.store().ListKVPrefix(ctx, keyKVPrefix, cursor, scanPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range kvs {
+			var rec keyRecord
+			if err := json.Unmarshal(kv.Value, &rec); err != nil {
+				return nil, fmt.Errorf("API key %q: %w", strings.TrimPrefix(kv.Key, keyKVPrefix), err)
+			}
+			recs = append(recs, &rec)
+		}
+		if len(kvs) < scanPageSize {
+			break
+		}
+		next := kvs[len(kvs)-1].CreatedAt
+		if cursor != nil && !next.Before(*cursor) {
+			break
+		}
+		cursor = &next
+	}
+	return recs, nil
+}
+
+func (rec *keyRecord) view() Key {
+	return Key{
+		ID:        rec.ID,
+		Identity:  rec.Identity,
+		Scopes:    rec.Scopes,
+		ExpiresAt: rec.ExpiresAt,
+		CreatedAt: rec.CreatedAt,
+		Source:    SourceStore,
+	}
+}
+
+// checkGrant refuses to create a key with more access than the caller's own
+// key, so a scoped key cannot mint an unrestricted one.
+func checkGrant(ctx context.Context, scopes []apiframework.Scope) error {
+	caller := apiframework.ScopesFromContext(ctx)
+	if caller == nil {
+		return nil
+	}
+	if scopes == nil {
+		return apiframework.Forbidden("a scoped API key cannot create an unrestricted key")
+	}
+	for _, sc := range scopes {
+		if !apiframework.Allows(caller, sc.Resource, sc.Access) {
+			return apiframework.Forbidden(fmt.Sprintf("API key lacks %s access to %s to grant it", sc.Access, sc.Resource))
+		}
+	}
+	return nil
+}
+
+func validateScopes(scopes []apiframework.Scope) error {
+	for i, sc := range scopes {
+		if strings.TrimSpace(sc.Resource) == "" {
+			return fmt.Errorf("scope %d: resource is required %w", i, errdefs.ErrBadRequest)
+		}
+		if sc.Access != apiframework.AccessRead && sc.Access != apiframework.AccessManage {
+			return fmt.Errorf("scope %d: access must be %q or %q %w", i, apiframework.AccessRead, apiframework.AccessManage, errdefs.ErrBadRequest)
+		}
+	}
+	return nil
+}
+
+// generateSecret returns a fresh 24-byte (48 hex char) random key, the same
+// shape as the serve token.
+func generateSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,93 @@
+package apikeyservice
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/contenox/runtime/apiframework"
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func setupKeyDB(t *testing.T) (context.Context, libdb.DBManager) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "keys.db"), runtimetypes.SchemaSQLite)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return ctx, db
+}
+
+func TestUnit_APIKeyService_CreateListRevoke(t *testing.T) {
+	ctx, db := setupKeyDB(t)
+	static, err := ParseConfigKeys(`[{"identity": "ops", "key": "ops-key"}]`)
+	require.NoError(t, err)
+	svc, err := New(ctx, db, static)
+	require.NoError(t, err)
+
+	created, err := svc.Create(ctx, &CreateRequest{
+		Identity: "dash",
+		Scopes:   []apiframework.Scope{{Resource: "files", Access: apiframework.AccessRead}},
+	})
+	require.NoError(t, err)
+	identity, ok := svc.Keys().Resolve(created.Secret)
+	require.True(t, ok)
+	require.Equal(t, "dash", identity)
+	identity, ok = svc.Keys().Resolve("ops-key")
+	require.True(t, ok)
+	require.Equal(t, "ops", identity)
+
+	keys, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, Key{ID: created.ID, Identity: "dash", Scopes: created.Scopes, CreatedAt: created.CreatedAt, Source: SourceStore}, keys[0])
+	require.Equal(t, Key{ID: "config-1", Identity: "ops", Source: SourceConfig}, keys[1])
+
+	// A restart loads the stored key again.
+	restarted, err := New(ctx, db, nil)
+	require.NoError(t, err)
+	_, ok = restarted.Keys().Resolve(created.Secret)
+	require.True(t, ok)
+
+	require.NoError(t, svc.Revoke(ctx, created.ID))
+	_, ok = svc.Keys().Resolve(created.Secret)
+	require.False(t, ok, "a revoked key must stop resolving at once")
+	require.ErrorIs(t, svc.Revoke(ctx, created.ID), libdb.ErrNotFound)
+	require.ErrorIs(t, svc.Revoke(ctx, "config-1"), apiframework.ErrConflict)
+}
+
+func TestUnit_APIKeyService_ScopedCallerCannotEscalate(t *testing.T) {
+	ctx, db := setupKeyDB(t)
+	svc, err := New(ctx, db, nil)
+	require.NoError(t, err)
+
+	caller := apiframework.WithIdentity(ctx, "dash", []apiframework.Scope{
+		{Resource: "api-keys", Access: apiframework.AccessManage},
+		{Resource: "files", Access: apiframework.AccessRead},
+	})
+	_, err = svc.Create(caller, &CreateRequest{Identity: "other"})
+	require.ErrorIs(t, err, apiframework.ErrForbidden, "a scoped key cannot mint an unrestricted one")
+	_, err = svc.Create(caller, &CreateRequest{Identity: "other", Scopes: []apiframework.Scope{{Resource: "files", Access: apiframework.AccessManage}}})
+	require.ErrorIs(t, err, apiframework.ErrForbidden)
+	_, err = svc.Create(caller, &CreateRequest{Identity: "other", Scopes: []apiframework.Scope{{Resource: "files", Access: apiframework.AccessRead}}})
+	require.NoError(t, err)
+}
+
+func TestUnit_ParseConfigKeys_Rejects(t *testing.T) {
+	keys, err := ParseConfigKeys("")
+	require.NoError(t, err)
+	require.Nil(t, keys)
+
+	for _, raw := range []string{
+		`{"identity": "ci"}`,
+		`[{"identity": "ci"}]`,
+		`[{"identity": "ci", "key": "k", "scopes": [{"resource": "files", "access": "write"}]}]`,
+		`[{"id": "a", "identity": "ci", "key": "k"}, {"id": "a", "identity": "cd", "key": "l"}]`,
+		`[{"identity": "ci", "key": "k", "secret": "x"}]`,
+	} {
+		_, err := ParseConfigKeys(raw)
+		require.Error(t, err, raw)
+	}
+}
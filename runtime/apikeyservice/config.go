@@ -0,0 +1,81 @@
+package apikeyservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/contenox/runtime/apiframework"
+)
+
+// ConfigKey is one key of the API_KEYS setting.
+type ConfigKey struct {
+	// ID names the key in listings; it defaults to "config-N", N counting
+	// the keys of the setting from 1.
+	ID string
+	apiframework.APIKey
+}
+
+func (k ConfigKey) view() Key {
+	v := Key{ID: k.ID, Identity: k.Identity, Scopes: k.Scopes, Source: SourceConfig}
+	if !k.ExpiresAt.IsZero() {
+		expires := k.ExpiresAt
+		v.ExpiresAt = &expires
+	}
+	return v
+}
+
+// ParseConfigKeys parses the API_KEYS setting: a JSON array of keys, e.g.
+//
+//	[{"identity": "ci", "key": "…", "scopes": [{"resource": "files", "access": "read"}]}]
+//
+// key and identity are required. scopes limits the key as
+// apiframework.Scope does, omitted meaning unrestricted; expires_at (RFC
+// 3339) ends its validity; id names it in listings. Empty raw yields no keys.
+func ParseConfigKeys(raw string) ([]ConfigKey, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var decoded []struct {
+		ID        string               `json:"id"`
+		Key       string               `json:"key"`
+		Identity  string               `json:"identity"`
+		Scopes    []apiframework.Scope `json:"scopes"`
+		ExpiresAt time.Time            `json:"expires_at"`
+	}
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("must be a JSON array of {id, key, identity, scopes, expires_at}: %w", err)
+	}
+	keys := make([]ConfigKey, 0, len(decoded))
+	seen := map[string]bool{}
+	for i, d := range decoded {
+		id := strings.TrimSpace(d.ID)
+		if id == "" {
+			id = "config-" + strconv.Itoa(i+1)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("key %q: id is used twice", id)
+		}
+		seen[id] = true
+		if strings.TrimSpace(d.Key) == "" || strings.TrimSpace(d.Identity) == "" {
+			return nil, fmt.Errorf("key %q: key and identity are required", id)
+		}
+		if err := validateScopes(d.Scopes); err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		keys = append(keys, ConfigKey{
+			ID: id,
+			APIKey: apiframework.APIKey{
+				Key:       strings.TrimSpace(d.Key),
+				Identity:  strings.TrimSpace(d.Identity),
+				Scopes:    d.Scopes,
+				ExpiresAt: d.ExpiresAt,
+			},
+		})
+	}
+	return keys, nil
+}
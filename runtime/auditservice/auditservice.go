@@ -0,0 +1,258 @@
+// Package auditservice is the audit trail: a durable record of who created,
+// updated or deleted what through the API. Entries are written by the
+// tracker NewTracker returns, which sits over the activity tracker the
+// operation is reported to anyway, and read back with List, filtered by
+// identity, resource and time range.
+//
+// Entries are stored as runtimetypes KV records under one prefix, one record
+// per entry, and are never updated. An entry's Timestamp is the creation
+// time of its record, so listings come back newest first in the order the
+// KV prefix scan returns them. Entries older than the retention period are
+// deleted as new ones are recorded.
+package auditservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/google/uuid"
+)
+
+// auditKVPrefix namespaces audit entries in the KV store; each entry is
+// stored at auditKVPrefix+id.
+const auditKVPrefix = "audit_entry:"
+
+// scanPageSize bounds one page of the audit prefix scan.
+const scanPageSize = 200
+
+// DefaultRetention is how long the audit trail keeps an entry unless New is
+// given WithRetention.
+const DefaultRetention = 90 * 24 * time.Hour
+
+// pruneInterval is how often Record deletes the entries past retention.
+const pruneInterval = time.Hour
+
+// Outcomes, as Entry.Outcome reports them.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Entry is one audited operation.
+type Entry struct {
+	ID string `json:"id" example:"0b7c1f4e-2d1a-4c1e-9a51-6f3e2b8d4c7a"`
+	// Identity is who performed the operation: the API key's identity, the
+	// local operator for the TOKEN, or "anonymous" when serve runs without
+	// a TOKEN.
+	Identity string `json:"identity" example:"ci"`
+	// Operation is "create", "update" or "delete".
+	Operation    string `json:"operation" example:"update"`
+	ResourceType string `json:"resourceType" example:"prompts"`
+	// ResourceID is empty when the operation names no single resource.
+	ResourceID string    `json:"resourceId,omitempty" example:"ticket-triage"`
+	RequestID  string    `json:"requestId,omitempty" example:"9f2c4b1a7e3d5f60"`
+	Outcome    string    `json:"outcome" example:"success"`
+	Error      string    `json:"error,omitempty" example:""`
+	Timestamp  time.Time `json:"timestamp" example:"2026-01-01T00:00:00Z"`
+}
+
+// Query narrows List. Empty fields match everything; Since is inclusive and
+// Until exclusive.
+type Query struct {
+	Identity     string
+	ResourceType string
+	ResourceID   string
+	Since        time.Time
+	Until        time.Time
+}
+
+func (q *Query) matches(e *Entry) bool {
+	return (q.Identity == "" || e.Identity == q.Identity) &&
+		(q.ResourceType == "" || e.ResourceType == q.ResourceType) &&
+		(q.ResourceID == "" || e.ResourceID == q.ResourceID)
+}
+
+// Cursor is the position after which List resumes: the Timestamp and ID of
+// the last entry of the previous page.
+type Cursor struct {
+	Timestamp time.Time
+	ID        string
+}
+
+type Service interface {
+	// Record stores e, setting its ID. Its Timestamp is the time it was
+	// stored, as List reports it.
+	Record(ctx context.Context, e *Entry) error
+	// List returns up to limit entries matching q, newest first, after the
+	// cursor when one is given.
+	List(ctx context.Context, q Query, after *Cursor, limit int) ([]*Entry, error)
+}
+
+type service struct {
+	db        libdb.DBManager
+	retention time.Duration
+
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+}
+
+// Option configures the audit trail New returns.
+type Option func(*service)
+
+// WithRetention sets how long an entry is kept; a non-positive retention
+// keeps DefaultRetention.
+func WithRetention(retention time.Duration) Option {
+	return func(s *service) {
+		if retention > 0 {
+			s.retention = retention
+		}
+	}
+}
+
+// New returns the audit trail stored in db.
+func New(db libdb.DBManager, opts ...Option) Service {
+	s := &service{db: db, retention: DefaultRetention}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *service) store() runtimetypes.Store {
+	return runtimetypes.New(s.db.WithoutTransaction())
+}
+
+func (s *service) Record(ctx context.Context, e *Entry) error {
+	if e == nil {
+		return fmt.Errorf("audit entry is required %w", errdefs.ErrBadRequest)
+	}
+	if strings.TrimSpace(e.Operation) == "" || strings.TrimSpace(e.ResourceType) == "" {
+		return fmt.Errorf("audit entry needs an operation and a resource type %w", errdefs.ErrBadRequest)
+	}
+	e.ID = uuid.NewString()
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal audit entry: %w", err)
+	}
+	if err := s.store().SetKV(ctx, auditKVPrefix+e.ID, raw); err != nil {
+		return err
+	}
+	s.pruneIfDue(ctx)
+	return nil
+}
+
+// pruneIfDue deletes the entries past retention when the last prune is more
+// than pruneInterval, or the retention if shorter, ago. A failed prune is
+// logged and retried on a later Record; the entry is already stored.
+func (s *service) pruneIfDue(ctx context.Context) {
+	s.pruneMu.Lock()
+	defer s.pruneMu.Unlock()
+	now := time.Now().UTC()
+	if now.Sub(s.lastPrune) < min(pruneInterval, s.retention) {
+		return
+	}
+	if err := s.prune(ctx, now.Add(-s.retention)); err != nil {
+		slog.Error("auditservice: prune expired entries failed", "error", err)
+		return
+	}
+	s.lastPrune = now
+}
+
+// prune deletes every entry recorded before cutoff.
+func (s *service) prune(ctx context.Context, cutoff time.Time) error {
+	for {
+		kvs, err := s.store().ListKVPrefix(ctx, auditKVPrefix, &cutoff, scanPageSize)
+		if err != nil {
+			return err
+		}
+		for _, kv := range kvs {
+			if err := s.store().DeleteKV(ctx, kv.Key); err != nil {
+				return fmt.Errorf("delete audit entry %q: %w", strings.TrimPrefix(kv.Key, auditKVPrefix), err)
+			}
+		}
+		if len(kvs) < scanPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *service) List(ctx context.Context, q Query, after *Cursor, limit int) ([]*Entry, error) {
+	if limit <= 0 {
+		return []*Entry{}, nil
+	}
+	var start *time.Time
+	if !q.Until.IsZero() {
+		start = &q.Until
+	}
+	// The prefix scan returns records created strictly before its cursor, so
+	// resuming after an entry starts one nanosecond past its timestamp and
+	// passes over what was already returned by ID. That keeps entries sharing
+	// a timestamp from being lost at a page boundary.
+	var skip *Cursor
+	if after != nil && (start == nil || after.Timestamp.Before(*start)) {
+		next := after.Timestamp.Add(time.Nanosecond)
+		start, skip = &next, after
+	}
+	return s.scan(ctx, q, start, skip, limit)
+}
+
+// scan walks the prefix from cursor, newest first, collecting up to limit
+// entries matching q. Entries at or before skip in scan order are passed
+// over.
+func (s *service) scan(ctx context.Context, q Query, cursor *time.Time, skip *Cursor, limit int) ([]*Entry, error) {
+	entries := []*Entry{}
+	for {
+		kvs, err := s.store().ListKVPrefix(ctx, auditKVPrefix, cursor, scanPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range kvs {
+			if !q.Since.IsZero() && kv.CreatedAt.Before(q.Since) {
+				return entries, nil
+			}
+			var e Entry
+			if err := json.Unmarshal(kv.Value, &e); err != nil {
+				return nil, fmt.Errorf("audit entry %q: %w", strings.TrimPrefix(kv.Key, auditKVPrefix), err)
+			}
+			e.Timestamp = kv.CreatedAt
+			if skip != nil && !follows(&e, skip) {
+				continue
+			}
+			if !q.matches(&e) {
+				continue
+			}
+			entries = append(entries, &e)
+			if len(entries) == limit {
+				return entries, nil
+			}
+		}
+		if len(kvs) < scanPageSize {
+			return entries, nil
+		}
+		last := kvs[len(kvs)-1]
+		next := last.CreatedAt.Add(time.Nanosecond)
+		if cursor != nil && !next.Before(*cursor) {
+			// A whole page shares one timestamp; the scan cannot get past it.
+			return entries, nil
+		}
+		cursor = &next
+		skip = &Cursor{Timestamp: last.CreatedAt, ID: strings.TrimPrefix(last.Key, auditKVPrefix)}
+	}
+}
+
+// follows reports whether e comes after c in scan order, which is by
+// timestamp then ID, both descending.
+func follows(e *Entry, c *Cursor) bool {
+	if !e.Timestamp.Equal(c.Timestamp) {
+		return e.Timestamp.Before(c.Timestamp)
+	}
+	return e.ID < c.ID
+}
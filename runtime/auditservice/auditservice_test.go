@@ -0,0 +1,119 @@
+package auditservice
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/apiframework"
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuditDB(t *testing.T, opts ...Option) (context.Context, Service) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "audit.db"), runtimetypes.SchemaSQLite)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return ctx, New(db, opts...)
+}
+
+func TestUnit_AuditService_ListFiltersAndPages(t *testing.T) {
+	ctx, svc := setupAuditDB(t)
+	var ids []string
+	for i, e := range []Entry{
+		{Identity: "ci", Operation: "create", ResourceType: "prompts", ResourceID: "a"},
+		{Identity: "ops", Operation: "update", ResourceType: "prompts", ResourceID: "a"},
+		{Identity: "ci", Operation: "delete", ResourceType: "backends", ResourceID: "b"},
+		{Identity: "ci", Operation: "update", ResourceType: "prompts", ResourceID: "c"},
+	} {
+		e.Outcome = OutcomeSuccess
+		require.NoError(t, svc.Record(ctx, &e), "entry %d", i)
+		ids = append(ids, e.ID)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	all, err := svc.List(ctx, Query{}, nil, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[3], ids[2], ids[1], ids[0]}, entryIDs(all), "newest first")
+	require.False(t, all[0].Timestamp.IsZero())
+
+	byIdentity, err := svc.List(ctx, Query{Identity: "ci"}, nil, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[3], ids[2], ids[0]}, entryIDs(byIdentity))
+
+	byResource, err := svc.List(ctx, Query{ResourceType: "prompts", ResourceID: "a"}, nil, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[1], ids[0]}, entryIDs(byResource))
+
+	window, err := svc.List(ctx, Query{Since: all[2].Timestamp, Until: all[0].Timestamp}, nil, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[2], ids[1]}, entryIDs(window), "since is inclusive, until exclusive")
+
+	first, err := svc.List(ctx, Query{Identity: "ci"}, nil, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[3], ids[2]}, entryIDs(first))
+	last := first[len(first)-1]
+	rest, err := svc.List(ctx, Query{Identity: "ci"}, &Cursor{Timestamp: last.Timestamp, ID: last.ID}, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{ids[0]}, entryIDs(rest))
+
+	require.Error(t, svc.Record(ctx, &Entry{Identity: "ci"}), "an entry needs an operation and a resource type")
+}
+
+func TestUnit_AuditService_RecordPrunesExpiredEntries(t *testing.T) {
+	ctx, svc := setupAuditDB(t, WithRetention(200*time.Millisecond))
+	old := &Entry{Identity: "ci", Operation: "create", ResourceType: "prompts", Outcome: OutcomeSuccess}
+	require.NoError(t, svc.Record(ctx, old))
+	time.Sleep(400 * time.Millisecond)
+	recent := &Entry{Identity: "ci", Operation: "delete", ResourceType: "prompts", Outcome: OutcomeSuccess}
+	require.NoError(t, svc.Record(ctx, recent))
+
+	entries, err := svc.List(ctx, Query{}, nil, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{recent.ID}, entryIDs(entries), "entries past retention are deleted")
+}
+
+func TestUnit_AuditTracker_RecordsMutations(t *testing.T) {
+	ctx, svc := setupAuditDB(t)
+	tracker := NewTracker(svc, libtracker.NoopTracker{})
+	caller := apiframework.WithIdentity(context.WithValue(ctx, libtracker.ContextKeyRequestID, "req-1"), "ci", nil)
+
+	_, reportChange, end := tracker.Start(caller, "create", "prompts")
+	reportChange("triage", nil)
+	end()
+	end()
+	reportErr, _, end := tracker.Start(caller, "delete", "backends", "id", "b1")
+	reportErr(errors.New("backend is in use"))
+	end()
+	_, _, end = tracker.Start(ctx, "update", "models", "id", "m1")
+	end()
+	_, _, end = tracker.Start(caller, "get", "prompts", "id", "triage")
+	end()
+
+	entries, err := svc.List(ctx, Query{}, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "reads are not audited and end records once")
+	// The three were recorded back to back; compare them regardless of order.
+	for _, e := range entries {
+		e.ID, e.Timestamp = "", time.Time{}
+	}
+	require.ElementsMatch(t, []*Entry{
+		{Identity: anonymousIdentity, Operation: "update", ResourceType: "models", ResourceID: "m1", Outcome: OutcomeSuccess},
+		{Identity: "ci", Operation: "delete", ResourceType: "backends", ResourceID: "b1", RequestID: "req-1", Outcome: OutcomeFailure, Error: "backend is in use"},
+		{Identity: "ci", Operation: "create", ResourceType: "prompts", ResourceID: "triage", RequestID: "req-1", Outcome: OutcomeSuccess},
+	}, entries)
+}
+
+func entryIDs(entries []*Entry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
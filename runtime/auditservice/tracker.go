@@ -0,0 +1,108 @@
+package auditservice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/libtracker"
+)
+
+// anonymousIdentity is recorded for operations whose context carries no
+// identity, as happens when serve runs without a TOKEN.
+const anonymousIdentity = "anonymous"
+
+// auditedOperations are the operations the tracker records; everything else
+// only reaches the wrapped tracker.
+var auditedOperations = map[string]bool{"create": true, "update": true, "delete": true}
+
+type auditTracker struct {
+	audit Service
+	next  libtracker.ActivityTracker
+}
+
+// NewTracker returns an ActivityTracker that records every "create",
+// "update" and "delete" operation started on it in audit, and passes every
+// operation on to next. The subject passed to Start is the entry's resource
+// type; the resource ID is the ID given to reportChange, or else the "id"
+// kvArg. The identity and request ID come from the context.
+//
+// The entry is written when end is called. A failure to write it is logged,
+// not returned: the operation it describes has already happened.
+func NewTracker(audit Service, next libtracker.ActivityTracker) libtracker.ActivityTracker {
+	if next == nil {
+		next = libtracker.NoopTracker{}
+	}
+	return &auditTracker{audit: audit, next: next}
+}
+
+func (t *auditTracker) Start(
+	ctx context.Context,
+	operation string,
+	subject string,
+	kvArgs ...any,
+) (func(error), func(string, any), func()) {
+	reportErr, reportChange, end := t.next.Start(ctx, operation, subject, kvArgs...)
+	if !auditedOperations[operation] {
+		return reportErr, reportChange, end
+	}
+
+	entry := &Entry{
+		Identity:     anonymousIdentity,
+		Operation:    operation,
+		ResourceType: subject,
+		ResourceID:   kvArg(kvArgs, "id"),
+		Outcome:      OutcomeSuccess,
+	}
+	if identity, ok := apiframework.IdentityFromContext(ctx); ok {
+		entry.Identity = identity
+	}
+	if requestID, ok := ctx.Value(libtracker.ContextKeyRequestID).(string); ok {
+		entry.RequestID = requestID
+	}
+
+	var mu sync.Mutex
+	var once sync.Once
+	auditErr := func(err error) {
+		if err != nil {
+			mu.Lock()
+			entry.Outcome, entry.Error = OutcomeFailure, err.Error()
+			mu.Unlock()
+		}
+		reportErr(err)
+	}
+	auditChange := func(id string, data any) {
+		if id != "" {
+			mu.Lock()
+			entry.ResourceID = id
+			mu.Unlock()
+		}
+		reportChange(id, data)
+	}
+	auditEnd := func() {
+		once.Do(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if err := t.audit.Record(context.WithoutCancel(ctx), entry); err != nil {
+				slog.Error("auditservice: record entry failed; operation not audited",
+					"operation", operation, "subject", subject, "request_id", entry.RequestID, "error", err)
+			}
+		})
+		end()
+	}
+	return auditErr, auditChange, auditEnd
+}
+
+// kvArg returns the value following key in the Start key-value pairs, or "".
+func kvArg(kvArgs []any, key string) string {
+	for i := 0; i+1 < len(kvArgs); i += 2 {
+		if k, ok := kvArgs[i].(string); ok && k == key && kvArgs[i+1] != nil {
+			return fmt.Sprint(kvArgs[i+1])
+		}
+	}
+	return ""
+}
+
+var _ libtracker.ActivityTracker = (*auditTracker)(nil)
@@ -0,0 +1,218 @@
+package backendservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"gopkg.in/yaml.v3"
+)
+
+// ImportMode selects how ImportBackends treats backends already registered.
+type ImportMode string
+
+const (
+	// ImportMerge creates the backends the document names that do not exist
+	// and updates the ones that do, matched by name. Backends the document
+	// does not mention are left alone.
+	ImportMerge ImportMode = "merge"
+	// ImportReplace does what ImportMerge does and then deletes every backend
+	// the document does not mention — but only when every entry imported
+	// cleanly, so a document with a typo cannot empty the fleet.
+	ImportReplace ImportMode = "replace"
+)
+
+// Import actions reported per entry in ImportResult.Action.
+const (
+	ImportCreated   = "created"
+	ImportUpdated   = "updated"
+	ImportUnchanged = "unchanged"
+	ImportDeleted   = "deleted"
+	ImportFailed    = "failed"
+)
+
+// ConnectionTester probes an unsaved backend. stateservice.Service satisfies
+// it with TestBackendConnection.
+type ConnectionTester interface {
+	TestBackendConnection(ctx context.Context, backend runtimetypes.Backend) (ok bool, detail string)
+}
+
+// ImportResult is the outcome of one backend in an import. A failed entry is
+// not applied; Error says why.
+type ImportResult struct {
+	Name   string `json:"name" example:"ollama-production"`
+	Action string `json:"action" example:"created"`
+	Error  string `json:"error,omitempty" example:"invalid backend data: baseUrl must be an absolute http(s) URL"`
+	// ConnectionDetail is the connection probe's answer when the import was
+	// asked to test each backend before applying it.
+	ConnectionDetail string `json:"connectionDetail,omitempty" example:"connected; the backend reported 12 models"`
+}
+
+// backendDocument is the YAML shape ExportBackends writes and ImportBackends
+// reads. IDs and timestamps are left out on purpose: a document describes
+// backends by name so it can be applied to another runtime.
+type backendDocument struct {
+	Backends []backendEntry `yaml:"backends"`
+}
+
+type backendEntry struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type"`
+	BaseURL string `yaml:"baseUrl"`
+}
+
+// ExportBackends returns every registered backend as a YAML document, sorted
+// by name, that ImportBackends accepts.
+func (s *service) ExportBackends(ctx context.Context) ([]byte, error) {
+	backends, err := runtimetypes.New(s.dbInstance.WithoutTransaction()).ListAllBackends(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list backends: %w", err)
+	}
+	doc := backendDocument{Backends: make([]backendEntry, 0, len(backends))}
+	for _, b := range backends {
+		doc.Backends = append(doc.Backends, backendEntry{Name: b.Name, Type: b.Type, BaseURL: b.BaseURL})
+	}
+	sort.Slice(doc.Backends, func(i, j int) bool { return doc.Backends[i].Name < doc.Backends[j].Name })
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode backends: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode backends: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ImportBackends applies a YAML document in the ExportBackends shape and
+// reports the outcome of every entry. Entries are validated — name, type, and
+// for network backends an absolute http(s) base URL — and, when tester is
+// non-nil, probed before they are applied; an entry that fails either is
+// reported and skipped while the rest are still imported. A document that
+// does not parse, or an unknown mode, fails the whole call and changes
+// nothing.
+func (s *service) ImportBackends(ctx context.Context, data []byte, mode ImportMode, tester ConnectionTester) ([]ImportResult, error) {
+	if mode == "" {
+		mode = ImportMerge
+	}
+	if mode != ImportMerge && mode != ImportReplace {
+		return nil, fmt.Errorf("%w %w: import mode must be %q or %q, got %q", errdefs.ErrBadRequest, ErrInvalidBackend, ImportMerge, ImportReplace, mode)
+	}
+	var doc backendDocument
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w %w: parse backends document: %w", errdefs.ErrBadRequest, ErrInvalidBackend, err)
+	}
+
+	store := runtimetypes.New(s.dbInstance.WithoutTransaction())
+	results := make([]ImportResult, 0, len(doc.Backends))
+	named := map[string]bool{}
+	failed := false
+	for _, entry := range doc.Backends {
+		result := s.importOne(ctx, store, entry, named, tester)
+		if result.Action == ImportFailed {
+			failed = true
+		}
+		named[entry.Name] = true
+		results = append(results, result)
+	}
+	if mode != ImportReplace || failed {
+		return results, nil
+	}
+
+	existing, err := store.ListAllBackends(ctx)
+	if err != nil {
+		return results, fmt.Errorf("list backends: %w", err)
+	}
+	for _, b := range existing {
+		if named[b.Name] {
+			continue
+		}
+		result := ImportResult{Name: b.Name, Action: ImportDeleted}
+		if err := s.Delete(ctx, b.ID); err != nil {
+			result.Action, result.Error = ImportFailed, err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *service) importOne(ctx context.Context, store runtimetypes.Store, entry backendEntry, seen map[string]bool, tester ConnectionTester) ImportResult {
+	result := ImportResult{Name: entry.Name}
+	fail := func(err error) ImportResult {
+		result.Action, result.Error = ImportFailed, err.Error()
+		return result
+	}
+
+	backend := runtimetypes.Backend{Name: entry.Name, Type: entry.Type, BaseURL: entry.BaseURL}
+	if err := validateImport(&backend); err != nil {
+		return fail(err)
+	}
+	if seen[entry.Name] {
+		return fail(fmt.Errorf("%w: backend %q appears more than once in the document", ErrInvalidBackend, entry.Name))
+	}
+
+	current, err := store.GetBackendByName(ctx, entry.Name)
+	switch {
+	case errors.Is(err, libdb.ErrNotFound):
+		current = nil
+	case err != nil:
+		return fail(err)
+	}
+	if current != nil && current.Type == backend.Type && current.BaseURL == backend.BaseURL {
+		result.Action = ImportUnchanged
+		return result
+	}
+
+	if tester != nil {
+		ok, detail := tester.TestBackendConnection(ctx, backend)
+		result.ConnectionDetail = detail
+		if !ok {
+			return fail(fmt.Errorf("connection test failed: %s", detail))
+		}
+	}
+
+	if current == nil {
+		if err := s.Create(ctx, &backend); err != nil {
+			return fail(err)
+		}
+		result.Action = ImportCreated
+		return result
+	}
+	backend.ID = current.ID
+	if err := s.Update(ctx, &backend); err != nil {
+		return fail(err)
+	}
+	result.Action = ImportUpdated
+	return result
+}
+
+// validateImport applies validate and, for backends reached over HTTP,
+// checks that the base URL is an absolute http(s) URL. Local model
+// directories (llama, openvino) and modeld addresses are not URLs and are
+// only checked for presence.
+func validateImport(backend *runtimetypes.Backend) error {
+	if err := validate(backend); err != nil {
+		return err
+	}
+	switch modelrepo.CanonicalBackendType(backend.Type) {
+	case "llama", "openvino", "modeld":
+		return nil
+	}
+	u, err := url.Parse(backend.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: baseUrl %q must be an absolute http(s) URL", ErrInvalidBackend, backend.BaseURL)
+	}
+	return nil
+}
@@ -18,7 +18,10 @@ type Service interface {
 	Get(ctx context.Context, id string) (*runtimetypes.Backend, error)
 	Update(ctx context.Context, backend *runtimetypes.Backend) error
 	Delete(ctx context.Context, id string) error
+	Ensure(ctx context.Context, backend *runtimetypes.Backend, match EnsureMatch) (string, error)
 	List(ctx context.Context, createdAtCursor *time.Time, limit int) ([]*runtimetypes.Backend, error)
+	ExportBackends(ctx context.Context) ([]byte, error)
+	ImportBackends(ctx context.Context, data []byte, mode ImportMode, tester ConnectionTester) ([]ImportResult, error)
 }
 
 type service struct {
@@ -159,3 +159,258 @@ func setupBackendServiceDB(t *testing.T) (context.Context, libdb.DBManager) {
 	t.Cleanup(func() { _ = db.Close() })
 	return ctx, db
 }
+
+type stubTester struct {
+	fail   map[string]bool
+	probed []string
+}
+
+func (s *stubTester) TestBackendConnection(_ context.Context, backend runtimetypes.Backend) (bool, string) {
+	s.probed = append(s.probed, backend.Name)
+	if s.fail[backend.Name] {
+		return false, "connection refused"
+	}
+	return true, "connected"
+}
+
+func resultsByName(results []ImportResult) map[string]ImportResult {
+	out := map[string]ImportResult{}
+	for _, r := range results {
+		out[r.Name] = r
+	}
+	return out
+}
+
+func TestUnit_BackendService_ExportImportRoundTrip(t *testing.T) {
+	ctx, db := setupBackendServiceDB(t)
+	src := New(db)
+	for _, b := range []*runtimetypes.Backend{
+		{Name: "ollama", Type: "ollama", BaseURL: "http://127.0.0.1:11434"},
+		{Name: "local", Type: "llama", BaseURL: "/tmp/models"},
+	} {
+		if err := src.Create(ctx, b); err != nil {
+			t.Fatalf("create %s: %v", b.Name, err)
+		}
+	}
+	data, err := src.ExportBackends(ctx)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.Contains(string(data), "baseUrl: http://127.0.0.1:11434") || strings.Contains(string(data), "id:") {
+		t.Fatalf("unexpected export document:\n%s", data)
+	}
+
+	_, dstDB := setupBackendServiceDB(t)
+	dst := New(dstDB)
+	results, err := dst.ImportBackends(ctx, data, ImportMerge, nil)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	got := resultsByName(results)
+	if len(got) != 2 || got["ollama"].Action != ImportCreated || got["local"].Action != ImportCreated {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	results, err = dst.ImportBackends(ctx, data, ImportMerge, nil)
+	if err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	for _, r := range results {
+		if r.Action != ImportUnchanged {
+			t.Fatalf("re-import of an identical document should change nothing: %+v", results)
+		}
+	}
+}
+
+func TestUnit_BackendService_ImportReportsInvalidEntries(t *testing.T) {
+	ctx, db := setupBackendServiceDB(t)
+	svc := New(db)
+
+	doc := []byte(`backends:
+  - name: good
+    type: ollama
+    baseUrl: http://127.0.0.1:11434
+  - name: bad-url
+    type: openai
+    baseUrl: api.openai.com/v1
+  - name: bad-type
+    type: nope
+    baseUrl: http://127.0.0.1:8000
+  - name: good
+    type: vllm
+    baseUrl: http://127.0.0.1:8000
+`)
+	results, err := svc.ImportBackends(ctx, doc, ImportMerge, nil)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("want one result per entry, got %+v", results)
+	}
+	if results[0].Action != ImportCreated {
+		t.Fatalf("valid entry should be created: %+v", results[0])
+	}
+	for _, r := range results[1:] {
+		if r.Action != ImportFailed || r.Error == "" {
+			t.Fatalf("entry should fail with a reason: %+v", r)
+		}
+	}
+	if !strings.Contains(results[1].Error, "absolute http(s) URL") {
+		t.Fatalf("unexpected URL error: %q", results[1].Error)
+	}
+	if !strings.Contains(results[3].Error, "more than once") {
+		t.Fatalf("unexpected duplicate error: %q", results[3].Error)
+	}
+
+	if _, err := svc.ImportBackends(ctx, []byte("backends: [\n"), ImportMerge, nil); !errors.Is(err, ErrInvalidBackend) {
+		t.Fatalf("malformed document error = %v, want ErrInvalidBackend", err)
+	}
+	if _, err := svc.ImportBackends(ctx, []byte("backend: []\n"), ImportMerge, nil); !errors.Is(err, ErrInvalidBackend) {
+		t.Fatalf("unknown field error = %v, want ErrInvalidBackend", err)
+	}
+	if _, err := svc.ImportBackends(ctx, doc, "upsert", nil); !errors.Is(err, ErrInvalidBackend) {
+		t.Fatalf("unknown mode error = %v, want ErrInvalidBackend", err)
+	}
+}
+
+func TestUnit_BackendService_ImportReplace(t *testing.T) {
+	ctx, db := setupBackendServiceDB(t)
+	svc := New(db)
+	for _, b := range []*runtimetypes.Backend{
+		{Name: "keep", Type: "ollama", BaseURL: "http://127.0.0.1:11434"},
+		{Name: "stale", Type: "ollama", BaseURL: "http://127.0.0.1:11435"},
+	} {
+		if err := svc.Create(ctx, b); err != nil {
+			t.Fatalf("create %s: %v", b.Name, err)
+		}
+	}
+
+	// A failed entry blocks the deletions.
+	bad := []byte("backends:\n  - name: keep\n    type: ollama\n    baseUrl: http://127.0.0.1:11436\n  - name: broken\n    type: ollama\n    baseUrl: \"\"\n")
+	results, err := svc.ImportBackends(ctx, bad, ImportReplace, nil)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if _, ok := resultsByName(results)["stale"]; ok {
+		t.Fatalf("replace must not delete when an entry failed: %+v", results)
+	}
+
+	good := []byte("backends:\n  - name: keep\n    type: ollama\n    baseUrl: http://127.0.0.1:11436\n")
+	results, err = svc.ImportBackends(ctx, good, ImportReplace, nil)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	got := resultsByName(results)
+	if got["keep"].Action != ImportUnchanged || got["stale"].Action != ImportDeleted {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	backends, err := svc.List(ctx, nil, 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(backends) != 1 || backends[0].BaseURL != "http://127.0.0.1:11436" {
+		t.Fatalf("unexpected backends after replace: %+v", backends)
+	}
+}
+
+func TestUnit_BackendService_ImportTestsConnections(t *testing.T) {
+	ctx, db := setupBackendServiceDB(t)
+	svc := New(db)
+
+	doc := []byte("backends:\n  - name: up\n    type: ollama\n    baseUrl: http://127.0.0.1:11434\n  - name: down\n    type: ollama\n    baseUrl: http://127.0.0.1:11435\n")
+	tester := &stubTester{fail: map[string]bool{"down": true}}
+	results, err := svc.ImportBackends(ctx, doc, ImportMerge, tester)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	got := resultsByName(results)
+	if got["up"].Action != ImportCreated || got["up"].ConnectionDetail != "connected" {
+		t.Fatalf("reachable backend should be created: %+v", got["up"])
+	}
+	if got["down"].Action != ImportFailed || !strings.Contains(got["down"].Error, "connection refused") {
+		t.Fatalf("unreachable backend should fail: %+v", got["down"])
+	}
+	if _, err := runtimetypes.New(db.WithoutTransaction()).GetBackendByName(ctx, "down"); !errors.Is(err, libdb.ErrNotFound) {
+		t.Fatalf("failed probe must not store the backend, got %v", err)
+	}
+
+	// Unchanged entries are not probed again.
+	tester.probed = nil
+	if _, err := svc.ImportBackends(ctx, doc, ImportMerge, tester); err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if len(tester.probed) != 1 || tester.probed[0] != "down" {
+		t.Fatalf("probed = %v, want only the still-missing backend", tester.probed)
+	}
+}
+
+func TestUnit_BackendService_EnsureByName(t *testing.T) {
+	ctx, db := setupBackendServiceDB(t)
+	svc := New(db)
+
+	want := runtimetypes.Backend{Name: "ollama", Type: "ollama", BaseURL: "http://127.0.0.1:11434"}
+	first := want
+	action, err := svc.Ensure(ctx, &first, EnsureMatchName)
+	if err != nil || action != ImportCreated {
+		t.Fatalf("first ensure = %q, %v; want created", action, err)
+	}
+	again := want
+	action, err = svc.Ensure(ctx, &again, EnsureMatchName)
+	if err != nil || action != ImportUnchanged {
+		t.Fatalf("repeated ensure = %q, %v; want unchanged", action, err)
+	}
+	if again.ID != first.ID {
+		t.Fatalf("repeated ensure returned ID %q, want %q", again.ID, first.ID)
+	}
+
+	moved := want
+	moved.BaseURL = "http://127.0.0.1:11435"
+	action, err = svc.Ensure(ctx, &moved, EnsureMatchName)
+	if err != nil || action != ImportUpdated {
+		t.Fatalf("changed ensure = %q, %v; want updated", action, err)
+	}
+	got, err := svc.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("get backend: %v", err)
+	}
+	if got.BaseURL != moved.BaseURL {
+		t.Fatalf("stored baseURL = %q, want %q", got.BaseURL, moved.BaseURL)
+	}
+	all, err := svc.List(ctx, nil, 10)
+	if err != nil {
+		t.Fatalf("list backends: %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("ensure left %d backends, want 1", len(all))
+	}
+}
+
+func TestUnit_BackendService_EnsureByURL(t *testing.T) {
+	ctx, db := setupBackendServiceDB(t)
+	svc := New(db)
+
+	existing := &runtimetypes.Backend{Name: "old-name", Type: "ollama", BaseURL: "http://127.0.0.1:11434"}
+	if err := svc.Create(ctx, existing); err != nil {
+		t.Fatalf("create backend: %v", err)
+	}
+
+	renamed := runtimetypes.Backend{Name: "new-name", Type: "ollama", BaseURL: existing.BaseURL}
+	action, err := svc.Ensure(ctx, &renamed, EnsureMatchURL)
+	if err != nil || action != ImportUpdated {
+		t.Fatalf("ensure by url = %q, %v; want updated", action, err)
+	}
+	if renamed.ID != existing.ID {
+		t.Fatalf("ensure by url updated ID %q, want %q", renamed.ID, existing.ID)
+	}
+
+	// Matching by name does not look at the URL: the pair is taken, so the
+	// create conflicts instead of silently renaming the other backend.
+	other := runtimetypes.Backend{Name: "third", Type: "ollama", BaseURL: existing.BaseURL}
+	if _, err := svc.Ensure(ctx, &other, EnsureMatchName); !errors.Is(err, libdb.ErrUniqueViolation) {
+		t.Fatalf("ensure by name on a taken URL = %v, want ErrUniqueViolation", err)
+	}
+
+	if _, err := svc.Ensure(ctx, &other, "id"); !errors.Is(err, ErrInvalidBackend) {
+		t.Fatalf("unknown match = %v, want ErrInvalidBackend", err)
+	}
+}
@@ -99,6 +99,33 @@ func (d *activityTrackerDecorator) Delete(ctx context.Context, id string) error
 	return err
 }
 
+func (d *activityTrackerDecorator) Ensure(ctx context.Context, backend *runtimetypes.Backend, match EnsureMatch) (string, error) {
+	reportErrFn, reportChangeFn, endFn := d.tracker.Start(
+		ctx,
+		"ensure",
+		"backend",
+		"name", backend.Name,
+		"type", backend.Type,
+		"baseURL", backend.BaseURL,
+		"match", string(match),
+	)
+	defer endFn()
+
+	action, err := d.service.Ensure(ctx, backend, match)
+	if err != nil {
+		reportErrFn(err)
+	} else if action != ImportUnchanged {
+		reportChangeFn(backend.ID, map[string]interface{}{
+			"action":  action,
+			"name":    backend.Name,
+			"type":    backend.Type,
+			"baseURL": backend.BaseURL,
+		})
+	}
+
+	return action, err
+}
+
 func (d *activityTrackerDecorator) List(ctx context.Context, createdAtCursor *time.Time, limit int) ([]*runtimetypes.Backend, error) {
 	reportErrFn, _, endFn := d.tracker.Start(
 		ctx,
@@ -117,6 +144,43 @@ func (d *activityTrackerDecorator) List(ctx context.Context, createdAtCursor *ti
 	return backends, err
 }
 
+func (d *activityTrackerDecorator) ExportBackends(ctx context.Context) ([]byte, error) {
+	reportErrFn, _, endFn := d.tracker.Start(ctx, "export", "backends")
+	defer endFn()
+
+	data, err := d.service.ExportBackends(ctx)
+	if err != nil {
+		reportErrFn(err)
+	}
+
+	return data, err
+}
+
+func (d *activityTrackerDecorator) ImportBackends(ctx context.Context, data []byte, mode ImportMode, tester ConnectionTester) ([]ImportResult, error) {
+	reportErrFn, reportChangeFn, endFn := d.tracker.Start(
+		ctx,
+		"import",
+		"backends",
+		"mode", string(mode),
+		"testConnection", fmt.Sprintf("%t", tester != nil),
+	)
+	defer endFn()
+
+	results, err := d.service.ImportBackends(ctx, data, mode, tester)
+	if err != nil {
+		reportErrFn(err)
+	}
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Action]++
+	}
+	if len(results) > 0 {
+		reportChangeFn("backends", counts)
+	}
+
+	return results, err
+}
+
 func WithActivityTracker(service Service, tracker libtracker.ActivityTracker) Service {
 	return &activityTrackerDecorator{
 		service: service,
@@ -0,0 +1,98 @@
+package backendservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+)
+
+// EnsureMatch selects the key Ensure looks an existing backend up by.
+type EnsureMatch string
+
+const (
+	// EnsureMatchName treats a backend with the same name as the one to
+	// update; its type and base URL are overwritten.
+	EnsureMatchName EnsureMatch = "name"
+	// EnsureMatchURL treats a backend with the same type and base URL as the
+	// one to update; its name is overwritten. Type and base URL are the
+	// pair the store already keeps unique.
+	EnsureMatchURL EnsureMatch = "url"
+)
+
+// Ensure creates backend if no backend matches it under match and updates
+// the matching one otherwise, reporting ImportCreated, ImportUpdated, or
+// ImportUnchanged when the stored backend already equals it. backend is
+// filled in with the stored record, ID included. Calling Ensure again with
+// the same backend is a no-op, so provisioning scripts can run it
+// unconditionally instead of handling the 409 Create returns.
+//
+// Only the match key is looked up: a backend that matches on the other key
+// is not touched, and the write fails with the usual conflict instead.
+func (s *service) Ensure(ctx context.Context, backend *runtimetypes.Backend, match EnsureMatch) (string, error) {
+	if match == "" {
+		match = EnsureMatchName
+	}
+	if match != EnsureMatchName && match != EnsureMatchURL {
+		return "", fmt.Errorf("%w %w: match must be %q or %q, got %q", errdefs.ErrBadRequest, ErrInvalidBackend, EnsureMatchName, EnsureMatchURL, match)
+	}
+	if err := validate(backend); err != nil {
+		return "", err
+	}
+
+	current, err := s.findMatch(ctx, backend, match)
+	if err != nil {
+		return "", err
+	}
+	if current == nil {
+		backend.ID = ""
+		err := s.Create(ctx, backend)
+		if err == nil {
+			return ImportCreated, nil
+		}
+		if !errors.Is(err, libdb.ErrUniqueViolation) {
+			return "", err
+		}
+		// A concurrent Ensure may have created the match between the lookup
+		// and the insert; look again before reporting the conflict.
+		if current, _ = s.findMatch(ctx, backend, match); current == nil {
+			return "", err
+		}
+	}
+
+	if current.Name == backend.Name && current.Type == backend.Type && current.BaseURL == backend.BaseURL {
+		*backend = *current
+		return ImportUnchanged, nil
+	}
+	backend.ID = current.ID
+	backend.CreatedAt = current.CreatedAt
+	if err := s.Update(ctx, backend); err != nil {
+		return "", err
+	}
+	return ImportUpdated, nil
+}
+
+// findMatch returns the backend matching backend under match, or nil.
+func (s *service) findMatch(ctx context.Context, backend *runtimetypes.Backend, match EnsureMatch) (*runtimetypes.Backend, error) {
+	store := runtimetypes.New(s.dbInstance.WithoutTransaction())
+	if match == EnsureMatchName {
+		current, err := store.GetBackendByName(ctx, backend.Name)
+		if errors.Is(err, libdb.ErrNotFound) {
+			return nil, nil
+		}
+		return current, err
+	}
+	backends, err := store.ListAllBackends(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list backends: %w", err)
+	}
+	for _, b := range backends {
+		if b.Type == backend.Type && b.BaseURL == backend.BaseURL {
+			return b, nil
+		}
+	}
+	return nil, nil
+}
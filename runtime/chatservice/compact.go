@@ -3,7 +3,11 @@ package chatservice
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
+	libdb "github.com/contenox/runtime/libdbexec"
 	"github.com/contenox/runtime/runtime/taskengine"
 )
 
@@ -27,11 +31,17 @@ func CompactHistory(ctx context.Context, exec ChainExecutor, chain *taskengine.T
 	for sysEnd < len(history) && history[sysEnd].Role == "system" {
 		sysEnd++
 	}
-	if len(history)-sysEnd <= keep {
-		return nil, fmt.Errorf("session too short to summarize (have %d non-system messages, keep=%d)", len(history)-sysEnd, keep)
+	return compactAfter(ctx, exec, chain, history, sysEnd, keep)
+}
+
+// compactAfter replaces history[prefixEnd:len(history)-keep] with one summary
+// message, keeping history[:prefixEnd] and the last keep messages verbatim.
+func compactAfter(ctx context.Context, exec ChainExecutor, chain *taskengine.TaskChainDefinition, history []taskengine.Message, prefixEnd, keep int) ([]taskengine.Message, error) {
+	if len(history)-prefixEnd <= keep {
+		return nil, fmt.Errorf("session too short to summarize (have %d non-system messages, keep=%d)", len(history)-prefixEnd, keep)
 	}
 	compactEnd := len(history) - keep
-	toCompact := taskengine.ChatHistory{Messages: history[sysEnd:compactEnd]}
+	toCompact := taskengine.ChatHistory{Messages: history[prefixEnd:compactEnd]}
 
 	out, _, _, err := exec.Execute(ctx, chain, toCompact, taskengine.DataTypeChatHistory)
 	if err != nil {
@@ -46,17 +56,153 @@ func CompactHistory(ctx context.Context, exec ChainExecutor, chain *taskengine.T
 	// Stamp the summary with the timestamp of the last compacted message so it
 	// sorts into the gap it fills — messages are persisted and reloaded by
 	// added_at ASC, so time.Now() would float the summary past the kept recent
-	// messages and corrupt the conversation order. compactEnd-1 >= sysEnd is
+	// messages and corrupt the conversation order. compactEnd-1 >= prefixEnd is
 	// guaranteed by the keep check above.
 	summaryTimestamp := history[compactEnd-1].Timestamp
 
-	spliced := make([]taskengine.Message, 0, sysEnd+1+keep)
-	spliced = append(spliced, history[:sysEnd]...)
+	spliced := make([]taskengine.Message, 0, prefixEnd+1+keep)
+	spliced = append(spliced, history[:prefixEnd]...)
 	spliced = append(spliced, taskengine.Message{
 		Role:      "user",
-		Content:   fmt.Sprintf("<compact-summary>\n%s\n</compact-summary>", summaryContent),
+		Content:   fmt.Sprintf("%s\n%s\n%s", compactSummaryOpen, summaryContent, compactSummaryClose),
 		Timestamp: summaryTimestamp,
 	})
 	spliced = append(spliced, history[compactEnd:]...)
 	return spliced, nil
 }
+
+const (
+	compactSummaryOpen  = "<compact-summary>"
+	compactSummaryClose = "</compact-summary>"
+)
+
+// IsCompactSummary reports whether msg is a summary written by a compaction.
+func IsCompactSummary(msg taskengine.Message) bool {
+	return msg.Role == "user" && strings.HasPrefix(msg.Content, compactSummaryOpen)
+}
+
+// AutoCompactPolicy decides when a conversation is compacted automatically
+// after a turn. Only the messages after the conversation's compacted prefix —
+// its leading system messages and the summaries earlier compactions left —
+// are measured and summarized, so a summary is never fed back into the
+// summarizer and compacting a conversation that is under the threshold is a
+// no-op. Each compaction adds one summary after the previous ones.
+//
+// The summary is a <compact-summary> user message, as /compact writes it,
+// not a system message: several providers accept system content only at the
+// start of a conversation and reject or drop it mid-history.
+type AutoCompactPolicy struct {
+	// MaxMessages compacts once more than this many messages follow the
+	// compacted prefix. Zero disables the message threshold.
+	MaxMessages int
+	// MaxTokens compacts once the messages after the compacted prefix are
+	// estimated at more than this many tokens (about four characters per
+	// token). Zero disables the token threshold.
+	MaxTokens int
+	// Keep is how many of the most recent messages stay verbatim.
+	Keep int
+}
+
+// DefaultAutoCompactKeep is the Keep ParseAutoCompactPolicy uses when the
+// setting names none.
+const DefaultAutoCompactKeep = 6
+
+// ParseAutoCompactPolicy parses the auto-compact setting: comma-separated
+// messages=N, tokens=N and keep=N, e.g. "tokens=60000,keep=8". An empty
+// setting is the disabled policy.
+func ParseAutoCompactPolicy(setting string) (AutoCompactPolicy, error) {
+	policy := AutoCompactPolicy{Keep: DefaultAutoCompactKeep}
+	if strings.TrimSpace(setting) == "" {
+		return AutoCompactPolicy{}, nil
+	}
+	for _, field := range strings.Split(setting, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil || n < 0 {
+			return AutoCompactPolicy{}, fmt.Errorf("auto-compact: %q is not name=N with a non-negative N", strings.TrimSpace(field))
+		}
+		switch strings.TrimSpace(name) {
+		case "messages":
+			policy.MaxMessages = n
+		case "tokens":
+			policy.MaxTokens = n
+		case "keep":
+			policy.Keep = n
+		default:
+			return AutoCompactPolicy{}, fmt.Errorf("auto-compact: unknown setting %q, want messages, tokens or keep", name)
+		}
+	}
+	if !policy.Enabled() {
+		return AutoCompactPolicy{}, fmt.Errorf("auto-compact: set messages or tokens to a positive threshold")
+	}
+	return policy, nil
+}
+
+// Enabled reports whether either threshold is set.
+func (p AutoCompactPolicy) Enabled() bool {
+	return p.MaxMessages > 0 || p.MaxTokens > 0
+}
+
+// Due reports whether history crosses one of the policy's thresholds and has
+// more than Keep messages to summarize.
+func (p AutoCompactPolicy) Due(history []taskengine.Message) bool {
+	tail := history[compactedPrefixEnd(history):]
+	if !p.Enabled() || len(tail) <= p.Keep {
+		return false
+	}
+	if p.MaxMessages > 0 && len(tail) > p.MaxMessages {
+		return true
+	}
+	if p.MaxTokens > 0 {
+		tokens := 0
+		for _, msg := range tail {
+			tokens += utf8.RuneCountInString(msg.Content) / 4
+		}
+		return tokens > p.MaxTokens
+	}
+	return false
+}
+
+// compactedPrefixEnd returns the length of history's leading run of system
+// messages and compaction summaries.
+func compactedPrefixEnd(history []taskengine.Message) int {
+	end := 0
+	for end < len(history) && (history[end].Role == "system" || IsCompactSummary(history[end])) {
+		end++
+	}
+	return end
+}
+
+// AutoCompact compacts the stored conversation subjectID when policy says it
+// is due, replacing the stored messages with the compacted set in one
+// transaction. It reports whether it compacted. The chain's template vars
+// must be on ctx, as for CompactHistory.
+func (m *Manager) AutoCompact(ctx context.Context, db libdb.DBManager, exec ChainExecutor, chain *taskengine.TaskChainDefinition, subjectID string, policy AutoCompactPolicy) (bool, error) {
+	history, err := m.ListMessages(ctx, db.WithoutTransaction(), subjectID)
+	if err != nil {
+		return false, fmt.Errorf("load history: %w", err)
+	}
+	if !policy.Due(history) {
+		return false, nil
+	}
+	compacted, err := compactAfter(ctx, exec, chain, history, compactedPrefixEnd(history), policy.Keep)
+	if err != nil {
+		return false, err
+	}
+
+	tx, commit, release, err := db.WithTransaction(ctx)
+	if err != nil {
+		return false, fmt.Errorf("start transaction: %w", err)
+	}
+	defer release()
+	if err := m.ClearSession(ctx, tx, subjectID); err != nil {
+		return false, err
+	}
+	if err := m.PersistDiff(ctx, tx, subjectID, compacted); err != nil {
+		return false, fmt.Errorf("persist compacted history: %w", err)
+	}
+	if err := commit(ctx); err != nil {
+		return false, fmt.Errorf("commit: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,127 @@
+package chatservice_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/runtime/chatservice"
+	"github.com/contenox/runtime/runtime/messagestore"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+// summarizer is a ChainExecutor that records what it was asked to summarize
+// and answers with the contents it saw, joined.
+type summarizer struct {
+	calls [][]string
+}
+
+func (s *summarizer) Execute(_ context.Context, _ *taskengine.TaskChainDefinition, input any, _ taskengine.DataType) (any, taskengine.DataType, []taskengine.CapturedStateUnit, error) {
+	hist := input.(taskengine.ChatHistory)
+	var seen []string
+	for _, m := range hist.Messages {
+		seen = append(seen, m.Content)
+	}
+	s.calls = append(s.calls, seen)
+	return taskengine.ChatHistory{Messages: []taskengine.Message{
+		{Role: "assistant", Content: "summary of " + strings.Join(seen, ",")},
+	}}, taskengine.DataTypeChatHistory, nil, nil
+}
+
+func turns(base time.Time, from, n int) []taskengine.Message {
+	var msgs []taskengine.Message
+	for i := from; i < from+n; i++ {
+		msgs = append(msgs, taskengine.Message{
+			Role:      "user",
+			Content:   fmt.Sprintf("m%d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+		})
+	}
+	return msgs
+}
+
+func TestUnit_AutoCompactPolicy_Due(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sys := taskengine.Message{Role: "system", Content: "be brief"}
+	hist := append([]taskengine.Message{sys}, turns(base, 0, 6)...)
+
+	require.False(t, chatservice.AutoCompactPolicy{}.Due(hist), "no threshold, never due")
+	require.True(t, chatservice.AutoCompactPolicy{MaxMessages: 5, Keep: 2}.Due(hist))
+	require.False(t, chatservice.AutoCompactPolicy{MaxMessages: 6, Keep: 2}.Due(hist), "system messages are not counted")
+	require.False(t, chatservice.AutoCompactPolicy{MaxMessages: 1, Keep: 6}.Due(hist), "nothing left to summarize")
+
+	long := []taskengine.Message{{Role: "user", Content: strings.Repeat("x", 400)}, {Role: "user", Content: "hi"}}
+	require.True(t, chatservice.AutoCompactPolicy{MaxTokens: 50, Keep: 1}.Due(long))
+	require.False(t, chatservice.AutoCompactPolicy{MaxTokens: 200, Keep: 1}.Due(long))
+}
+
+func TestUnit_ParseAutoCompactPolicy(t *testing.T) {
+	policy, err := chatservice.ParseAutoCompactPolicy("")
+	require.NoError(t, err)
+	require.False(t, policy.Enabled())
+
+	policy, err = chatservice.ParseAutoCompactPolicy(" tokens=60000, keep=8 ")
+	require.NoError(t, err)
+	require.Equal(t, chatservice.AutoCompactPolicy{MaxTokens: 60000, Keep: 8}, policy)
+
+	policy, err = chatservice.ParseAutoCompactPolicy("messages=80")
+	require.NoError(t, err)
+	require.Equal(t, chatservice.AutoCompactPolicy{MaxMessages: 80, Keep: chatservice.DefaultAutoCompactKeep}, policy)
+
+	for _, bad := range []string{"messages", "messages=-1", "turns=5", "keep=4"} {
+		_, err := chatservice.ParseAutoCompactPolicy(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestIntegration_AutoCompact_PersistsAndSkipsSummarizedPrefix(t *testing.T) {
+	ctx, db := setupDB(t)
+	exec := db.WithoutTransaction()
+	const sessionID = "auto-compact"
+	require.NoError(t, messagestore.New(exec, "ws").CreateMessageIndex(ctx, sessionID, "alice"))
+	mgr := chatservice.NewManager("ws")
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sys := taskengine.Message{Role: "system", Content: "be brief", Timestamp: base.Add(-time.Second)}
+	require.NoError(t, mgr.PersistDiff(ctx, exec, sessionID, append([]taskengine.Message{sys}, turns(base, 0, 6)...)))
+
+	policy := chatservice.AutoCompactPolicy{MaxMessages: 4, Keep: 2}
+	chain := &taskengine.TaskChainDefinition{ID: "compact"}
+	sum := &summarizer{}
+
+	compacted, err := mgr.AutoCompact(ctx, db, sum, chain, sessionID, policy)
+	require.NoError(t, err)
+	require.True(t, compacted)
+	require.Equal(t, [][]string{{"m0", "m1", "m2", "m3"}}, sum.calls)
+
+	stored, err := mgr.ListMessages(ctx, exec, sessionID)
+	require.NoError(t, err)
+	require.Len(t, stored, 4)
+	require.Equal(t, "be brief", stored[0].Content)
+	require.True(t, chatservice.IsCompactSummary(stored[1]))
+	require.Equal(t, []string{"m4", "m5"}, []string{stored[2].Content, stored[3].Content})
+
+	compacted, err = mgr.AutoCompact(ctx, db, sum, chain, sessionID, policy)
+	require.NoError(t, err)
+	require.False(t, compacted, "an already-compacted history under the threshold is left alone")
+	require.Len(t, sum.calls, 1)
+
+	// Three more turns cross the threshold again; the earlier summary stays
+	// verbatim and only the new messages are summarized.
+	stored = append(stored, turns(base, 6, 3)...)
+	require.NoError(t, mgr.PersistDiff(ctx, exec, sessionID, stored))
+	compacted, err = mgr.AutoCompact(ctx, db, sum, chain, sessionID, policy)
+	require.NoError(t, err)
+	require.True(t, compacted)
+	require.Equal(t, []string{"m4", "m5", "m6"}, sum.calls[1])
+
+	stored, err = mgr.ListMessages(ctx, exec, sessionID)
+	require.NoError(t, err)
+	require.Len(t, stored, 5)
+	require.Contains(t, stored[1].Content, "summary of m0,m1,m2,m3")
+	require.Contains(t, stored[2].Content, "summary of m4,m5,m6")
+	require.Equal(t, []string{"m7", "m8"}, []string{stored[3].Content, stored[4].Content})
+}
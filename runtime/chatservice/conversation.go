@@ -0,0 +1,117 @@
+package chatservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/messagestore"
+	"github.com/contenox/runtime/runtime/taskengine"
+)
+
+// DefaultConversationPageSize is how many messages GetConversation returns
+// when the caller passes no positive limit.
+const DefaultConversationPageSize = 50
+
+// MaxConversationPageSize caps one GetConversation page.
+const MaxConversationPageSize = 500
+
+// Conversation is one page of a stored conversation, for a UI that loads
+// history without running a chain.
+type Conversation struct {
+	ID       string `json:"id"`
+	Identity string `json:"identity"`
+	Name     string `json:"name,omitempty"`
+	// Messages are in chronological order, oldest first.
+	Messages []taskengine.Message `json:"messages"`
+	// NextBefore is the cursor for the page of older messages: pass it back
+	// as before. Nil when this page reaches the start of the conversation.
+	NextBefore *time.Time `json:"nextBefore,omitempty"`
+}
+
+// GetConversation returns the newest limit messages of conversation convID
+// added before the cursor (the end of the conversation when before is nil),
+// so a UI can page backwards through history with NextBefore.
+//
+// The conversation must belong to identity. One owned by someone else is
+// reported exactly like one that does not exist — wrapping
+// messagestore.ErrNotFound — so the response does not reveal which IDs are
+// in use.
+func (m *Manager) GetConversation(ctx context.Context, tx libdb.Exec, identity, convID string, before *time.Time, limit int) (*Conversation, error) {
+	if limit <= 0 {
+		limit = DefaultConversationPageSize
+	}
+	if limit > MaxConversationPageSize {
+		return nil, fmt.Errorf("limit %d exceeds the maximum page size of %d", limit, MaxConversationPageSize)
+	}
+	store := messagestore.New(tx, m.workspaceID)
+	info, err := m.ownedSession(ctx, store, identity, convID)
+	if err != nil {
+		return nil, err
+	}
+
+	// One row more than the page decides whether an older page exists.
+	rows, err := store.ListMessagesBefore(ctx, convID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	conv := &Conversation{ID: info.ID, Identity: info.Identity, Name: info.Name}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		oldest := rows[len(rows)-1].AddedAt
+		conv.NextBefore = &oldest
+	}
+	conv.Messages = make([]taskengine.Message, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		var msg taskengine.Message
+		if err := json.Unmarshal(rows[i].Payload, &msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		conv.Messages = append(conv.Messages, msg)
+	}
+	return conv, nil
+}
+
+// AppendConversationMessage stores msg at the end of conversation convID,
+// which must belong to identity (see GetConversation). An empty ID or
+// Timestamp is filled in the same way PersistDiff fills them; the message is
+// returned as stored.
+func (m *Manager) AppendConversationMessage(ctx context.Context, tx libdb.Exec, identity, convID string, msg taskengine.Message) (taskengine.Message, error) {
+	store := messagestore.New(tx, m.workspaceID)
+	if _, err := m.ownedSession(ctx, store, identity, convID); err != nil {
+		return taskengine.Message{}, err
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now().UTC()
+	}
+	if msg.ID == "" {
+		msg.ID = generateMessageID(convID, &msg)
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return taskengine.Message{}, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	if err := store.AppendMessages(ctx, &messagestore.Message{
+		ID:      msg.ID,
+		IDX:     convID,
+		Payload: payload,
+		AddedAt: msg.Timestamp,
+	}); err != nil {
+		return taskengine.Message{}, err
+	}
+	return msg, nil
+}
+
+func (m *Manager) ownedSession(ctx context.Context, store messagestore.Store, identity, convID string) (*messagestore.SessionInfo, error) {
+	info, err := store.GetSession(ctx, convID)
+	if errors.Is(err, messagestore.ErrNotFound) || (err == nil && info.Identity != identity) {
+		return nil, fmt.Errorf("conversation %s: %w", convID, messagestore.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,73 @@
+package chatservice_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/runtime/chatservice"
+	"github.com/contenox/runtime/runtime/messagestore"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_Conversation_AppendAndPage(t *testing.T) {
+	ctx, db := setupDB(t)
+	exec := db.WithoutTransaction()
+	mgr := chatservice.NewManager("ws")
+	require.NoError(t, messagestore.New(exec, "ws").CreateNamedMessageIndex(ctx, "conv-1", "alice", "Trip"))
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, content := range []string{"one", "two", "three", "four", "five"} {
+		stored, err := mgr.AppendConversationMessage(ctx, exec, "alice", "conv-1", taskengine.Message{
+			Role:      "user",
+			Content:   content,
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, stored.ID, "an empty ID is filled in")
+	}
+
+	page, err := mgr.GetConversation(ctx, exec, "alice", "conv-1", nil, 2)
+	require.NoError(t, err)
+	require.Equal(t, "Trip", page.Name)
+	require.Equal(t, []string{"four", "five"}, contents(page.Messages), "newest page, oldest first")
+	require.NotNil(t, page.NextBefore)
+
+	page, err = mgr.GetConversation(ctx, exec, "alice", "conv-1", page.NextBefore, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"two", "three"}, contents(page.Messages))
+
+	page, err = mgr.GetConversation(ctx, exec, "alice", "conv-1", page.NextBefore, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"one"}, contents(page.Messages))
+	require.Nil(t, page.NextBefore, "the first message ends the history")
+}
+
+func TestIntegration_Conversation_OtherOwnerLooksMissing(t *testing.T) {
+	ctx, db := setupDB(t)
+	exec := db.WithoutTransaction()
+	mgr := chatservice.NewManager("ws")
+	require.NoError(t, messagestore.New(exec, "ws").CreateMessageIndex(ctx, "conv-1", "alice"))
+
+	_, err := mgr.GetConversation(ctx, exec, "mallory", "conv-1", nil, 0)
+	require.ErrorIs(t, err, messagestore.ErrNotFound)
+	_, err = mgr.AppendConversationMessage(ctx, exec, "mallory", "conv-1", taskengine.Message{Role: "user", Content: "hi"})
+	require.ErrorIs(t, err, messagestore.ErrNotFound)
+	_, err = mgr.GetConversation(ctx, exec, "alice", "no-such-conv", nil, 0)
+	require.ErrorIs(t, err, messagestore.ErrNotFound)
+
+	page, err := mgr.GetConversation(ctx, exec, "alice", "conv-1", nil, 0)
+	require.NoError(t, err)
+	require.Empty(t, page.Messages, "the rejected append stored nothing")
+
+	_, err = mgr.GetConversation(ctx, exec, "alice", "conv-1", nil, chatservice.MaxConversationPageSize+1)
+	require.Error(t, err)
+}
+
+func contents(msgs []taskengine.Message) []string {
+	out := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, m.Content)
+	}
+	return out
+}
@@ -248,6 +248,11 @@ func runACPProfile(cmd *cobra.Command, profile acpProfile) error {
 		defaultThink = level
 	}
 
+	autoCompact, err := resolveAutoCompact(ctx, runtimetypes.New(db.WithoutTransaction()))
+	if err != nil {
+		return err
+	}
+
 	chains, err := acpsvc.LoadChainRegistryFrom(profile.chainFile, profile.chainEnv)
 	if err != nil {
 		return err
@@ -274,9 +279,10 @@ func runACPProfile(cmd *cobra.Command, profile acpProfile) error {
 	missions := missionservice.New(db, missionservice.WithEventPublisher(bus))
 
 	tools := map[string]taskengine.ToolsRepo{
-		"echo":     localtools.NewEchoTools(),
-		"print":    localtools.NewPrint(tracker),
-		"webtools": localtools.NewWebCaller(tracker),
+		"echo":       localtools.NewEchoTools(),
+		"print":      localtools.NewPrint(tracker),
+		"redact_pii": localtools.NewPIIRedactor(tracker),
+		"webtools":   localtools.NewWebCaller(tracker),
 		"local_fs": localtools.NewLocalFSToolsWith(
 			"",
 			db,
@@ -434,6 +440,7 @@ func runACPProfile(cmd *cobra.Command, profile acpProfile) error {
 		DefaultThink:          defaultThink,
 		WorkspaceID:           workspaceID,
 		ContenoxDir:           contenoxDir,
+		AutoCompact:           autoCompact,
 		KnownPolicies:         embeddedPolicyNames(),
 		HITLDefaultPolicyName: profile.hitlPolicy,
 		UpdateBanner:          updateBanner,
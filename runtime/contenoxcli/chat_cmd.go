@@ -11,6 +11,7 @@ import (
 
 	libdb "github.com/contenox/runtime/libdbexec"
 	"github.com/contenox/runtime/runtime/agentservice"
+	"github.com/contenox/runtime/runtime/chatservice"
 	"github.com/contenox/runtime/runtime/localtools"
 	"github.com/contenox/runtime/runtime/taskengine"
 )
@@ -36,6 +37,7 @@ type chatOpts struct {
 	EffectiveRaw                 bool
 	EffectiveThink               string
 	HistoryTrim                  int
+	AutoCompact                  chatservice.AutoCompactPolicy
 	LastN                        int
 	InputValue                   string
 	InputFlagPassed              bool
@@ -131,6 +133,7 @@ func execChat(ctx context.Context, db libdb.DBManager, opts chatOpts, out, errW
 	defer stopThoughts()
 
 	agentsMD, agentsMDSource := loadAgentsMDFromCwd()
+	compactChain := loadAutoCompactChain(opts, errW)
 
 	resp, err := ag.Prompt(ctx, agentservice.PromptRequest{
 		SessionID:      sessionID,
@@ -141,6 +144,8 @@ func execChat(ctx context.Context, db libdb.DBManager, opts chatOpts, out, errW
 		HistoryTrim:    opts.HistoryTrim,
 		AgentsMD:       agentsMD,
 		AgentsMDSource: agentsMDSource,
+		AutoCompact:    opts.AutoCompact,
+		CompactChain:   compactChain,
 	})
 	if err != nil {
 		if isModelResolverFailure(err) {
@@ -228,3 +233,21 @@ func buildTemplateVars(opts chatOpts) map[string]string {
 	}
 	return templateVars
 }
+
+// loadAutoCompactChain returns chain-compact.json when the auto-compact
+// setting is on. Without it the history is not compacted, which is worth a
+// warning but not a failed turn.
+func loadAutoCompactChain(opts chatOpts, errW io.Writer) *taskengine.TaskChainDefinition {
+	if !opts.AutoCompact.Enabled() {
+		return nil
+	}
+	path, err := lookupSystemFile(opts.ContenoxDir, "chain-compact.json")
+	if err == nil {
+		var chain *taskengine.TaskChainDefinition
+		if chain, err = loadChainFromFile(path); err == nil {
+			return chain
+		}
+	}
+	fmt.Fprintf(errW, "warning: auto-compact is on but the compaction chain is unavailable — history will not be compacted: %v\n", err)
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/chatservice"
 	"github.com/contenox/runtime/runtime/internal/clikv"
 	"github.com/contenox/runtime/runtime/modelrepo"
 	"github.com/contenox/runtime/runtime/project"
@@ -747,6 +748,10 @@ func runChat(cmd *cobra.Command, args []string) error {
 	autoMode, _ := cmd.Flags().GetBool("auto")
 	effectiveHITL := !autoMode
 	historyTrim, _ := cmd.Flags().GetInt("trim")
+	autoCompact, err := resolveAutoCompact(dbCtx, store)
+	if err != nil {
+		return err
+	}
 	lastN, _ := cmd.Flags().GetInt("last")
 
 	opts := chatOpts{
@@ -769,6 +774,7 @@ func runChat(cmd *cobra.Command, args []string) error {
 		EffectiveRaw:                 effectiveRaw,
 		EffectiveThink:               effectiveThink,
 		HistoryTrim:                  historyTrim,
+		AutoCompact:                  autoCompact,
 		LastN:                        lastN,
 		InputValue:                   inputValue,
 		InputFlagPassed:              inputPassed,
@@ -777,6 +783,17 @@ func runChat(cmd *cobra.Command, args []string) error {
 	return execChat(ctx, db, opts, cmd.OutOrStdout(), cmd.ErrOrStderr())
 }
 
+// resolveAutoCompact reads the auto-compact config key; unset is the disabled
+// policy.
+func resolveAutoCompact(ctx context.Context, store runtimetypes.Store) (chatservice.AutoCompactPolicy, error) {
+	setting, _ := getConfigKV(ctx, store, "auto-compact")
+	policy, err := chatservice.ParseAutoCompactPolicy(setting)
+	if err != nil {
+		return chatservice.AutoCompactPolicy{}, fmt.Errorf("config %w", err)
+	}
+	return policy, nil
+}
+
 func resolveEffectiveThink(ctx context.Context, store runtimetypes.Store, flags *pflag.FlagSet) (string, error) {
 	if flags != nil && flags.Changed("think") {
 		v, _ := flags.GetString("think")
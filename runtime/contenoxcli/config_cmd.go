@@ -10,9 +10,11 @@ import (
 
 	libdb "github.com/contenox/runtime/libdbexec"
 	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/chatservice"
 	"github.com/contenox/runtime/runtime/internal/clikv"
 	"github.com/contenox/runtime/runtime/reasoning"
 	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/contenox/runtime/runtime/taskengine"
 	"github.com/spf13/cobra"
 )
 
@@ -32,6 +34,8 @@ var validConfigKeys = map[string]string{
 	"update-check":                  "Enable automatic update availability checks (true/false). Set false for zero-trust/air-gapped environments.",
 	"default-mission-agent":         "Default declared agent fired by '/mission <intent>' and 'contenox mission fire' with no --agent.",
 	"default-mission-policy":        "Default mission envelope (HITL policy) used when '/mission' or 'contenox mission fire' names none.",
+	"auto-compact":                  "Summarize long chat histories after a turn: messages=N and/or tokens=N thresholds, keep=N recent messages verbatim (e.g. tokens=60000,keep=8). Empty = off.",
+	"model-prices":                  `JSON price table (USD per 1K tokens) for run cost estimates, e.g. {"gpt-4o":{"input_per_1k":0.0025,"output_per_1k":0.01}}.`,
 }
 
 var configCmd = &cobra.Command{
@@ -39,7 +43,7 @@ var configCmd = &cobra.Command{
 	Short: "Manage persistent CLI settings (default model, provider, chain, HITL policy).",
 	Long: `Store and retrieve persistent CLI defaults backed by SQLite.
 
-Global keys (shared across all projects): default-model, default-provider, default-alt-model, default-alt-provider, default-autocomplete-model, default-autocomplete-provider, default-max-tokens, default-think, telemetry-enabled, update-check, default-mission-agent, default-mission-policy
+Global keys (shared across all projects): default-model, default-provider, default-alt-model, default-alt-provider, default-autocomplete-model, default-autocomplete-provider, default-max-tokens, default-think, telemetry-enabled, update-check, default-mission-agent, default-mission-policy, model-prices, auto-compact
 Workspace keys (scoped to current project): default-chain, hitl-policy-name
 
 Supported keys:
@@ -56,7 +60,9 @@ Supported keys:
   default-chain                  Default chain file path
   hitl-policy-name               Active HITL policy file name (e.g. hitl-policy-strict.json)
   default-mission-agent          Default agent fired by /mission and 'mission fire' with no --agent
-  default-mission-policy         Default mission envelope (HITL policy) when none is named`,
+  default-mission-policy         Default mission envelope (HITL policy) when none is named
+  model-prices                   JSON price table per model for run cost estimates
+  auto-compact                   Summarize long chat histories, e.g. tokens=60000,keep=8`,
 }
 
 var configSetCmd = &cobra.Command{
@@ -87,7 +93,8 @@ Examples:
   contenox config set default-max-tokens 8192
   contenox config set default-think    high
   contenox config set default-chain    .contenox/default-chain.json
-  contenox config set hitl-policy-name hitl-policy-strict.json`,
+  contenox config set hitl-policy-name hitl-policy-strict.json
+  contenox config set auto-compact     tokens=60000,keep=8`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		key, value := args[0], args[1]
@@ -108,6 +115,16 @@ Examples:
 			}
 			value = normalized
 		}
+		if key == "model-prices" {
+			if _, err := taskengine.ParsePriceTable(value); err != nil {
+				return err
+			}
+		}
+		if key == "auto-compact" {
+			if _, err := chatservice.ParseAutoCompactPolicy(value); err != nil {
+				return err
+			}
+		}
 		db, store, workspaceID, err := openConfigDBWithWorkspace(cmd)
 		if err != nil {
 			return err
@@ -42,10 +42,11 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 	defer end()
 
 	tools := map[string]taskengine.ToolsRepo{
-		"echo":     localtools.NewEchoTools(),
-		"print":    localtools.NewPrint(tracker),
-		"webtools": localtools.NewWebCaller(tracker),
-		"local_fs": localtools.NewLocalFSTools(opts.EffectiveLocalExecAllowedDir, db),
+		"echo":       localtools.NewEchoTools(),
+		"print":      localtools.NewPrint(tracker),
+		"redact_pii": localtools.NewPIIRedactor(tracker),
+		"webtools":   localtools.NewWebCaller(tracker),
+		"local_fs":   localtools.NewLocalFSTools(opts.EffectiveLocalExecAllowedDir, db),
 	}
 	if opts.EffectiveEnableLocalExec {
 		execOpts := []localtools.LocalExecOption{}
@@ -77,6 +78,7 @@ func BuildEngine(ctx context.Context, db libdbexec.DBManager, opts chatOpts) (*E
 		ContextLength:            opts.EffectiveContext,
 		NoDeleteModels:           opts.EffectiveNoDeleteModels,
 		LocalTools:               tools,
+		AttachmentReader:         localtools.NewWorkspaceAttachmentReader(func(context.Context) string { return opts.EffectiveLocalExecAllowedDir }),
 		EnableHITL:               opts.EffectiveHITL,
 		AskApproval:              askApproval,
 		Tracker:                  tracker,
@@ -25,6 +25,7 @@ Examples:
   contenox model capability set openai gpt-5-mini --think true
   contenox model capability set vllm Qwen/Qwen3-32B --think false
   contenox model capability set ollama my-vlm --vision true
+  contenox model capability set ollama gemma3:1b --tools false
   contenox model capability show openai gpt-5-mini
   contenox model capability unset openai gpt-5-mini`,
 	Args: cobra.NoArgs,
@@ -43,7 +44,11 @@ controls. This is different from --think on chat/run, which selects a reasoning
 level for one invocation.
 
 The --vision flag records whether this provider/model accepts image input. The
-resolver routes image-bearing requests only to vision-capable models.`,
+resolver routes image-bearing requests only to vision-capable models.
+
+The --tools flag records whether this provider/model accepts tool definitions.
+Tools are assumed supported; with --tools false, tasks drop their tool
+definitions before calling the model.`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := libtracker.WithNewRequestID(context.Background())
@@ -55,8 +60,9 @@ resolver routes image-bearing requests only to vision-capable models.`,
 
 		thinkRaw, _ := cmd.Flags().GetString("think")
 		visionRaw, _ := cmd.Flags().GetString("vision")
-		if strings.TrimSpace(thinkRaw) == "" && strings.TrimSpace(visionRaw) == "" {
-			return fmt.Errorf("provide at least one capability flag: --think or --vision")
+		toolsRaw, _ := cmd.Flags().GetString("tools")
+		if strings.TrimSpace(thinkRaw) == "" && strings.TrimSpace(visionRaw) == "" && strings.TrimSpace(toolsRaw) == "" {
+			return fmt.Errorf("provide at least one capability flag: --think, --vision or --tools")
 		}
 		var reported []string
 		if strings.TrimSpace(thinkRaw) != "" {
@@ -80,6 +86,16 @@ resolver routes image-bearing requests only to vision-capable models.`,
 			}
 			reported = append(reported, fmt.Sprintf("vision=%t", canVision))
 		}
+		if strings.TrimSpace(toolsRaw) != "" {
+			canTools, err := parseModelCapabilityBool("--tools", toolsRaw)
+			if err != nil {
+				return err
+			}
+			if override, err = svc.SetTools(ctx, args[0], args[1], canTools); err != nil {
+				return fmt.Errorf("failed to set capability override: %w", err)
+			}
+			reported = append(reported, fmt.Sprintf("tools=%t", canTools))
+		}
 		_, provider, model, keyErr := modelcapability.Key(args[0], args[1])
 		if keyErr != nil {
 			return keyErr
@@ -96,8 +112,9 @@ var modelCapabilityShowCmd = &cobra.Command{
 	Use:   "show <provider> <model>",
 	Short: "Show a manual capability override.",
 	Long: `Print the manual capability override recorded for a provider/model pair.
-Reports the think (reasoning controls) and vision (image input) settings. If no
-override is recorded for the pair, prints that none exists.`,
+Reports the think (reasoning controls), vision (image input) and tools (tool
+definitions) settings. If no override is recorded for the pair, prints that
+none exists.`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := libtracker.WithNewRequestID(context.Background())
@@ -111,7 +128,7 @@ override is recorded for the pair, prints that none exists.`,
 		if err != nil {
 			return fmt.Errorf("failed to read capability override: %w", err)
 		}
-		if !ok || (override.CanThink == nil && override.CanVision == nil) {
+		if !ok || (override.CanThink == nil && override.CanVision == nil && override.CanTools == nil) {
 			_, provider, model, keyErr := modelcapability.Key(args[0], args[1])
 			if keyErr != nil {
 				return keyErr
@@ -126,6 +143,9 @@ override is recorded for the pair, prints that none exists.`,
 		if override.CanVision != nil {
 			parts = append(parts, fmt.Sprintf("vision=%t", *override.CanVision))
 		}
+		if override.CanTools != nil {
+			parts = append(parts, fmt.Sprintf("tools=%t", *override.CanTools))
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "Capability override for %s/%s: %s.\n", override.Provider, override.Model, strings.Join(parts, ", "))
 		return nil
 	},
@@ -185,6 +205,7 @@ func openModelCapabilityService(cmd *cobra.Command) (libdb.DBManager, modelcapab
 func init() {
 	modelCapabilitySetCmd.Flags().String("think", "", "Whether this provider/model supports thinking/reasoning controls (true or false).")
 	modelCapabilitySetCmd.Flags().String("vision", "", "Whether this provider/model accepts image input (true or false).")
+	modelCapabilitySetCmd.Flags().String("tools", "", "Whether this provider/model accepts tool definitions (true or false).")
 
 	modelCapabilityCmd.AddCommand(modelCapabilitySetCmd)
 	modelCapabilityCmd.AddCommand(modelCapabilityShowCmd)
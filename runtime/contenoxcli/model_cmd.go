@@ -463,6 +463,10 @@ var modelSetContextCmd = &cobra.Command{
 	Short: "Set a local context override for a model.",
 	Long: `Override the locally stored context window for a model already known to the local runtime state.
 
+The stored value is the fallback: a context window the backend reports for the
+model (Ollama /api/show, vLLM or OpenAI-compatible model metadata) takes
+precedence, and the stored value applies when detection fails.
+
 Accepts a bare integer or a k/m shorthand (case-insensitive):
   k  – thousands   (12k  = 12 000)
   m  – millions    (1m   = 1 000 000)
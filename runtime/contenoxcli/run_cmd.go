@@ -329,6 +329,10 @@ func buildRunOpts(cmd *cobra.Command, db libdbexec.DBManager, contenoxDir string
 	if err != nil {
 		return chatOpts{}, err
 	}
+	autoCompact, err := resolveAutoCompact(ctx, store)
+	if err != nil {
+		return chatOpts{}, err
+	}
 
 	effectiveModel, _ := flags.GetString("model")
 	if !flags.Changed("model") && (effectiveModel == "" || effectiveModel == defaultModel) {
@@ -385,6 +389,7 @@ func buildRunOpts(cmd *cobra.Command, db libdbexec.DBManager, contenoxDir string
 		EffectiveHITL:                effectiveHITL,
 		EffectiveTracing:             effectiveTracing,
 		EffectiveThink:               effectiveThink,
+		AutoCompact:                  autoCompact,
 		ContenoxDir:                  contenoxDir,
 	}, nil
 }
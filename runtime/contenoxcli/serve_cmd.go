@@ -24,6 +24,8 @@ import (
 	"github.com/contenox/runtime/runtime/agentinstance"
 	"github.com/contenox/runtime/runtime/agentregistryservice"
 	"github.com/contenox/runtime/runtime/agentservice"
+	"github.com/contenox/runtime/runtime/apikeyservice"
+	"github.com/contenox/runtime/runtime/auditservice"
 	"github.com/contenox/runtime/runtime/chainagents"
 	"github.com/contenox/runtime/runtime/enginesvc"
 	"github.com/contenox/runtime/runtime/fleetservice"
@@ -39,7 +41,9 @@ import (
 	"github.com/contenox/runtime/runtime/modelrepo"
 	"github.com/contenox/runtime/runtime/operatorinbox"
 	"github.com/contenox/runtime/runtime/presence"
+	"github.com/contenox/runtime/runtime/promptservice"
 	"github.com/contenox/runtime/runtime/reportrouter"
+	"github.com/contenox/runtime/runtime/runtimestate"
 	"github.com/contenox/runtime/runtime/runtimetypes"
 	"github.com/contenox/runtime/runtime/serverapi"
 	"github.com/contenox/runtime/runtime/shellsession"
@@ -193,6 +197,23 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if err := serverapi.LoadConfig(config); err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	// Validate every setting before anything is opened or started, so a typo
+	// in the environment fails here with the variable's name, not minutes
+	// into startup.
+	settings, err := serverapi.ValidateConfig(config)
+	if err != nil {
+		return err
+	}
+	// LOG_FORMAT / LOG_LEVEL replace the default logger for the whole
+	// process, so the activity tracker's operation lines (which carry the
+	// same request_id) land in the same format as the request lines.
+	if config.StructuredLogging() {
+		logger, err := serverapi.NewLogger(config, cmd.ErrOrStderr())
+		if err != nil {
+			return fmt.Errorf("configure logging: %w", err)
+		}
+		slog.SetDefault(logger)
+	}
 	remote, _ := cmd.Flags().GetBool("remote")
 	config.Addr = resolveServeAddr(remote, config.Addr)
 	if config.Port == "" {
@@ -231,6 +252,9 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if err := serverapi.ValidateLocalServeSecurity(config.Addr, config.Token); err != nil {
 		return err
 	}
+	if len(settings.APIKeys) > 0 && config.Token == "" {
+		return fmt.Errorf("API_KEYS needs a TOKEN: without one the API is not gated and the keys would not be checked")
+	}
 
 	dbPath, err := resolveDBPath(cmd)
 	if err != nil {
@@ -242,6 +266,13 @@ func runServe(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("open database %q: %w", dbPath, err)
 	}
 	defer db.Close()
+	// API keys accepted besides the TOKEN: API_KEYS plus the keys created over
+	// /api/api-keys. The /api gate below enforces the service's live set, so a
+	// revoked key stops working on its next request.
+	apiKeys, err := apikeyservice.New(dbCtx, db, settings.APIKeys)
+	if err != nil {
+		return err
+	}
 
 	contenoxDir, err := ResolveContenoxDir(cmd)
 	if err != nil {
@@ -311,9 +342,13 @@ func runServe(cmd *cobra.Command, args []string) error {
 	}
 	workspaceReloader := newWorkspaceRootReloader(workspaceFactory, workspaceBaseRoots, store)
 
-	var tracker libtracker.ActivityTracker = libtracker.NoopTracker{}
+	// Metrics are always collected: the tracker is already threaded through
+	// reconciliation, downloads, task handlers and model calls, so aggregating
+	// it is what feeds /metrics. Tracing adds the per-operation log lines.
+	metrics := libtracker.NewMetricsTracker()
+	var tracker libtracker.ActivityTracker = metrics
 	if opts.EffectiveTracing {
-		tracker = libtracker.NewLogActivityTracker(slog.Default())
+		tracker = libtracker.NewChainedTracker(libtracker.NewLogActivityTracker(slog.Default()), metrics)
 	}
 
 	bus := libbus.NewSQLite(db.WithoutTransaction())
@@ -326,6 +361,22 @@ func runServe(cmd *cobra.Command, args []string) error {
 	// registers its own row, kind serve, for symmetry) and the READER the board
 	// join below surfaces the observed section from. Best-effort throughout — a
 	// presence write never blocks serve.
+	// Long-running subsystems register their stop here as they start; on
+	// SIGINT/SIGTERM the drain stops them in reverse order, each bounded, and
+	// reports any that hang. The deferred run covers the early-return paths and
+	// is a no-op after the signal path has drained.
+	drain := newServeDrain(serveDrainTimeout)
+	defer func() { _ = drain.run() }()
+
+	// OTEL_EXPORTER_OTLP_ENDPOINT turns on span export. Registered first, the
+	// tracer provider is stopped last, flushing the spans of everything the
+	// drain stopped before it.
+	shutdownTracing, err := serverapi.SetupTracing(ctx, config)
+	if err != nil {
+		return err
+	}
+	drain.add("tracer provider", shutdownTracing)
+
 	presenceStore := presence.NewStore(kvMgr)
 	presenceReporter := presence.StartReporter(ctx, presenceStore, presence.Record{
 		Kind: presence.KindServe,
@@ -337,7 +388,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 		// serve listens on. The token is NOT advertised — presence is world-readable.
 		Address: net.JoinHostPort(config.Addr, config.Port),
 	})
-	defer presenceReporter.Stop()
+	drain.addFunc("presence reporter", presenceReporter.Stop)
 
 	// The workspace-root reload doorbell. `contenox workspace add/remove` (a
 	// SECOND process) and POST/DELETE /workspace/roots both write the durable
@@ -352,9 +403,10 @@ func runServe(cmd *cobra.Command, args []string) error {
 	defer stopWorkspaceReload()
 
 	localTools := map[string]taskengine.ToolsRepo{
-		"echo":     localtools.NewEchoTools(),
-		"print":    localtools.NewPrint(tracker),
-		"webtools": localtools.NewWebCaller(tracker),
+		"echo":       localtools.NewEchoTools(),
+		"print":      localtools.NewPrint(tracker),
+		"redact_pii": localtools.NewPIIRedactor(tracker),
+		"webtools":   localtools.NewWebCaller(tracker),
 		// local_fs roots at the session's chosen workspace (its cwd), falling back
 		// to the default workspace root for sessions without one. The fixed root is
 		// intentionally empty so the cwd resolver, not a static dir, drives
@@ -399,18 +451,14 @@ func runServe(cmd *cobra.Command, args []string) error {
 		taskengine.NewBusTaskEventSink(bus), kvMgr, tracker)
 	hitlSource := hitlPolicySource(contenoxDir)
 	hitlSvc := hitlservice.NewWithDefaultPolicy(hitlSource, runtimetypes.LocalTenantID, store, tracker, "")
-	approvalCeiling, err := parseHITLApprovalCeiling(config.HITLApprovalTimeout)
-	if err != nil {
-		return err
-	}
-	hitlservice.SetApprovalCeiling(hitlSvc, approvalCeiling)
+	hitlservice.SetApprovalCeiling(hitlSvc, settings.HITLApprovalTimeout)
 	// The durability backstop for pending approvals: resolves any row whose
 	// deadline (rule TimeoutS or the ceiling just above) has passed, applying
 	// its stored OnTimeout. Covers both a requester whose own bounded wait
 	// already returned without touching the row, and one whose process
 	// restarted before it could. Mirrors startTerminalReaper's shape below.
 	stopHITLApprovalSweeper := startHITLApprovalSweeper(ctx, hitlSvc, hitlApprovalSweepInterval)
-	defer stopHITLApprovalSweeper()
+	drain.addFunc("HITL approval sweeper", stopHITLApprovalSweeper)
 
 	// serve runs many ACP WebSocket connections (each its own acpsvc.Transport)
 	// behind this SINGLE shared engine, so the engine's one AskApproval callback
@@ -428,6 +476,12 @@ func runServe(cmd *cobra.Command, args []string) error {
 		AltDefaultProvider: opts.EffectiveAltDefaultProvider,
 		ContextLength:      opts.EffectiveContext,
 		NoDeleteModels:     opts.EffectiveNoDeleteModels,
+		BackendTimeout:     settings.BackendTimeout,
+		ReconcileWorkers:   settings.ReconcileWorkers,
+		ModelKeepAlive:     settings.ModelKeepAlive,
+		ModelSelection:     settings.ModelSelection,
+		ToolsRateLimits:    settings.ToolsRateLimits,
+		AttachmentReader:   localtools.NewWorkspaceAttachmentReader(acpsvc.NewServeCwdResolver(db, workspaceFactory)),
 		LocalTools:         localTools,
 		EnableHITL:         true,
 		// Dispatch HITL approval per request: when the contenox session (from ctx)
@@ -464,7 +518,12 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if engine.State == nil {
 		return fmt.Errorf("build engine: runtime state is not configured")
 	}
-	defer engine.Stop()
+	drain.addFunc("engine", engine.Stop)
+
+	// The reconcile loop serves POST /state/reconcile (and the optional
+	// RECONCILE_INTERVAL cadence): triggers are coalesced and run here, off the
+	// request path.
+	drain.addFunc("reconcile loop", startReconcileLoop(ctx, engine.State, settings.ReconcileInterval))
 
 	chainFiles, err := localfileservice.NewPrivileged(contenoxDir)
 	if err != nil {
@@ -486,9 +545,9 @@ func runServe(cmd *cobra.Command, args []string) error {
 	}
 	if terminalCfg.Enabled {
 		terminalSvc = terminalservice.WithActivityTracker(terminalSvc, tracker)
-		defer func() { _ = terminalSvc.CloseAll(context.Background()) }()
+		drain.add("terminal sessions", terminalSvc.CloseAll)
 		stopTerminalReaper := startTerminalReaper(ctx, terminalSvc, terminalReapInterval(terminalCfg.IdleTimeout))
-		defer stopTerminalReaper()
+		drain.addFunc("terminal reaper", stopTerminalReaper)
 	}
 
 	// External-agent instances live OFF any single connection: the Manager owns each
@@ -584,7 +643,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("start report router: %w", err)
 	}
-	defer stopReportRouter()
+	drain.addFunc("report router", stopReportRouter)
 
 	// /acp serves the same acpsvc agent `contenox acp` speaks over stdio, over
 	// a WebSocket instead — see acp_ws.go. It reuses the engine/db/workspace
@@ -607,6 +666,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 			DefaultThink:       opts.EffectiveThink,
 			WorkspaceID:        workspaceID,
 			ContenoxDir:        contenoxDir,
+			AutoCompact:        opts.AutoCompact,
 			WorkspaceRoots:     workspaceFactory,
 			ShellSessions:      shellSessions,
 			// Share the same router the engine's AskApproval consults, so each WS
@@ -640,6 +700,13 @@ func runServe(cmd *cobra.Command, args []string) error {
 		Think:       opts.EffectiveThink,
 	}
 
+	// The audit trail: every mutating /api request is recorded with the
+	// identity that made it (see serverapi.AuditMutations below) and can be
+	// queried at /api/audit. The audit tracker forwards to the shared one, so
+	// these operations still reach the logs and /metrics.
+	audit := auditservice.New(db)
+	auditTracker := auditservice.NewTracker(audit, tracker)
+
 	apiMux := http.NewServeMux()
 	cleanupAPI, err := serverapi.New(ctx, apiMux, nodeID, "local", config, serverapi.Dependencies{
 		DB:                   db,
@@ -648,6 +715,10 @@ func runServe(cmd *cobra.Command, args []string) error {
 		ToolsProviderService: toolsProviderSvc,
 		Agent:                agent,
 		Chains:               chains,
+		Tools:                toolsRepo,
+		Embedder:             engine.Embedder,
+		APIKeys:              apiKeys,
+		Audit:                audit,
 		Fleet:                fleet,
 		Missions:             missions,
 		// The attention layer's per-mission changed-files/diff/scope view, folded
@@ -661,6 +732,9 @@ func runServe(cmd *cobra.Command, args []string) error {
 		// they reached no live supervisor (operator-fired missions, or a parent
 		// session that had ended). Same durable store the router above writes.
 		OperatorInbox: operatorInbox,
+		// The prompt library; the usage report and the in-use check on
+		// delete scan the same chain store the /taskchains routes edit.
+		Prompts: promptservice.New(db, chains),
 		// The /approvals inbox (fleet-consolidation.md slice C2): the same
 		// hitlSvc the engine's AskApproval falls back to above, so answering
 		// a pending ask over REST/CLI resolves the exact row a headless
@@ -700,11 +774,17 @@ func runServe(cmd *cobra.Command, args []string) error {
 	serverapi.AddHealthRoutes(rootMux)
 	serverapi.AddVersionRoutes(rootMux, version.Get(), nodeID, "local")
 	// When a TOKEN is configured, EVERY /api/* request (all methods, incl. GET)
-	// requires a valid credential — a session-cookie JWT or the raw token as a
-	// bearer — closing the same-origin-read hole. Without a token (loopback dev),
-	// browser-originated mutations must be same-origin or explicitly allowed.
-	// StripPrefix lets route packages register clean paths (/state, /models, ...).
-	rootMux.Handle("/api/", http.StripPrefix("/api", serverapi.ProtectAPI(config.Token, config.AllowedAPIOrigins, apiMux)))
+	// requires a valid credential — a session-cookie JWT, the raw token as a
+	// bearer, or an API key limited to its scopes — closing the same-origin-read
+	// hole. Without a token (loopback dev), browser-originated mutations must be
+	// same-origin or explicitly allowed. StripPrefix lets route packages
+	// register clean paths (/state, /models, ...).
+	rootMux.Handle("/api/", http.StripPrefix("/api", serverapi.ProtectAPIWithKeys(config.Token, apiKeys.Keys(), config.AllowedAPIOrigins, serverapi.AuditMutations(auditTracker, apiMux))))
+	// Prometheus scrape endpoint, at the root where scrapers expect it but
+	// behind the same credential gate as /api/*: operation names and counts
+	// describe what the runtime is doing. A scoped key needs the "metrics"
+	// scope.
+	rootMux.Handle("GET /metrics", serverapi.ProtectAPIWithKeys(config.Token, apiKeys.Keys(), config.AllowedAPIOrigins, metrics))
 	// Beam remote-access login: /ui/login issues an HttpOnly session cookie for
 	// the configured TOKEN, /ui/logout clears it, /ui/auth-status reports whether
 	// login is required and the caller is authenticated. Registered directly on
@@ -726,6 +806,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 		StateService: stateservice.New(engine.State, db, workspaceID),
 		Defaults:     runtimeDefaults,
 		Token:        config.Token,
+		Embedder:     engine.Embedder,
 	}
 	compatapi.AddRootRoutes(rootMux, compatDeps)
 	compatapi.AddOllamaRoutes(rootMux, compatDeps)
@@ -743,18 +824,25 @@ func runServe(cmd *cobra.Command, args []string) error {
 	}
 	rootMux.Handle("/", uiHandler)
 
+	// Body limits wrap the whole root mux, so the /api/* product routes and
+	// the root-level compat aliases read through the same cap.
+	var rootHandler http.Handler = apiframework.BodyLimitMiddleware(settings.BodyLimits("/api"), rootMux)
+	if config.StructuredLogging() {
+		rootHandler = apiframework.RequestLogMiddleware(slog.Default(), runtimetypes.LocalTenantID, rootHandler)
+	}
 	handler := middleware.EnableCORS(&middleware.CORSConfig{
 		AllowedAPIOrigins: firstNonEmptyStr(config.AllowedAPIOrigins, middleware.DefaultAllowedAPIOrigins),
 		AllowedMethods:    middleware.DefaultAllowedMethods,
 		AllowedHeaders:    middleware.DefaultAllowedHeaders,
 		ProxyOrigin:       config.ProxyOrigin,
-	}, apiframework.RequestIDMiddleware(rootMux))
+	}, apiframework.RequestIDMiddleware(rootHandler))
 
 	srv := &http.Server{
 		Addr:              net.JoinHostPort(config.Addr, config.Port),
 		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
+	drain.add("http server", srv.Shutdown)
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -775,9 +863,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 
 	select {
 	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		return srv.Shutdown(shutdownCtx)
+		return drain.run()
 	case err := <-errCh:
 		return fmt.Errorf("serve: %w", err)
 	}
@@ -836,7 +922,9 @@ func startTerminalReaper(ctx context.Context, svc terminalservice.Service, inter
 		return func() {}
 	}
 	reaperCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		for {
@@ -848,7 +936,10 @@ func startTerminalReaper(ctx context.Context, svc terminalservice.Service, inter
 			}
 		}
 	}()
-	return cancel
+	return func() {
+		cancel()
+		<-done
+	}
 }
 
 // hitlApprovalSweepInterval is how often startHITLApprovalSweeper resolves
@@ -858,21 +949,19 @@ func startTerminalReaper(ctx context.Context, svc terminalservice.Service, inter
 // short regardless of the ceiling's value.
 const hitlApprovalSweepInterval = 30 * time.Second
 
-// parseHITLApprovalCeiling parses HITL_APPROVAL_TIMEOUT (a Go duration
-// string, e.g. "1h"). Empty keeps hitlservice's built-in
-// DefaultApprovalCeiling; SetApprovalCeiling(svc, 0) is a no-op, so returning
-// a zero duration for "unset" is safe. Mirrors terminalservice.ParseEnv's
-// validation style for a serve config setting.
-func parseHITLApprovalCeiling(raw string) (time.Duration, error) {
-	raw = strings.TrimSpace(raw)
-	if raw == "" {
-		return 0, nil
-	}
-	d, err := time.ParseDuration(raw)
-	if err != nil || d <= 0 {
-		return 0, fmt.Errorf("invalid HITL_APPROVAL_TIMEOUT %q: must be a positive Go duration (e.g. 1h)", raw)
-	}
-	return d, nil
+// startReconcileLoop runs state.RunReconcileLoop until the returned stop is
+// called, which cancels the loop and waits out a cycle already in flight.
+func startReconcileLoop(ctx context.Context, state *runtimestate.State, interval time.Duration) func() {
+	loopCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		state.RunReconcileLoop(loopCtx, interval)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
 }
 
 // startHITLApprovalSweeper periodically resolves pending human-in-the-loop
@@ -881,13 +970,16 @@ func parseHITLApprovalCeiling(raw string) (time.Duration, error) {
 // It is the durability backstop for a requester whose own bounded wait
 // already returned without touching its row, and for one whose process
 // restarted before it could — see hitlservice.Service.SweepExpired's doc.
-// Mirrors startTerminalReaper's ticker/shutdown shape immediately above.
+// Mirrors startTerminalReaper's ticker/shutdown shape immediately above: the
+// returned stop cancels the loop and waits out a sweep already in flight.
 func startHITLApprovalSweeper(ctx context.Context, svc hitlservice.Service, interval time.Duration) func() {
 	if svc == nil || interval <= 0 {
 		return func() {}
 	}
 	sweepCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		for {
@@ -899,7 +991,10 @@ func startHITLApprovalSweeper(ctx context.Context, svc hitlservice.Service, inte
 			}
 		}
 	}()
-	return cancel
+	return func() {
+		cancel()
+		<-done
+	}
 }
 
 // discoverChainAgents runs one chain-agent discovery pass over the two
@@ -0,0 +1,84 @@
+package contenoxcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// serveDrainTimeout bounds how long serve waits for any ONE subsystem to stop
+// on shutdown. Per step rather than overall so a wedged subsystem cannot eat
+// the budget of the ones stopped after it.
+const serveDrainTimeout = 10 * time.Second
+
+// serveDrain stops serve's long-running subsystems — the HTTP server, the
+// background loops, the engine — in the reverse of the order they were
+// started, each within serveDrainTimeout, and names the ones that did not
+// stop cleanly. The stores they write to (db, bus, logs) are closed by
+// runServe's own defers, which run after the drain.
+//
+// A subsystem that misses its deadline is abandoned, not waited on again: its
+// goroutine is left to the process exit that follows.
+type serveDrain struct {
+	timeout time.Duration
+	steps   []serveDrainStep
+}
+
+type serveDrainStep struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+func newServeDrain(timeout time.Duration) *serveDrain {
+	return &serveDrain{timeout: timeout}
+}
+
+// add registers a subsystem. stop should return once the subsystem has
+// finished its in-flight work, or with ctx's error when it gives up.
+func (d *serveDrain) add(name string, stop func(ctx context.Context) error) {
+	d.steps = append(d.steps, serveDrainStep{name: name, stop: stop})
+}
+
+// addFunc registers a subsystem whose stop function takes no deadline.
+func (d *serveDrain) addFunc(name string, stop func()) {
+	d.add(name, func(context.Context) error {
+		stop()
+		return nil
+	})
+}
+
+// run stops every registered subsystem, last registered first, and returns
+// an error naming each one that failed or timed out. It is safe to call more
+// than once; later calls have nothing left to stop.
+func (d *serveDrain) run() error {
+	steps := d.steps
+	d.steps = nil
+	var failed []string
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if err := d.stopOne(step); err != nil {
+			slog.Warn("contenox serve: subsystem did not shut down cleanly", "subsystem", step.name, "error", err)
+			failed = append(failed, fmt.Sprintf("%s (%v)", step.name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("shutdown: %d subsystem(s) did not drain cleanly: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (d *serveDrain) stopOne(step serveDrainStep) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- step.stop(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("timed out after " + d.timeout.String())
+	}
+}
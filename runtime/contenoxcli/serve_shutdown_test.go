@@ -0,0 +1,49 @@
+package contenoxcli
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ServeDrain_StopsInReverseAndNamesFailures(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}
+	drain := newServeDrain(50 * time.Millisecond)
+	drain.addFunc("engine", func() { record("engine") })
+	drain.add("report router", func(context.Context) error {
+		record("report router")
+		return errors.New("stream still open")
+	})
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	drain.add("http server", func(ctx context.Context) error {
+		record("http server")
+		<-release // ignores ctx: a handler that never returns
+		return nil
+	})
+
+	err := drain.run()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "2 subsystem(s)")
+	require.Contains(t, err.Error(), "http server (timed out after 50ms)")
+	require.Contains(t, err.Error(), "report router (stream still open)")
+	require.NotContains(t, err.Error(), "engine")
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"http server", "report router", "engine"}, order,
+		"a hung subsystem must not stop the ones started before it from draining")
+
+	require.NoError(t, drain.run(), "a second run has nothing left to stop")
+}
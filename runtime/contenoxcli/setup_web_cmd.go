@@ -64,6 +64,10 @@ func runSetupWeb(ctx context.Context, out io.Writer, openBrowser bool) error {
 		return fmt.Errorf("runtime state: %w", err)
 	}
 
+	// Trigger-only, so POST /state/reconcile works here as it does under serve.
+	stopReconcileLoop := startReconcileLoop(ctx, state, 0)
+	defer stopReconcileLoop()
+
 	stateSvc := stateservice.New(state, db, workspaceID)
 	backendSvc := backendservice.New(db)
 	providerSvc := providerservice.New(db, workspaceID)
@@ -0,0 +1,47 @@
+package embedservice
+
+import (
+	"context"
+
+	"github.com/contenox/runtime/libtracker"
+)
+
+type activityTrackerDecorator struct {
+	svc     Service
+	tracker libtracker.ActivityTracker
+}
+
+func WithActivityTracker(svc Service, tracker libtracker.ActivityTracker) Service {
+	if tracker == nil {
+		tracker = libtracker.NoopTracker{}
+	}
+	return &activityTrackerDecorator{svc: svc, tracker: tracker}
+}
+
+var _ Service = (*activityTrackerDecorator)(nil)
+
+func (d *activityTrackerDecorator) EmbedBatch(ctx context.Context, texts []string) (*BatchResult, error) {
+	reportErr, reportChange, end := d.tracker.Start(ctx, "embed", "embedding_batch", "model", d.svc.Model(), "inputs", len(texts))
+	defer end()
+	out, err := d.svc.EmbedBatch(ctx, texts)
+	if err != nil {
+		reportErr(err)
+		return nil, err
+	}
+	failed := 0
+	for _, err := range out.Errors {
+		if err != nil {
+			failed++
+		}
+	}
+	reportChange(out.Model, map[string]int{"inputs": len(out.Embeddings), "failed": failed, "prompt_tokens": out.PromptTokens})
+	return out, nil
+}
+
+func (d *activityTrackerDecorator) Model() string {
+	return d.svc.Model()
+}
+
+func (d *activityTrackerDecorator) Dimensions(ctx context.Context) (int, error) {
+	return d.svc.Dimensions(ctx)
+}
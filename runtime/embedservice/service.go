@@ -0,0 +1,287 @@
+// Package embedservice turns text into embedding vectors with the runtime's
+// configured embed model. It is the one place that knows which model embeds:
+// HTTP surfaces such as the OpenAI-compatible /v1/embeddings route go through
+// it rather than picking a model of their own.
+package embedservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/llmrepo"
+	"golang.org/x/sync/errgroup"
+)
+
+// MaxBatchSize is the most texts one EmbedBatch call accepts.
+const MaxBatchSize = 2048
+
+// maxConcurrentEmbeds bounds the single-text embed calls EmbedBatch runs at
+// once for a backend that cannot embed a batch in one call.
+const maxConcurrentEmbeds = 8
+
+// ErrDimensionMismatch is returned when the embed model returns a vector
+// whose length differs from the model's dimension.
+var ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+
+// dimensionProbe is the text embedded to learn the model's dimension.
+const dimensionProbe = "dimension probe"
+
+// BatchResult is the outcome of EmbedBatch.
+type BatchResult struct {
+	// Model is the model that produced the embeddings.
+	Model string
+	// Embeddings holds one vector per input text, in input order.
+	Embeddings [][]float64
+	// PromptTokens is the number of tokens across all inputs.
+	PromptTokens int
+	// Errors is set only by a service built WithItemErrors. It holds one
+	// entry per input text: nil where the text embedded, the failure where
+	// it did not, in which case the text's vector is nil.
+	Errors []error
+}
+
+type Service interface {
+	// EmbedBatch embeds every text with the configured embed model, in one
+	// provider call where the backend supports it and otherwise with
+	// concurrent single-text calls. By default it fails as a whole when any
+	// text fails: callers get all vectors or none. WithItemErrors reports
+	// failures per text instead.
+	EmbedBatch(ctx context.Context, texts []string) (*BatchResult, error)
+	// Model returns the name of the configured embed model.
+	Model() string
+	// Dimensions returns the length of the vectors the embed model returns.
+	// The first call embeds a probe text unless EmbedBatch already learned
+	// the dimension; the answer is cached for the life of the service.
+	Dimensions(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo       llmrepo.ModelRepo
+	model      string
+	provider   string
+	itemErrors bool
+	normalize  bool
+
+	// dims is the model's dimension once known, zero before.
+	mu   sync.Mutex
+	dims int
+}
+
+// Option configures a Service at construction.
+type Option func(*service)
+
+// WithItemErrors makes EmbedBatch report failures per text in
+// BatchResult.Errors rather than failing the whole batch. A failed provider
+// batch call is then retried one text at a time, so the failure is pinned to
+// the texts that cause it. EmbedBatch still fails as a whole on invalid
+// input, when no text embeds, or when ctx ends.
+func WithItemErrors() Option {
+	return func(s *service) { s.itemErrors = true }
+}
+
+// WithNormalize makes EmbedBatch scale every vector to unit length (L2
+// norm 1). This suits stores that rank by cosine similarity or inner
+// product. It changes distance semantics: Euclidean distances between
+// normalized vectors no longer reflect the model's raw magnitudes, so a
+// store must not mix normalized and raw vectors. A zero vector is returned
+// unchanged. Off by default.
+func WithNormalize() Option {
+	return func(s *service) { s.normalize = true }
+}
+
+// New returns a Service that embeds with model, served by a backend of
+// provider type provider. An empty provider accepts any backend serving the
+// model.
+func New(repo llmrepo.ModelRepo, model, provider string, opts ...Option) Service {
+	s := &service{repo: repo, model: model, provider: provider}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *service) Model() string {
+	return s.model
+}
+
+func (s *service) Dimensions(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	dims := s.dims
+	s.mu.Unlock()
+	if dims > 0 {
+		return dims, nil
+	}
+	req := llmrepo.EmbedRequest{ModelName: s.model, ProviderType: s.provider}
+	vector, _, err := s.repo.Embed(ctx, req, dimensionProbe)
+	if err != nil {
+		return 0, fmt.Errorf("probe dimensions of %q: %w", s.model, err)
+	}
+	if len(vector) == 0 {
+		return 0, fmt.Errorf("probe dimensions of %q: model returned an empty vector", s.model)
+	}
+	return s.learnDimensions(len(vector)), nil
+}
+
+// learnDimensions records n as the model's dimension unless one is already
+// known, and returns the dimension in effect.
+func (s *service) learnDimensions(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dims == 0 {
+		s.dims = n
+	}
+	return s.dims
+}
+
+func (s *service) EmbedBatch(ctx context.Context, texts []string) (*BatchResult, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("%w: no input to embed", errdefs.ErrBadRequest)
+	}
+	if len(texts) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: %d inputs exceed the batch limit of %d", errdefs.ErrBadRequest, len(texts), MaxBatchSize)
+	}
+	for i, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("%w: input %d is empty", errdefs.ErrBadRequest, i)
+		}
+	}
+
+	result := &BatchResult{Model: s.model}
+	req := llmrepo.EmbedRequest{ModelName: s.model, ProviderType: s.provider}
+	vectors, meta, err := s.repo.EmbedBatch(ctx, req, texts)
+	switch {
+	case err == nil:
+		if meta.ModelName != "" {
+			result.Model = meta.ModelName
+		}
+		result.Embeddings = vectors
+	case errors.Is(err, llmrepo.ErrBatchEmbedUnsupported) || s.itemErrors:
+		if err := s.embedEach(ctx, req, texts, result); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("embed batch: %w", err)
+	}
+	if s.itemErrors && result.Errors == nil {
+		result.Errors = make([]error, len(texts))
+	}
+	if err := s.checkDimensions(result); err != nil {
+		return nil, err
+	}
+	if s.normalize {
+		for _, vector := range result.Embeddings {
+			normalize(vector)
+		}
+	}
+
+	counts, err := s.repo.CountTokensBatch(ctx, s.model, texts)
+	if err != nil {
+		return nil, fmt.Errorf("count tokens: %w", err)
+	}
+	for _, n := range counts {
+		result.PromptTokens += n
+	}
+	return result, nil
+}
+
+// checkDimensions verifies every vector in result has the model's
+// dimension, learning it from the first vector when it is not yet known. With
+// item errors a mismatched vector fails only its own text.
+func (s *service) checkDimensions(result *BatchResult) error {
+	embedded := 0
+	for i, vector := range result.Embeddings {
+		if vector == nil {
+			continue
+		}
+		var err error
+		if len(vector) == 0 {
+			err = fmt.Errorf("%w: model %q returned an empty vector for input %d", ErrDimensionMismatch, result.Model, i)
+		} else if want := s.learnDimensions(len(vector)); len(vector) != want {
+			err = fmt.Errorf("%w: model %q returned %d dimensions for input %d, want %d", ErrDimensionMismatch, result.Model, len(vector), i, want)
+		}
+		if err != nil {
+			if !s.itemErrors {
+				return err
+			}
+			result.Embeddings[i], result.Errors[i] = nil, err
+			continue
+		}
+		embedded++
+	}
+	if embedded == 0 {
+		return errors.Join(result.Errors...)
+	}
+	return nil
+}
+
+// normalize scales v in place to unit L2 norm. A zero vector has no
+// direction and is left as is.
+func normalize(v []float64) {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+// embedEach embeds texts one call each, at most maxConcurrentEmbeds at a
+// time, into result. The first failure cancels the calls still running,
+// unless the service reports item errors: then every text is tried and the
+// failures are recorded in result.Errors.
+func (s *service) embedEach(ctx context.Context, req llmrepo.EmbedRequest, texts []string, result *BatchResult) error {
+	result.Embeddings = make([][]float64, len(texts))
+	var mu sync.Mutex
+	g, gctx := errgroup.WithContext(ctx)
+	if s.itemErrors {
+		result.Errors = make([]error, len(texts))
+		g = &errgroup.Group{}
+		gctx = ctx
+	}
+	g.SetLimit(maxConcurrentEmbeds)
+	for i, text := range texts {
+		g.Go(func() error {
+			vector, meta, err := s.repo.Embed(gctx, req, text)
+			if err != nil {
+				err = fmt.Errorf("embed input %d: %w", i, err)
+				if s.itemErrors {
+					result.Errors[i] = err
+					return nil
+				}
+				return err
+			}
+			result.Embeddings[i] = vector
+			if meta.ModelName != "" {
+				mu.Lock()
+				result.Model = meta.ModelName
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if s.itemErrors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, vector := range result.Embeddings {
+			if vector != nil {
+				return nil
+			}
+		}
+		return result.Errors[0]
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package embedservice_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/runtime/embedservice"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/llmrepo"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRepo embeds a text as [len(text)], or as [len(text), 0] when it is
+// wide, and counts one token per byte. With batch set it embeds batches in one call; otherwise EmbedBatch is
+// unsupported and texts are embedded one by one.
+type stubRepo struct {
+	llmrepo.ModelRepo
+	failOn string
+	wide   string
+	batch  bool
+
+	mu          sync.Mutex
+	req         llmrepo.EmbedRequest
+	calls       int
+	batchCalls  int
+	inFlight    int
+	maxInFlight int
+}
+
+func (r *stubRepo) Embed(ctx context.Context, req llmrepo.EmbedRequest, prompt string) ([]float64, llmrepo.Meta, error) {
+	r.mu.Lock()
+	r.req = req
+	r.calls++
+	r.inFlight++
+	r.maxInFlight = max(r.maxInFlight, r.inFlight)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.inFlight--
+		r.mu.Unlock()
+	}()
+	time.Sleep(time.Millisecond)
+	if prompt == r.failOn {
+		return nil, llmrepo.Meta{}, errors.New("backend down")
+	}
+	return r.vector(prompt), llmrepo.Meta{ModelName: req.ModelName}, nil
+}
+
+func (r *stubRepo) vector(prompt string) []float64 {
+	if prompt == r.wide {
+		return []float64{float64(len(prompt)), 0}
+	}
+	return []float64{float64(len(prompt))}
+}
+
+func (r *stubRepo) EmbedBatch(ctx context.Context, req llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error) {
+	if !r.batch {
+		return nil, llmrepo.Meta{}, llmrepo.ErrBatchEmbedUnsupported
+	}
+	r.mu.Lock()
+	r.req = req
+	r.batchCalls++
+	r.mu.Unlock()
+	vectors := make([][]float64, len(prompts))
+	for i, prompt := range prompts {
+		if prompt == r.failOn {
+			return nil, llmrepo.Meta{}, errors.New("backend down")
+		}
+		vectors[i] = r.vector(prompt)
+	}
+	return vectors, llmrepo.Meta{ModelName: req.ModelName}, nil
+}
+
+func (r *stubRepo) CountTokensBatch(ctx context.Context, modelName string, texts []string) ([]int, error) {
+	counts := make([]int, len(texts))
+	for i, text := range texts {
+		counts[i] = len(text)
+	}
+	return counts, nil
+}
+
+func TestUnit_EmbedBatch_KeepsInputOrder(t *testing.T) {
+	repo := &stubRepo{}
+	svc := embedservice.New(repo, "nomic-embed-text", "ollama")
+
+	result, err := svc.EmbedBatch(context.Background(), []string{"a", "abc", "ab"})
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{1}, {3}, {2}}, result.Embeddings)
+	require.Equal(t, 6, result.PromptTokens)
+	require.Equal(t, "nomic-embed-text", result.Model)
+	require.Equal(t, llmrepo.EmbedRequest{ModelName: "nomic-embed-text", ProviderType: "ollama"}, repo.req)
+}
+
+func TestUnit_EmbedBatch_UsesProviderBatchCall(t *testing.T) {
+	repo := &stubRepo{batch: true}
+	svc := embedservice.New(repo, "nomic-embed-text", "ollama")
+
+	result, err := svc.EmbedBatch(context.Background(), []string{"a", "abc", "ab"})
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{1}, {3}, {2}}, result.Embeddings)
+	require.Equal(t, 1, repo.batchCalls)
+	require.Zero(t, repo.calls)
+}
+
+func TestUnit_EmbedBatch_BoundsConcurrentCalls(t *testing.T) {
+	repo := &stubRepo{}
+	svc := embedservice.New(repo, "m", "")
+
+	texts := make([]string, 50)
+	for i := range texts {
+		texts[i] = "text"
+	}
+	result, err := svc.EmbedBatch(context.Background(), texts)
+	require.NoError(t, err)
+	require.Len(t, result.Embeddings, 50)
+	require.Equal(t, 50, repo.calls)
+	require.Greater(t, repo.maxInFlight, 1, "single-text calls should run concurrently")
+	require.LessOrEqual(t, repo.maxInFlight, 8)
+}
+
+func TestUnit_EmbedBatch_RejectsEmptyInput(t *testing.T) {
+	svc := embedservice.New(&stubRepo{}, "m", "")
+
+	_, err := svc.EmbedBatch(context.Background(), nil)
+	require.ErrorIs(t, err, errdefs.ErrBadRequest)
+
+	_, err = svc.EmbedBatch(context.Background(), []string{"ok", " "})
+	require.ErrorIs(t, err, errdefs.ErrBadRequest)
+}
+
+func TestUnit_EmbedBatch_FailsWhole(t *testing.T) {
+	svc := embedservice.New(&stubRepo{failOn: "bad"}, "m", "")
+
+	result, err := svc.EmbedBatch(context.Background(), []string{"good", "bad"})
+	require.Error(t, err)
+	require.Nil(t, result)
+	require.Contains(t, err.Error(), "input 1")
+}
+
+func TestUnit_EmbedBatch_ItemErrors(t *testing.T) {
+	for _, batch := range []bool{false, true} {
+		repo := &stubRepo{failOn: "bad", batch: batch}
+		svc := embedservice.New(repo, "m", "", embedservice.WithItemErrors())
+
+		result, err := svc.EmbedBatch(context.Background(), []string{"good", "bad", "ok"})
+		require.NoError(t, err)
+		require.Equal(t, [][]float64{{4}, nil, {2}}, result.Embeddings)
+		require.Len(t, result.Errors, 3)
+		require.NoError(t, result.Errors[0])
+		require.ErrorContains(t, result.Errors[1], "input 1")
+		require.NoError(t, result.Errors[2])
+		require.Equal(t, 3, repo.calls, "every text is tried after a failure")
+	}
+
+	_, err := embedservice.New(&stubRepo{failOn: "bad"}, "m", "", embedservice.WithItemErrors()).
+		EmbedBatch(context.Background(), []string{"bad"})
+	require.ErrorContains(t, err, "input 0", "a batch with no vector fails as a whole")
+}
+
+func TestUnit_Dimensions_ProbesOnce(t *testing.T) {
+	repo := &stubRepo{}
+	svc := embedservice.New(repo, "m", "")
+
+	dims, err := svc.Dimensions(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, dims)
+	dims, err = svc.Dimensions(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, dims)
+	require.Equal(t, 1, repo.calls)
+
+	repo = &stubRepo{}
+	svc = embedservice.New(repo, "m", "")
+	_, err = svc.EmbedBatch(context.Background(), []string{"a"})
+	require.NoError(t, err)
+	_, err = svc.Dimensions(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, repo.calls, "a batch already told the dimension")
+}
+
+func TestUnit_EmbedBatch_RejectsDimensionMismatch(t *testing.T) {
+	svc := embedservice.New(&stubRepo{wide: "wide"}, "nomic-embed-text", "")
+
+	_, err := svc.EmbedBatch(context.Background(), []string{"a", "wide"})
+	require.ErrorIs(t, err, embedservice.ErrDimensionMismatch)
+	require.ErrorContains(t, err, `"nomic-embed-text"`)
+
+	svc = embedservice.New(&stubRepo{wide: "wide"}, "m", "", embedservice.WithItemErrors())
+	result, err := svc.EmbedBatch(context.Background(), []string{"a", "wide"})
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{1}, nil}, result.Embeddings)
+	require.ErrorIs(t, result.Errors[1], embedservice.ErrDimensionMismatch)
+}
+
+func TestUnit_EmbedBatch_Normalize(t *testing.T) {
+	repo := &zeroRepo{stubRepo: &stubRepo{wide: "wide"}}
+	svc := embedservice.New(repo, "m", "", embedservice.WithNormalize())
+
+	result, err := svc.EmbedBatch(context.Background(), []string{"wide", "zero"})
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 0}, result.Embeddings[0])
+	require.Equal(t, []float64{0, 0}, result.Embeddings[1], "a zero vector stays zero, not NaN")
+
+	result, err = embedservice.New(&stubRepo{}, "m", "").EmbedBatch(context.Background(), []string{"abc"})
+	require.NoError(t, err)
+	require.Equal(t, []float64{3}, result.Embeddings[0], "vectors are raw by default")
+}
+
+// zeroRepo embeds "zero" as a two-dimensional zero vector.
+type zeroRepo struct {
+	*stubRepo
+}
+
+func (r *zeroRepo) Embed(ctx context.Context, req llmrepo.EmbedRequest, prompt string) ([]float64, llmrepo.Meta, error) {
+	if prompt == "zero" {
+		return []float64{0, 0}, llmrepo.Meta{}, nil
+	}
+	return r.stubRepo.Embed(ctx, req, prompt)
+}
@@ -2,13 +2,16 @@ package enginesvc
 
 import (
 	"context"
+	"time"
 
 	"github.com/contenox/runtime/libbus"
 	"github.com/contenox/runtime/libkvstore"
 	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/embedservice"
 	"github.com/contenox/runtime/runtime/execservice"
 	"github.com/contenox/runtime/runtime/hitlservice"
 	"github.com/contenox/runtime/runtime/internal/setupcheck"
+	"github.com/contenox/runtime/runtime/llmrepo"
 	"github.com/contenox/runtime/runtime/localtools"
 	"github.com/contenox/runtime/runtime/mcpworker"
 	"github.com/contenox/runtime/runtime/runtimestate"
@@ -33,6 +36,28 @@ type Config struct {
 
 	NoDeleteModels bool
 
+	// BackendTimeout bounds how long one backend may take to be observed in a
+	// reconcile cycle. 0 keeps runtimestate.DefaultBackendTimeout.
+	BackendTimeout time.Duration
+	// ReconcileWorkers is how many backends a reconcile cycle observes
+	// concurrently. 0 keeps runtimestate.DefaultReconcileWorkers.
+	ReconcileWorkers int
+	// ModelKeepAlive keeps the declared models of Ollama backends loaded for
+	// this long after each reconcile (see runtimestate.WithWarmUp). 0
+	// disables warm-up.
+	ModelKeepAlive time.Duration
+	// ModelSelection picks the provider and backend among the candidates that
+	// match a model request (see llmrepo.ModelManagerConfig.Selection). Nil
+	// keeps the random default.
+	ModelSelection llmrepo.SelectionPolicy
+	// ToolsRateLimits holds calls to the named tools to a quota (see
+	// localtools.RateLimitedTools). Nil leaves every tools unlimited.
+	ToolsRateLimits map[string]localtools.RateLimit
+	// AttachmentReader reads the files attached to chat messages for
+	// chat_completion tasks with inline_attachments. Nil leaves attachments
+	// uninlined.
+	AttachmentReader taskengine.AttachmentReader
+
 	LocalTools map[string]taskengine.ToolsRepo
 
 	EnableHITL            bool
@@ -73,7 +98,10 @@ type Engine struct {
 	LocalTools    []string
 	SetupCheck    setupcheck.Result
 	TaskEventSink taskengine.TaskEventSink
-	Stop          func()
+	// Embedder embeds text with the engine's embed model; the OpenAI-compatible
+	// /v1/embeddings route is served from it.
+	Embedder embedservice.Service
+	Stop     func()
 	// SetupStatus recomputes current readiness from live runtime state (read-only:
 	// reads synced backend state + config, never probes or runs a completion).
 	// SetupCheck above is the build-time snapshot; this reflects the latest state.
@@ -11,6 +11,7 @@ import (
 	"github.com/contenox/runtime/libdbexec"
 	"github.com/contenox/runtime/libkvstore"
 	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/embedservice"
 	"github.com/contenox/runtime/runtime/execservice"
 	"github.com/contenox/runtime/runtime/hitlservice"
 	"github.com/contenox/runtime/runtime/internal/setupcheck"
@@ -19,6 +20,7 @@ import (
 	"github.com/contenox/runtime/runtime/localtools"
 	"github.com/contenox/runtime/runtime/mcpworker"
 	"github.com/contenox/runtime/runtime/missiontools"
+	"github.com/contenox/runtime/runtime/modelrepo/ollama"
 	"github.com/contenox/runtime/runtime/ollamatokenizer"
 	"github.com/contenox/runtime/runtime/runtimestate"
 	"github.com/contenox/runtime/runtime/runtimetypes"
@@ -65,10 +67,20 @@ func Build(ctx context.Context, db libdbexec.DBManager, cfg Config) (*Engine, er
 		stateOpts := []runtimestate.Option{
 			runtimestate.WithKVStore(kvMgr),
 			runtimestate.WithAutoDiscoverModels(),
+			runtimestate.WithContextProbe(llmrepo.NewContextProbe(http.DefaultClient).ContextLength),
 		}
 		if cfg.NoDeleteModels {
 			stateOpts = append(stateOpts, runtimestate.WithSkipDeleteUndeclaredModels())
 		}
+		if cfg.BackendTimeout > 0 {
+			stateOpts = append(stateOpts, runtimestate.WithBackendTimeout(cfg.BackendTimeout))
+		}
+		if cfg.ReconcileWorkers > 0 {
+			stateOpts = append(stateOpts, runtimestate.WithReconcileWorkers(cfg.ReconcileWorkers))
+		}
+		if cfg.ModelKeepAlive > 0 {
+			stateOpts = append(stateOpts, runtimestate.WithWarmUp(cfg.ModelKeepAlive, warmOllamaModel))
+		}
 		var err error
 		state, err = runtimestate.New(engineCtx, db, bus, stateOpts...)
 		if err != nil {
@@ -169,10 +181,12 @@ func Build(ctx context.Context, db libdbexec.DBManager, cfg Config) (*Engine, er
 		DefaultPromptModel:    llmrepo.ModelConfig{Name: cfg.DefaultModel, Provider: cfg.DefaultProvider},
 		DefaultEmbeddingModel: llmrepo.ModelConfig{Name: cfg.DefaultModel, Provider: cfg.DefaultProvider},
 		DefaultChatModel:      llmrepo.ModelConfig{Name: cfg.DefaultModel, Provider: cfg.DefaultProvider},
+		Selection:             cfg.ModelSelection,
 	}, tracker)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create model manager: %w", err)
 	}
+	engine.Embedder = embedservice.WithActivityTracker(embedservice.New(repo, cfg.DefaultModel, cfg.DefaultProvider), tracker)
 
 	eventSink := cfg.TaskEventSink
 	if eventSink == nil {
@@ -186,6 +200,9 @@ func Build(ctx context.Context, db libdbexec.DBManager, cfg Config) (*Engine, er
 	}
 
 	execCtx := taskengine.WithTaskEventSink(engineCtx, eventSink)
+	if cfg.AttachmentReader != nil {
+		execCtx = taskengine.WithAttachmentReader(execCtx, cfg.AttachmentReader)
+	}
 
 	exec, err := taskengine.NewExec(execCtx, repo, toolsRepo, tracker)
 	if err != nil {
@@ -238,6 +255,12 @@ func buildTools(engineCtx context.Context, cfg Config, db libdbexec.DBManager, t
 	}
 	toolsRepo := tools.NewPersistentRepo(cfg.LocalTools, db, http.DefaultClient, bus, tracker)
 
+	// Quotas sit inside the HITL gate, so a call waiting for approval does
+	// not hold a token and a denied call never takes one.
+	if len(cfg.ToolsRateLimits) > 0 {
+		toolsRepo = localtools.NewRateLimitedTools(toolsRepo, cfg.ToolsRateLimits, tracker)
+	}
+
 	if cfg.EnableHITL {
 		if cfg.AskApproval == nil {
 			return nil, nil, nil, fmt.Errorf("enginesvc: EnableHITL is true but AskApproval callback is nil")
@@ -270,6 +293,11 @@ func buildTools(engineCtx context.Context, cfg Config, db libdbexec.DBManager, t
 		)
 	}
 
+	// Tasks marked idempotent must not repeat a side effect when they are
+	// retried. The cache sits outside the HITL gate so a repeat is answered
+	// without asking for approval a second time.
+	toolsRepo = taskengine.IdempotentTools(toolsRepo, nil)
+
 	// Attention layer, Stage 0 — the inward face. This is THE single seam: one
 	// decorator over the aggregate tools repo observes every provider (local_fs,
 	// local_shell, webtools, mission, MCP) without touching them, and feeds
@@ -308,3 +336,9 @@ func (h *hitlExemptRepo) Exec(ctx context.Context, startingTime time.Time, input
 	}
 	return h.ToolsRepo.Exec(ctx, startingTime, input, debug, args)
 }
+
+// warmOllamaModel is the runtimestate.ModelWarmer the engine wires in:
+// warm-up is only offered for Ollama backends.
+func warmOllamaModel(ctx context.Context, backend *runtimetypes.Backend, apiKey, model string, embedOnly bool, keepAlive time.Duration) error {
+	return ollama.WarmUp(ctx, backend.BaseURL, apiKey, model, embedOnly, keepAlive, http.DefaultClient)
+}
@@ -0,0 +1,203 @@
+package execservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRunNotFound is returned for a run ID the registry does not know:
+	// never started, or finished long enough ago to have been evicted.
+	ErrRunNotFound = errors.New("execution run not found")
+	// ErrRunExists is returned when a run ID is reused while that run is
+	// still in flight.
+	ErrRunExists = errors.New("execution run already in flight")
+)
+
+// RunStatus is the lifecycle state of a chain execution.
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunCompleted RunStatus = "completed"
+	RunFailed    RunStatus = "failed"
+	// RunCancelled is a run stopped by CancelExecution or by its caller going
+	// away — reported apart from RunFailed so clients can tell "stopped on
+	// request" from "broke".
+	RunCancelled RunStatus = "cancelled"
+)
+
+// DefaultRunRetention is how many finished runs a Runs registry remembers for
+// status queries when NewRuns is given no positive limit.
+const DefaultRunRetention = 256
+
+// RunInfo is the status of one chain execution.
+type RunInfo struct {
+	RunID     string     `json:"runId" example:"7d1c6f0a-3b4e-4a8e-9f1e-2c5d8b9a0e11"`
+	ChainID   string     `json:"chainId" example:"chat-chain"`
+	Status    RunStatus  `json:"status" example:"running"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	// Result is what the run returned, as set with SetResult; absent while
+	// the run is going.
+	Result any `json:"result,omitempty"`
+}
+
+// Runs is a registry of chain executions keyed by run ID. It owns the cancel
+// function of every in-flight run, so a run started by one request can be
+// cancelled from another, and it remembers the outcome of the most recent
+// finished runs for status queries. It is safe for concurrent use.
+type Runs struct {
+	mu       sync.Mutex
+	runs     map[string]*run
+	finished []string // finished run IDs, oldest first
+	retain   int
+}
+
+type run struct {
+	info      RunInfo
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// NewRuns returns an empty registry that remembers up to retain finished runs;
+// a non-positive retain uses DefaultRunRetention.
+func NewRuns(retain int) *Runs {
+	if retain <= 0 {
+		retain = DefaultRunRetention
+	}
+	return &Runs{runs: map[string]*run{}, retain: retain}
+}
+
+// Start registers an execution under runID and returns the context to run it
+// with and the function to call with its result once it returns. The context
+// is cancelled by CancelExecution. A finished run's ID may be reused; a
+// running one's may not.
+func (s *Runs) Start(ctx context.Context, runID, chainID string) (context.Context, func(error), error) {
+	if runID == "" {
+		return nil, nil, fmt.Errorf("run ID is required")
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	r := &run{
+		info: RunInfo{
+			RunID:     runID,
+			ChainID:   chainID,
+			Status:    RunRunning,
+			StartedAt: time.Now().UTC(),
+		},
+		cancel: cancel,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prev, ok := s.runs[runID]; ok {
+		if prev.info.Status == RunRunning {
+			cancel()
+			return nil, nil, fmt.Errorf("%w: %s", ErrRunExists, runID)
+		}
+		s.forgetLocked(runID)
+	}
+	s.runs[runID] = r
+
+	var once sync.Once
+	finish := func(err error) {
+		once.Do(func() {
+			s.finish(r, err)
+			cancel()
+		})
+	}
+	return runCtx, finish, nil
+}
+
+func (s *Runs) finish(r *run, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ended := time.Now().UTC()
+	r.info.EndedAt = &ended
+	switch {
+	case err == nil:
+		r.info.Status = RunCompleted
+	case r.cancelled || errors.Is(err, context.Canceled):
+		r.info.Status = RunCancelled
+		r.info.Error = err.Error()
+	default:
+		r.info.Status = RunFailed
+		r.info.Error = err.Error()
+	}
+	if s.runs[r.info.RunID] != r {
+		return
+	}
+	s.finished = append(s.finished, r.info.RunID)
+	for len(s.finished) > s.retain {
+		delete(s.runs, s.finished[0])
+		s.finished = s.finished[1:]
+	}
+}
+
+func (s *Runs) forgetLocked(runID string) {
+	delete(s.runs, runID)
+	for i, id := range s.finished {
+		if id == runID {
+			s.finished = append(s.finished[:i], s.finished[i+1:]...)
+			break
+		}
+	}
+}
+
+// CancelExecution cancels the context of the in-flight run runID, which
+// aborts the model or hook call it is waiting on; the run then finishes as
+// RunCancelled. Cancelling a run that already finished is a no-op, so a
+// cancel racing the run's own completion is not an error.
+func (s *Runs) CancelExecution(ctx context.Context, runID string) error {
+	_ = ctx // cancellation is in-process; kept for interface uniformity.
+	s.mu.Lock()
+	r, ok := s.runs[runID]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	running := r.info.Status == RunRunning
+	if running {
+		r.cancelled = true
+	}
+	s.mu.Unlock()
+	if running {
+		r.cancel()
+	}
+	return nil
+}
+
+// SetResult records what run runID returned, reported by Get alongside its
+// status. Call it before the run's finish function so a finished run is never
+// seen without its result.
+func (s *Runs) SetResult(runID string, result any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.runs[runID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	r.info.Result = result
+	return nil
+}
+
+// Get returns the status of run runID.
+func (s *Runs) Get(ctx context.Context, runID string) (RunInfo, error) {
+	_ = ctx
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.runs[runID]
+	if !ok {
+		return RunInfo{}, fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	info := r.info
+	if info.EndedAt != nil {
+		ended := *info.EndedAt
+		info.EndedAt = &ended
+	}
+	return info, nil
+}
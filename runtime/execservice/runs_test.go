@@ -0,0 +1,65 @@
+package execservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUnit_Runs_CancelExecutionReportsCancelledNotFailed(t *testing.T) {
+	runs := NewRuns(0)
+
+	ctx, finish, err := runs.Start(context.Background(), "run-1", "chain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runs.Start(context.Background(), "run-1", "chain"); !errors.Is(err, ErrRunExists) {
+		t.Fatalf("expected ErrRunExists for an in-flight run, got: %v", err)
+	}
+	if err := runs.CancelExecution(context.Background(), "run-1"); err != nil {
+		t.Fatal(err)
+	}
+	<-ctx.Done()
+	finish(ctx.Err())
+
+	info, err := runs.Get(context.Background(), "run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != RunCancelled || info.EndedAt == nil {
+		t.Fatalf("info = %#v, want cancelled", info)
+	}
+	if err := runs.CancelExecution(context.Background(), "run-1"); err != nil {
+		t.Fatalf("cancelling a finished run should be a no-op, got: %v", err)
+	}
+
+	_, finish, err = runs.Start(context.Background(), "run-2", "chain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	finish(errors.New("model unavailable"))
+	if info, _ := runs.Get(context.Background(), "run-2"); info.Status != RunFailed {
+		t.Fatalf("status = %q, want failed", info.Status)
+	}
+
+	if err := runs.CancelExecution(context.Background(), "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("expected ErrRunNotFound, got: %v", err)
+	}
+}
+
+func TestUnit_Runs_EvictsOldestFinishedRuns(t *testing.T) {
+	runs := NewRuns(2)
+	for _, id := range []string{"a", "b", "c"} {
+		_, finish, err := runs.Start(context.Background(), id, "chain")
+		if err != nil {
+			t.Fatal(err)
+		}
+		finish(nil)
+	}
+	if _, err := runs.Get(context.Background(), "a"); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("expected the oldest run to be evicted, got: %v", err)
+	}
+	if info, err := runs.Get(context.Background(), "c"); err != nil || info.Status != RunCompleted {
+		t.Fatalf("info = %#v, err = %v", info, err)
+	}
+}
@@ -836,6 +836,7 @@ func defaultPolicy() *Policy {
 			{Tools: "webtools", Tool: "web_delete", Action: ActionApprove},
 			{Tools: "echo", Action: ActionAllow},
 			{Tools: "print", Action: ActionAllow},
+			{Tools: "redact_pii", Action: ActionAllow},
 		}...),
 	}
 }
@@ -0,0 +1,66 @@
+// Package apikeyapi exposes API key management (runtime/apikeyservice) over
+// REST: create a key with an identity and optional scopes, list keys without
+// their secrets, and revoke a key.
+package apikeyapi
+
+import (
+	"fmt"
+	"net/http"
+
+	apiframework "github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/runtime/apikeyservice"
+)
+
+// AddRoutes registers the API key routes on mux.
+func AddRoutes(mux *http.ServeMux, svc apikeyservice.Service) {
+	h := &keyHandler{svc: svc}
+
+	mux.HandleFunc("GET /api-keys", h.list)
+	mux.HandleFunc("POST /api-keys", h.create)
+	mux.HandleFunc("DELETE /api-keys/{id}", h.revoke)
+}
+
+type keyHandler struct {
+	svc apikeyservice.Service
+}
+
+// list returns every API key, configured and created, without secrets.
+func (h *keyHandler) list(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.svc.List(r.Context())
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, keys) // @response []apikeyservice.Key
+}
+
+// create makes a new API key. The response carries the key's secret, which
+// is not shown again.
+func (h *keyHandler) create(w http.ResponseWriter, r *http.Request) {
+	req, err := apiframework.Decode[apikeyservice.CreateRequest](r) // @request apikeyservice.CreateRequest
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	key, err := h.svc.Create(r.Context(), &req)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusCreated, key) // @response apikeyservice.CreatedKey
+}
+
+// revoke deletes a created API key; requests presenting it fail from then
+// on. Keys configured by API_KEYS are refused with 409.
+func (h *keyHandler) revoke(w http.ResponseWriter, r *http.Request) {
+	id := apiframework.GetPathParam(r, "id", "The API key ID.")
+	if id == "" {
+		_ = apiframework.Error(w, r, fmt.Errorf("missing id parameter %w", apiframework.ErrBadPathValue), apiframework.DeleteOperation)
+		return
+	}
+	if err := h.svc.Revoke(r.Context(), id); err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.DeleteOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, "API key revoked") // @response string
+}
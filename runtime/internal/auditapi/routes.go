@@ -0,0 +1,87 @@
+// Package auditapi exposes the audit trail (runtime/auditservice) over REST:
+// the create, update and delete operations performed through the API, by
+// whom, and whether they succeeded.
+package auditapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	apiframework "github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/runtime/auditservice"
+)
+
+// AddRoutes registers the audit trail routes on mux.
+func AddRoutes(mux *http.ServeMux, svc auditservice.Service) {
+	h := &auditHandler{svc: svc}
+
+	mux.HandleFunc("GET /audit", h.list)
+}
+
+type auditHandler struct {
+	svc auditservice.Service
+}
+
+// EntryPage is one page of GET /audit.
+type EntryPage struct {
+	Data       []*auditservice.Entry `json:"data"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+	HasMore    bool                  `json:"hasMore"`
+}
+
+// list returns audit entries, newest first, optionally narrowed to one
+// identity, one resource type or resource, and a time range. To fetch the
+// next page pass the nextCursor of the previous one as cursor.
+func (h *auditHandler) list(w http.ResponseWriter, r *http.Request) {
+	cursor, limit, err := apiframework.PageParams(r, 100)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	q := auditservice.Query{
+		Identity:     strings.TrimSpace(apiframework.GetQueryParam(r, "identity", "", "Only return operations performed by this identity.")),
+		ResourceType: strings.TrimSpace(apiframework.GetQueryParam(r, "resourceType", "", "Only return operations on this resource type, the API route group such as prompts or backends.")),
+		ResourceID:   strings.TrimSpace(apiframework.GetQueryParam(r, "resourceId", "", "Only return operations on the resource with this ID.")),
+	}
+	since := apiframework.GetQueryParam(r, "since", "", "Only return operations at or after this RFC 3339 time.")
+	if q.Since, err = parseTime("since", since); err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	until := apiframework.GetQueryParam(r, "until", "", "Only return operations before this RFC 3339 time.")
+	if q.Until, err = parseTime("until", until); err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+
+	var after *auditservice.Cursor
+	if cursor != nil {
+		after = &auditservice.Cursor{Timestamp: cursor.CreatedAt, ID: cursor.ID}
+	}
+	// One extra entry tells Paginate whether another page follows.
+	entries, err := h.svc.List(r.Context(), q, after, limit+1)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	page := apiframework.Paginate(entries, limit, func(e *auditservice.Entry) apiframework.PageCursor {
+		return apiframework.PageCursor{CreatedAt: e.Timestamp, ID: e.ID}
+	})
+
+	_ = apiframework.Encode(w, r, http.StatusOK, EntryPage(page)) // @response auditapi.EntryPage
+}
+
+// parseTime parses the RFC 3339 time in the query parameter name; empty is
+// the zero time.
+func parseTime(name, raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, apiframework.InvalidParameterValue(name, "expected an RFC 3339 time such as 2026-01-01T00:00:00Z")
+	}
+	return t, nil
+}
@@ -17,7 +17,11 @@ func AddBackendRoutes(mux *http.ServeMux, backendService backendservice.Service,
 	b := &backendManager{service: backendService, stateService: stateService}
 
 	mux.HandleFunc("POST /backends", b.createBackend)
+	mux.HandleFunc("POST /backends/test", b.testBackendConnection)
+	mux.HandleFunc("POST /backends/ensure", b.ensureBackend)
 	mux.HandleFunc("GET /backends", b.listBackends)
+	mux.HandleFunc("GET /backends/export", b.exportBackends)
+	mux.HandleFunc("POST /backends/import", b.importBackends)
 	mux.HandleFunc("GET /backends/{id}", b.getBackend)
 	mux.HandleFunc("PUT /backends/{id}", b.updateBackend)
 	mux.HandleFunc("DELETE /backends/{id}", b.deleteBackend)
@@ -62,6 +66,61 @@ func (b *backendManager) createBackend(w http.ResponseWriter, r *http.Request) {
 	_ = apiframework.Encode(w, r, http.StatusCreated, backend) // @response runtimetypes.Backend
 }
 
+type backendConnectionTest struct {
+	OK     bool   `json:"ok" example:"false"`
+	Detail string `json:"detail" example:"API key not configured for openai"`
+}
+
+// testBackendConnection probes an unsaved backend configuration — listing its
+// models with the provider credentials configured for its type — and reports
+// whether it answered. Nothing is stored. A failed probe is still a 200: the
+// request was served, and ok/detail carry the backend's answer.
+func (b *backendManager) testBackendConnection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	backend, err := apiframework.Decode[runtimetypes.Backend](r) // @request runtimetypes.Backend
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ExecuteOperation)
+		return
+	}
+	ok, detail := b.stateService.TestBackendConnection(ctx, backend)
+
+	_ = apiframework.Encode(w, r, http.StatusOK, backendConnectionTest{OK: ok, Detail: detail}) // @response backendapi.backendConnectionTest
+}
+
+type backendEnsureResult struct {
+	// Action is created, updated, or unchanged.
+	Action  string               `json:"action" example:"created"`
+	Backend runtimetypes.Backend `json:"backend" openapi_include_type:"runtimetypes.Backend"`
+}
+
+// ensureBackend creates the backend if none matches it and updates the
+// matching one otherwise, so provisioning scripts can apply the same
+// configuration on every run without handling conflicts. The match key is
+// the backend's name unless match=url asks for its type and baseUrl. A
+// created backend is answered with 201, an updated or unchanged one with 200.
+func (b *backendManager) ensureBackend(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	match := apiframework.GetQueryParam(r, "match", "name", "Key an existing backend is matched by: name, or url for the type and baseUrl pair.")
+
+	backend, err := apiframework.Decode[runtimetypes.Backend](r) // @request runtimetypes.Backend
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.UpdateOperation)
+		return
+	}
+	action, err := b.service.Ensure(ctx, &backend, backendservice.EnsureMatch(match))
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.UpdateOperation)
+		return
+	}
+
+	status := http.StatusOK
+	if action == backendservice.ImportCreated {
+		status = http.StatusCreated
+	}
+	_ = apiframework.Encode(w, r, status, backendEnsureResult{Action: action, Backend: backend}) // @response backendapi.backendEnsureResult
+}
+
 // listBackends returns the registered backends, each merged with its observed
 // runtime state (downloaded models, pull progress, last error).
 func (b *backendManager) listBackends(w http.ResponseWriter, r *http.Request) {
@@ -62,6 +62,94 @@ func TestCreateBackendDuplicateReturnsSanitizedConflict(t *testing.T) {
 	}
 }
 
+func TestTestBackendConnectionReportsProbeWithoutSaving(t *testing.T) {
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "backendtest.db"), runtimetypes.SchemaSQLite)
+	if err != nil {
+		t.Fatalf("open sqlite db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	state := &stubStateService{connectionDetail: "API key not configured for openai"}
+	svc := backendservice.New(db)
+	mux := http.NewServeMux()
+	backendapi.AddBackendRoutes(mux, svc, state)
+
+	rr := postJSON(t, mux, "/backends/test", map[string]string{
+		"name":    "candidate",
+		"type":    "openai",
+		"baseUrl": "https://api.openai.com/v1",
+	}, http.StatusOK)
+
+	var got struct {
+		OK     bool   `json:"ok"`
+		Detail string `json:"detail"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.OK || got.Detail != "API key not configured for openai" {
+		t.Fatalf("response = %+v, want the state service's failed probe", got)
+	}
+	if len(state.tested) != 1 || state.tested[0].Type != "openai" || state.tested[0].BaseURL != "https://api.openai.com/v1" {
+		t.Fatalf("probed backends = %+v, want the posted one", state.tested)
+	}
+	saved, err := svc.List(ctx, nil, 10)
+	if err != nil {
+		t.Fatalf("list backends: %v", err)
+	}
+	if len(saved) != 0 {
+		t.Fatalf("testing a backend saved %d backends, want none", len(saved))
+	}
+}
+
+func TestEnsureBackendIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "backendensure.db"), runtimetypes.SchemaSQLite)
+	if err != nil {
+		t.Fatalf("open sqlite db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	mux := http.NewServeMux()
+	backendapi.AddBackendRoutes(mux, backendservice.New(db), &stubStateService{})
+
+	body := map[string]string{
+		"name":    "ollama",
+		"type":    "ollama",
+		"baseUrl": "http://127.0.0.1:11434",
+	}
+	type result struct {
+		Action  string               `json:"action"`
+		Backend runtimetypes.Backend `json:"backend"`
+	}
+	decode := func(rr *httptest.ResponseRecorder) result {
+		t.Helper()
+		var got result
+		if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return got
+	}
+
+	created := decode(postJSON(t, mux, "/backends/ensure", body, http.StatusCreated))
+	if created.Action != "created" || created.Backend.ID == "" {
+		t.Fatalf("first ensure = %+v, want a created backend with an ID", created)
+	}
+	unchanged := decode(postJSON(t, mux, "/backends/ensure", body, http.StatusOK))
+	if unchanged.Action != "unchanged" || unchanged.Backend.ID != created.Backend.ID {
+		t.Fatalf("repeated ensure = %+v, want unchanged %s", unchanged, created.Backend.ID)
+	}
+
+	body["name"] = "renamed"
+	updated := decode(postJSON(t, mux, "/backends/ensure?match=url", body, http.StatusOK))
+	if updated.Action != "updated" || updated.Backend.ID != created.Backend.ID || updated.Backend.Name != "renamed" {
+		t.Fatalf("ensure by url = %+v, want %s renamed", updated, created.Backend.ID)
+	}
+
+	postJSON(t, mux, "/backends/ensure?match=id", body, http.StatusBadRequest)
+}
+
 // TestListPaginationErrorsAgreeAcrossRoutes mounts two list routes that used
 // to disagree onto one mux and asserts they now answer identical malformed
 // pagination input identically.
@@ -0,0 +1,69 @@
+package backendapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	apiframework "github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/runtime/backendservice"
+)
+
+// maxBackendDocumentBytes bounds an import body. A document is a few lines
+// per backend, so this is generous and only stops runaway uploads.
+const maxBackendDocumentBytes = 1 << 20
+
+// exportBackends returns every registered backend as a YAML document that
+// POST /backends/import accepts, for copying a backend fleet to another
+// runtime or keeping it under version control.
+func (b *backendManager) exportBackends(w http.ResponseWriter, r *http.Request) {
+	// @response binary YAML document listing every backend by name, type and baseUrl.
+	data, err := b.service.ExportBackends(r.Context())
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", `attachment; filename="backends.yaml"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// importBackends applies a YAML backends document and reports the outcome of
+// each entry. Invalid entries are skipped and reported while the rest are
+// applied, so a partly bad document still returns 200; only a document that
+// does not parse, or an unknown mode, is refused outright.
+func (b *backendManager) importBackends(w http.ResponseWriter, r *http.Request) {
+	// @request binary YAML document in the shape GET /backends/export returns.
+	ctx := r.Context()
+	mode := apiframework.GetQueryParam(r, "mode", "merge", "merge updates and creates backends by name; replace also deletes backends the document does not list, when every entry imported cleanly.")
+	testRaw := apiframework.GetQueryParam(r, "test", "false", "Probe each new or changed backend before applying it; entries whose probe fails are skipped.")
+	test, err := strconv.ParseBool(testRaw)
+	if err != nil {
+		_ = apiframework.Error(w, r, apiframework.InvalidParameterValue("test", "test must be true or false"), apiframework.ExecuteOperation)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBackendDocumentBytes))
+	if err != nil {
+		_ = apiframework.Error(w, r, fmt.Errorf("read backends document: %w", err), apiframework.ExecuteOperation)
+		return
+	}
+	if len(data) == 0 {
+		_ = apiframework.Error(w, r, apiframework.ErrEmptyRequestBody, apiframework.ExecuteOperation)
+		return
+	}
+
+	var tester backendservice.ConnectionTester
+	if test {
+		tester = b.stateService
+	}
+	results, err := b.service.ImportBackends(ctx, data, backendservice.ImportMode(mode), tester)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ExecuteOperation)
+		return
+	}
+
+	_ = apiframework.Encode(w, r, http.StatusOK, results) // @response []backendservice.ImportResult
+}
@@ -0,0 +1,68 @@
+package backendapi
+
+import (
+	"net/http"
+	"strings"
+
+	apiframework "github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/runtime/modelservice"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+)
+
+// AddModelListRoutes mounts the searchable listing of the stored model
+// records — the per-model capability and context overrides — so a client can
+// find models by name or affinity group without fetching every page.
+func AddModelListRoutes(mux *http.ServeMux, modelService modelservice.Service) {
+	m := &modelLister{service: modelService}
+
+	mux.HandleFunc("GET /models/records", m.list)
+}
+
+type modelLister struct {
+	service modelservice.Service
+}
+
+// ModelRecordPage is one page of GET /models/records.
+type ModelRecordPage struct {
+	Data       []*runtimetypes.Model `json:"data"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+	HasMore    bool                  `json:"hasMore"`
+}
+
+// list returns stored model records, newest first, optionally narrowed to
+// names starting with prefix (case-insensitive) and to the members of one
+// affinity group. To fetch the next page pass the nextCursor of the previous
+// one as cursor; it resumes exactly after that page even when several
+// records share a creation time.
+func (m *modelLister) list(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	cursor, limit, err := apiframework.PageParams(r, 100)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	// One extra record tells Paginate whether another page follows; a larger
+	// limit is served across several pages instead of being refused.
+	limit = min(limit, runtimetypes.MAXLIMIT-1)
+	filter := runtimetypes.ModelFilter{
+		NamePrefix:      strings.TrimSpace(apiframework.GetQueryParam(r, "prefix", "", "Only return models whose name starts with this prefix, compared case-insensitively.")),
+		AffinityGroupID: apiframework.GetQueryParam(r, "affinityGroup", "", "Only return models assigned to the affinity group with this ID."),
+	}
+
+	var after *runtimetypes.ModelCursor
+	if cursor != nil {
+		after = &runtimetypes.ModelCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID}
+	}
+
+	models, err := m.service.ListFiltered(ctx, filter, after, limit+1)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	page := apiframework.Paginate(models, limit, func(model *runtimetypes.Model) apiframework.PageCursor {
+		return apiframework.PageCursor{CreatedAt: model.CreatedAt, ID: model.ID}
+	})
+
+	_ = apiframework.Encode(w, r, http.StatusOK, ModelRecordPage(page)) // @response backendapi.ModelRecordPage
+}
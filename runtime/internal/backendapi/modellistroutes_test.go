@@ -0,0 +1,90 @@
+package backendapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/internal/backendapi"
+	"github.com/contenox/runtime/runtime/modelservice"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+)
+
+func TestListModelRecordsFiltersAndPages(t *testing.T) {
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "models.db"), runtimetypes.SchemaSQLite)
+	if err != nil {
+		t.Fatalf("open sqlite db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	store := runtimetypes.New(db.WithoutTransaction())
+	for _, name := range []string{"qwen2.5:7b", "llama3:8b", "Qwen3:8b", "qwen3:32b"} {
+		if err := store.AppendModel(ctx, &runtimetypes.Model{Model: name, CanChat: true}); err != nil {
+			t.Fatalf("append %s: %v", name, err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	backendapi.AddModelListRoutes(mux, modelservice.New(db, ""))
+
+	var names []string
+	query := url.Values{"prefix": {"qwen"}, "limit": {"2"}}
+	for range 5 {
+		page := getModelRecords(t, mux, query, http.StatusOK)
+		for _, m := range page.Data {
+			names = append(names, m.Model)
+		}
+		if !page.HasMore {
+			if page.NextCursor != "" {
+				t.Fatalf("last page carries nextCursor %q", page.NextCursor)
+			}
+			break
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+	want := []string{"qwen3:32b", "Qwen3:8b", "qwen2.5:7b"}
+	if len(names) != len(want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("names = %v, want %v", names, want)
+		}
+	}
+
+	getModelRecords(t, mux, url.Values{"cursor": {"2024-03-01T12:00:00Z"}}, http.StatusBadRequest)
+	getModelRecords(t, mux, url.Values{"cursor": {"garbage"}}, http.StatusBadRequest)
+
+	exact := getModelRecords(t, mux, url.Values{"prefix": {"qwen"}, "limit": {"3"}}, http.StatusOK)
+	if len(exact.Data) != 3 || exact.HasMore || exact.NextCursor != "" {
+		t.Fatalf("exact-limit page = %d records, hasMore %v, cursor %q; want 3 records and no next page", len(exact.Data), exact.HasMore, exact.NextCursor)
+	}
+	empty := getModelRecords(t, mux, url.Values{"prefix": {"mistral"}}, http.StatusOK)
+	if empty.Data == nil || len(empty.Data) != 0 || empty.HasMore {
+		t.Fatalf("empty listing = %+v, want an empty data array", empty)
+	}
+}
+
+func getModelRecords(t *testing.T, mux *http.ServeMux, query url.Values, wantStatus int) backendapi.ModelRecordPage {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/models/records?"+query.Encode(), nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != wantStatus {
+		t.Fatalf("GET /models/records status = %d, want %d: %s", rr.Code, wantStatus, rr.Body.String())
+	}
+	var page backendapi.ModelRecordPage
+	if wantStatus != http.StatusOK {
+		return page
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return page
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/contenox/runtime/runtime/internal/backendapi"
 	"github.com/contenox/runtime/runtime/internal/setupcheck"
+	"github.com/contenox/runtime/runtime/runtimetypes"
 	"github.com/contenox/runtime/runtime/stateservice"
 	"github.com/contenox/runtime/runtime/statetype"
 )
@@ -47,6 +48,13 @@ func TestOpenAIModelsIncludesDefaultAliasAndObservedModels(t *testing.T) {
 type stubStateService struct {
 	states []statetype.BackendRuntimeState
 	config stateservice.CLIConfigSnapshot
+
+	connectionOK     bool
+	connectionDetail string
+	tested           []runtimetypes.Backend
+
+	reconcileTriggers int
+	reports           []statetype.ModelReport
 }
 
 func (s *stubStateService) Get(_ context.Context) ([]statetype.BackendRuntimeState, error) {
@@ -64,3 +72,13 @@ func (s *stubStateService) Refresh(_ context.Context) (setupcheck.Result, error)
 func (s *stubStateService) SetCLIConfig(_ context.Context, _ stateservice.CLIConfigPatch) (stateservice.CLIConfigSnapshot, error) {
 	return stateservice.CLIConfigSnapshot{}, nil
 }
+func (s *stubStateService) TestBackendConnection(_ context.Context, backend runtimetypes.Backend) (bool, string) {
+	s.tested = append(s.tested, backend)
+	return s.connectionOK, s.connectionDetail
+}
+func (s *stubStateService) ModelReport(_ context.Context) ([]statetype.ModelReport, error) {
+	return s.reports, nil
+}
+func (s *stubStateService) TriggerReconcile(_ context.Context) {
+	s.reconcileTriggers++
+}
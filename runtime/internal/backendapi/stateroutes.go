@@ -11,6 +11,8 @@ func AddStateRoutes(mux *http.ServeMux, stateService stateservice.Service) {
 	s := &statemux{stateService: stateService}
 
 	mux.HandleFunc("GET /state", s.list)
+	mux.HandleFunc("POST /state/reconcile", s.triggerReconcile)
+	mux.HandleFunc("GET /state/models", s.modelReport)
 }
 
 type statemux struct {
@@ -28,3 +30,26 @@ func (s *statemux) list(w http.ResponseWriter, r *http.Request) {
 	}
 	_ = apiframework.Encode(w, r, http.StatusOK, sanitizeRuntimeStates(internalModels)) // @response []statetype.BackendRuntimeState
 }
+
+// modelReport returns, per backend, the models declared for it, the models it
+// reported, and the difference in each direction: declared-but-missing and
+// pulled-but-undeclared (orphaned). Nothing is pulled or deleted; the report
+// is for an operator to act on.
+func (s *statemux) modelReport(w http.ResponseWriter, r *http.Request) {
+	reports, err := s.stateService.ModelReport(r.Context())
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, reports) // @response []statetype.ModelReport
+}
+
+// triggerReconcile asks for a backend reconcile now instead of at the next
+// scheduled one, so a backend created a moment ago appears in GET /state
+// within seconds. It returns 202 without waiting for the cycle; triggers sent
+// while one is already pending coalesce into a single cycle.
+func (s *statemux) triggerReconcile(w http.ResponseWriter, r *http.Request) {
+	// @request none requests an asynchronous reconcile; the request carries no body
+	s.stateService.TriggerReconcile(r.Context())
+	_ = apiframework.Encode(w, r, http.StatusAccepted, "reconcile triggered") // @response string
+}
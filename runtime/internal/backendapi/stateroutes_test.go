@@ -0,0 +1,54 @@
+package backendapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/internal/backendapi"
+	"github.com/contenox/runtime/runtime/statetype"
+)
+
+func TestTriggerReconcileAcceptsWithoutWaiting(t *testing.T) {
+	state := &stubStateService{}
+	mux := http.NewServeMux()
+	backendapi.AddStateRoutes(mux, state)
+
+	for range 2 {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/state/reconcile", nil))
+		if rr.Code != http.StatusAccepted {
+			t.Fatalf("POST /state/reconcile status = %d, want %d: %s", rr.Code, http.StatusAccepted, rr.Body.String())
+		}
+	}
+	if state.reconcileTriggers != 2 {
+		t.Fatalf("reconcile triggers = %d, want 2", state.reconcileTriggers)
+	}
+}
+
+func TestModelReportListsEachBackendsDiff(t *testing.T) {
+	state := &stubStateService{reports: []statetype.ModelReport{{
+		BackendID:   "b1",
+		BackendName: "ollama",
+		Declared:    []string{"mistral:instruct"},
+		Pulled:      []string{"llama2:7b"},
+		Missing:     []string{"mistral:instruct"},
+		Orphaned:    []string{"llama2:7b"},
+	}}}
+	mux := http.NewServeMux()
+	backendapi.AddStateRoutes(mux, state)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/state/models", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /state/models status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var got []statetype.ModelReport
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got) != 1 || got[0].BackendID != "b1" || len(got[0].Orphaned) != 1 || got[0].Orphaned[0] != "llama2:7b" {
+		t.Fatalf("report = %+v, want the state service's report", got)
+	}
+}
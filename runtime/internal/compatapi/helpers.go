@@ -184,8 +184,22 @@ func observedModels(ctx context.Context, deps CompatDeps) ([]backendapi.Observed
 	return backendapi.ListObservedModels(states), nil
 }
 
-func patchExecOverrides(chain *taskengine.TaskChainDefinition, temp *float64) *taskengine.TaskChainDefinition {
-	if chain == nil || temp == nil {
+// execOverrides are the sampling parameters a compat request sets on the
+// chain's model call. Each unset field keeps the chain's own value, which in
+// turn defaults to the provider's.
+type execOverrides struct {
+	Temperature     *float64
+	TopP            *float64
+	PresencePenalty *float64
+	Stop            []string
+}
+
+func (o execOverrides) empty() bool {
+	return o.Temperature == nil && o.TopP == nil && o.PresencePenalty == nil && len(o.Stop) == 0
+}
+
+func patchExecOverrides(chain *taskengine.TaskChainDefinition, o execOverrides) *taskengine.TaskChainDefinition {
+	if chain == nil || o.empty() {
 		return chain
 	}
 	clone := *chain
@@ -195,16 +209,59 @@ func patchExecOverrides(chain *taskengine.TaskChainDefinition, temp *float64) *t
 			continue
 		}
 		cfg := *clone.Tasks[i].ExecuteConfig
-		if temp != nil {
-			t := float32(*temp)
+		if o.Temperature != nil {
+			t := float32(*o.Temperature)
 			cfg.Temperature = &t
 		}
+		if o.TopP != nil {
+			cfg.TopP = o.TopP
+		}
+		if o.PresencePenalty != nil {
+			cfg.PresencePenalty = o.PresencePenalty
+		}
+		if len(o.Stop) > 0 {
+			cfg.Stop = o.Stop
+		}
 		clone.Tasks[i].ExecuteConfig = &cfg
 		break
 	}
 	return &clone
 }
 
+// patchClientTools returns a copy of chain set up for client-supplied tools:
+// chat and tool_loop tasks offer them to the model, and execute_tool_calls and
+// tool_loop tasks end the chain when the model calls one, so the calls reach
+// the client instead of the server-side tool executor.
+func patchClientTools(chain *taskengine.TaskChainDefinition) *taskengine.TaskChainDefinition {
+	if chain == nil {
+		return chain
+	}
+	clone := *chain
+	clone.Tasks = append([]taskengine.TaskDefinition(nil), chain.Tasks...)
+	endOnClientCalls := taskengine.TransitionBranch{
+		Operator: taskengine.OpEquals,
+		When:     taskengine.TransitionClientToolCalls,
+		Goto:     taskengine.TermEnd,
+	}
+	for i := range clone.Tasks {
+		task := &clone.Tasks[i]
+		switch task.Handler {
+		case taskengine.HandleChatCompletion, taskengine.HandleToolLoop:
+			cfg := taskengine.LLMExecutionConfig{}
+			if task.ExecuteConfig != nil {
+				cfg = *task.ExecuteConfig
+			}
+			cfg.PassClientsTools = true
+			task.ExecuteConfig = &cfg
+		}
+		switch task.Handler {
+		case taskengine.HandleExecuteToolCalls, taskengine.HandleToolLoop:
+			task.Transition.Branches = append([]taskengine.TransitionBranch{endOnClientCalls}, task.Transition.Branches...)
+		}
+	}
+	return &clone
+}
+
 func applyStopStrings(text string, stop []string) string {
 	first := -1
 	for _, s := range stop {
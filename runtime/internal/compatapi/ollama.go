@@ -228,9 +228,9 @@ func (h *ollamaHandler) chat(w http.ResponseWriter, r *http.Request) {
 	}
 
 	model := resolveRequestedModel(ctx, h.deps, defaults, req.Model)
-	temp, maxTokens := ollamaExecOverrides(req.Options)
+	overrides, maxTokens := ollamaExecOverrides(req.Options)
 	templateVars := buildTemplateVars(chain, defaults, model, maxTokens)
-	chain = patchExecOverrides(chain, temp)
+	chain = patchExecOverrides(chain, overrides)
 	sessionID, err := compatSessionID(ctx, w, r, h.deps)
 	if err != nil {
 		writeOllamaError(w, http.StatusInternalServerError, err.Error())
@@ -331,9 +331,9 @@ func (h *ollamaHandler) generate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	model := resolveRequestedModel(ctx, h.deps, defaults, req.Model)
-	temp, maxTokens := ollamaExecOverrides(req.Options)
+	overrides, maxTokens := ollamaExecOverrides(req.Options)
 	templateVars := buildTemplateVars(chain, defaults, model, maxTokens)
-	chain = patchExecOverrides(chain, temp)
+	chain = patchExecOverrides(chain, overrides)
 	sessionID, err := compatSessionID(ctx, w, r, h.deps)
 	if err != nil {
 		writeOllamaError(w, http.StatusInternalServerError, err.Error())
@@ -608,16 +608,23 @@ func ollamaStream(stream *bool) bool {
 	return stream == nil || *stream
 }
 
-func ollamaExecOverrides(options map[string]any) (*float64, *int) {
-	var temp *float64
+func ollamaExecOverrides(options map[string]any) (execOverrides, *int) {
+	var o execOverrides
 	if v, ok := optionFloat(options["temperature"]); ok {
-		temp = &v
+		o.Temperature = &v
 	}
+	if v, ok := optionFloat(options["top_p"]); ok {
+		o.TopP = &v
+	}
+	if v, ok := optionFloat(options["presence_penalty"]); ok {
+		o.PresencePenalty = &v
+	}
+	o.Stop = ollamaStopStrings(options)
 	var maxTokens *int
 	if v, ok := optionInt(options["num_predict"]); ok && v >= 0 {
 		maxTokens = &v
 	}
-	return temp, maxTokens
+	return o, maxTokens
 }
 
 func ollamaStopStrings(options map[string]any) []string {
@@ -247,7 +247,7 @@ func TestOllamaChatStreamsNDJSONAndUsesKnownModel(t *testing.T) {
 	mux := http.NewServeMux()
 	compatapi.AddOllamaRoutes(mux, deps)
 
-	body := `{"model":"known-model","messages":[{"role":"user","content":"hi"}],"options":{"temperature":0.2,"num_predict":12,"stop":[" STOP"]}}`
+	body := `{"model":"known-model","messages":[{"role":"user","content":"hi"}],"options":{"temperature":0.2,"num_predict":12,"top_p":0.7,"stop":[" STOP"]}}`
 	req := httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(body))
 	rr := httptest.NewRecorder()
 	mux.ServeHTTP(rr, req)
@@ -309,6 +309,15 @@ func TestOllamaChatStreamsNDJSONAndUsesKnownModel(t *testing.T) {
 	if cfg.Temperature == nil || *cfg.Temperature < 0.199 || *cfg.Temperature > 0.201 {
 		t.Fatalf("expected patched temperature 0.2, got %#v", cfg.Temperature)
 	}
+	if cfg.TopP == nil || *cfg.TopP != 0.7 {
+		t.Fatalf("expected patched top_p 0.7, got %#v", cfg.TopP)
+	}
+	if len(cfg.Stop) != 1 || cfg.Stop[0] != " STOP" {
+		t.Fatalf("expected patched stop [\" STOP\"], got %#v", cfg.Stop)
+	}
+	if cfg.PresencePenalty != nil {
+		t.Fatalf("expected presence_penalty to stay unset, got %#v", cfg.PresencePenalty)
+	}
 }
 
 func TestOllamaChatFallsBackWhenModelBelongsToDifferentProvider(t *testing.T) {
@@ -63,7 +63,16 @@ func (h *chatHandler) handle(w http.ResponseWriter, r *http.Request) {
 		maxTokens = req.MaxCompletionTokens
 	}
 	templateVars := buildTemplateVars(chain, defaults, model, maxTokens)
-	chain = patchExecOverrides(chain, req.Temperature)
+	chain = patchExecOverrides(chain, execOverrides{
+		Temperature:     req.Temperature,
+		TopP:            req.TopP,
+		PresencePenalty: req.PresencePenalty,
+		Stop:            req.Stop,
+	})
+	if len(req.Tools) > 0 {
+		ctx = taskengine.WithClientTools(ctx, req.Tools)
+		chain = patchClientTools(chain)
+	}
 	sessionID, err := compatSessionID(ctx, w, r, h.deps)
 	if err != nil {
 		http.Error(w, fmt.Sprintf(`{"error":{"message":"%s","type":"server_error"}}`, jsonEscape(err.Error())), http.StatusInternalServerError)
@@ -73,9 +82,11 @@ func (h *chatHandler) handle(w http.ResponseWriter, r *http.Request) {
 	messages := make([]taskengine.Message, 0, len(req.Messages))
 	for _, m := range req.Messages {
 		messages = append(messages, taskengine.Message{
-			Role:    m.Role,
-			Content: m.Content,
-			Images:  m.Images,
+			Role:       m.Role,
+			Content:    m.Content,
+			Images:     m.Images,
+			CallTools:  m.ToolCalls,
+			ToolCallID: m.ToolCallID,
 		})
 	}
 	chatHistory := taskengine.ChatHistory{Messages: messages}
@@ -94,17 +105,21 @@ func (h *chatHandler) handle(w http.ResponseWriter, r *http.Request) {
 
 	reply := extractChatReply(resp)
 	reply = applyStopStrings(reply, req.Stop)
+	var toolCalls []taskengine.ToolCall
+	if len(req.Tools) > 0 {
+		toolCalls = extractClientToolCalls(resp)
+	}
 	id := newCompletionID("chatcmpl")
 	ts := unixNow()
 
 	if req.Stream {
-		h.writeStreamingResponse(w, id, ts, model, reply, resp)
+		h.writeStreamingResponse(w, id, ts, model, reply, toolCalls, resp)
 		return
 	}
-	h.writeJSONResponse(w, id, ts, model, reply, resp)
+	h.writeJSONResponse(w, id, ts, model, reply, toolCalls, resp)
 }
 
-func (h *chatHandler) writeStreamingResponse(w http.ResponseWriter, id string, ts int64, model, reply string, resp *agentservice.PromptResponse) {
+func (h *chatHandler) writeStreamingResponse(w http.ResponseWriter, id string, ts int64, model, reply string, toolCalls []taskengine.ToolCall, resp *agentservice.PromptResponse) {
 	setSSEHeaders(w)
 	w.WriteHeader(http.StatusOK)
 
@@ -134,9 +149,30 @@ func (h *chatHandler) writeStreamingResponse(w http.ResponseWriter, id string, t
 		}},
 	})
 
+	if len(toolCalls) > 0 {
+		delta := chatDelta{ToolCalls: make([]chatDeltaToolCall, len(toolCalls))}
+		for i, call := range toolCalls {
+			delta.ToolCalls[i] = chatDeltaToolCall{Index: i, ToolCall: call}
+		}
+		_ = writeSSE(w, chatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: ts,
+			Model:   model,
+			Choices: []chatChunkChoice{{
+				Index:        0,
+				Delta:        delta,
+				FinishReason: nullFinishReason(),
+			}},
+		})
+	}
+
 	// Frame 3: stop + usage
 	usage := extractUsage(resp)
 	stopReason := openAIFinishReason(resp)
+	if len(toolCalls) > 0 {
+		stopReason = "tool_calls"
+	}
 	_ = writeSSE(w, chatCompletionChunk{
 		ID:      id,
 		Object:  "chat.completion.chunk",
@@ -153,8 +189,12 @@ func (h *chatHandler) writeStreamingResponse(w http.ResponseWriter, id string, t
 	writeDone(w)
 }
 
-func (h *chatHandler) writeJSONResponse(w http.ResponseWriter, id string, ts int64, model, reply string, resp *agentservice.PromptResponse) {
+func (h *chatHandler) writeJSONResponse(w http.ResponseWriter, id string, ts int64, model, reply string, toolCalls []taskengine.ToolCall, resp *agentservice.PromptResponse) {
 	usage := extractUsage(resp)
+	stopReason := openAIFinishReason(resp)
+	if len(toolCalls) > 0 {
+		stopReason = "tool_calls"
+	}
 	out := chatCompletionResponse{
 		ID:      id,
 		Object:  "chat.completion",
@@ -163,10 +203,11 @@ func (h *chatHandler) writeJSONResponse(w http.ResponseWriter, id string, ts int
 		Choices: []chatChoiceResponse{{
 			Index: 0,
 			Message: ChatMessage{
-				Role:    "assistant",
-				Content: reply,
+				Role:      "assistant",
+				Content:   reply,
+				ToolCalls: toolCalls,
 			},
-			FinishReason: openAIFinishReason(resp),
+			FinishReason: stopReason,
 		}},
 		Usage: usage,
 	}
@@ -197,6 +238,32 @@ func extractChatReply(resp *agentservice.PromptResponse) string {
 	return ""
 }
 
+// extractClientToolCalls returns the calls of a chain that ended waiting on
+// the client: its output history ends with an assistant message whose tool
+// calls nobody answered.
+func extractClientToolCalls(resp *agentservice.PromptResponse) []taskengine.ToolCall {
+	if resp == nil {
+		return nil
+	}
+	var messages []taskengine.Message
+	switch hist := resp.Output.(type) {
+	case taskengine.ChatHistory:
+		messages = hist.Messages
+	case *taskengine.ChatHistory:
+		if hist != nil {
+			messages = hist.Messages
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	last := messages[len(messages)-1]
+	if last.Role != "assistant" {
+		return nil
+	}
+	return last.CallTools
+}
+
 func extractUsage(resp *agentservice.PromptResponse) chatCompletionUsage {
 	if resp == nil {
 		return chatCompletionUsage{}
@@ -13,6 +13,7 @@ import (
 	"github.com/contenox/runtime/runtime/agentservice"
 	"github.com/contenox/runtime/runtime/internal/compatapi"
 	"github.com/contenox/runtime/runtime/internal/setupcheck"
+	"github.com/contenox/runtime/runtime/runtimetypes"
 	"github.com/contenox/runtime/runtime/stateservice"
 	"github.com/contenox/runtime/runtime/statetype"
 	"github.com/contenox/runtime/runtime/taskengine"
@@ -179,7 +180,7 @@ func TestChatCompletions_OverridesSessionAndUnknownModelFallsBack(t *testing.T)
 	mux := http.NewServeMux()
 	compatapi.AddOpenAIRoutes(mux, deps)
 
-	body := `{"model":"ide-only-model","messages":[{"role":"user","content":"ping"}],"temperature":0.25,"max_completion_tokens":64}`
+	body := `{"model":"ide-only-model","messages":[{"role":"user","content":"ping"}],"temperature":0.25,"max_completion_tokens":64,"top_p":0.8,"presence_penalty":0.5,"stop":["END"]}`
 	req := httptest.NewRequest(http.MethodPost, "/openai/v1/chat/completions", strings.NewReader(body))
 	req.Header.Set("X-Session-ID", "session-123")
 	rr := httptest.NewRecorder()
@@ -214,6 +215,18 @@ func TestChatCompletions_OverridesSessionAndUnknownModelFallsBack(t *testing.T)
 	if cfg.Temperature == nil || *cfg.Temperature < 0.249 || *cfg.Temperature > 0.251 {
 		t.Fatalf("expected patched temperature 0.25, got %#v", cfg.Temperature)
 	}
+	if cfg.TopP == nil || *cfg.TopP != 0.8 {
+		t.Fatalf("expected patched top_p 0.8, got %#v", cfg.TopP)
+	}
+	if cfg.PresencePenalty == nil || *cfg.PresencePenalty != 0.5 {
+		t.Fatalf("expected patched presence_penalty 0.5, got %#v", cfg.PresencePenalty)
+	}
+	if len(cfg.Stop) != 1 || cfg.Stop[0] != "END" {
+		t.Fatalf("expected patched stop [END], got %#v", cfg.Stop)
+	}
+	if chains.chain.Tasks[0].ExecuteConfig.TopP != nil {
+		t.Fatal("original chain top_p was mutated")
+	}
 	if chains.chain.Tasks[0].ExecuteConfig.MaxTokens != nil {
 		t.Fatal("original chain max tokens was mutated")
 	}
@@ -482,6 +495,15 @@ func (s *stubStateService) Refresh(_ context.Context) (setupcheck.Result, error)
 func (s *stubStateService) SetCLIConfig(_ context.Context, _ stateservice.CLIConfigPatch) (stateservice.CLIConfigSnapshot, error) {
 	return stateservice.CLIConfigSnapshot{}, nil
 }
+func (s *stubStateService) TestBackendConnection(_ context.Context, _ runtimetypes.Backend) (bool, string) {
+	return true, ""
+}
+
+func (s *stubStateService) TriggerReconcile(_ context.Context) {}
+
+func (s *stubStateService) ModelReport(_ context.Context) ([]statetype.ModelReport, error) {
+	return nil, nil
+}
 
 func observedState(models ...string) []statetype.BackendRuntimeState {
 	pulled := make([]statetype.ModelPullStatus, 0, len(models))
@@ -526,3 +548,99 @@ func modelAndMaxTokensTemplateChain(id string) *taskengine.TaskChainDefinition {
 		}},
 	}
 }
+
+// toolCallingAgent answers every prompt with a call to the client's first tool.
+type toolCallingAgent struct {
+	stubAgent
+	clientTools []taskengine.Tool
+}
+
+func (s *toolCallingAgent) Prompt(ctx context.Context, req agentservice.PromptRequest) (*agentservice.PromptResponse, error) {
+	s.lastReq = req
+	s.clientTools = taskengine.ClientToolsFromContext(ctx)
+	call := taskengine.ToolCall{ID: "call_1", Type: "function"}
+	call.Function.Name = s.clientTools[0].Function.Name
+	call.Function.Arguments = `{"city":"Paris"}`
+	return &agentservice.PromptResponse{
+		Output: taskengine.ChatHistory{Messages: []taskengine.Message{
+			{Role: "user", Content: "weather?"},
+			{Role: "assistant", CallTools: []taskengine.ToolCall{call}},
+		}},
+		OutputType: taskengine.DataTypeChatHistory,
+		StopReason: agentservice.StopEndTurn,
+	}, nil
+}
+
+func TestChatCompletions_ClientToolsReturnToolCalls(t *testing.T) {
+	agent := &toolCallingAgent{}
+	chains := &stubChains{chain: &taskengine.TaskChainDefinition{
+		ID: "test-chain",
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "chat", Handler: taskengine.HandleChatCompletion},
+			{ID: "run_tools", Handler: taskengine.HandleExecuteToolCalls},
+		},
+	}}
+	deps := compatapi.CompatDeps{
+		Agent:    agent,
+		Chains:   chains,
+		Defaults: stateservice.RuntimeDefaults{ChainRef: "test-chain", Model: "test-model"},
+	}
+	mux := http.NewServeMux()
+	compatapi.AddOpenAIRoutes(mux, deps)
+
+	body := `{"model":"default","messages":[
+		{"role":"user","content":"weather?"},
+		{"role":"assistant","content":null,"tool_calls":[{"id":"call_0","type":"function","function":{"name":"get_weather","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"call_0","content":"sunny"}
+	],"tools":[{"type":"function","function":{"name":"get_weather","parameters":{"type":"object"}}}]}`
+	req := httptest.NewRequest(http.MethodPost, "/openai/v1/chat/completions", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if len(agent.clientTools) != 1 || agent.clientTools[0].Function.Name != "get_weather" {
+		t.Fatalf("client tools on context = %+v", agent.clientTools)
+	}
+	chat := agent.lastReq.Chain.Tasks[0]
+	if chat.ExecuteConfig == nil || !chat.ExecuteConfig.PassClientsTools {
+		t.Fatalf("chat task does not pass client tools: %+v", chat.ExecuteConfig)
+	}
+	branches := agent.lastReq.Chain.Tasks[1].Transition.Branches
+	if len(branches) == 0 || branches[0].When != taskengine.TransitionClientToolCalls || branches[0].Goto != taskengine.TermEnd {
+		t.Fatalf("execute_tool_calls does not end on client tool calls: %+v", branches)
+	}
+	if chains.chain.Tasks[0].ExecuteConfig != nil {
+		t.Fatal("the stored chain was modified")
+	}
+	hist, ok := agent.lastReq.InputValue.(taskengine.ChatHistory)
+	if !ok || len(hist.Messages) != 3 || len(hist.Messages[1].CallTools) != 1 || hist.Messages[2].ToolCallID != "call_0" {
+		t.Fatalf("tool messages not carried into the history: %+v", agent.lastReq.InputValue)
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []struct {
+					ID       string `json:"id"`
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" {
+		t.Errorf("finish_reason = %q, want tool_calls", choice.FinishReason)
+	}
+	if len(choice.Message.ToolCalls) != 1 || choice.Message.ToolCalls[0].Function.Name != "get_weather" || choice.Message.ToolCalls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("tool_calls = %+v", choice.Message.ToolCalls)
+	}
+}
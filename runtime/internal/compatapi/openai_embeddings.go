@@ -0,0 +1,76 @@
+package compatapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/contenox/runtime/runtime/errdefs"
+)
+
+type embeddingsHandler struct {
+	deps CompatDeps
+}
+
+// handle serves OpenAI-compatible embeddings with the runtime's embed model.
+// The request may name that model, "default", or nothing; any other model is
+// refused with model_not_found rather than silently embedded with a
+// different model, since vectors from different models are not comparable.
+func (h *embeddingsHandler) handle(w http.ResponseWriter, r *http.Request) {
+	// @request compatapi.EmbeddingRequest
+	// @response compatapi.embeddingResponse
+	ctx := r.Context()
+	if err := authorizeCompatRequest(r, h.deps, true); err != nil {
+		http.Error(w, `{"error":{"message":"Unauthorized","type":"auth_error"}}`, http.StatusUnauthorized)
+		return
+	}
+	if h.deps.Embedder == nil {
+		http.Error(w, `{"error":{"message":"embeddings are not configured","type":"server_error"}}`, http.StatusInternalServerError)
+		return
+	}
+
+	var req EmbeddingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":{"message":"invalid request body: %s","type":"invalid_request_error"}}`, jsonEscape(err.Error())), http.StatusBadRequest)
+		return
+	}
+	if len(req.Input) == 0 {
+		http.Error(w, `{"error":{"message":"input must not be empty","type":"invalid_request_error","param":"input"}}`, http.StatusBadRequest)
+		return
+	}
+	if f := strings.TrimSpace(req.EncodingFormat); f != "" && f != "float" {
+		msg := fmt.Sprintf("encoding_format %q is not supported; use \"float\"", f)
+		http.Error(w, fmt.Sprintf(`{"error":{"message":"%s","type":"invalid_request_error","param":"encoding_format"}}`, jsonEscape(msg)), http.StatusBadRequest)
+		return
+	}
+	embedModel := h.deps.Embedder.Model()
+	if requested := strings.TrimSpace(req.Model); requested != "" && requested != "default" && !strings.EqualFold(requested, embedModel) {
+		msg := fmt.Sprintf("The model %q is not the configured embedding model; use %q.", requested, embedModel)
+		http.Error(w, fmt.Sprintf(`{"error":{"message":"%s","type":"invalid_request_error","param":"model","code":"model_not_found"}}`, jsonEscape(msg)), http.StatusNotFound)
+		return
+	}
+
+	result, err := h.deps.Embedder.EmbedBatch(ctx, req.Input)
+	if err != nil {
+		if errors.Is(err, errdefs.ErrBadRequest) {
+			http.Error(w, fmt.Sprintf(`{"error":{"message":"%s","type":"invalid_request_error","param":"input"}}`, jsonEscape(err.Error())), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf(`{"error":{"message":"%s","type":"server_error"}}`, jsonEscape(err.Error())), http.StatusInternalServerError)
+		return
+	}
+
+	out := embeddingResponse{
+		Object: "list",
+		Data:   make([]embeddingData, len(result.Embeddings)),
+		Model:  result.Model,
+		Usage:  embeddingUsage{PromptTokens: result.PromptTokens, TotalTokens: result.PromptTokens},
+	}
+	for i, vector := range result.Embeddings {
+		out.Data[i] = embeddingData{Object: "embedding", Index: i, Embedding: vector}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
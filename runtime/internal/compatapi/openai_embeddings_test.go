@@ -0,0 +1,118 @@
+package compatapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/embedservice"
+	"github.com/contenox/runtime/runtime/internal/compatapi"
+)
+
+// stubEmbedder embeds a text as [len(text)] and counts one token per byte.
+type stubEmbedder struct{}
+
+func (stubEmbedder) Model() string { return "nomic-embed-text" }
+
+func (stubEmbedder) Dimensions(context.Context) (int, error) { return 1, nil }
+
+func (stubEmbedder) EmbedBatch(_ context.Context, texts []string) (*embedservice.BatchResult, error) {
+	out := &embedservice.BatchResult{Model: "nomic-embed-text"}
+	for _, text := range texts {
+		out.Embeddings = append(out.Embeddings, []float64{float64(len(text))})
+		out.PromptTokens += len(text)
+	}
+	return out, nil
+}
+
+func postEmbeddings(t *testing.T, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	deps := compatapi.CompatDeps{Embedder: stubEmbedder{}}
+	compatapi.AddOpenAIRoutes(mux, deps)
+	compatapi.AddRootRoutes(mux, deps)
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestEmbeddings_ArrayInput(t *testing.T) {
+	rr := postEmbeddings(t, "/v1/embeddings", `{"model":"nomic-embed-text","input":["a","abc"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Object string `json:"object"`
+		Model  string `json:"model"`
+		Data   []struct {
+			Object    string    `json:"object"`
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			PromptTokens int `json:"prompt_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Object != "list" || resp.Model != "nomic-embed-text" {
+		t.Fatalf("unexpected envelope: %+v", resp)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("expected 2 embeddings, got %d", len(resp.Data))
+	}
+	for i, want := range []float64{1, 3} {
+		d := resp.Data[i]
+		if d.Object != "embedding" || d.Index != i || len(d.Embedding) != 1 || d.Embedding[0] != want {
+			t.Fatalf("data[%d] = %+v, want index %d embedding [%v]", i, d, i, want)
+		}
+	}
+	if resp.Usage.PromptTokens != 4 || resp.Usage.TotalTokens != 4 {
+		t.Fatalf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestEmbeddings_StringInputAndDefaultModel(t *testing.T) {
+	rr := postEmbeddings(t, "/openai/v1/embeddings", `{"model":"default","input":"hello"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), `"embedding":[5]`) {
+		t.Fatalf("expected a single embedding of the string input, got %s", rr.Body.String())
+	}
+}
+
+func TestEmbeddings_ModelMismatch(t *testing.T) {
+	rr := postEmbeddings(t, "/v1/embeddings", `{"model":"text-embedding-3-small","input":"hello"}`)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Error struct {
+			Type  string `json:"type"`
+			Param string `json:"param"`
+			Code  string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode error body: %v", err)
+	}
+	if resp.Error.Type != "invalid_request_error" || resp.Error.Param != "model" || resp.Error.Code != "model_not_found" {
+		t.Fatalf("unexpected error object: %+v", resp.Error)
+	}
+}
+
+func TestEmbeddings_RejectsTokenArrays(t *testing.T) {
+	rr := postEmbeddings(t, "/v1/embeddings", `{"input":[1,2,3]}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
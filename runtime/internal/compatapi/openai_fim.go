@@ -55,7 +55,7 @@ func (h *fimHandler) handle(w http.ResponseWriter, r *http.Request) {
 
 	model := resolveRequestedModel(ctx, h.deps, defaults, req.Model)
 	templateVars := buildTemplateVars(chain, defaults, model, req.MaxTokens)
-	chain = patchExecOverrides(chain, req.Temperature)
+	chain = patchExecOverrides(chain, execOverrides{Temperature: req.Temperature, Stop: req.Stop})
 	sessionID, err := compatSessionID(ctx, w, r, h.deps)
 	if err != nil {
 		http.Error(w, fmt.Sprintf(`{"error":{"message":"%s","type":"server_error"}}`, jsonEscape(err.Error())), http.StatusInternalServerError)
@@ -17,7 +17,13 @@ type ChatCompletionRequest struct {
 	Temperature         *float64      `json:"temperature,omitempty"`
 	MaxTokens           *int          `json:"max_tokens,omitempty"`
 	MaxCompletionTokens *int          `json:"max_completion_tokens,omitempty"`
+	TopP                *float64      `json:"top_p,omitempty"`
+	PresencePenalty     *float64      `json:"presence_penalty,omitempty"`
 	Stop                []string      `json:"stop,omitempty"`
+	// Tools are function tools the client runs itself. The model may call
+	// them; such calls are returned with finish_reason "tool_calls" and the
+	// client sends the results back as role "tool" messages.
+	Tools []taskengine.Tool `json:"tools,omitempty"`
 }
 
 // ChatMessage is a single message in the OpenAI chat format.
@@ -32,6 +38,10 @@ type ChatMessage struct {
 	// Images holds image attachments decoded from image_url content parts.
 	// Request-only; responses never carry images.
 	Images []taskengine.ImagePart `json:"-"`
+	// ToolCalls are the calls an assistant message made to client tools.
+	ToolCalls []taskengine.ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID ties a role "tool" message to the call it answers.
+	ToolCallID string `json:"tool_call_id,omitempty"`
 }
 
 // chatContentPart is one element of the OpenAI content-parts array form.
@@ -48,8 +58,10 @@ type chatImageURL struct {
 // UnmarshalJSON accepts content as either a JSON string or a content-parts array.
 func (m *ChatMessage) UnmarshalJSON(data []byte) error {
 	var wire struct {
-		Role    string          `json:"role"`
-		Content json.RawMessage `json:"content"`
+		Role       string                `json:"role"`
+		Content    json.RawMessage       `json:"content"`
+		ToolCalls  []taskengine.ToolCall `json:"tool_calls"`
+		ToolCallID string                `json:"tool_call_id"`
 	}
 	if err := json.Unmarshal(data, &wire); err != nil {
 		return err
@@ -57,6 +69,8 @@ func (m *ChatMessage) UnmarshalJSON(data []byte) error {
 	m.Role = wire.Role
 	m.Content = ""
 	m.Images = nil
+	m.ToolCalls = wire.ToolCalls
+	m.ToolCallID = wire.ToolCallID
 
 	trimmed := strings.TrimSpace(string(wire.Content))
 	if trimmed == "" || trimmed == "null" {
@@ -147,8 +161,16 @@ type chatChunkChoice struct {
 }
 
 type chatDelta struct {
-	Role    string `json:"role,omitempty"`
-	Content string `json:"content,omitempty"`
+	Role      string              `json:"role,omitempty"`
+	Content   string              `json:"content,omitempty"`
+	ToolCalls []chatDeltaToolCall `json:"tool_calls,omitempty"`
+}
+
+// chatDeltaToolCall is a tool call in a streamed delta, which OpenAI
+// positions by index.
+type chatDeltaToolCall struct {
+	Index int `json:"index"`
+	taskengine.ToolCall
 }
 
 type chatCompletionUsage struct {
@@ -198,3 +220,51 @@ type fimChoiceResponse struct {
 	Text         string `json:"text"`
 	FinishReason string `json:"finish_reason"`
 }
+
+// EmbeddingRequest is the OpenAI /v1/embeddings request body.
+type EmbeddingRequest struct {
+	Model string         `json:"model"`
+	Input EmbeddingInput `json:"input"`
+	// EncodingFormat is accepted for compatibility; only "float" (the
+	// default) is supported.
+	EncodingFormat string `json:"encoding_format,omitempty"`
+}
+
+// EmbeddingInput is the input of an embeddings request: OpenAI accepts a
+// single string or an array of strings. Token-array inputs are not
+// supported.
+type EmbeddingInput []string
+
+func (in *EmbeddingInput) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*in = EmbeddingInput{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("input must be a string or an array of strings")
+	}
+	*in = many
+	return nil
+}
+
+// embeddingResponse is the response for embeddings: one entry per input, in
+// input order.
+type embeddingResponse struct {
+	Object string          `json:"object" example:"list"`
+	Data   []embeddingData `json:"data"`
+	Model  string          `json:"model"`
+	Usage  embeddingUsage  `json:"usage"`
+}
+
+type embeddingData struct {
+	Object    string    `json:"object" example:"embedding"`
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
+
+type embeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
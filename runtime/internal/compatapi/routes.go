@@ -5,6 +5,7 @@ import (
 
 	"github.com/contenox/runtime/apiframework/middleware"
 	"github.com/contenox/runtime/runtime/agentservice"
+	"github.com/contenox/runtime/runtime/embedservice"
 	"github.com/contenox/runtime/runtime/stateservice"
 	"github.com/contenox/runtime/runtime/taskchainservice"
 )
@@ -17,6 +18,7 @@ type CompatDeps struct {
 	Defaults     stateservice.RuntimeDefaults
 	Auth         middleware.AuthZReader // nil = no auth required on compat routes
 	Token        string                 // protects root-level mutating compat routes when set
+	Embedder     embedservice.Service   // nil = /v1/embeddings answers with a server_error
 }
 
 // AddOpenAIRoutes registers the OpenAI-compatible routes on mux (apiMux, paths without /api prefix).
@@ -24,6 +26,7 @@ type CompatDeps struct {
 func AddOpenAIRoutes(mux *http.ServeMux, deps CompatDeps) {
 	chat := &chatHandler{deps: deps}
 	fim := &fimHandler{deps: deps}
+	embeddings := &embeddingsHandler{deps: deps}
 
 	mux.HandleFunc("POST /openai/v1/chat/completions", chat.handle)
 	mux.HandleFunc("POST /openai/{chainID}/v1/chat/completions", chat.handle)
@@ -31,6 +34,7 @@ func AddOpenAIRoutes(mux *http.ServeMux, deps CompatDeps) {
 	mux.HandleFunc("POST /openai/{chainID}/v1/fim/completions", fim.handle)
 	// Legacy OpenAI /v1/completions alias — same as fim/completions.
 	mux.HandleFunc("POST /openai/v1/completions", fim.handle)
+	mux.HandleFunc("POST /openai/v1/embeddings", embeddings.handle)
 }
 
 // AddRootRoutes registers root-level /v1/* aliases on rootMux for clients that set
@@ -40,9 +44,11 @@ func AddOpenAIRoutes(mux *http.ServeMux, deps CompatDeps) {
 func AddRootRoutes(mux *http.ServeMux, deps CompatDeps) {
 	chat := &chatHandler{deps: deps}
 	fim := &fimHandler{deps: deps}
+	embeddings := &embeddingsHandler{deps: deps}
 
 	mux.HandleFunc("GET /v1/models", rootModels(deps))
 	mux.HandleFunc("POST /v1/chat/completions", chat.handle)
 	mux.HandleFunc("POST /v1/fim/completions", fim.handle)
 	mux.HandleFunc("POST /v1/completions", fim.handle) // legacy alias
+	mux.HandleFunc("POST /v1/embeddings", embeddings.handle)
 }
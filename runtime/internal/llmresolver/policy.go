@@ -0,0 +1,134 @@
+package llmresolver
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+)
+
+// Policy picks one provider and one of its backends from the candidates a
+// resolution produced. Candidates arrive in preference order (the order of the
+// request's model names). Randomly is the stateless default; the constructors
+// below return stateful policies whose state — a rotation counter, last-use
+// order — is what spreads load, so build one per model manager and share it
+// across calls rather than constructing one per request. All are safe for
+// concurrent use.
+type Policy func(candidates []libmodelprovider.Provider) (libmodelprovider.Provider, string, error)
+
+var _ Policy = Randomly
+
+// target is one selectable (provider, backend) pair.
+type target struct {
+	provider libmodelprovider.Provider
+	backend  string
+}
+
+// expandTargets flattens candidates into their (provider, backend) pairs in
+// candidate order, so the stateful policies balance across every backend that
+// serves a model, not only across models.
+func expandTargets(candidates []libmodelprovider.Provider) []target {
+	var targets []target
+	for _, p := range candidates {
+		if p == nil {
+			continue
+		}
+		for _, b := range p.GetBackendIDs() {
+			targets = append(targets, target{provider: p, backend: b})
+		}
+	}
+	return targets
+}
+
+// RoundRobin returns a policy that cycles through every (provider, backend)
+// pair of the candidates in turn. The rotation is a single counter shared by
+// all calls, so with a changing candidate set it is an even spread, not a
+// strict per-set cycle.
+func RoundRobin() Policy {
+	var next atomic.Uint64
+	return func(candidates []libmodelprovider.Provider) (libmodelprovider.Provider, string, error) {
+		targets := expandTargets(candidates)
+		if len(targets) == 0 {
+			return nil, "", ErrNoSatisfactoryModel
+		}
+		t := targets[(next.Add(1)-1)%uint64(len(targets))]
+		return t.provider, t.backend, nil
+	}
+}
+
+// LeastRecentlyUsed returns a policy that picks the (provider, backend) pair
+// this policy handed out longest ago; pairs it has never handed out come
+// first, in candidate order, so the first call honors the request's model
+// preference.
+func LeastRecentlyUsed() Policy {
+	var (
+		mu      sync.Mutex
+		seq     uint64
+		lastUse = map[string]uint64{}
+	)
+	return func(candidates []libmodelprovider.Provider) (libmodelprovider.Provider, string, error) {
+		targets := expandTargets(candidates)
+		if len(targets) == 0 {
+			return nil, "", ErrNoSatisfactoryModel
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		best, bestKey := 0, ""
+		for i, t := range targets {
+			key := t.provider.GetID() + "\x00" + t.backend
+			if i == 0 || lastUse[key] < lastUse[bestKey] {
+				best, bestKey = i, key
+			}
+		}
+		seq++
+		lastUse[bestKey] = seq
+		return targets[best].provider, targets[best].backend, nil
+	}
+}
+
+// Weighted returns a policy that picks a provider at random in proportion to
+// its weight, then one of its backends at random. weights is keyed by model
+// name and matched like request model names (exact, else normalized); a model
+// without an entry weighs 1 and a weight of 0 or less takes it out of
+// rotation. When every candidate weighs 0 the policy fails with
+// ErrNoSatisfactoryModel rather than ignoring the configuration.
+func Weighted(weights map[string]int) Policy {
+	normalized := make(map[string]int, len(weights))
+	for name, w := range weights {
+		normalized[NormalizeModelName(name)] = w
+	}
+	weightOf := func(p libmodelprovider.Provider) int {
+		if w, ok := weights[p.ModelName()]; ok {
+			return w
+		}
+		if w, ok := normalized[NormalizeModelName(p.ModelName())]; ok {
+			return w
+		}
+		return 1
+	}
+	return func(candidates []libmodelprovider.Provider) (libmodelprovider.Provider, string, error) {
+		total := 0
+		for _, p := range candidates {
+			if p != nil && len(p.GetBackendIDs()) > 0 {
+				total += max(weightOf(p), 0)
+			}
+		}
+		if total == 0 {
+			return nil, "", ErrNoSatisfactoryModel
+		}
+		pick := rand.Intn(total)
+		for _, p := range candidates {
+			if p == nil || len(p.GetBackendIDs()) == 0 {
+				continue
+			}
+			w := max(weightOf(p), 0)
+			if pick < w {
+				backend, err := selectRandomBackend(p)
+				return p, backend, err
+			}
+			pick -= w
+		}
+		return nil, "", ErrNoSatisfactoryModel
+	}
+}
@@ -0,0 +1,121 @@
+package llmresolver_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/internal/llmresolver"
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+)
+
+func policyCandidates() []libmodelprovider.Provider {
+	return []libmodelprovider.Provider{
+		&libmodelprovider.MockProvider{ID: "p1", Name: "primary:latest", Backends: []string{"b1", "b2"}},
+		&libmodelprovider.MockProvider{ID: "p2", Name: "secondary", Backends: []string{"b3"}},
+	}
+}
+
+func pick(t *testing.T, policy llmresolver.Policy, candidates []libmodelprovider.Provider) string {
+	t.Helper()
+	p, backend, err := policy(candidates)
+	if err != nil {
+		t.Fatalf("policy: %v", err)
+	}
+	return p.GetID() + "/" + backend
+}
+
+func TestUnit_RoundRobinCyclesEveryBackend(t *testing.T) {
+	policy := llmresolver.RoundRobin()
+	candidates := policyCandidates()
+	var got []string
+	for range 6 {
+		got = append(got, pick(t, policy, candidates))
+	}
+	want := []string{"p1/b1", "p1/b2", "p2/b3", "p1/b1", "p1/b2", "p2/b3"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("rotation = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestUnit_LeastRecentlyUsedPrefersUnusedThenOldest(t *testing.T) {
+	policy := llmresolver.LeastRecentlyUsed()
+	candidates := policyCandidates()
+	if got := pick(t, policy, candidates); got != "p1/b1" {
+		t.Fatalf("first pick must honor candidate order, got %s", got)
+	}
+	if got := pick(t, policy, candidates); got != "p1/b2" {
+		t.Fatalf("second pick must take the next unused pair, got %s", got)
+	}
+	if got := pick(t, policy, candidates); got != "p2/b3" {
+		t.Fatalf("third pick must take the last unused pair, got %s", got)
+	}
+	// Narrowing the candidates to p1 only: b1 was used before b2.
+	if got := pick(t, policy, candidates[:1]); got != "p1/b1" {
+		t.Fatalf("oldest pair must win once all are used, got %s", got)
+	}
+}
+
+func TestUnit_WeightedHonorsZeroAndDefaults(t *testing.T) {
+	candidates := policyCandidates()
+
+	// "primary" matches "primary:latest" after normalization.
+	onlySecondary := llmresolver.Weighted(map[string]int{"primary": 0})
+	for range 20 {
+		if got := pick(t, onlySecondary, candidates); got != "p2/b3" {
+			t.Fatalf("a zero-weight model must never be picked, got %s", got)
+		}
+	}
+
+	none := llmresolver.Weighted(map[string]int{"primary:latest": 0, "secondary": 0})
+	if _, _, err := none(candidates); !errors.Is(err, llmresolver.ErrNoSatisfactoryModel) {
+		t.Fatalf("all-zero weights must fail with ErrNoSatisfactoryModel, got %v", err)
+	}
+
+	seen := map[string]bool{}
+	unweighted := llmresolver.Weighted(nil)
+	for range 200 {
+		p, _, err := unweighted(candidates)
+		if err != nil {
+			t.Fatalf("policy: %v", err)
+		}
+		seen[p.GetID()] = true
+	}
+	if !seen["p1"] || !seen["p2"] {
+		t.Fatalf("models without a weight must default to 1 and both be picked, saw %v", seen)
+	}
+}
+
+func TestUnit_PoliciesRejectEmptyCandidates(t *testing.T) {
+	for name, policy := range map[string]llmresolver.Policy{
+		"round_robin":         llmresolver.RoundRobin(),
+		"least_recently_used": llmresolver.LeastRecentlyUsed(),
+		"weighted":            llmresolver.Weighted(nil),
+	} {
+		if _, _, err := policy(nil); !errors.Is(err, llmresolver.ErrNoSatisfactoryModel) {
+			t.Errorf("%s: want ErrNoSatisfactoryModel for no candidates, got %v", name, err)
+		}
+	}
+}
+
+func TestUnit_StatefulPoliciesAreConcurrencySafe(t *testing.T) {
+	candidates := policyCandidates()
+	for _, policy := range []llmresolver.Policy{llmresolver.RoundRobin(), llmresolver.LeastRecentlyUsed()} {
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 50 {
+					if _, _, err := policy(candidates); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
@@ -24,11 +24,13 @@ func AddRoutes(mux *http.ServeMux, service localfileservice.Service) {
 	mux.HandleFunc("GET /files", h.list)
 	mux.HandleFunc("GET /files/stat", h.stat)
 	mux.HandleFunc("GET /files/content", h.content)
+	mux.HandleFunc("GET /files/text", h.text)
 	mux.HandleFunc("GET /files/download", h.download)
 	mux.HandleFunc("POST /files", h.createFile)
 	mux.HandleFunc("PUT /files", h.updateFile)
 	mux.HandleFunc("DELETE /files", h.deleteFile)
 	mux.HandleFunc("PUT /files/move", h.movePath)
+	mux.HandleFunc("POST /files/copy", h.copyPath)
 	mux.HandleFunc("POST /folders", h.createFolder)
 	mux.HandleFunc("DELETE /folders", h.deleteFolder)
 }
@@ -58,6 +60,17 @@ type moveRequest struct {
 	NewPath string `json:"newPath"`
 }
 
+type copyRequest struct {
+	Path    string `json:"path"`
+	NewPath string `json:"newPath"`
+}
+
+type fileTextResponse struct {
+	Path     string                 `json:"path"`
+	Text     string                 `json:"text"`
+	Metadata localfileservice.Entry `json:"metadata"`
+}
+
 type fileContentResponse struct {
 	Path          string                 `json:"path"`
 	Content       string                 `json:"content"`
@@ -66,21 +79,64 @@ type fileContentResponse struct {
 	Metadata      localfileservice.Entry `json:"metadata"`
 }
 
+// EntryPage is one page of GET /files, returned when the request passes
+// limit or cursor.
+type EntryPage struct {
+	Data       []Entry `json:"data"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+	HasMore    bool    `json:"hasMore"`
+}
+
 // list returns the directory entries under path, optionally filtered to a
 // server-side tree view (e.g. the agent's view with access verdicts).
+// Directories come first, then entries by name. Without limit or cursor the
+// whole directory is returned as an array; with either, one page of it is,
+// and the nextCursor of that page passed back as cursor fetches the next.
 func (h *handler) list(w http.ResponseWriter, r *http.Request) {
 	path := apiframework.GetQueryParam(r, "path", ".", "Directory path relative to the project root.")
-	entries, err := h.service.List(r.Context(), path)
+	query := r.URL.Query()
+	paged := query.Has("limit") || query.Has("cursor")
+	cursor, limit, err := apiframework.PageParams(r, 200)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	var after *localfileservice.Entry
+	if cursor != nil {
+		// The cursor's ID is the entry's path, with a trailing slash for a
+		// directory; see fileCursor.
+		name := strings.TrimSuffix(cursor.ID, "/")
+		after = &localfileservice.Entry{
+			Name:        name[strings.LastIndex(name, "/")+1:],
+			IsDirectory: strings.HasSuffix(cursor.ID, "/"),
+		}
+	}
+	// One extra entry tells Paginate whether another page follows; unpaged,
+	// the whole directory is read.
+	fetch := 0
+	if paged {
+		fetch = limit + 1
+	}
+	entries, err := h.service.ListPage(r.Context(), path, after, fetch)
 	if err != nil {
 		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
 		return
 	}
+	var page apiframework.Page[localfileservice.Entry]
+	if paged {
+		page = apiframework.Paginate(entries, limit, fileCursor)
+		entries = page.Data
+	}
 
 	// filter selects a server-side view of the tree. Absent or "full" returns
 	// today's raw listing byte-identically (backward compatible). "agent" (and
 	// any future token) is looked up in the registry and applied.
 	filterName := strings.TrimSpace(apiframework.GetQueryParam(r, "filter", "", "Tree view to apply: 'full' (default, raw tree) or 'agent' (the tree as the agent sees it, with per-path access verdicts)."))
 	if filterName == "" || filterName == "full" {
+		if paged {
+			_ = apiframework.Encode(w, r, http.StatusOK, entryPage(page, wrapEntries(entries)))
+			return
+		}
 		_ = apiframework.Encode(w, r, http.StatusOK, entries) // @response []localfileservice.Entry
 		return
 	}
@@ -104,18 +160,44 @@ func (h *handler) list(w http.ResponseWriter, r *http.Request) {
 	policyName := strings.TrimSpace(apiframework.GetQueryParam(r, "policy", "", "HITL policy name to evaluate the agent view against; omitted uses the configured default."))
 	ev := agentview.NewEvaluator(h.view, h.hitlFor(policyName), policyName)
 
-	annotated := make([]Entry, len(entries))
-	for i, e := range entries {
-		annotated[i] = Entry{Entry: e}
-	}
-	result, err := filter.Apply(r.Context(), annotated, ev)
+	result, err := filter.Apply(r.Context(), wrapEntries(entries), ev)
 	if err != nil {
 		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
 		return
 	}
+	if paged {
+		// The cursor follows the unfiltered page, so a filter that drops
+		// entries leaves the page short rather than skipping any.
+		_ = apiframework.Encode(w, r, http.StatusOK, entryPage(page, result))
+		return
+	}
 	_ = apiframework.Encode(w, r, http.StatusOK, result) // @response []localfileapi.Entry
 }
 
+// fileCursor is the page cursor after entry: its path, with a trailing slash
+// when it is a directory, so list can tell where in the directories-first
+// order to resume. Files are not ordered by time; the modification time only
+// fills the cursor's timestamp.
+func fileCursor(entry localfileservice.Entry) apiframework.PageCursor {
+	id := entry.Path
+	if entry.IsDirectory {
+		id += "/"
+	}
+	return apiframework.PageCursor{CreatedAt: entry.UpdatedAt, ID: id}
+}
+
+func entryPage(page apiframework.Page[localfileservice.Entry], data []Entry) EntryPage {
+	return EntryPage{Data: data, NextCursor: page.NextCursor, HasMore: page.HasMore}
+}
+
+func wrapEntries(entries []localfileservice.Entry) []Entry {
+	wrapped := make([]Entry, len(entries))
+	for i, e := range entries {
+		wrapped[i] = Entry{Entry: e}
+	}
+	return wrapped
+}
+
 // stat returns the metadata entry for one path.
 func (h *handler) stat(w http.ResponseWriter, r *http.Request) {
 	path := apiframework.GetQueryParam(r, "path", "", "Path relative to the project root.")
@@ -136,6 +218,9 @@ func (h *handler) content(w http.ResponseWriter, r *http.Request) {
 		_ = apiframework.Error(w, r, err, apiframework.GetOperation)
 		return
 	}
+	if notModified(w, r, meta) {
+		return
+	}
 	resp := fileContentResponse{
 		Path:     meta.Path,
 		Metadata: *meta,
@@ -150,6 +235,22 @@ func (h *handler) content(w http.ResponseWriter, r *http.Request) {
 	_ = apiframework.Encode(w, r, http.StatusOK, resp) // @response localfileapi.fileContentResponse
 }
 
+// text returns a file's plain text: text files as they are, PDFs with their
+// text extracted. Files with no text form are rejected with 422.
+func (h *handler) text(w http.ResponseWriter, r *http.Request) {
+	path := apiframework.GetQueryParam(r, "path", "", "File path relative to the project root.")
+	text, meta, err := h.service.GetFileText(r.Context(), path)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.GetOperation)
+		return
+	}
+	if notModified(w, r, meta) {
+		return
+	}
+	resp := fileTextResponse{Path: meta.Path, Text: text, Metadata: *meta}
+	_ = apiframework.Encode(w, r, http.StatusOK, resp) // @response localfileapi.fileTextResponse
+}
+
 // download serves a file's raw bytes with its stored content type.
 func (h *handler) download(w http.ResponseWriter, r *http.Request) {
 	// @response binary The file's raw bytes, served with its stored content type (application/octet-stream fallback).
@@ -159,6 +260,9 @@ func (h *handler) download(w http.ResponseWriter, r *http.Request) {
 		_ = apiframework.Error(w, r, err, apiframework.GetOperation)
 		return
 	}
+	if notModified(w, r, meta) {
+		return
+	}
 	if meta.ContentType != "" {
 		w.Header().Set("Content-Type", meta.ContentType)
 	} else {
@@ -169,6 +273,27 @@ func (h *handler) download(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(data)
 }
 
+// notModified sets the ETag for a file read (its content hash) and, when the
+// request's If-None-Match already names that tag, answers 304 with no body so
+// the client keeps its cached copy. It reports whether the response was
+// written. The hash comes from the bytes just read, so an edit on disk always
+// yields a new tag.
+func notModified(w http.ResponseWriter, r *http.Request, meta *localfileservice.Entry) bool {
+	if meta.Hash == "" {
+		return false
+	}
+	etag := `"` + meta.Hash + `"`
+	w.Header().Set("ETag", etag)
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
 // createFile writes a new file from the request payload and returns its
 // entry.
 func (h *handler) createFile(w http.ResponseWriter, r *http.Request) {
@@ -242,6 +367,22 @@ func (h *handler) movePath(w http.ResponseWriter, r *http.Request) {
 	_ = apiframework.Encode(w, r, http.StatusOK, entry) // @response localfileservice.Entry
 }
 
+// copyPath duplicates a file or deep-copies a folder to a new path and
+// returns the entry at the new path. The destination must not exist.
+func (h *handler) copyPath(w http.ResponseWriter, r *http.Request) {
+	req, err := apiframework.Decode[copyRequest](r) // @request localfileapi.copyRequest
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	entry, err := h.service.Copy(r.Context(), req.Path, req.NewPath)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusCreated, entry) // @response localfileservice.Entry
+}
+
 // Delete a file.
 //
 // Removes the file at the given path, relative to the project root.
@@ -56,11 +56,13 @@ func AddWorkspaceRoutes(mux *http.ServeMux, factory *vfs.Factory, hitlFor Policy
 	mux.HandleFunc("GET /files", wh.wrap((*handler).list))
 	mux.HandleFunc("GET /files/stat", wh.wrap((*handler).stat))
 	mux.HandleFunc("GET /files/content", wh.wrap((*handler).content))
+	mux.HandleFunc("GET /files/text", wh.wrap((*handler).text))
 	mux.HandleFunc("GET /files/download", wh.wrap((*handler).download))
 	mux.HandleFunc("POST /files", wh.wrap((*handler).createFile))
 	mux.HandleFunc("PUT /files", wh.wrap((*handler).updateFile))
 	mux.HandleFunc("DELETE /files", wh.wrap((*handler).deleteFile))
 	mux.HandleFunc("PUT /files/move", wh.wrap((*handler).movePath))
+	mux.HandleFunc("POST /files/copy", wh.wrap((*handler).copyPath))
 	mux.HandleFunc("POST /folders", wh.wrap((*handler).createFolder))
 	mux.HandleFunc("DELETE /folders", wh.wrap((*handler).deleteFolder))
 	return nil
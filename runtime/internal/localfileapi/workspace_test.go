@@ -68,3 +68,91 @@ func TestUnit_WorkspaceRoutes_PerRootAndAllowlist(t *testing.T) {
 	resp, _ = list(t.TempDir())
 	assert.GreaterOrEqual(t, resp.StatusCode, 400, "a root outside the allowlist must be rejected")
 }
+
+func TestUnit_WorkspaceRoutes_ContentETag(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello"), 0o644))
+
+	factory, err := vfs.NewFactory(root)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	require.NoError(t, localfileapi.AddWorkspaceRoutes(mux, factory, nil))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	get := func(route, ifNoneMatch string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+route+"?path=a.txt", nil)
+		require.NoError(t, err)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	for _, route := range []string{"/files/content", "/files/text", "/files/download"} {
+		first := get(route, "")
+		require.Equal(t, http.StatusOK, first.StatusCode, route)
+		etag := first.Header.Get("ETag")
+		require.NotEmpty(t, etag, route)
+
+		assert.Equal(t, http.StatusNotModified, get(route, etag).StatusCode, route)
+		assert.Equal(t, http.StatusNotModified, get(route, `"other", W/`+etag).StatusCode, route)
+		assert.Equal(t, http.StatusOK, get(route, `"stale"`).StatusCode, route)
+	}
+
+	// Changing the file on disk changes its tag.
+	before := get("/files/content", "").Header.Get("ETag")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("changed"), 0o644))
+	assert.Equal(t, http.StatusOK, get("/files/content", before).StatusCode)
+}
+
+func TestUnit_WorkspaceRoutes_ListPages(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "sub"), 0o750))
+	for _, f := range []string{"c.txt", "a.txt", "b.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, f), []byte("x"), 0o644))
+	}
+	factory, err := vfs.NewFactory(root)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	require.NoError(t, localfileapi.AddWorkspaceRoutes(mux, factory, nil))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var paths []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "paging must end")
+		resp, err := http.Get(srv.URL + "/files?path=.&limit=2&cursor=" + url.QueryEscape(cursor))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var page localfileapi.EntryPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		_ = resp.Body.Close()
+		for _, e := range page.Data {
+			paths = append(paths, e.Path)
+		}
+		if !page.HasMore {
+			require.Empty(t, page.NextCursor)
+			break
+		}
+		cursor = page.NextCursor
+	}
+	require.Equal(t, []string{"sub", "a.txt", "b.txt", "c.txt"}, paths, "directories first, then by name")
+
+	// Without limit or cursor the listing is still the whole directory.
+	resp, err := http.Get(srv.URL + "/files?path=.")
+	require.NoError(t, err)
+	var entries []localfileservice.Entry
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+	_ = resp.Body.Close()
+	require.Len(t, entries, 4)
+
+	resp, err = http.Get(srv.URL + "/files?path=.&cursor=bogus")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
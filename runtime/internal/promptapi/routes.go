@@ -0,0 +1,141 @@
+// Package promptapi exposes the prompt library (runtime/promptservice) over
+// REST: named, versioned prompt templates that chains reference with
+// {{prompt "name"}}, and the usage report that shows which chains reference
+// each one.
+package promptapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	apiframework "github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/runtime/promptservice"
+)
+
+// AddRoutes registers the prompt library routes on mux.
+func AddRoutes(mux *http.ServeMux, svc promptservice.Service) {
+	h := &promptHandler{svc: svc}
+
+	mux.HandleFunc("GET /prompts", h.list)
+	mux.HandleFunc("POST /prompts", h.create)
+	mux.HandleFunc("GET /prompts/usage", h.usage)
+	mux.HandleFunc("GET /prompts/{name}", h.get)
+	mux.HandleFunc("GET /prompts/{name}/versions", h.versions)
+	mux.HandleFunc("PUT /prompts/{name}", h.update)
+	mux.HandleFunc("DELETE /prompts/{name}", h.delete)
+}
+
+type promptHandler struct {
+	svc promptservice.Service
+}
+
+// list returns the latest version of every prompt, sorted by name.
+func (h *promptHandler) list(w http.ResponseWriter, r *http.Request) {
+	prompts, err := h.svc.List(r.Context())
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, prompts) // @response []promptservice.Prompt
+}
+
+// create stores the first version of a new prompt.
+func (h *promptHandler) create(w http.ResponseWriter, r *http.Request) {
+	prompt, err := apiframework.Decode[promptservice.Prompt](r) // @request promptservice.Prompt
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	if err := h.svc.Create(r.Context(), &prompt); err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusCreated, prompt) // @response promptservice.Prompt
+}
+
+// usage lists, for every prompt, the chains whose tasks reference it. A
+// prompt with chains listed here cannot be deleted.
+func (h *promptHandler) usage(w http.ResponseWriter, r *http.Request) {
+	usage, err := h.svc.Usage(r.Context())
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, usage) // @response []promptservice.Usage
+}
+
+// get returns one version of a prompt, the latest unless version is given.
+func (h *promptHandler) get(w http.ResponseWriter, r *http.Request) {
+	name := apiframework.GetPathParam(r, "name", "The prompt name.")
+	if name == "" {
+		_ = apiframework.Error(w, r, fmt.Errorf("missing name parameter %w", apiframework.ErrBadPathValue), apiframework.GetOperation)
+		return
+	}
+	version := 0
+	if raw := apiframework.GetQueryParam(r, "version", "", "Version to return; the latest when omitted."); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			_ = apiframework.Error(w, r, apiframework.InvalidParameterValue("version", "version must be a positive integer"), apiframework.GetOperation)
+			return
+		}
+		version = v
+	}
+	prompt, err := h.svc.Get(r.Context(), name, version)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.GetOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, prompt) // @response promptservice.Prompt
+}
+
+// versions returns every version of a prompt, oldest first.
+func (h *promptHandler) versions(w http.ResponseWriter, r *http.Request) {
+	name := apiframework.GetPathParam(r, "name", "The prompt name.")
+	if name == "" {
+		_ = apiframework.Error(w, r, fmt.Errorf("missing name parameter %w", apiframework.ErrBadPathValue), apiframework.ListOperation)
+		return
+	}
+	versions, err := h.svc.Versions(r.Context(), name)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ListOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, versions) // @response []promptservice.Prompt
+}
+
+// update stores the body as the next version of the prompt and returns it.
+// Earlier versions are kept for pinned references.
+func (h *promptHandler) update(w http.ResponseWriter, r *http.Request) {
+	name := apiframework.GetPathParam(r, "name", "The prompt name.")
+	if name == "" {
+		_ = apiframework.Error(w, r, fmt.Errorf("missing name parameter %w", apiframework.ErrBadPathValue), apiframework.UpdateOperation)
+		return
+	}
+	prompt, err := apiframework.Decode[promptservice.Prompt](r) // @request promptservice.Prompt
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.UpdateOperation)
+		return
+	}
+	prompt.Name = name
+	if err := h.svc.Update(r.Context(), &prompt); err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.UpdateOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, prompt) // @response promptservice.Prompt
+}
+
+// delete removes a prompt and all its versions. It is refused with 409 while
+// a chain references the prompt.
+func (h *promptHandler) delete(w http.ResponseWriter, r *http.Request) {
+	name := apiframework.GetPathParam(r, "name", "The prompt name.")
+	if name == "" {
+		_ = apiframework.Error(w, r, fmt.Errorf("missing name parameter %w", apiframework.ErrBadPathValue), apiframework.DeleteOperation)
+		return
+	}
+	if err := h.svc.Delete(r.Context(), name); err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.DeleteOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, "prompt removed") // @response string
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/contenox/runtime/runtime/internal/setupcheck"
+	"github.com/contenox/runtime/runtime/runtimetypes"
 	"github.com/contenox/runtime/runtime/stateservice"
 	"github.com/contenox/runtime/runtime/statetype"
 	"github.com/stretchr/testify/require"
@@ -44,6 +45,15 @@ func (f *fakeStateService) SetCLIConfig(_ context.Context, patch stateservice.CL
 	f.setPatch = patch
 	return f.setSnapshot, nil
 }
+func (f *fakeStateService) TestBackendConnection(_ context.Context, _ runtimetypes.Backend) (bool, string) {
+	return true, ""
+}
+
+func (f *fakeStateService) TriggerReconcile(_ context.Context) {}
+
+func (f *fakeStateService) ModelReport(_ context.Context) ([]statetype.ModelReport, error) {
+	return nil, nil
+}
 
 func TestRefreshStatusRunsStateRefresh(t *testing.T) {
 	svc := &fakeStateService{
@@ -0,0 +1,50 @@
+package taskchainapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/runtime/taskchainservice"
+	"github.com/contenox/runtime/runtime/taskengine"
+)
+
+type simulateRequest struct {
+	// Chain is the chain to run. When omitted, Ref names a stored chain.
+	Chain *taskengine.TaskChainDefinition `json:"chain,omitempty" openapi_include_type:"taskengine.TaskChainDefinition"`
+	Ref   string                          `json:"ref,omitempty" example:"triage.json"`
+	Input any                             `json:"input" example:"Where is my order?"`
+	// Responses scripts the reply each model or tools task gets, keyed by task ID.
+	Responses map[string]taskchainservice.MockResponse `json:"responses"`
+}
+
+// simulateTaskChain runs a chain with every model and tools call answered
+// from the scripted responses instead of a backend, and returns the full
+// execution trace. A chain that fails while running still returns 200 with
+// the error and the steps up to it, so failure paths can be asserted too.
+func (h *handler) simulateTaskChain(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := apiframework.Decode[simulateRequest](r) // @request taskchainapi.simulateRequest
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ExecuteOperation)
+		return
+	}
+	chain := req.Chain
+	if chain == nil {
+		if req.Ref == "" {
+			_ = apiframework.Error(w, r, fmt.Errorf("%w: chain or ref is required", apiframework.ErrBadRequest), apiframework.ExecuteOperation)
+			return
+		}
+		chain, err = h.service.Get(ctx, req.Ref)
+		if err != nil {
+			_ = apiframework.Error(w, r, err, apiframework.ExecuteOperation)
+			return
+		}
+	}
+	result, err := taskchainservice.Simulate(ctx, chain, req.Input, req.Responses)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.ExecuteOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, result) // @response taskchainservice.SimulationResult
+}
@@ -16,6 +16,7 @@ func AddTaskChainRoutes(mux *http.ServeMux, service taskchainservice.Service) {
 	mux.HandleFunc("POST /taskchains", h.createTaskChain)
 	mux.HandleFunc("PUT /taskchains", h.updateTaskChain)
 	mux.HandleFunc("DELETE /taskchains", h.deleteTaskChain)
+	mux.HandleFunc("POST /taskchains/simulate", h.simulateTaskChain)
 }
 
 type handler struct {
@@ -0,0 +1,74 @@
+package taskchainapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/runtime/taskchainservice"
+	"github.com/contenox/runtime/runtime/taskengine"
+)
+
+// AddTaskChainTransferRoutes mounts chain export and import — the routes a
+// chains-as-code workflow syncs through.
+func AddTaskChainTransferRoutes(mux *http.ServeMux, transfer *taskchainservice.Transfer) {
+	h := &transferHandler{transfer: transfer}
+	mux.HandleFunc("GET /taskchains/export", h.exportTaskChains)
+	mux.HandleFunc("POST /taskchains/import", h.importTaskChain)
+}
+
+type transferHandler struct {
+	transfer *taskchainservice.Transfer
+}
+
+type importedTaskChain struct {
+	Path  string                          `json:"path" example:"default-chain.json"`
+	Chain *taskengine.TaskChainDefinition `json:"chain" openapi_include_type:"taskengine.TaskChainDefinition"`
+}
+
+// exportTaskChains returns one chain (query parameter `ref`) as YAML, or,
+// without `ref`, every stored chain as a single YAML document listing each
+// chain with its path.
+func (h *transferHandler) exportTaskChains(w http.ResponseWriter, r *http.Request) {
+	// @response binary YAML task chain definition, or a document of every chain when ref is omitted.
+	ref := apiframework.GetQueryParam(r, "ref", "", "Path or ID of the chain to export; omit to export every chain.")
+	var (
+		data []byte
+		err  error
+	)
+	if ref == "" {
+		data, err = h.transfer.ExportChains(r.Context())
+	} else {
+		data, err = h.transfer.ExportChain(r.Context(), ref)
+	}
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.GetOperation)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// importTaskChain validates a chain — the engine's pre-execution checks plus
+// a check that every tool it names is registered — and stores it under its
+// existing path, or "<id>.json" for a new chain. A chain whose ID is already
+// stored is refused with 409 unless overwrite is true.
+func (h *transferHandler) importTaskChain(w http.ResponseWriter, r *http.Request) {
+	overwrite, err := strconv.ParseBool(apiframework.GetQueryParam(r, "overwrite", "false", "Replace a stored chain with the same ID instead of refusing the import."))
+	if err != nil {
+		_ = apiframework.Error(w, r, apiframework.InvalidParameterValue("overwrite", "overwrite must be true or false"), apiframework.CreateOperation)
+		return
+	}
+	chain, err := apiframework.Decode[taskengine.TaskChainDefinition](r) // @request taskengine.TaskChainDefinition
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	path, err := h.transfer.ImportChain(r.Context(), &chain, overwrite)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, importedTaskChain{Path: path, Chain: &chain}) // @response taskchainapi.importedTaskChain
+}
@@ -2,6 +2,7 @@ package taskexecapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,15 +11,26 @@ import (
 	"github.com/contenox/runtime/apiframework/middleware"
 	"github.com/contenox/runtime/libtracker"
 	"github.com/contenox/runtime/runtime/agentservice"
+	"github.com/contenox/runtime/runtime/execservice"
 	"github.com/contenox/runtime/runtime/stateservice"
 	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/google/uuid"
 )
 
 type Defaults = stateservice.RuntimeDefaults
 
+// AddRoutes mounts chain execution. Every execution is registered under its
+// request ID (the X-Request-ID header, generated when absent) as its run ID.
+// POST /tasks answers once the chain is done, so only a client that sets
+// X-Request-ID knows the run ID while it is in flight; POST /tasks/runs starts
+// the chain in the background and answers at once with the run ID. Either way
+// GET /tasks/{runId} polls the run and POST /tasks/{runId}/cancel stops it.
 func AddRoutes(mux *http.ServeMux, agent agentservice.Agent, auth middleware.AuthZReader, stateService stateservice.Service, defaults Defaults) {
-	h := &handler{agent: agent, auth: auth, stateService: stateService, defaults: defaults}
+	h := &handler{agent: agent, auth: auth, stateService: stateService, defaults: defaults, runs: execservice.NewRuns(0)}
 	mux.HandleFunc("POST /tasks", h.execute)
+	mux.HandleFunc("POST /tasks/runs", h.start)
+	mux.HandleFunc("GET /tasks/{runId}", h.status)
+	mux.HandleFunc("POST /tasks/{runId}/cancel", h.cancel)
 }
 
 type handler struct {
@@ -26,6 +38,7 @@ type handler struct {
 	auth         middleware.AuthZReader
 	stateService stateservice.Service
 	defaults     Defaults
+	runs         *execservice.Runs
 }
 
 type executeTaskRequest struct {
@@ -36,11 +49,16 @@ type executeTaskRequest struct {
 }
 
 type executeTaskResponse struct {
+	// RequestID is also the run ID the execution was registered under.
 	RequestID  string                         `json:"requestId,omitempty"`
+	Status     execservice.RunStatus          `json:"status" example:"completed"`
 	Output     any                            `json:"output"`
 	OutputType string                         `json:"outputType"`
 	State      []taskengine.CapturedStateUnit `json:"state" openapi_include_type:"taskengine.CapturedStateUnit"`
 	StopReason agentservice.StopReason        `json:"stopReason,omitempty"`
+	// Cost is the estimated price of the run's model calls, summed from the
+	// steps; omitted when no price table covers the models used.
+	Cost float64 `json:"cost,omitempty" example:"0.0042"`
 }
 
 func (h *handler) authorize(ctx context.Context) error {
@@ -54,24 +72,70 @@ func (h *handler) authorize(ctx context.Context) error {
 // execute runs the submitted task chain through the configured agent and
 // returns the output together with the captured per-step state and stop reason.
 func (h *handler) execute(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	if err := h.authorize(ctx); err != nil {
-		_ = apiframework.Error(w, r, err, apiframework.AuthorizeOperation)
+	req, inputType, ok := h.decodeRun(w, r) // @request taskexecapi.executeTaskRequest
+	if !ok {
 		return
 	}
-	if h.agent == nil {
-		_ = apiframework.Error(w, r, fmt.Errorf("task agent is not configured"), apiframework.ServerOperation)
+	ctx, runID := withRunID(r.Context())
+	runCtx, finish, err := h.startRun(ctx, runID, req.Chain.ID)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
 		return
 	}
+	out, err := h.run(runCtx, runID, req, inputType, finish)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, out) // @response taskexecapi.executeTaskResponse
+}
 
-	req, err := apiframework.Decode[executeTaskRequest](r) // @request taskexecapi.executeTaskRequest
+// start registers the submitted task chain as a run and executes it in the
+// background, answering 202 with the run at once. The run outlives the
+// request; poll GET /tasks/{runId} for its status and, once it has finished,
+// its result — the body POST /tasks would have returned.
+func (h *handler) start(w http.ResponseWriter, r *http.Request) {
+	req, inputType, ok := h.decodeRun(w, r) // @request taskexecapi.executeTaskRequest
+	if !ok {
+		return
+	}
+	ctx, runID := withRunID(context.WithoutCancel(r.Context()))
+	runCtx, finish, err := h.startRun(ctx, runID, req.Chain.ID)
 	if err != nil {
 		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
 		return
 	}
+	go func() {
+		_, _ = h.run(runCtx, runID, req, inputType, finish)
+	}()
+	info, err := h.runs.Get(ctx, runID)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusAccepted, info) // @response execservice.RunInfo
+}
+
+// decodeRun authorizes and decodes a chain execution request, writing the
+// error response and reporting false when it is not acceptable.
+func (h *handler) decodeRun(w http.ResponseWriter, r *http.Request) (executeTaskRequest, taskengine.DataType, bool) {
+	if err := h.authorize(r.Context()); err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.AuthorizeOperation)
+		return executeTaskRequest{}, taskengine.DataTypeAny, false
+	}
+	if h.agent == nil {
+		_ = apiframework.Error(w, r, fmt.Errorf("task agent is not configured"), apiframework.ServerOperation)
+		return executeTaskRequest{}, taskengine.DataTypeAny, false
+	}
+
+	req, err := apiframework.Decode[executeTaskRequest](r)
+	if err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
+		return executeTaskRequest{}, taskengine.DataTypeAny, false
+	}
 	if len(req.Chain.Tasks) == 0 {
 		_ = apiframework.Error(w, r, apiframework.BadRequest("chain must contain at least one task"), apiframework.CreateOperation)
-		return
+		return executeTaskRequest{}, taskengine.DataTypeAny, false
 	}
 
 	inputType := taskengine.DataTypeAny
@@ -79,30 +143,107 @@ func (h *handler) execute(w http.ResponseWriter, r *http.Request) {
 		inputType, err = taskengine.DataTypeFromString(req.InputType)
 		if err != nil {
 			_ = apiframework.Error(w, r, apiframework.BadRequest(err.Error()), apiframework.CreateOperation)
-			return
+			return executeTaskRequest{}, taskengine.DataTypeAny, false
 		}
 	}
+	return req, inputType, true
+}
+
+// withRunID returns ctx carrying a request ID, generating one when the
+// request brought none, and that ID as the run ID.
+func withRunID(ctx context.Context) (context.Context, string) {
+	runID := requestID(ctx)
+	if runID == "" {
+		runID = uuid.NewString()
+		ctx = context.WithValue(ctx, libtracker.ContextKeyRequestID, runID)
+	}
+	return ctx, runID
+}
+
+func (h *handler) startRun(ctx context.Context, runID, chainID string) (context.Context, func(error), error) {
+	runCtx, finish, err := h.runs.Start(ctx, runID, chainID)
+	if errors.Is(err, execservice.ErrRunExists) {
+		err = apiframework.Conflict(err.Error())
+	}
+	return runCtx, finish, err
+}
 
+// run executes req under the registered run runID, records the response as
+// the run's result and finishes the run.
+func (h *handler) run(ctx context.Context, runID string, req executeTaskRequest, inputType taskengine.DataType, finish func(error)) (executeTaskResponse, error) {
 	resp, err := h.agent.Prompt(ctx, agentservice.PromptRequest{
 		InputValue:   req.Input,
 		InputType:    inputType,
 		Chain:        &req.Chain,
 		TemplateVars: h.templateVars(ctx, req.TemplateVars),
 	})
-	if err != nil {
-		_ = apiframework.Error(w, r, err, apiframework.CreateOperation)
-		return
+	// A cancelled run still answers with the steps it completed, so the
+	// caller can see how far it got; other failures stay errors.
+	cancelled := err != nil && resp != nil && errors.Is(ctx.Err(), context.Canceled)
+	if err != nil && !cancelled {
+		finish(err)
+		return executeTaskResponse{}, err
+	}
+	if cancelled && !errors.Is(err, context.Canceled) {
+		// Have the registry file the run as cancelled too.
+		err = fmt.Errorf("%w: %w", context.Canceled, err)
 	}
-
-	outputType := resp.OutputType.String()
 	out := executeTaskResponse{
-		RequestID:  requestID(ctx),
+		RequestID:  runID,
 		Output:     resp.Output,
-		OutputType: outputType,
+		OutputType: resp.OutputType.String(),
 		State:      resp.Steps,
 		StopReason: resp.StopReason,
+		Cost:       taskengine.TotalCost(resp.Steps),
 	}
-	_ = apiframework.Encode(w, r, http.StatusOK, out) // @response taskexecapi.executeTaskResponse
+	out.Status = execservice.RunCompleted
+	if cancelled {
+		out.Status = execservice.RunCancelled
+	}
+	_ = h.runs.SetResult(runID, out)
+	finish(err)
+	return out, nil
+}
+
+// status reports whether a run is still going, completed, failed or was
+// cancelled. Finished runs are remembered for a bounded number of later runs.
+func (h *handler) status(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := h.authorize(ctx); err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.AuthorizeOperation)
+		return
+	}
+	runID := apiframework.GetPathParam(r, "runId", "The run ID (request ID) of the execution.")
+	info, err := h.runs.Get(ctx, runID)
+	if err != nil {
+		if errors.Is(err, execservice.ErrRunNotFound) {
+			err = apiframework.NotFound(err.Error())
+		}
+		_ = apiframework.Error(w, r, err, apiframework.GetOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, info) // @response execservice.RunInfo
+}
+
+// cancel aborts an in-flight run: the model or hook call it is waiting on is
+// cancelled and POST /tasks returns the steps completed so far with status
+// "cancelled". Cancelling a finished run is a no-op.
+func (h *handler) cancel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := h.authorize(ctx); err != nil {
+		_ = apiframework.Error(w, r, err, apiframework.AuthorizeOperation)
+		return
+	}
+	runID := apiframework.GetPathParam(r, "runId", "The run ID (request ID) of the execution.")
+	// @request none cancels the run named in the path; the request carries no body
+	if err := h.runs.CancelExecution(ctx, runID); err != nil {
+		if errors.Is(err, execservice.ErrRunNotFound) {
+			err = apiframework.NotFound(err.Error())
+		}
+		_ = apiframework.Error(w, r, err, apiframework.UpdateOperation)
+		return
+	}
+	_ = apiframework.Encode(w, r, http.StatusOK, "cancelled") // @response string
 }
 
 func (h *handler) templateVars(ctx context.Context, raw map[string]string) map[string]string {
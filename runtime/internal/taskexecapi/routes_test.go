@@ -7,10 +7,13 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/contenox/runtime/apiframework"
 	"github.com/contenox/runtime/runtime/agentservice"
+	"github.com/contenox/runtime/runtime/execservice"
 	"github.com/contenox/runtime/runtime/internal/setupcheck"
+	"github.com/contenox/runtime/runtime/runtimetypes"
 	"github.com/contenox/runtime/runtime/stateservice"
 	"github.com/contenox/runtime/runtime/statetype"
 	"github.com/contenox/runtime/runtime/taskengine"
@@ -77,6 +80,15 @@ func (s stubStateService) CLIConfig(context.Context) (stateservice.CLIConfigSnap
 func (s stubStateService) SetCLIConfig(context.Context, stateservice.CLIConfigPatch) (stateservice.CLIConfigSnapshot, error) {
 	return s.config, nil
 }
+func (s stubStateService) TestBackendConnection(context.Context, runtimetypes.Backend) (bool, string) {
+	return true, ""
+}
+
+func (s stubStateService) TriggerReconcile(context.Context) {}
+
+func (s stubStateService) ModelReport(context.Context) ([]statetype.ModelReport, error) {
+	return nil, nil
+}
 
 func TestUnit_ExecuteTask_PostsChainToAgent(t *testing.T) {
 	agent := &mockAgent{}
@@ -196,3 +208,137 @@ func TestUnit_ExecuteTask_RejectsEmptyChain(t *testing.T) {
 		t.Fatalf("agent should not be called, got %#v", agent.req)
 	}
 }
+
+// blockingAgent runs until its context is cancelled, then returns the steps
+// it completed the way agentservice does for a cancelled chain.
+type blockingAgent struct {
+	mockAgent
+	started chan struct{}
+}
+
+func (m *blockingAgent) Prompt(ctx context.Context, _ agentservice.PromptRequest) (*agentservice.PromptResponse, error) {
+	close(m.started)
+	<-ctx.Done()
+	return &agentservice.PromptResponse{
+		Steps:      []taskengine.CapturedStateUnit{{TaskID: "one", TaskHandler: string(taskengine.HandleNoop)}},
+		StopReason: agentservice.InferStopReason(ctx.Err(), nil),
+	}, ctx.Err()
+}
+
+func TestUnit_ExecuteTask_CancelInFlightRun(t *testing.T) {
+	agent := &blockingAgent{started: make(chan struct{})}
+	mux := http.NewServeMux()
+	AddRoutes(mux, agent, nil, nil, Defaults{})
+	handler := apiframework.RequestIDMiddleware(mux)
+
+	body := `{"chain": {"id": "slow", "tasks": [{"id": "one", "handler": "noop"}]}}`
+	req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "run-1")
+	rr := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(rr, req)
+	}()
+	<-agent.started
+
+	get := func() execservice.RunInfo {
+		t.Helper()
+		st := httptest.NewRecorder()
+		handler.ServeHTTP(st, httptest.NewRequest(http.MethodGet, "/tasks/run-1", nil))
+		if st.Code != http.StatusOK {
+			t.Fatalf("status code = %d, body = %s", st.Code, st.Body.String())
+		}
+		var info execservice.RunInfo
+		if err := json.Unmarshal(st.Body.Bytes(), &info); err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+	if info := get(); info.Status != execservice.RunRunning || info.ChainID != "slow" {
+		t.Fatalf("run = %#v, want running", info)
+	}
+
+	cancel := httptest.NewRecorder()
+	handler.ServeHTTP(cancel, httptest.NewRequest(http.MethodPost, "/tasks/run-1/cancel", nil))
+	if cancel.Code != http.StatusOK {
+		t.Fatalf("cancel status = %d, body = %s", cancel.Code, cancel.Body.String())
+	}
+	<-done
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	var got executeTaskResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != execservice.RunCancelled || got.StopReason != agentservice.StopCancelled || len(got.State) != 1 {
+		t.Fatalf("response = %#v", got)
+	}
+	if info := get(); info.Status != execservice.RunCancelled {
+		t.Fatalf("run = %#v, want cancelled", info)
+	}
+
+	missing := httptest.NewRecorder()
+	handler.ServeHTTP(missing, httptest.NewRequest(http.MethodPost, "/tasks/nope/cancel", nil))
+	if missing.Code != http.StatusNotFound {
+		t.Fatalf("unknown run status = %d", missing.Code)
+	}
+}
+
+func TestUnit_StartTask_ReturnsRunIDBeforeExecution(t *testing.T) {
+	agent := &blockingAgent{started: make(chan struct{})}
+	mux := http.NewServeMux()
+	AddRoutes(mux, agent, nil, nil, Defaults{})
+	handler := apiframework.RequestIDMiddleware(mux)
+
+	body := `{"chain": {"id": "slow", "tasks": [{"id": "one", "handler": "noop"}]}}`
+	req := httptest.NewRequest(http.MethodPost, "/tasks/runs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	var started execservice.RunInfo
+	if err := json.Unmarshal(rr.Body.Bytes(), &started); err != nil {
+		t.Fatal(err)
+	}
+	if started.RunID == "" || started.RunID != rr.Header().Get("X-Request-ID") || started.Status != execservice.RunRunning {
+		t.Fatalf("run = %#v, X-Request-ID = %q", started, rr.Header().Get("X-Request-ID"))
+	}
+	<-agent.started
+
+	cancel := httptest.NewRecorder()
+	handler.ServeHTTP(cancel, httptest.NewRequest(http.MethodPost, "/tasks/"+started.RunID+"/cancel", nil))
+	if cancel.Code != http.StatusOK {
+		t.Fatalf("cancel status = %d, body = %s", cancel.Code, cancel.Body.String())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st := httptest.NewRecorder()
+		handler.ServeHTTP(st, httptest.NewRequest(http.MethodGet, "/tasks/"+started.RunID, nil))
+		var info struct {
+			Status execservice.RunStatus `json:"status"`
+			Result *executeTaskResponse  `json:"result"`
+		}
+		if err := json.Unmarshal(st.Body.Bytes(), &info); err != nil {
+			t.Fatal(err)
+		}
+		if info.Status != execservice.RunRunning {
+			if info.Status != execservice.RunCancelled || info.Result == nil ||
+				info.Result.RequestID != started.RunID || info.Result.Status != execservice.RunCancelled || len(info.Result.State) != 1 {
+				t.Fatalf("finished run = %s", st.Body.String())
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run still running: %s", st.Body.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
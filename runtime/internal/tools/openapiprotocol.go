@@ -206,6 +206,7 @@ func (p *OpenAPIToolProtocol) ExecuteTool(
 
 	req.Header = headers
 	req.Header.Set("Accept", "application/json")
+	taskengine.InjectTraceContext(ctx, req.Header)
 	if reqBody != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
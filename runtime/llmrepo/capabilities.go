@@ -0,0 +1,111 @@
+package llmrepo
+
+import (
+	"context"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/internal/llmresolver"
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/contenox/runtime/runtime/statetype"
+)
+
+// Capabilities summarizes what a model supports across every backend that
+// currently serves it. Resolution may hand a request to any of those backends,
+// so each field is what a caller can rely on whichever one is picked.
+type Capabilities struct {
+	// SupportsTools is false when any backend serving the model is marked as
+	// unable to take tool definitions (a capability override in the store).
+	SupportsTools bool `json:"supportsTools"`
+	// SupportsStreaming is true when at least one backend can stream the
+	// model; stream resolution only considers those.
+	SupportsStreaming bool `json:"supportsStreaming"`
+	// MaxContext is the largest context length any backend reports for the
+	// model, 0 when none reports one.
+	MaxContext int `json:"maxContext"`
+}
+
+func (e *modelManager) Capabilities(ctx context.Context, modelName string) (Capabilities, bool) {
+	return capabilitiesOf(e.runtime.Get(ctx), modelName)
+}
+
+// capabilitiesOf folds the pulled-model entries that match modelName — exactly
+// by model or display name, else after normalization, like request model
+// names — into one Capabilities. ok is false when no backend serves the model.
+func capabilitiesOf(state map[string]statetype.BackendRuntimeState, modelName string) (Capabilities, bool) {
+	if modelName == "" {
+		return Capabilities{}, false
+	}
+	normalized := llmresolver.NormalizeModelName(modelName)
+	caps := Capabilities{SupportsTools: true}
+	found := false
+	for _, backend := range state {
+		for _, m := range backend.PulledModels {
+			if m.Model != modelName && m.Name != modelName &&
+				llmresolver.NormalizeModelName(m.Model) != normalized {
+				continue
+			}
+			found = true
+			if m.ToolsUnsupported {
+				caps.SupportsTools = false
+			}
+			if m.CanStream {
+				caps.SupportsStreaming = true
+			}
+			caps.MaxContext = max(caps.MaxContext, m.ContextLength)
+		}
+	}
+	if !found {
+		return Capabilities{}, false
+	}
+	return caps, true
+}
+
+// dropUnsupportedTools removes the tool definitions from opts when the
+// resolved model, on the backend resolution picked, is marked as unable to
+// take them, and reports the drop on tracker. Callers check Capabilities
+// before resolution, but a request naming several models, or a model served
+// by several backends, only lands on one of them; this is the check for it.
+func (e *modelManager) dropUnsupportedTools(ctx context.Context, tracker libtracker.ActivityTracker, meta Meta, opts []libmodelprovider.ChatArgument) []libmodelprovider.ChatArgument {
+	if len(opts) == 0 {
+		return opts
+	}
+	args, dropped := withoutUnsupportedTools(e.runtime.Get(ctx), meta, opts)
+	if dropped == 0 {
+		return opts
+	}
+	if tracker == nil {
+		tracker = libtracker.NoopTracker{}
+	}
+	_, reportChange, end := tracker.Start(ctx, "drop", "llm_tools",
+		"model_name", meta.ModelName,
+		"provider_type", meta.ProviderType,
+		"backend_id", meta.BackendID,
+	)
+	defer end()
+	reportChange(meta.ModelName, map[string]any{"dropped_tools": dropped})
+	return args
+}
+
+// withoutUnsupportedTools folds opts into one ChatConfig without its tools
+// when meta's backend in state marks meta's model as unable to take them.
+// dropped is the number of tools removed; 0 leaves opts as they are.
+func withoutUnsupportedTools(state map[string]statetype.BackendRuntimeState, meta Meta, opts []libmodelprovider.ChatArgument) ([]libmodelprovider.ChatArgument, int) {
+	var cfg libmodelprovider.ChatConfig
+	for _, opt := range opts {
+		opt.Apply(&cfg)
+	}
+	if len(cfg.Tools) == 0 {
+		return opts, 0
+	}
+	backend, ok := state[meta.BackendID]
+	if !ok {
+		return opts, 0
+	}
+	caps, ok := capabilitiesOf(map[string]statetype.BackendRuntimeState{meta.BackendID: backend}, meta.ModelName)
+	if !ok || caps.SupportsTools {
+		return opts, 0
+	}
+	dropped := len(cfg.Tools)
+	cfg.Tools = nil
+	return []libmodelprovider.ChatArgument{chatConfigArgument{cfg: cfg}}, dropped
+}
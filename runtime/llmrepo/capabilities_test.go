@@ -0,0 +1,62 @@
+package llmrepo
+
+import (
+	"testing"
+
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/contenox/runtime/runtime/statetype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_CapabilitiesOf_FoldsEveryServingBackend(t *testing.T) {
+	state := map[string]statetype.BackendRuntimeState{
+		"a": {PulledModels: []statetype.ModelPullStatus{
+			{Model: "gemma3:1b", ContextLength: 8192, CanStream: true},
+			{Model: "qwen3:4b", ContextLength: 32768, CanStream: true},
+		}},
+		"b": {PulledModels: []statetype.ModelPullStatus{
+			{Model: "gemma3:1b", ContextLength: 32768, ToolsUnsupported: true},
+		}},
+	}
+
+	caps, ok := capabilitiesOf(state, "gemma3:1b")
+	require.True(t, ok)
+	require.False(t, caps.SupportsTools, "one backend without tool support makes the model unsafe for tools")
+	require.True(t, caps.SupportsStreaming)
+	require.Equal(t, 32768, caps.MaxContext)
+
+	caps, ok = capabilitiesOf(state, "Qwen3:4B")
+	require.True(t, ok, "model names match after normalization")
+	require.True(t, caps.SupportsTools)
+
+	_, ok = capabilitiesOf(state, "missing")
+	require.False(t, ok)
+	_, ok = capabilitiesOf(state, "")
+	require.False(t, ok)
+}
+
+func TestUnit_WithoutUnsupportedTools_ChecksTheResolvedBackend(t *testing.T) {
+	state := map[string]statetype.BackendRuntimeState{
+		"a": {ID: "a", PulledModels: []statetype.ModelPullStatus{{Model: "gemma3:1b"}}},
+		"b": {ID: "b", PulledModels: []statetype.ModelPullStatus{{Model: "gemma3:1b", ToolsUnsupported: true}}},
+	}
+	temperature := 0.2
+	opts := []libmodelprovider.ChatArgument{
+		libmodelprovider.WithTemperature(temperature),
+		libmodelprovider.WithTools(libmodelprovider.Tool{Type: "function", Function: &libmodelprovider.FunctionTool{Name: "read_file"}}),
+	}
+
+	args, dropped := withoutUnsupportedTools(state, Meta{ModelName: "gemma3:1b", BackendID: "a"}, opts)
+	require.Zero(t, dropped, "the backend resolution picked takes tools")
+	require.Equal(t, opts, args)
+
+	args, dropped = withoutUnsupportedTools(state, Meta{ModelName: "gemma3:1b", BackendID: "b"}, opts)
+	require.Equal(t, 1, dropped)
+	var cfg libmodelprovider.ChatConfig
+	for _, arg := range args {
+		arg.Apply(&cfg)
+	}
+	require.Empty(t, cfg.Tools)
+	require.NotNil(t, cfg.Temperature)
+	require.Equal(t, temperature, *cfg.Temperature, "other arguments are kept")
+}
@@ -0,0 +1,198 @@
+package llmrepo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+)
+
+// ContextProbeRetryInterval is how long a failed probe is remembered before
+// the backend is asked again. Successful probes are kept for the life of the
+// ContextProbe: a model name's context window does not change under it.
+const ContextProbeRetryInterval = 10 * time.Minute
+
+// openAICompatibleBaseURLs are the endpoints used for OpenAI-style backends
+// registered without a base URL, matching their catalog providers.
+var openAICompatibleBaseURLs = map[string]string{
+	"openai":     "https://api.openai.com/v1",
+	"openrouter": "https://openrouter.ai/api/v1",
+	"mistral":    "https://api.mistral.ai/v1",
+}
+
+// ContextProbe asks a backend for a model's context window so it does not
+// have to be configured by hand. Ollama is asked through /api/show; vLLM and
+// the OpenAI-compatible providers through their model list, whose entries
+// carry the window under one of a few provider-specific keys. Results are
+// cached per backend and model. ContextLength has the signature
+// runtimestate.WithContextProbe expects.
+type ContextProbe struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]contextProbeEntry
+}
+
+type contextProbeEntry struct {
+	length int
+	err    error
+	at     time.Time
+}
+
+// NewContextProbe returns a ContextProbe using client; nil means
+// http.DefaultClient.
+func NewContextProbe(client *http.Client) *ContextProbe {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &ContextProbe{client: client, cache: map[string]contextProbeEntry{}}
+}
+
+// ContextLength returns the context window backend reports for model. apiKey
+// is the resolved provider credential, empty when the backend needs none. It
+// fails when the backend type cannot be probed, the backend is unreachable,
+// or its metadata does not include the window; callers then fall back to the
+// configured value.
+func (p *ContextProbe) ContextLength(ctx context.Context, backend *runtimetypes.Backend, apiKey, model string) (int, error) {
+	if backend == nil || strings.TrimSpace(model) == "" {
+		return 0, fmt.Errorf("context probe: backend and model are required")
+	}
+	key := backend.ID + "\x00" + model
+	p.mu.Lock()
+	entry, ok := p.cache[key]
+	p.mu.Unlock()
+	if ok && (entry.err == nil || time.Since(entry.at) < ContextProbeRetryInterval) {
+		return entry.length, entry.err
+	}
+
+	length, err := p.probe(ctx, backend, apiKey, model)
+	if err == nil && length <= 0 {
+		err = fmt.Errorf("context probe: %s backend %q reported no context window for %q", backend.Type, backend.Name, model)
+	}
+	if err != nil {
+		length = 0
+	}
+	if ctx.Err() == nil {
+		p.mu.Lock()
+		p.cache[key] = contextProbeEntry{length: length, err: err, at: time.Now()}
+		p.mu.Unlock()
+	}
+	return length, err
+}
+
+func (p *ContextProbe) probe(ctx context.Context, backend *runtimetypes.Backend, apiKey, model string) (int, error) {
+	base := strings.TrimRight(strings.TrimSpace(backend.BaseURL), "/")
+	backendType := modelrepo.CanonicalBackendType(backend.Type)
+	switch backendType {
+	case "ollama":
+		return p.probeOllama(ctx, base, apiKey, model)
+	case "vllm":
+		return p.probeModelList(ctx, base+"/v1/models", apiKey, model)
+	case "openai", "openrouter", "mistral":
+		if base == "" {
+			base = openAICompatibleBaseURLs[backendType]
+		}
+		return p.probeModelList(ctx, base+"/models", apiKey, model)
+	default:
+		return 0, fmt.Errorf("context probe: backend type %q does not report context windows", backend.Type)
+	}
+}
+
+// probeOllama reads the "<arch>.context_length" entry of /api/show's
+// model_info.
+func (p *ContextProbe) probeOllama(ctx context.Context, base, apiKey, model string) (int, error) {
+	payload, err := json.Marshal(map[string]string{"model": model})
+	if err != nil {
+		return 0, err
+	}
+	var show struct {
+		ModelInfo map[string]any `json:"model_info"`
+	}
+	if err := p.fetch(ctx, http.MethodPost, base+"/api/show", apiKey, payload, &show); err != nil {
+		return 0, err
+	}
+	for key, value := range show.ModelInfo {
+		if strings.HasSuffix(key, ".context_length") {
+			if n, ok := value.(float64); ok {
+				return int(n), nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// probeModelList finds model in an OpenAI-style /models listing and reads
+// its context window.
+func (p *ContextProbe) probeModelList(ctx context.Context, url, apiKey, model string) (int, error) {
+	var list struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := p.fetch(ctx, http.MethodGet, url, apiKey, nil, &list); err != nil {
+		return 0, err
+	}
+	for _, entry := range list.Data {
+		if id, _ := entry["id"].(string); id == model {
+			return contextLengthFromMetadata(entry), nil
+		}
+	}
+	return 0, fmt.Errorf("context probe: model %q is not listed at %s", model, url)
+}
+
+// contextLengthFromMetadata reads the context window from one model entry.
+// Providers disagree on the key: OpenRouter and LM Studio use context_length,
+// vLLM max_model_len, Mistral max_context_length, and some proxies
+// context_window. OpenRouter also nests it under top_provider.
+func contextLengthFromMetadata(entry map[string]any) int {
+	for _, key := range []string{"context_length", "max_model_len", "max_context_length", "context_window"} {
+		if n, ok := entry[key].(float64); ok && n > 0 {
+			return int(n)
+		}
+	}
+	if top, ok := entry["top_provider"].(map[string]any); ok {
+		if n, ok := top["context_length"].(float64); ok && n > 0 {
+			return int(n)
+		}
+	}
+	return 0
+}
+
+func (p *ContextProbe) fetch(ctx context.Context, method, url, apiKey string, payload []byte, out any) error {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("context probe: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("context probe: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return fmt.Errorf("context probe: read %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("context probe: %s returned %d: %s", url, resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("context probe: decode %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package llmrepo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ContextProbe_ReadsOllamaShow(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		require.Equal(t, "/api/show", r.URL.Path)
+		var req map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "qwen3:4b", req["model"])
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"model_info": map[string]any{"general.architecture": "qwen3", "qwen3.context_length": 40960},
+		})
+	}))
+	defer server.Close()
+
+	probe := NewContextProbe(nil)
+	backend := &runtimetypes.Backend{ID: "b1", Name: "ollama", Type: "ollama", BaseURL: server.URL}
+	for range 2 {
+		n, err := probe.ContextLength(context.Background(), backend, "", "qwen3:4b")
+		require.NoError(t, err)
+		require.Equal(t, 40960, n)
+	}
+	require.Equal(t, 1, calls, "a detected window is cached")
+}
+
+func TestUnit_ContextProbe_ReadsOpenAICompatibleModelList(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		require.Equal(t, "/models", r.URL.Path)
+		require.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+			{"id": "meta-llama/llama-3.3-70b", "context_length": 131072},
+			{"id": "mistral-large", "max_context_length": 128000},
+			{"id": "gpt-5"},
+		}})
+	}))
+	defer server.Close()
+
+	probe := NewContextProbe(server.Client())
+	backend := &runtimetypes.Backend{ID: "b2", Name: "router", Type: "openrouter", BaseURL: server.URL}
+
+	n, err := probe.ContextLength(context.Background(), backend, "sk-test", "meta-llama/llama-3.3-70b")
+	require.NoError(t, err)
+	require.Equal(t, 131072, n)
+	n, err = probe.ContextLength(context.Background(), backend, "sk-test", "mistral-large")
+	require.NoError(t, err)
+	require.Equal(t, 128000, n)
+
+	_, err = probe.ContextLength(context.Background(), backend, "sk-test", "gpt-5")
+	require.Error(t, err, "metadata without a context window is a failed probe")
+	_, err = probe.ContextLength(context.Background(), backend, "sk-test", "gpt-5")
+	require.Error(t, err)
+	require.Equal(t, 3, calls, "failures are remembered until the retry interval passes")
+}
+
+func TestUnit_ContextProbe_RejectsUnsupportedBackends(t *testing.T) {
+	_, err := NewContextProbe(nil).ContextLength(context.Background(),
+		&runtimetypes.Backend{ID: "b3", Type: "gemini"}, "", "gemini-2.5-pro")
+	require.Error(t, err)
+}
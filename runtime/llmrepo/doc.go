@@ -5,5 +5,8 @@
 // External consumers can implement ModelRepo themselves or construct the
 // default implementation with NewModelManager, which selects a concrete
 // backend per call using model/provider hints and runtimestate's view of
-// the available backends.
+// the available backends. PromptExecute and Chat fail over to the next
+// matching candidate when a call fails with a retryable error. Sampling
+// parameters are clamped into the range of the provider a call resolved to
+// (see ParamLimits) before it is dispatched.
 package llmrepo
@@ -0,0 +1,103 @@
+package llmrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/internal/llmresolver"
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/contenox/runtime/runtime/taskengine/llmretry"
+)
+
+// failover runs call against a candidate picked by policy and, when the call
+// fails with a retryable error (timeout, 5xx, rate limit, refused connection —
+// see llmretry.ClassifyError), resolves again among the candidates that have
+// not failed yet and retries there. Non-retryable errors (auth, invalid
+// request, context overflow, cancellation) fail fast on the first candidate,
+// since another model would reject the same request the same way.
+//
+// Only the first resolution goes through reconcile: once a candidate has been
+// tried, a failed resolution means the remaining candidates are exhausted, not
+// that runtime state is stale, and the last call error is returned so the
+// caller sees why the models failed rather than "no satisfactory model".
+//
+// Every attempt is recorded on tracker with the model, provider and backend it
+// went to, and the error class when it failed.
+func failover[C, R any](
+	ctx context.Context,
+	tracker libtracker.ActivityTracker,
+	policy llmresolver.Policy,
+	reconcile func(ctx context.Context, resolveErr error) bool,
+	resolve func(policy llmresolver.Policy) (C, libmodelprovider.Provider, string, error),
+	call func(client C) (R, error),
+) (R, Meta, error) {
+	var zero R
+	if tracker == nil {
+		tracker = libtracker.NoopTracker{}
+	}
+	failed := map[string]bool{}
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		pick := skipFailed(policy, failed)
+		client, provider, backend, err := resolve(pick)
+		if err != nil && lastErr == nil && reconcile(ctx, err) {
+			client, provider, backend, err = resolve(pick)
+		}
+		if err != nil {
+			if lastErr != nil {
+				return zero, Meta{}, lastErr
+			}
+			return zero, Meta{}, err
+		}
+		meta := Meta{
+			ModelName:    provider.ModelName(),
+			ProviderType: provider.GetType(),
+			BackendID:    backend,
+		}
+
+		reportErr, reportChange, end := tracker.Start(ctx, "attempt", "llm_model",
+			"attempt", attempt,
+			"model_name", meta.ModelName,
+			"provider_type", meta.ProviderType,
+			"backend_id", meta.BackendID,
+		)
+		result, err := call(client)
+		safeClose(client)
+		if err == nil {
+			reportChange(provider.GetID(), meta)
+			end()
+			return result, meta, nil
+		}
+		class := llmretry.ClassifyError(err)
+		reportErr(fmt.Errorf("%s: %w", class, err))
+		end()
+
+		if !class.IsRetryable() || ctx.Err() != nil {
+			return zero, Meta{}, err
+		}
+		failed[provider.GetID()] = true
+		lastErr = err
+	}
+}
+
+// skipFailed narrows the candidates handed to policy to the providers that
+// have not failed in this request. With none left it reports
+// ErrNoSatisfactoryModel, which ends the failover loop.
+func skipFailed(policy llmresolver.Policy, failed map[string]bool) llmresolver.Policy {
+	if len(failed) == 0 {
+		return policy
+	}
+	return func(candidates []libmodelprovider.Provider) (libmodelprovider.Provider, string, error) {
+		remaining := make([]libmodelprovider.Provider, 0, len(candidates))
+		for _, p := range candidates {
+			if p != nil && !failed[p.GetID()] {
+				remaining = append(remaining, p)
+			}
+		}
+		if len(remaining) == 0 {
+			return nil, "", llmresolver.ErrNoSatisfactoryModel
+		}
+		return policy(remaining)
+	}
+}
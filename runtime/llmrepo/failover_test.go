@@ -0,0 +1,95 @@
+package llmrepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/internal/llmresolver"
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTracker keeps the errors reported per attempt so tests can assert
+// that each failover step was recorded.
+type recordingTracker struct {
+	attempts int
+	errs     []error
+}
+
+func (r *recordingTracker) Start(ctx context.Context, operation, subject string, kvArgs ...any) (func(error), func(string, any), func()) {
+	r.attempts++
+	return func(err error) { r.errs = append(r.errs, err) }, func(string, any) {}, func() {}
+}
+
+func failoverCandidates() []libmodelprovider.Provider {
+	return []libmodelprovider.Provider{
+		&libmodelprovider.MockProvider{ID: "p1", Name: "primary", Backends: []string{"b1"}},
+		&libmodelprovider.MockProvider{ID: "p2", Name: "secondary", Backends: []string{"b2"}},
+	}
+}
+
+// runFailover drives failover with a first-candidate policy; the client is the
+// provider ID and callErrs scripts each provider's failure.
+func runFailover(t *testing.T, tracker *recordingTracker, callErrs map[string]error) (string, Meta, []string, int, error) {
+	t.Helper()
+	first := func(c []libmodelprovider.Provider) (libmodelprovider.Provider, string, error) {
+		if len(c) == 0 {
+			return nil, "", llmresolver.ErrNoSatisfactoryModel
+		}
+		return c[0], c[0].GetBackendIDs()[0], nil
+	}
+	reconciles := 0
+	var called []string
+	result, meta, err := failover(context.Background(), tracker, first,
+		func(context.Context, error) bool { reconciles++; return false },
+		func(policy llmresolver.Policy) (string, libmodelprovider.Provider, string, error) {
+			p, backend, err := policy(failoverCandidates())
+			if err != nil {
+				return "", nil, "", err
+			}
+			return p.GetID(), p, backend, nil
+		},
+		func(client string) (string, error) {
+			called = append(called, client)
+			if err := callErrs[client]; err != nil {
+				return "", err
+			}
+			return "ok from " + client, nil
+		},
+	)
+	return result, meta, called, reconciles, err
+}
+
+func TestUnit_Failover_RetryableErrorAdvancesToNextCandidate(t *testing.T) {
+	tracker := &recordingTracker{}
+	result, meta, called, _, err := runFailover(t, tracker, map[string]error{
+		"p1": errors.New("OpenAI API returned non-200 status: 503 service unavailable"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ok from p2", result)
+	require.Equal(t, []string{"p1", "p2"}, called)
+	require.Equal(t, Meta{ModelName: "secondary", ProviderType: "mock", BackendID: "b2"}, meta)
+
+	require.Equal(t, 2, tracker.attempts)
+	require.Len(t, tracker.errs, 1)
+	require.ErrorContains(t, tracker.errs[0], "server_error")
+}
+
+func TestUnit_Failover_NonRetryableErrorFailsFast(t *testing.T) {
+	authErr := errors.New("status: 401 unauthorized")
+	_, _, called, _, err := runFailover(t, &recordingTracker{}, map[string]error{"p1": authErr})
+	require.ErrorIs(t, err, authErr)
+	require.Equal(t, []string{"p1"}, called)
+}
+
+func TestUnit_Failover_ExhaustedCandidatesReturnLastCallError(t *testing.T) {
+	lastErr := errors.New("dial tcp 10.0.0.2:11434: connect: connection refused")
+	_, _, called, reconciles, err := runFailover(t, &recordingTracker{}, map[string]error{
+		"p1": errors.New("i/o timeout"),
+		"p2": lastErr,
+	})
+	require.ErrorIs(t, err, lastErr)
+	require.Equal(t, []string{"p1", "p2"}, called)
+	require.Zero(t, reconciles, "running out of candidates is not stale runtime state")
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +18,10 @@ import (
 
 var _ ModelRepo = (*modelManager)(nil)
 
+// ErrBatchEmbedUnsupported is returned by EmbedBatch when the backend serving
+// the model has no call that embeds several inputs at once.
+var ErrBatchEmbedUnsupported = errors.New("backend does not embed batches")
+
 // Unified Request type for all operations
 type Request struct {
 	ProviderTypes []string // Optional: if empty, uses all default providers
@@ -39,6 +45,12 @@ type Meta struct {
 type ModelRepo interface {
 	Tokenize(ctx context.Context, modelName string, prompt string) ([]int, error)
 	CountTokens(ctx context.Context, modelName string, prompt string) (int, error)
+	// CountTokensBatch counts each text with the tokenizer for modelName in a
+	// single tokenizer call and returns the counts in text order.
+	CountTokensBatch(ctx context.Context, modelName string, texts []string) ([]int, error)
+	// Capabilities reports what modelName supports across the backends that
+	// serve it; ok is false when no backend currently serves the model.
+	Capabilities(ctx context.Context, modelName string) (caps Capabilities, ok bool)
 	PromptExecute(
 		ctx context.Context,
 		req Request,
@@ -54,6 +66,14 @@ type ModelRepo interface {
 		embedReq EmbedRequest,
 		prompt string,
 	) ([]float64, Meta, error)
+	// EmbedBatch embeds prompts in a single provider call and returns the
+	// vectors in prompt order. It fails with ErrBatchEmbedUnsupported when
+	// the resolved backend cannot embed several inputs at once.
+	EmbedBatch(
+		ctx context.Context,
+		embedReq EmbedRequest,
+		prompts []string,
+	) ([][]float64, Meta, error)
 	Stream(
 		ctx context.Context,
 		req Request,
@@ -65,6 +85,7 @@ type ModelRepo interface {
 type Tokenizer interface {
 	Tokenize(ctx context.Context, prompt string) ([]int, error)
 	CountTokens(ctx context.Context, prompt string) (int, error)
+	CountTokensBatch(ctx context.Context, prompts []string) ([]int, error)
 }
 
 var _ ModelRepo = (*modelManager)(nil)
@@ -120,6 +141,74 @@ type ModelManagerConfig struct {
 	DefaultPromptModel    ModelConfig
 	DefaultEmbeddingModel ModelConfig
 	DefaultChatModel      ModelConfig
+	// Selection picks the provider and backend among the candidates that
+	// match a request (every listed model that is available and capable).
+	// nil keeps the default, a uniform random pick. The picked model and
+	// backend are reported in the returned Meta.
+	Selection SelectionPolicy
+	// ParamLimits overrides DefaultParamLimits per provider type, e.g. to
+	// set a DefaultTemperature for one provider or to cover a provider type
+	// without built-in limits.
+	ParamLimits map[string]ParamLimits
+}
+
+// SelectionPolicy picks one provider and backend from the candidates a request
+// resolved to, in the request's model-name preference order.
+type SelectionPolicy = llmresolver.Policy
+
+// RandomSelection is the default policy: a uniform random candidate, then a
+// uniform random backend serving it.
+func RandomSelection() SelectionPolicy { return llmresolver.Randomly }
+
+// RoundRobinSelection cycles through every backend of every candidate model in
+// turn. The returned policy is stateful; configure one per model manager.
+func RoundRobinSelection() SelectionPolicy { return llmresolver.RoundRobin() }
+
+// LeastRecentlyUsedSelection picks the candidate backend this policy handed out
+// longest ago, preferring never-used ones in model preference order. The
+// returned policy is stateful; configure one per model manager.
+func LeastRecentlyUsedSelection() SelectionPolicy { return llmresolver.LeastRecentlyUsed() }
+
+// WeightedSelection picks a candidate model at random in proportion to its
+// configured capacity, keyed by model name (unlisted models weigh 1, a weight
+// of 0 or less takes a model out of rotation), then a random backend.
+func WeightedSelection(weights map[string]int) SelectionPolicy {
+	return llmresolver.Weighted(weights)
+}
+
+// ParseSelectionPolicy parses a selection setting: "random" (or empty, which
+// returns nil for the default), "round-robin", "least-recently-used", or
+// "weighted:" followed by comma-separated model=weight pairs, e.g.
+// "weighted:qwen2.5:7b=3,llama3.1:8b=1". A stateful policy is returned fresh
+// on every call.
+func ParseSelectionPolicy(setting string) (SelectionPolicy, error) {
+	setting = strings.TrimSpace(setting)
+	switch setting {
+	case "", "random":
+		return nil, nil
+	case "round-robin":
+		return RoundRobinSelection(), nil
+	case "least-recently-used":
+		return LeastRecentlyUsedSelection(), nil
+	}
+	pairs, ok := strings.CutPrefix(setting, "weighted:")
+	if !ok {
+		return nil, fmt.Errorf("unknown selection policy %q: want random, round-robin, least-recently-used or weighted:model=weight,...", setting)
+	}
+	weights := map[string]int{}
+	for _, pair := range strings.Split(pairs, ",") {
+		pair = strings.TrimSpace(pair)
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("selection weight %q: want model=weight", pair)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(pair[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("selection weight %q: weight must be an integer", pair)
+		}
+		weights[strings.TrimSpace(pair[:i])] = weight
+	}
+	return WeightedSelection(weights), nil
 }
 
 func NewModelManager(runtime *runtimestate.State, tokenizer ollamatokenizer.Tokenizer, config ModelManagerConfig, tracker libtracker.ActivityTracker) (*modelManager, error) {
@@ -176,6 +265,40 @@ func (e *modelManager) CountTokens(ctx context.Context, modelName string, prompt
 	return count, nil
 }
 
+func (e *modelManager) CountTokensBatch(ctx context.Context, modelName string, texts []string) ([]int, error) {
+	counts := make([]int, len(texts))
+	// Empty texts count as 0 without reaching the tokenizer, as in CountTokens.
+	var prompts []string
+	var at []int
+	for i, text := range texts {
+		if text != "" {
+			prompts = append(prompts, text)
+			at = append(at, i)
+		}
+	}
+	if len(prompts) == 0 {
+		return counts, nil
+	}
+
+	tokenizer, err := e.GetTokenizer(ctx, modelName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tokenizer: %w", err)
+	}
+
+	batch, err := tokenizer.CountTokensBatch(ctx, prompts)
+	if err != nil {
+		return nil, fmt.Errorf("token counting failed: %w", err)
+	}
+	if len(batch) != len(prompts) {
+		return nil, fmt.Errorf("token counting failed: got %d counts for %d texts", len(batch), len(prompts))
+	}
+	for j, n := range batch {
+		counts[at[j]] = n
+	}
+
+	return counts, nil
+}
+
 func (e *modelManager) PromptExecute(
 	ctx context.Context,
 	req Request,
@@ -185,8 +308,6 @@ func (e *modelManager) PromptExecute(
 		return "", Meta{}, fmt.Errorf("invalid request: %w", err)
 	}
 
-	runtimeStateResolution := e.GetRuntime(ctx)
-
 	// Apply defaults if not provided
 	if len(req.ModelNames) == 0 {
 		req.ModelNames = []string{e.config.DefaultPromptModel.Name}
@@ -196,34 +317,26 @@ func (e *modelManager) PromptExecute(
 	}
 
 	resolverReq := e.convertToResolverRequest(req, nil)
-	client, provider, backend, err := llmresolver.PromptExecute(ctx,
-		resolverReq,
-		runtimeStateResolution,
-		llmresolver.Randomly,
+	tracker := e.requestTracker(req)
+	var target Meta
+	return failover(ctx, tracker, e.selection(), e.reconcileForResolution,
+		func(policy llmresolver.Policy) (libmodelprovider.LLMPromptExecClient, libmodelprovider.Provider, string, error) {
+			client, provider, backend, err := llmresolver.PromptExecute(ctx, resolverReq, e.GetRuntime(ctx), policy)
+			if err != nil {
+				return nil, nil, "", fmt.Errorf("prompt execute: client resolution failed: %w", err)
+			}
+			target = Meta{ModelName: provider.ModelName(), ProviderType: provider.GetType(), BackendID: backend}
+			return client, provider, backend, nil
+		},
+		func(client libmodelprovider.LLMPromptExecClient) (string, error) {
+			temperature := e.clampTemperature(ctx, tracker, target, temperature)
+			result, err := client.Prompt(ctx, systemInstruction, temperature, prompt)
+			if err != nil {
+				return "", fmt.Errorf("prompt execution failed: %w", err)
+			}
+			return result, nil
+		},
 	)
-	if err != nil && e.reconcileForResolution(ctx, err) {
-		client, provider, backend, err = llmresolver.PromptExecute(ctx,
-			resolverReq,
-			e.GetRuntime(ctx),
-			llmresolver.Randomly,
-		)
-	}
-	if err != nil {
-		return "", Meta{}, fmt.Errorf("prompt execute: client resolution failed: %w", err)
-	}
-	defer safeClose(client)
-
-	result, err := client.Prompt(ctx, systemInstruction, temperature, prompt)
-	if err != nil {
-		return "", Meta{}, fmt.Errorf("prompt execution failed: %w", err)
-	}
-
-	meta := Meta{
-		ModelName:    provider.ModelName(),
-		ProviderType: provider.GetType(),
-		BackendID:    backend,
-	}
-	return result, meta, nil
 }
 
 func (e *modelManager) Chat(
@@ -239,8 +352,6 @@ func (e *modelManager) Chat(
 		return libmodelprovider.ChatResult{}, Meta{}, errors.New("messages cannot be empty")
 	}
 
-	runtimeStateResolution := e.GetRuntime(ctx)
-
 	// Apply defaults if not provided
 	if len(req.ModelNames) == 0 {
 		req.ModelNames = []string{e.config.DefaultChatModel.Name}
@@ -250,45 +361,82 @@ func (e *modelManager) Chat(
 	}
 
 	resolverReq := e.convertToResolverRequest(req, messages)
-	client, provider, backend, err := llmresolver.Chat(ctx,
-		resolverReq,
-		runtimeStateResolution,
-		llmresolver.Randomly,
+	tracker := e.requestTracker(req)
+	var target Meta
+	return failover(ctx, tracker, e.selection(), e.reconcileForResolution,
+		func(policy llmresolver.Policy) (libmodelprovider.LLMChatClient, libmodelprovider.Provider, string, error) {
+			client, provider, backend, err := llmresolver.Chat(ctx, resolverReq, e.GetRuntime(ctx), policy)
+			if err != nil {
+				return nil, nil, "", fmt.Errorf("chat: client resolution failed: %w", err)
+			}
+			target = Meta{ModelName: provider.ModelName(), ProviderType: provider.GetType(), BackendID: backend}
+			return client, provider, backend, nil
+		},
+		func(client libmodelprovider.LLMChatClient) (libmodelprovider.ChatResult, error) {
+			args := e.dropUnsupportedTools(ctx, tracker, target, opts)
+			response, err := client.Chat(ctx, messages, e.clampArgs(ctx, tracker, target, args)...)
+			if err != nil {
+				return libmodelprovider.ChatResult{}, fmt.Errorf("chat execution failed: %w", err)
+			}
+			return response, nil
+		},
 	)
-	if err != nil && e.reconcileForResolution(ctx, err) {
-		client, provider, backend, err = llmresolver.Chat(ctx,
-			resolverReq,
-			e.GetRuntime(ctx),
-			llmresolver.Randomly,
-		)
+}
+
+func (e *modelManager) Embed(
+	ctx context.Context,
+	embedReq EmbedRequest,
+	prompt string,
+) ([]float64, Meta, error) {
+	if prompt == "" {
+		return nil, Meta{}, errors.New("prompt cannot be empty")
 	}
+
+	client, meta, err := e.resolveEmbed(ctx, embedReq)
 	if err != nil {
-		return libmodelprovider.ChatResult{}, Meta{}, fmt.Errorf("chat: client resolution failed: %w", err)
+		return nil, Meta{}, err
 	}
 	defer safeClose(client)
 
-	response, err := client.Chat(ctx, messages, opts...)
+	embeddings, err := client.Embed(ctx, prompt)
 	if err != nil {
-		return libmodelprovider.ChatResult{}, Meta{}, fmt.Errorf("chat execution failed: %w", err)
-	}
-
-	meta := Meta{
-		ModelName:    provider.ModelName(),
-		ProviderType: provider.GetType(),
-		BackendID:    backend,
+		return nil, Meta{}, fmt.Errorf("embedding generation failed: %w", err)
 	}
-	return response, meta, nil
+	return embeddings, meta, nil
 }
 
-func (e *modelManager) Embed(
+func (e *modelManager) EmbedBatch(
 	ctx context.Context,
 	embedReq EmbedRequest,
-	prompt string,
-) ([]float64, Meta, error) {
-	if prompt == "" {
-		return nil, Meta{}, errors.New("prompt cannot be empty")
+	prompts []string,
+) ([][]float64, Meta, error) {
+	if len(prompts) == 0 {
+		return nil, Meta{}, errors.New("prompts cannot be empty")
+	}
+
+	client, meta, err := e.resolveEmbed(ctx, embedReq)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	defer safeClose(client)
+
+	batcher, ok := client.(libmodelprovider.LLMBatchEmbedClient)
+	if !ok {
+		return nil, Meta{}, fmt.Errorf("%w: %s (%s)", ErrBatchEmbedUnsupported, meta.ModelName, meta.ProviderType)
 	}
+	embeddings, err := batcher.EmbedBatch(ctx, prompts)
+	if err != nil {
+		return nil, Meta{}, fmt.Errorf("embedding generation failed: %w", err)
+	}
+	if len(embeddings) != len(prompts) {
+		return nil, Meta{}, fmt.Errorf("embedding generation failed: %d vectors for %d inputs", len(embeddings), len(prompts))
+	}
+	return embeddings, meta, nil
+}
 
+// resolveEmbed picks the backend that embeds with embedReq's model, or the
+// default embedding model, and opens an embed client on it.
+func (e *modelManager) resolveEmbed(ctx context.Context, embedReq EmbedRequest) (libmodelprovider.LLMEmbedClient, Meta, error) {
 	runtimeStateResolution := e.GetRuntime(ctx)
 
 	// Apply defaults if not provided
@@ -303,31 +451,25 @@ func (e *modelManager) Embed(
 	client, provider, backend, err := llmresolver.Embed(ctx,
 		resolverReq,
 		runtimeStateResolution,
-		llmresolver.Randomly,
+		e.selection(),
 	)
 	if err != nil && e.reconcileForResolution(ctx, err) {
 		client, provider, backend, err = llmresolver.Embed(ctx,
 			resolverReq,
 			e.GetRuntime(ctx),
-			llmresolver.Randomly,
+			e.selection(),
 		)
 	}
 	if err != nil {
 		return nil, Meta{}, fmt.Errorf("embed: client resolution failed: %w", err)
 	}
-	defer safeClose(client)
-
-	embeddings, err := client.Embed(ctx, prompt)
-	if err != nil {
-		return nil, Meta{}, fmt.Errorf("embedding generation failed: %w", err)
-	}
 
 	meta := Meta{
 		ModelName:    provider.ModelName(),
 		ProviderType: provider.GetType(),
 		BackendID:    backend,
 	}
-	return embeddings, meta, nil
+	return client, meta, nil
 }
 
 func (e *modelManager) Stream(
@@ -358,20 +500,27 @@ func (e *modelManager) Stream(
 	client, provider, backend, err := llmresolver.Stream(ctx,
 		resolverReq,
 		runtimeStateResolution,
-		llmresolver.Randomly,
+		e.selection(),
 	)
 	if err != nil && e.reconcileForResolution(ctx, err) {
 		client, provider, backend, err = llmresolver.Stream(ctx,
 			resolverReq,
 			e.GetRuntime(ctx),
-			llmresolver.Randomly,
+			e.selection(),
 		)
 	}
 	if err != nil {
 		return nil, Meta{}, fmt.Errorf("stream: client resolution failed: %w", err)
 	}
 
-	stream, err := client.Stream(ctx, messages, opts...)
+	meta := Meta{
+		ModelName:    provider.ModelName(),
+		ProviderType: provider.GetType(),
+		BackendID:    backend,
+	}
+	tracker := e.requestTracker(req)
+	args := e.dropUnsupportedTools(ctx, tracker, meta, opts)
+	stream, err := client.Stream(ctx, messages, e.clampArgs(ctx, tracker, meta, args)...)
 	if err != nil {
 		safeClose(client)
 		return nil, Meta{}, fmt.Errorf("stream initialization failed: %w", err)
@@ -391,17 +540,28 @@ func (e *modelManager) Stream(
 		}
 	}()
 
-	meta := Meta{
-		ModelName:    provider.ModelName(),
-		ProviderType: provider.GetType(),
-		BackendID:    backend,
-	}
 	return wrappedStream, meta, nil
 }
 
+// requestTracker returns the tracker failover attempts are recorded on: the
+// request's own when set, else the manager's.
+func (e *modelManager) requestTracker(req Request) libtracker.ActivityTracker {
+	if req.Tracker != nil {
+		return req.Tracker
+	}
+	return e.tracker
+}
+
+// selection returns the configured selection policy, defaulting to random.
+func (e *modelManager) selection() llmresolver.Policy {
+	if e.config.Selection != nil {
+		return e.config.Selection
+	}
+	return llmresolver.Randomly
+}
+
 func (e *modelManager) GetRuntime(ctx context.Context) runtimestate.ProviderFromRuntimeState {
-	state := e.runtime.Get(ctx)
-	return runtimestate.LocalProviderAdapter(ctx, e.tracker, state)
+	return e.runtime.ProviderAdapter(ctx, e.tracker)
 }
 
 func (e *modelManager) GetTokenizer(ctx context.Context, modelName string) (Tokenizer, error) {
@@ -476,3 +636,7 @@ func (a *tokenizerAdapter) Tokenize(ctx context.Context, prompt string) ([]int,
 func (a *tokenizerAdapter) CountTokens(ctx context.Context, prompt string) (int, error) {
 	return a.tokenizer.CountTokens(ctx, a.modelName, prompt)
 }
+
+func (a *tokenizerAdapter) CountTokensBatch(ctx context.Context, prompts []string) ([]int, error) {
+	return a.tokenizer.CountTokensBatch(ctx, a.modelName, prompts)
+}
@@ -0,0 +1,177 @@
+package llmrepo
+
+import (
+	"context"
+
+	"github.com/contenox/runtime/libtracker"
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+)
+
+// ParamLimits is the range of sampling parameters one provider type accepts.
+// Chains are written without knowing which provider a request resolves to, so
+// a temperature that is fine for Ollama (no upper bound) can be rejected by
+// Anthropic (at most 1). ModelManager clamps every chat, stream and prompt
+// request into the limits of the provider it resolved to before dispatching
+// it. Field names (max_tokens, max_completion_tokens, maxOutputTokens,
+// num_predict) are not a concern here: each provider client already maps
+// ChatConfig onto its own wire format.
+//
+// A zero maximum means the provider sets no upper bound.
+type ParamLimits struct {
+	MinTemperature float64
+	MaxTemperature float64
+	// MaxTopP bounds top_p; a negative top_p is always raised to 0.
+	MaxTopP            float64
+	MinPresencePenalty float64
+	MaxPresencePenalty float64
+	// MaxStopSequences is the most stop sequences the provider accepts;
+	// the ones past it are dropped. 0 means no limit.
+	MaxStopSequences int
+	// DefaultTemperature is sent when the caller sets no temperature. nil
+	// leaves the choice to the provider.
+	DefaultTemperature *float64
+}
+
+// DefaultParamLimits are the documented parameter ranges of the built-in
+// provider types, keyed by provider type. ModelManagerConfig.ParamLimits
+// overrides or extends them. Provider types without an entry are sent
+// parameters unchanged.
+var DefaultParamLimits = map[string]ParamLimits{
+	"openai":           {MaxTemperature: 2, MaxTopP: 1, MinPresencePenalty: -2, MaxPresencePenalty: 2, MaxStopSequences: 4},
+	"openrouter":       {MaxTemperature: 2, MaxTopP: 1, MinPresencePenalty: -2, MaxPresencePenalty: 2},
+	"anthropic":        {MaxTemperature: 1, MaxTopP: 1},
+	"vertex-anthropic": {MaxTemperature: 1, MaxTopP: 1},
+	"bedrock":          {MaxTemperature: 1, MaxTopP: 1},
+	"gemini":           {MaxTemperature: 2, MaxTopP: 1, MinPresencePenalty: -2, MaxPresencePenalty: 2, MaxStopSequences: 5},
+	"vertex-google":    {MaxTemperature: 2, MaxTopP: 1, MinPresencePenalty: -2, MaxPresencePenalty: 2, MaxStopSequences: 5},
+	"mistral":          {MaxTemperature: 1.5, MaxTopP: 1, MinPresencePenalty: -2, MaxPresencePenalty: 2},
+	"vertex-mistralai": {MaxTemperature: 1.5, MaxTopP: 1, MinPresencePenalty: -2, MaxPresencePenalty: 2},
+	"vllm":             {MaxTopP: 1, MinPresencePenalty: -2, MaxPresencePenalty: 2},
+	"ollama":           {MaxTopP: 1},
+	"llama":            {MaxTopP: 1},
+	"openvino":         {MaxTopP: 1},
+}
+
+// ParamAdjustment records one parameter ClampChatConfig changed.
+type ParamAdjustment struct {
+	Param string `json:"param"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// ClampChatConfig returns cfg with its sampling parameters moved into limits,
+// and the changes it made. cfg itself is not modified.
+func ClampChatConfig(cfg libmodelprovider.ChatConfig, limits ParamLimits) (libmodelprovider.ChatConfig, []ParamAdjustment) {
+	var changes []ParamAdjustment
+
+	if cfg.Temperature == nil && limits.DefaultTemperature != nil {
+		t := *limits.DefaultTemperature
+		cfg.Temperature = &t
+		changes = append(changes, ParamAdjustment{Param: "temperature", From: nil, To: t})
+	}
+	if cfg.Temperature != nil {
+		if t := clampFloat(*cfg.Temperature, limits.MinTemperature, limits.MaxTemperature); t != *cfg.Temperature {
+			changes = append(changes, ParamAdjustment{Param: "temperature", From: *cfg.Temperature, To: t})
+			cfg.Temperature = &t
+		}
+	}
+	if cfg.TopP != nil {
+		if p := clampFloat(*cfg.TopP, 0, limits.MaxTopP); p != *cfg.TopP {
+			changes = append(changes, ParamAdjustment{Param: "top_p", From: *cfg.TopP, To: p})
+			cfg.TopP = &p
+		}
+	}
+	if cfg.PresencePenalty != nil && (limits.MinPresencePenalty != 0 || limits.MaxPresencePenalty != 0) {
+		if p := clampFloat(*cfg.PresencePenalty, limits.MinPresencePenalty, limits.MaxPresencePenalty); p != *cfg.PresencePenalty {
+			changes = append(changes, ParamAdjustment{Param: "presence_penalty", From: *cfg.PresencePenalty, To: p})
+			cfg.PresencePenalty = &p
+		}
+	}
+	if cfg.MaxTokens != nil && *cfg.MaxTokens < 1 {
+		changes = append(changes, ParamAdjustment{Param: "max_tokens", From: *cfg.MaxTokens, To: nil})
+		cfg.MaxTokens = nil
+	}
+	if limits.MaxStopSequences > 0 && len(cfg.Stop) > limits.MaxStopSequences {
+		changes = append(changes, ParamAdjustment{Param: "stop", From: len(cfg.Stop), To: limits.MaxStopSequences})
+		cfg.Stop = cfg.Stop[:limits.MaxStopSequences:limits.MaxStopSequences]
+	}
+	return cfg, changes
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if hi > 0 && v > hi {
+		return hi
+	}
+	return v
+}
+
+// chatConfigArgument replaces the config a provider client builds from its
+// arguments with an already clamped one.
+type chatConfigArgument struct {
+	cfg libmodelprovider.ChatConfig
+}
+
+func (a chatConfigArgument) Apply(cfg *libmodelprovider.ChatConfig) {
+	*cfg = a.cfg
+}
+
+// paramLimits returns the limits for providerType: the configured override,
+// else the built-in default.
+func (e *modelManager) paramLimits(providerType string) (ParamLimits, bool) {
+	if limits, ok := e.config.ParamLimits[providerType]; ok {
+		return limits, true
+	}
+	limits, ok := DefaultParamLimits[providerType]
+	return limits, ok
+}
+
+// clampArgs folds opts into one ChatConfig, clamps it into the limits of
+// providerType, and reports any change on tracker. Without limits for the
+// provider type the arguments pass through untouched.
+func (e *modelManager) clampArgs(ctx context.Context, tracker libtracker.ActivityTracker, meta Meta, opts []libmodelprovider.ChatArgument) []libmodelprovider.ChatArgument {
+	limits, ok := e.paramLimits(meta.ProviderType)
+	if !ok {
+		return opts
+	}
+	var cfg libmodelprovider.ChatConfig
+	for _, opt := range opts {
+		opt.Apply(&cfg)
+	}
+	clamped, changes := ClampChatConfig(cfg, limits)
+	if len(changes) == 0 {
+		return opts
+	}
+	reportClamp(ctx, tracker, meta, changes)
+	return []libmodelprovider.ChatArgument{chatConfigArgument{cfg: clamped}}
+}
+
+// clampTemperature is clampArgs for PromptExecute, whose only sampling
+// parameter is temperature.
+func (e *modelManager) clampTemperature(ctx context.Context, tracker libtracker.ActivityTracker, meta Meta, temperature float32) float32 {
+	limits, ok := e.paramLimits(meta.ProviderType)
+	if !ok {
+		return temperature
+	}
+	t := clampFloat(float64(temperature), limits.MinTemperature, limits.MaxTemperature)
+	if t == float64(temperature) {
+		return temperature
+	}
+	reportClamp(ctx, tracker, meta, []ParamAdjustment{{Param: "temperature", From: temperature, To: t}})
+	return float32(t)
+}
+
+func reportClamp(ctx context.Context, tracker libtracker.ActivityTracker, meta Meta, changes []ParamAdjustment) {
+	if tracker == nil {
+		tracker = libtracker.NoopTracker{}
+	}
+	_, reportChange, end := tracker.Start(ctx, "clamp", "llm_params",
+		"model_name", meta.ModelName,
+		"provider_type", meta.ProviderType,
+		"backend_id", meta.BackendID,
+	)
+	defer end()
+	reportChange(meta.ProviderType, changes)
+}
@@ -0,0 +1,75 @@
+package llmrepo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/runtime/libtracker"
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ClampChatConfig_MovesParamsIntoProviderRange(t *testing.T) {
+	var cfg libmodelprovider.ChatConfig
+	for _, arg := range []libmodelprovider.ChatArgument{
+		libmodelprovider.WithTemperature(1.7),
+		libmodelprovider.WithTopP(1.2),
+		libmodelprovider.WithMaxTokens(0),
+		libmodelprovider.WithStop("a", "b", "c", "d", "e"),
+		libmodelprovider.WithSeed(7),
+	} {
+		arg.Apply(&cfg)
+	}
+
+	clamped, changes := ClampChatConfig(cfg, DefaultParamLimits["anthropic"])
+	require.Equal(t, 1.0, *clamped.Temperature)
+	require.Equal(t, 1.0, *clamped.TopP)
+	require.Nil(t, clamped.MaxTokens, "a non-positive max_tokens is dropped, not sent")
+	require.Len(t, clamped.Stop, 5, "anthropic sets no stop sequence limit")
+	require.Equal(t, 7, *clamped.Seed, "parameters without limits pass through")
+	require.Len(t, changes, 3)
+	require.Equal(t, 1.7, *cfg.Temperature, "the input config is not modified")
+
+	clamped, changes = ClampChatConfig(cfg, DefaultParamLimits["openai"])
+	require.Equal(t, 1.7, *clamped.Temperature, "openai accepts temperatures up to 2")
+	require.Equal(t, []string{"a", "b", "c", "d"}, clamped.Stop)
+	require.Contains(t, changes, ParamAdjustment{Param: "stop", From: 5, To: 4})
+}
+
+func TestUnit_ClampChatConfig_DefaultTemperature(t *testing.T) {
+	def := 0.2
+	limits := ParamLimits{MaxTemperature: 1, DefaultTemperature: &def}
+
+	clamped, changes := ClampChatConfig(libmodelprovider.ChatConfig{}, limits)
+	require.Equal(t, 0.2, *clamped.Temperature)
+	require.Len(t, changes, 1)
+
+	set := 0.9
+	clamped, changes = ClampChatConfig(libmodelprovider.ChatConfig{Temperature: &set}, limits)
+	require.Equal(t, 0.9, *clamped.Temperature, "a caller's temperature wins over the default")
+	require.Empty(t, changes)
+}
+
+func TestUnit_ClampArgs_UsesOverridesAndPassesUnknownProviders(t *testing.T) {
+	e := &modelManager{config: ModelManagerConfig{ParamLimits: map[string]ParamLimits{
+		"ollama": {MaxTemperature: 0.5},
+	}}}
+	ctx := context.Background()
+	opts := []libmodelprovider.ChatArgument{libmodelprovider.WithTemperature(0.8)}
+
+	apply := func(args []libmodelprovider.ChatArgument) libmodelprovider.ChatConfig {
+		var cfg libmodelprovider.ChatConfig
+		for _, arg := range args {
+			arg.Apply(&cfg)
+		}
+		return cfg
+	}
+
+	got := apply(e.clampArgs(ctx, libtracker.NoopTracker{}, Meta{ProviderType: "ollama"}, opts))
+	require.Equal(t, 0.5, *got.Temperature)
+
+	got = apply(e.clampArgs(ctx, libtracker.NoopTracker{}, Meta{ProviderType: "custom"}, opts))
+	require.Equal(t, 0.8, *got.Temperature)
+
+	require.Equal(t, float32(1), e.clampTemperature(ctx, libtracker.NoopTracker{}, Meta{ProviderType: "anthropic"}, 1.5))
+}
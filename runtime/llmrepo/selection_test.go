@@ -0,0 +1,24 @@
+package llmrepo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ParseSelectionPolicy(t *testing.T) {
+	for _, setting := range []string{"", "random"} {
+		policy, err := ParseSelectionPolicy(setting)
+		require.NoError(t, err, setting)
+		require.Nil(t, policy, "%q keeps the default", setting)
+	}
+	for _, setting := range []string{"round-robin", "least-recently-used", "weighted: qwen2.5:7b=3, llama3.1:8b=0"} {
+		policy, err := ParseSelectionPolicy(setting)
+		require.NoError(t, err, setting)
+		require.NotNil(t, policy, setting)
+	}
+	for _, setting := range []string{"fastest", "weighted:", "weighted:qwen=many", "weighted:=2"} {
+		_, err := ParseSelectionPolicy(setting)
+		require.Error(t, err, setting)
+	}
+}
@@ -2,8 +2,11 @@ package localfileservice
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"mime"
 	"net/http"
 	"os"
@@ -28,17 +31,30 @@ type Entry struct {
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 	IsDirectory bool      `json:"isDirectory"`
+	// Hash is the hex SHA-256 of the file's bytes. It is only populated by
+	// Read and Write, which have the content in hand; listings and Stat leave
+	// it empty rather than reading every file.
+	Hash string `json:"hash,omitempty"`
 }
 
 type Service interface {
 	Root() string
 	List(ctx context.Context, relPath string) ([]Entry, error)
+	// ListPage is List one page at a time: the entries of relPath that follow
+	// after in List's order (from the first when after is nil), at most limit
+	// of them (all when limit <= 0). Only after's Name and IsDirectory are
+	// used, so a page resumes in place even if that entry has since been
+	// removed. Only the returned entries are stat'ed, so a page of a large
+	// directory costs one directory read and limit stats.
+	ListPage(ctx context.Context, relPath string, after *Entry, limit int) ([]Entry, error)
 	Stat(ctx context.Context, relPath string) (*Entry, error)
 	Read(ctx context.Context, relPath string) ([]byte, *Entry, error)
+	GetFileText(ctx context.Context, relPath string) (string, *Entry, error)
 	Write(ctx context.Context, relPath string, data []byte, createOnly bool) (*Entry, error)
 	Mkdir(ctx context.Context, relPath string) (*Entry, error)
 	Delete(ctx context.Context, relPath string) error
 	Move(ctx context.Context, fromPath, toPath string) (*Entry, error)
+	Copy(ctx context.Context, fromPath, toPath string) (*Entry, error)
 	Find(ctx context.Context, opts FindOptions, emit func(Entry) error) (FindResult, error)
 }
 
@@ -241,6 +257,10 @@ func NormalizeRelPath(raw string, allowRoot bool) (string, error) {
 }
 
 func (s *localService) List(ctx context.Context, relPath string) ([]Entry, error) {
+	return s.ListPage(ctx, relPath, nil, 0)
+}
+
+func (s *localService) ListPage(ctx context.Context, relPath string, after *Entry, limit int) ([]Entry, error) {
 	_ = ctx
 	abs, rel, err := s.resolveExisting(relPath, true)
 	if err != nil {
@@ -257,6 +277,19 @@ func (s *localService) List(ctx context.Context, relPath string) ([]Entry, error
 	if err != nil {
 		return nil, mapOSError(err)
 	}
+	// Directories first, then by name; within one directory ordering by name
+	// is ordering by path.
+	sort.Slice(items, func(i, j int) bool {
+		return listsBefore(items[i].IsDir(), items[i].Name(), items[j].IsDir(), items[j].Name())
+	})
+	if after != nil {
+		items = items[sort.Search(len(items), func(i int) bool {
+			return listsBefore(after.IsDirectory, after.Name, items[i].IsDir(), items[i].Name())
+		}):]
+	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
 	entries := make([]Entry, 0, len(items))
 	for _, item := range items {
 		info, err := item.Info()
@@ -269,15 +302,18 @@ func (s *localService) List(ctx context.Context, relPath string) ([]Entry, error
 		}
 		entries = append(entries, entryFromInfo(childRel, info))
 	}
-	sort.Slice(entries, func(i, j int) bool {
-		if entries[i].IsDirectory != entries[j].IsDirectory {
-			return entries[i].IsDirectory
-		}
-		return entries[i].Path < entries[j].Path
-	})
 	return entries, nil
 }
 
+// listsBefore reports whether entry a comes before entry b in a listing:
+// directories first, then by name.
+func listsBefore(aDir bool, aName string, bDir bool, bName string) bool {
+	if aDir != bDir {
+		return aDir
+	}
+	return aName < bName
+}
+
 func (s *localService) Stat(ctx context.Context, relPath string) (*Entry, error) {
 	_ = ctx
 	abs, rel, err := s.resolveExisting(relPath, false)
@@ -310,6 +346,7 @@ func (s *localService) Read(ctx context.Context, relPath string) ([]byte, *Entry
 		return nil, nil, mapOSError(err)
 	}
 	entry := entryFromInfo(rel, info)
+	entry.Hash = contentHash(data)
 	return data, &entry, nil
 }
 
@@ -337,6 +374,7 @@ func (s *localService) Write(ctx context.Context, relPath string, data []byte, c
 		return nil, mapOSError(err)
 	}
 	entry := entryFromInfo(rel, info)
+	entry.Hash = contentHash(data)
 	return &entry, nil
 }
 
@@ -390,6 +428,110 @@ func (s *localService) Move(ctx context.Context, fromPath, toPath string) (*Entr
 	return &entry, nil
 }
 
+// Copy duplicates a file, or deep-copies a folder subtree, to toPath and returns
+// the entry at the new path. Unlike Write, a copy never overwrites: an existing
+// destination fails with a unique violation, and copying a folder into itself
+// (or any of its descendants) is rejected up front rather than recursing into
+// the tree being written.
+//
+// The subtree walk re-resolves every visited node through the same vfs view
+// Find uses, so an interior symlink that escapes the root or points into the
+// control plane is skipped instead of having its target duplicated into the
+// workspace.
+func (s *localService) Copy(ctx context.Context, fromPath, toPath string) (*Entry, error) {
+	fromAbs, fromRel, err := s.resolveExisting(fromPath, false)
+	if err != nil {
+		return nil, err
+	}
+	toRel, err := NormalizeRelPath(toPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if toRel == fromRel || strings.HasPrefix(toRel, fromRel+"/") {
+		return nil, fmt.Errorf("%w: cannot copy %s into itself", ErrInvalidPath, fromRel)
+	}
+	toAbs, toRel, err := s.resolveForWrite(toRel)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(toAbs); err == nil {
+		return nil, fmt.Errorf("%w: %s already exists", libdb.ErrUniqueViolation, toRel)
+	} else if !os.IsNotExist(err) {
+		return nil, mapOSError(err)
+	}
+	info, err := os.Stat(fromAbs)
+	if err != nil {
+		return nil, mapOSError(err)
+	}
+	if !info.IsDir() {
+		if err := copyFile(fromAbs, toAbs, info.Mode().Perm()); err != nil {
+			return nil, mapOSError(err)
+		}
+	} else {
+		walkErr := filepath.WalkDir(fromAbs, func(walkPath string, d os.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			sub, err := filepath.Rel(fromAbs, walkPath)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(s.root, walkPath)
+			if err != nil {
+				return err
+			}
+			if _, rerr := s.view.Resolve(filepath.ToSlash(rel)); rerr != nil {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			target := filepath.Join(toAbs, sub)
+			if d.IsDir() {
+				return os.MkdirAll(target, 0755)
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return copyFile(walkPath, target, fi.Mode().Perm())
+		})
+		if walkErr != nil {
+			_ = os.RemoveAll(toAbs)
+			return nil, mapOSError(walkErr)
+		}
+	}
+	copied, err := os.Stat(toAbs)
+	if err != nil {
+		return nil, mapOSError(err)
+	}
+	entry := entryFromInfo(toRel, copied)
+	return &entry, nil
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
 // resolveExisting normalizes a client path (rejecting absolute paths and
 // traversal via NormalizeRelPath), contains it within the root via vfs, then
 // confirms the target exists — vfs.Contain tolerates a missing leaf, but the
@@ -453,6 +595,11 @@ func entryFromInfo(rel string, info os.FileInfo) Entry {
 	}
 }
 
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 func mapOSError(err error) error {
 	if os.IsNotExist(err) {
 		return libdb.ErrNotFound
@@ -40,6 +40,51 @@ func TestUnit_LocalFileService_CRUD(t *testing.T) {
 	require.ErrorIs(t, err, libdb.ErrNotFound)
 }
 
+func TestUnit_LocalFileService_ListPage(t *testing.T) {
+	ctx := context.Background()
+	svc, err := localfileservice.New(t.TempDir())
+	require.NoError(t, err)
+	for _, dir := range []string{"src/b", "src/a"} {
+		_, err := svc.Mkdir(ctx, dir)
+		require.NoError(t, err)
+	}
+	for _, file := range []string{"src/c.go", "src/a.go", "src/b.go"} {
+		_, err := svc.Write(ctx, file, []byte("package src"), true)
+		require.NoError(t, err)
+	}
+
+	all, err := svc.List(ctx, "src")
+	require.NoError(t, err)
+	require.Equal(t, []string{"src/a", "src/b", "src/a.go", "src/b.go", "src/c.go"}, entryPaths(all))
+
+	var paged []localfileservice.Entry
+	var after *localfileservice.Entry
+	for {
+		page, err := svc.ListPage(ctx, "src", after, 2)
+		require.NoError(t, err)
+		paged = append(paged, page...)
+		if len(page) < 2 {
+			break
+		}
+		after = &page[len(page)-1]
+	}
+	require.Equal(t, all, paged, "paging walks List's order")
+
+	// A removed cursor entry still marks the position.
+	require.NoError(t, svc.Delete(ctx, "src/a.go"))
+	rest, err := svc.ListPage(ctx, "src", &localfileservice.Entry{Name: "a.go"}, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"src/b.go", "src/c.go"}, entryPaths(rest))
+}
+
+func entryPaths(entries []localfileservice.Entry) []string {
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
 func TestUnit_LocalFileService_RejectsTraversalAndSymlinkEscape(t *testing.T) {
 	root := t.TempDir()
 	outside := t.TempDir()
@@ -58,3 +103,64 @@ func TestUnit_LocalFileService_RejectsTraversalAndSymlinkEscape(t *testing.T) {
 	_, err = svc.Write(context.Background(), "out/new.txt", []byte("nope"), true)
 	require.ErrorIs(t, err, localfileservice.ErrInvalidPath)
 }
+
+func TestUnit_LocalFileService_Copy(t *testing.T) {
+	ctx := context.Background()
+	svc, err := localfileservice.New(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = svc.Write(ctx, "docs/a.txt", []byte("alpha"), true)
+	require.NoError(t, err)
+	_, err = svc.Write(ctx, "docs/nested/b.txt", []byte("beta"), true)
+	require.NoError(t, err)
+
+	entry, err := svc.Copy(ctx, "docs/a.txt", "a-copy.txt")
+	require.NoError(t, err)
+	require.Equal(t, "a-copy.txt", entry.Path)
+	data, _, err := svc.Read(ctx, "a-copy.txt")
+	require.NoError(t, err)
+	require.Equal(t, []byte("alpha"), data)
+
+	entry, err = svc.Copy(ctx, "docs", "archive/docs")
+	require.NoError(t, err)
+	require.True(t, entry.IsDirectory)
+	data, _, err = svc.Read(ctx, "archive/docs/nested/b.txt")
+	require.NoError(t, err)
+	require.Equal(t, []byte("beta"), data)
+
+	// The source is left untouched.
+	_, _, err = svc.Read(ctx, "docs/nested/b.txt")
+	require.NoError(t, err)
+
+	_, err = svc.Copy(ctx, "docs/a.txt", "a-copy.txt")
+	require.ErrorIs(t, err, libdb.ErrUniqueViolation)
+
+	_, err = svc.Copy(ctx, "docs", "docs/nested/docs")
+	require.ErrorIs(t, err, localfileservice.ErrInvalidPath)
+
+	_, err = svc.Copy(ctx, "missing.txt", "elsewhere.txt")
+	require.ErrorIs(t, err, libdb.ErrNotFound)
+}
+
+func TestUnit_LocalFileService_ContentHash(t *testing.T) {
+	ctx := context.Background()
+	svc, err := localfileservice.New(t.TempDir())
+	require.NoError(t, err)
+
+	written, err := svc.Write(ctx, "a.txt", []byte("hello"), true)
+	require.NoError(t, err)
+	// sha256("hello")
+	require.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", written.Hash)
+
+	_, meta, err := svc.Read(ctx, "a.txt")
+	require.NoError(t, err)
+	require.Equal(t, written.Hash, meta.Hash, "Read and Write hash the same bytes identically")
+
+	updated, err := svc.Write(ctx, "a.txt", []byte("hello, world"), false)
+	require.NoError(t, err)
+	require.NotEqual(t, written.Hash, updated.Hash)
+
+	stat, err := svc.Stat(ctx, "a.txt")
+	require.NoError(t, err)
+	require.Empty(t, stat.Hash, "Stat does not read content")
+}
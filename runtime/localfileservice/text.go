@@ -0,0 +1,108 @@
+package localfileservice
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"mime"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/pdftext"
+)
+
+// ErrNoText is returned by GetFileText for files with no plain-text form,
+// such as images or archives.
+var ErrNoText = fmt.Errorf("%w: file has no text content", errdefs.ErrUnprocessableEntity)
+
+// maxCachedText bounds the bytes of extracted text kept in memory.
+const maxCachedText = 32 << 20
+
+// extracted caches PDF text by content hash. It is shared by every Service:
+// equal bytes extract to equal text whichever root they were read from, and
+// an edited file hashes differently, so entries never go stale.
+var extracted = newTextCache(maxCachedText)
+
+// GetFileText returns the plain text of the file at relPath. Text files are
+// returned as they are; PDFs have their text extracted, cached by the file's
+// content hash so reading the same document again does not extract it again.
+func (s *localService) GetFileText(ctx context.Context, relPath string) (string, *Entry, error) {
+	data, entry, err := s.Read(ctx, relPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if isPDF(entry.ContentType, data) {
+		if text, ok := extracted.get(entry.Hash); ok {
+			return text, entry, nil
+		}
+		text, err := pdftext.Extract(data)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %w", ErrNoText, err)
+		}
+		extracted.put(entry.Hash, text)
+		return text, entry, nil
+	}
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		return "", nil, ErrNoText
+	}
+	return string(data), entry, nil
+}
+
+func isPDF(contentType string, data []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "application/pdf" {
+		return true
+	}
+	return bytes.HasPrefix(data, []byte("%PDF-"))
+}
+
+// textCache is a least-recently-used map from content hash to text, bounded
+// by the total text size.
+type textCache struct {
+	mu      sync.Mutex
+	max     int
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cachedText struct {
+	hash string
+	text string
+}
+
+func newTextCache(max int) *textCache {
+	return &textCache{max: max, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *textCache) get(hash string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[hash]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cachedText).text, true
+}
+
+func (c *textCache) put(hash, text string) {
+	if len(text) > c.max {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[hash]; ok {
+		return
+	}
+	c.entries[hash] = c.order.PushFront(&cachedText{hash: hash, text: text})
+	c.size += len(text)
+	for c.size > c.max {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*cachedText)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.hash)
+		c.size -= len(entry.text)
+	}
+}
@@ -0,0 +1,28 @@
+package localfileservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_TextCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newTextCache(10)
+	c.put("a", "aaaa")
+	c.put("b", "bbbb")
+	_, ok := c.get("a")
+	require.True(t, ok)
+	c.put("c", "cccc") // over the bound: b is the least recently used
+
+	_, ok = c.get("b")
+	require.False(t, ok)
+	text, ok := c.get("a")
+	require.True(t, ok)
+	require.Equal(t, "aaaa", text)
+	_, ok = c.get("c")
+	require.True(t, ok)
+
+	c.put("huge", "more than ten bytes")
+	_, ok = c.get("huge")
+	require.False(t, ok, "text larger than the whole cache is not kept")
+}
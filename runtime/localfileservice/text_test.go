@@ -0,0 +1,66 @@
+package localfileservice_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/localfileservice"
+	"github.com/stretchr/testify/require"
+)
+
+// minimalPDF is a one-page document showing text in a standard font.
+func minimalPDF(text string) []byte {
+	content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+	return []byte(fmt.Sprintf(`%%PDF-1.4
+1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj
+2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj
+3 0 obj << /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >> endobj
+4 0 obj << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> endobj
+5 0 obj << /Length %d >>
+stream
+%s
+endstream
+endobj
+trailer << /Root 1 0 R >>
+%%%%EOF
+`, len(content), content))
+}
+
+func TestUnit_LocalFileService_GetFileText(t *testing.T) {
+	ctx := context.Background()
+	svc, err := localfileservice.New(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = svc.Write(ctx, "notes.json", []byte(`{"ok":true}`), true)
+	require.NoError(t, err)
+	text, entry, err := svc.GetFileText(ctx, "notes.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"ok":true}`, text)
+	require.Equal(t, "notes.json", entry.Path)
+
+	_, err = svc.Write(ctx, "report.pdf", minimalPDF("Quarterly report"), true)
+	require.NoError(t, err)
+	text, entry, err = svc.GetFileText(ctx, "report.pdf")
+	require.NoError(t, err)
+	require.Equal(t, "Quarterly report", text)
+	require.NotEmpty(t, entry.Hash)
+
+	// Rewriting the document changes its hash, so the cached text of the
+	// old bytes is not served.
+	_, err = svc.Write(ctx, "report.pdf", minimalPDF("Annual report"), false)
+	require.NoError(t, err)
+	text, _, err = svc.GetFileText(ctx, "report.pdf")
+	require.NoError(t, err)
+	require.Equal(t, "Annual report", text)
+
+	_, err = svc.Write(ctx, "logo.png", []byte{0x89, 'P', 'N', 'G', 0, 1}, true)
+	require.NoError(t, err)
+	_, _, err = svc.GetFileText(ctx, "logo.png")
+	require.ErrorIs(t, err, localfileservice.ErrNoText)
+
+	_, err = svc.Write(ctx, "broken.pdf", []byte("not really a pdf"), true)
+	require.NoError(t, err)
+	_, _, err = svc.GetFileText(ctx, "broken.pdf")
+	require.ErrorIs(t, err, localfileservice.ErrNoText)
+}
@@ -0,0 +1,84 @@
+package localtools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/contenox/runtime/runtime/localfileservice"
+	"github.com/contenox/runtime/runtime/taskengine"
+)
+
+// maxAttachmentReadBytes is the largest attached file the workspace reader
+// reads; larger files are described instead. The inlining budget is far
+// smaller, so this only bounds the read.
+const maxAttachmentReadBytes = 4 << 20
+
+// NewWorkspaceAttachmentReader returns a taskengine.AttachmentReader that
+// reads an attachment's FileID as a path under the workspace root resolveRoot
+// returns for the request — the root local_fs works in — with the same
+// containment as the /files API. Text files are returned as text and PDFs
+// as their extracted text; other binary files, and files over
+// maxAttachmentReadBytes, only with their type and size, so the model is
+// told about them instead.
+func NewWorkspaceAttachmentReader(resolveRoot func(context.Context) string) taskengine.AttachmentReader {
+	return func(ctx context.Context, att taskengine.Attachment) (taskengine.AttachmentContent, error) {
+		root := resolveRoot(ctx)
+		if root == "" {
+			return taskengine.AttachmentContent{}, fmt.Errorf("no workspace root to read attachments from")
+		}
+		files, err := localfileservice.New(root)
+		if err != nil {
+			return taskengine.AttachmentContent{}, err
+		}
+		entry, err := files.Stat(ctx, att.FileID)
+		if err != nil {
+			return taskengine.AttachmentContent{}, err
+		}
+		if entry.IsDirectory {
+			return taskengine.AttachmentContent{}, fmt.Errorf("%s is a directory", att.FileID)
+		}
+		content := taskengine.AttachmentContent{ContentType: entry.ContentType, Size: entry.Size}
+		contentType := att.ContentType
+		if contentType == "" {
+			contentType = entry.ContentType
+		}
+		if entry.Size > maxAttachmentReadBytes || !maybeTextType(contentType) {
+			return content, nil
+		}
+		text, _, err := files.GetFileText(ctx, att.FileID)
+		if errors.Is(err, localfileservice.ErrNoText) {
+			return content, nil
+		}
+		if err != nil {
+			return taskengine.AttachmentContent{}, err
+		}
+		content.Text = text
+		return content, nil
+	}
+}
+
+// maybeTextType reports whether a file of contentType may hold text worth
+// reading: text formats and PDFs. Unknown and generic binary types are read
+// and checked.
+func maybeTextType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType == ""
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml",
+		"application/toml", "application/javascript", "application/x-sh", "application/sql",
+		"application/octet-stream", "application/pdf":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package localtools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/localtools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_WorkspaceAttachmentReader(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "notes.txt"), []byte("ship it"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "blob.bin"), []byte{0x89, 'P', 'N', 'G', 0, 1}, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(root), "outside.txt"), []byte("secret"), 0o644))
+	read := localtools.NewWorkspaceAttachmentReader(func(context.Context) string { return root })
+	ctx := context.Background()
+
+	content, err := read(ctx, taskengine.Attachment{FileID: "notes.txt"})
+	require.NoError(t, err)
+	require.Equal(t, "ship it", content.Text)
+	require.Equal(t, int64(7), content.Size)
+
+	content, err = read(ctx, taskengine.Attachment{FileID: "blob.bin"})
+	require.NoError(t, err)
+	require.Empty(t, content.Text, "binary content is described, not inlined")
+	require.Equal(t, int64(6), content.Size)
+
+	_, err = read(ctx, taskengine.Attachment{FileID: "../outside.txt"})
+	require.Error(t, err, "attachments cannot escape the workspace root")
+	_, err = read(ctx, taskengine.Attachment{FileID: "missing.txt"})
+	require.Error(t, err)
+}
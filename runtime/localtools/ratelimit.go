@@ -0,0 +1,212 @@
+package localtools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/taskengine"
+)
+
+// ErrRateLimitQueueFull is returned by a RateLimitedTools call that found its
+// resource's queue already at max_queue.
+var ErrRateLimitQueueFull = errors.New("rate limit queue full")
+
+// DefaultRateLimitQueue is the max_queue of a RateLimit that does not set one.
+const DefaultRateLimitQueue = 16
+
+// RateLimit is the quota of one outbound resource: calls are let through at
+// PerMinute, after an initial Burst, and up to MaxQueue calls wait for their
+// turn before further calls are refused.
+type RateLimit struct {
+	PerMinute float64
+	Burst     int
+	MaxQueue  int
+}
+
+// ParseRateLimits parses the TOOLS_RATE_LIMITS setting: a JSON object keyed
+// by tools name, e.g.
+//
+//	{"slack": {"per_minute": 50, "burst": 5, "max_queue": 20}}
+//
+// per_minute is required. burst defaults to 1, which spaces every call
+// evenly, and max_queue to DefaultRateLimitQueue; a max_queue of 0 refuses
+// any call that would have to wait. Empty raw yields no limits.
+func ParseRateLimits(raw string) (map[string]RateLimit, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var decoded map[string]struct {
+		PerMinute float64 `json:"per_minute"`
+		Burst     *int    `json:"burst"`
+		MaxQueue  *int    `json:"max_queue"`
+	}
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("must be a JSON object of tools name to {per_minute, burst, max_queue}: %w", err)
+	}
+	limits := make(map[string]RateLimit, len(decoded))
+	for name, l := range decoded {
+		limit := RateLimit{PerMinute: l.PerMinute, Burst: 1, MaxQueue: DefaultRateLimitQueue}
+		if l.Burst != nil {
+			limit.Burst = *l.Burst
+		}
+		if l.MaxQueue != nil {
+			limit.MaxQueue = *l.MaxQueue
+		}
+		if limit.PerMinute <= 0 || limit.Burst < 1 || limit.MaxQueue < 0 {
+			return nil, fmt.Errorf("%q: per_minute must be positive, burst at least 1 and max_queue non-negative", name)
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+// RateLimitedTools is a decorator around a ToolsRepo that holds calls to
+// quota-bound tools to their RateLimit. Each limited tools name has its own
+// token bucket, shared by every chain run in the process: a call takes a
+// token, or queues behind the calls already waiting and runs when the bucket
+// has refilled, in arrival order. A call that would make the queue longer
+// than MaxQueue fails at once with ErrRateLimitQueueFull instead of piling
+// up, and a queued call gives up when its context is done. Calls to other
+// tools pass straight through.
+//
+// Every change of a queue's depth is reported to the tracker as
+// "queue_depth" under the operation "rate_limit" and the tools name.
+type RateLimitedTools struct {
+	taskengine.ToolsRepo
+	tracker libtracker.ActivityTracker
+	buckets map[string]*toolsBucket
+}
+
+// NewRateLimitedTools wraps inner with one token bucket per entry of limits.
+func NewRateLimitedTools(inner taskengine.ToolsRepo, limits map[string]RateLimit, tracker libtracker.ActivityTracker) *RateLimitedTools {
+	if tracker == nil {
+		tracker = libtracker.NoopTracker{}
+	}
+	buckets := make(map[string]*toolsBucket, len(limits))
+	for name, limit := range limits {
+		buckets[name] = newToolsBucket(limit, time.Now())
+	}
+	return &RateLimitedTools{ToolsRepo: inner, tracker: tracker, buckets: buckets}
+}
+
+// Exec implements taskengine.ToolsRepo.
+func (r *RateLimitedTools) Exec(ctx context.Context, startTime time.Time, input any, debug bool, call *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	if call == nil || r.buckets[call.Name] == nil {
+		return r.ToolsRepo.Exec(ctx, startTime, input, debug, call)
+	}
+	if err := r.wait(ctx, call.Name); err != nil {
+		return nil, taskengine.DataTypeAny, err
+	}
+	return r.ToolsRepo.Exec(ctx, startTime, input, debug, call)
+}
+
+// QueueDepth returns the number of calls to name waiting for a token.
+func (r *RateLimitedTools) QueueDepth(name string) int {
+	b := r.buckets[name]
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.queued
+}
+
+// wait blocks until the call may go out.
+func (r *RateLimitedTools) wait(ctx context.Context, name string) error {
+	b := r.buckets[name]
+	reportErr, reportChange, end := r.tracker.Start(ctx, "rate_limit", name)
+	defer end()
+
+	delay, depth, ok := b.reserve(time.Now())
+	if !ok {
+		err := fmt.Errorf("%w: %s has %d calls waiting", ErrRateLimitQueueFull, name, depth)
+		reportErr(err)
+		return err
+	}
+	if delay <= 0 {
+		return nil
+	}
+	reportChange("queue_depth", map[string]any{"queue_depth": depth, "wait": delay.String()})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		reportChange("queue_depth", map[string]any{"queue_depth": b.dequeue(false)})
+		return nil
+	case <-ctx.Done():
+		reportChange("queue_depth", map[string]any{"queue_depth": b.dequeue(true)})
+		reportErr(ctx.Err())
+		return fmt.Errorf("waiting for %s rate limit: %w", name, ctx.Err())
+	}
+}
+
+// toolsBucket is a token bucket that hands out tokens in advance: a call
+// that finds it empty takes the next token to be refilled, which is what
+// orders queued calls by arrival.
+type toolsBucket struct {
+	limit    RateLimit
+	interval time.Duration // time to refill one token
+
+	mu     sync.Mutex
+	tokens float64 // negative while calls are queued for future tokens
+	last   time.Time
+	queued int
+}
+
+func newToolsBucket(limit RateLimit, now time.Time) *toolsBucket {
+	return &toolsBucket{
+		limit:    limit,
+		interval: time.Duration(float64(time.Minute) / limit.PerMinute),
+		tokens:   float64(limit.Burst),
+		last:     now,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait for it
+// and the queue depth including the caller. ok is false, and nothing is
+// taken, when the caller would have to wait but the queue is full.
+func (b *toolsBucket) reserve(now time.Time) (delay time.Duration, depth int, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(elapsed) / float64(b.interval)
+		if b.tokens > float64(b.limit.Burst) {
+			b.tokens = float64(b.limit.Burst)
+		}
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, b.queued, true
+	}
+	if b.queued >= b.limit.MaxQueue {
+		return 0, b.queued, false
+	}
+	delay = time.Duration((1 - b.tokens) * float64(b.interval))
+	b.tokens--
+	b.queued++
+	return delay, b.queued, true
+}
+
+// dequeue removes a queued call and returns the new depth. A call that gave
+// up returns its token, so the next call to arrive takes its slot.
+func (b *toolsBucket) dequeue(cancelled bool) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.queued--
+	if cancelled {
+		b.tokens++
+	}
+	return b.queued
+}
+
+var _ taskengine.ToolsRepo = (*RateLimitedTools)(nil)
@@ -0,0 +1,90 @@
+package localtools_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/localtools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ParseRateLimits(t *testing.T) {
+	limits, err := localtools.ParseRateLimits(`{"slack": {"per_minute": 30}, "crm": {"per_minute": 600, "burst": 5, "max_queue": 0}}`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]localtools.RateLimit{
+		"slack": {PerMinute: 30, Burst: 1, MaxQueue: localtools.DefaultRateLimitQueue},
+		"crm":   {PerMinute: 600, Burst: 5, MaxQueue: 0},
+	}, limits)
+
+	limits, err = localtools.ParseRateLimits("  ")
+	require.NoError(t, err)
+	require.Nil(t, limits)
+
+	for _, raw := range []string{`{"slack": {}}`, `{"slack": {"per_minute": 1, "burst": 0}}`, `{"slack": {"per_minute": 1, "rate": 2}}`, `[1]`} {
+		_, err := localtools.ParseRateLimits(raw)
+		require.Error(t, err, raw)
+	}
+}
+
+func TestUnit_RateLimitedTools_QueuesThenFailsFast(t *testing.T) {
+	// 600 per minute refills one token every 100ms.
+	limited := localtools.NewRateLimitedTools(localtools.NewEchoTools(), map[string]localtools.RateLimit{
+		"echo": {PerMinute: 600, Burst: 1, MaxQueue: 2},
+	}, libtracker.NoopTracker{})
+	call := &taskengine.ToolsCall{Name: "echo"}
+	ctx := context.Background()
+
+	start := time.Now()
+	_, _, err := limited.Exec(ctx, start, "first", false, call)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 50*time.Millisecond, "the burst token is used at once")
+
+	var wg sync.WaitGroup
+	finished := make([]time.Duration, 2)
+	errs := make([]error, 2)
+	for i := range finished {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = limited.Exec(ctx, time.Now(), "queued", false, call)
+			finished[i] = time.Since(start)
+		}(i)
+	}
+	require.Eventually(t, func() bool { return limited.QueueDepth("echo") == 2 }, time.Second, time.Millisecond)
+
+	_, _, err = limited.Exec(ctx, time.Now(), "overflow", false, call)
+	require.ErrorIs(t, err, localtools.ErrRateLimitQueueFull)
+
+	wg.Wait()
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.Equal(t, 0, limited.QueueDepth("echo"))
+	for _, d := range finished {
+		require.GreaterOrEqual(t, d, 90*time.Millisecond, "queued calls wait for the refill")
+	}
+	require.GreaterOrEqual(t, max(finished[0], finished[1]), 190*time.Millisecond, "queued calls are spaced one interval apart")
+}
+
+func TestUnit_RateLimitedTools_CancelledWaitLeavesQueue(t *testing.T) {
+	limited := localtools.NewRateLimitedTools(localtools.NewEchoTools(), map[string]localtools.RateLimit{
+		"echo": {PerMinute: 1, Burst: 1, MaxQueue: 1},
+	}, libtracker.NoopTracker{})
+	call := &taskengine.ToolsCall{Name: "echo"}
+
+	_, _, err := limited.Exec(context.Background(), time.Now(), "first", false, call)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err = limited.Exec(ctx, time.Now(), "second", false, call)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 0, limited.QueueDepth("echo"))
+
+	out, _, err := limited.Exec(context.Background(), time.Now(), "unlimited", false, &taskengine.ToolsCall{Name: "other"})
+	require.NoError(t, err, "calls to tools without a limit pass straight through")
+	require.Equal(t, "unlimited", out)
+}
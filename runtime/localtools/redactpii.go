@@ -0,0 +1,256 @@
+package localtools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const redactPIIToolsName = "redact_pii"
+
+// piiDetector finds one kind of PII. valid, when set, confirms a regex match
+// before it is redacted.
+type piiDetector struct {
+	kind  string
+	re    *regexp.Regexp
+	valid func(match string) bool
+}
+
+// builtinPIIDetectors run in this order: cards before phones, so a card
+// number is never half-redacted as a phone number.
+var builtinPIIDetectors = []piiDetector{
+	{
+		kind: "email",
+		re:   regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	},
+	{
+		kind:  "card",
+		re:    regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		valid: luhnValid,
+	},
+	{
+		kind: "phone",
+		// International numbers with a leading +, or the common national
+		// forms "(555) 123-4567", "555-123-4567" and "555.123.4567". Bare
+		// digit runs are left alone: they are far more often IDs or amounts.
+		re:    regexp.MustCompile(`\+\d{1,3}(?:[ .\-]?\(?\d{1,4}\)?){2,5}\b|(?:\(\d{3}\)\s?|\b\d{3}[.\-])\d{3}[.\-]\d{4}\b`),
+		valid: func(match string) bool { n := countDigits(match); return n >= 7 && n <= 15 },
+	},
+}
+
+// PIIRedactor implements the redact_pii tools: it replaces emails, phone
+// numbers, credit-card numbers and chain-defined patterns with placeholders
+// such as [EMAIL], so a chain can scrub text before it reaches a model or a
+// log.
+//
+// Extra patterns are a JSON object mapping a name to a regular expression,
+// given as "_patterns" in execute_config.tools_policies.redact_pii or as
+// "patterns" in the task's tools args; a match is replaced with the
+// upper-cased name, e.g. {"employee_id": "EMP-\\d{6}"} yields [EMPLOYEE_ID].
+type PIIRedactor struct {
+	tracker libtracker.ActivityTracker
+}
+
+// NewPIIRedactor creates a new PIIRedactor instance.
+func NewPIIRedactor(tracker libtracker.ActivityTracker) taskengine.ToolsRepo {
+	if tracker == nil {
+		tracker = libtracker.NoopTracker{}
+	}
+	return &PIIRedactor{tracker: tracker}
+}
+
+// piiRedaction is the result of redacting a string.
+type piiRedaction struct {
+	Text       string         `json:"text"`
+	Redactions int            `json:"redactions"`
+	ByKind     map[string]int `json:"by_kind"`
+}
+
+// Exec redacts the input. A string, or a model tool call with a "text"
+// argument, yields a JSON object with the redacted text, the number of
+// redactions and the count per kind. A chat history is returned with every
+// message's content redacted in place, keeping the chain's history type; its
+// counts are reported to the activity tracker.
+func (h *PIIRedactor) Exec(ctx context.Context, startTime time.Time, input any, debug bool, toolsCall *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	_, reportChange, end := h.tracker.Start(ctx, "exec", "redact_pii_tools")
+	defer end()
+
+	detectors, err := h.detectors(ctx, toolsCall)
+	if err != nil {
+		return nil, taskengine.DataTypeAny, err
+	}
+
+	switch v := input.(type) {
+	case taskengine.ChatHistory:
+		messages := make([]taskengine.Message, len(v.Messages))
+		copy(messages, v.Messages)
+		byKind := map[string]int{}
+		total := 0
+		for i := range messages {
+			text, counts := redactPII(messages[i].Content, detectors)
+			messages[i].Content = text
+			for kind, n := range counts {
+				byKind[kind] += n
+				total += n
+			}
+		}
+		v.Messages = messages
+		reportChange("redactions", map[string]any{"redactions": total, "by_kind": byKind})
+		return v, taskengine.DataTypeChatHistory, nil
+	case map[string]any:
+		if err := rejectUnknownArgs(redactPIIToolsName, v, "text"); err != nil {
+			return nil, taskengine.DataTypeAny, err
+		}
+		text, ok := v["text"].(string)
+		if !ok {
+			return nil, taskengine.DataTypeAny, fmt.Errorf("missing 'text' argument in redact_pii tools")
+		}
+		return redactPIIResult(text, detectors)
+	case string:
+		return redactPIIResult(v, detectors)
+	default:
+		return nil, taskengine.DataTypeAny, fmt.Errorf("redact_pii: unsupported input type %T, want string or chat history", input)
+	}
+}
+
+// detectors returns the built-in detectors followed by the configured
+// patterns, sorted by name so the order of replacement is stable.
+func (h *PIIRedactor) detectors(ctx context.Context, toolsCall *taskengine.ToolsCall) ([]piiDetector, error) {
+	patterns := map[string]string{}
+	if raw := taskengine.ToolsArgsFromContext(ctx, redactPIIToolsName)["_patterns"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &patterns); err != nil {
+			return nil, fmt.Errorf("redact_pii: _patterns must be a JSON object of name to regex: %w", err)
+		}
+	}
+	if toolsCall != nil && toolsCall.Args["patterns"] != "" {
+		extra := map[string]string{}
+		if err := json.Unmarshal([]byte(toolsCall.Args["patterns"]), &extra); err != nil {
+			return nil, fmt.Errorf("redact_pii: patterns must be a JSON object of name to regex: %w", err)
+		}
+		for name, expr := range extra {
+			patterns[name] = expr
+		}
+	}
+
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	detectors := append([]piiDetector(nil), builtinPIIDetectors...)
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("redact_pii: pattern name must not be empty")
+		}
+		re, err := regexp.Compile(patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("redact_pii: pattern %q: %w", name, err)
+		}
+		detectors = append(detectors, piiDetector{kind: name, re: re})
+	}
+	return detectors, nil
+}
+
+func redactPIIResult(text string, detectors []piiDetector) (any, taskengine.DataType, error) {
+	redacted, byKind := redactPII(text, detectors)
+	result := piiRedaction{Text: redacted, ByKind: byKind}
+	for _, n := range byKind {
+		result.Redactions += n
+	}
+	return result, taskengine.DataTypeJSON, nil
+}
+
+// redactPII applies detectors to text in order and returns the redacted text
+// with the number of replacements per kind.
+func redactPII(text string, detectors []piiDetector) (string, map[string]int) {
+	byKind := map[string]int{}
+	for _, d := range detectors {
+		placeholder := "[" + strings.ToUpper(d.kind) + "]"
+		text = d.re.ReplaceAllStringFunc(text, func(match string) string {
+			if d.valid != nil && !d.valid(match) {
+				return match
+			}
+			byKind[d.kind]++
+			return placeholder
+		})
+	}
+	return text, byKind
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum used by
+// payment card numbers.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func countDigits(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+func (h *PIIRedactor) Supports(ctx context.Context) ([]string, error) {
+	return []string{redactPIIToolsName}, nil
+}
+
+// GetSchemasForSupportedTools returns OpenAPI schemas for supported tools.
+func (h *PIIRedactor) GetSchemasForSupportedTools(ctx context.Context) (map[string]*openapi3.T, error) {
+	return map[string]*openapi3.T{}, nil
+}
+
+// GetToolsForToolsByName returns tools exposed by this tools.
+func (h *PIIRedactor) GetToolsForToolsByName(ctx context.Context, name string) ([]taskengine.Tool, error) {
+	if name != redactPIIToolsName {
+		return nil, fmt.Errorf("unknown tools: %s", name)
+	}
+
+	return []taskengine.Tool{
+		{
+			Type: "function",
+			Function: taskengine.FunctionTool{
+				Name:        redactPIIToolsName,
+				Description: "Replaces emails, phone numbers and credit-card numbers in a text with placeholders such as [EMAIL] and returns the redacted text with the number of redactions",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"text": map[string]interface{}{
+							"type":        "string",
+							"description": "The text to redact",
+						},
+					},
+					"required": []string{"text"},
+				},
+			},
+		},
+	}, nil
+}
+
+var _ taskengine.ToolsRepo = (*PIIRedactor)(nil)
@@ -0,0 +1,88 @@
+package localtools_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/localtools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func redactionOf(t *testing.T, out any) map[string]any {
+	t.Helper()
+	b, err := json.Marshal(out)
+	require.NoError(t, err)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(b, &m))
+	return m
+}
+
+func TestUnit_PIIRedactor_String(t *testing.T) {
+	tools := localtools.NewPIIRedactor(libtracker.NoopTracker{})
+
+	out, dt, err := tools.Exec(context.Background(), time.Now().UTC(),
+		"Mail jane.doe@example.com or call (555) 123-4567 / +49 30 1234567. "+
+			"Card 4111 1111 1111 1111, order 4111111111111112, meeting 2024-01-15.",
+		false, &taskengine.ToolsCall{Name: "redact_pii"})
+	require.NoError(t, err)
+	require.Equal(t, taskengine.DataTypeJSON, dt)
+
+	got := redactionOf(t, out)
+	require.Equal(t,
+		"Mail [EMAIL] or call [PHONE] / [PHONE]. Card [CARD], order 4111111111111112, meeting 2024-01-15.",
+		got["text"], "numbers failing the Luhn check and dates are left alone")
+	require.Equal(t, float64(4), got["redactions"])
+	require.Equal(t, map[string]any{"email": float64(1), "phone": float64(2), "card": float64(1)}, got["by_kind"])
+}
+
+func TestUnit_PIIRedactor_CustomPatterns(t *testing.T) {
+	tools := localtools.NewPIIRedactor(libtracker.NoopTracker{})
+	ctx := taskengine.WithToolsArgs(context.Background(), "redact_pii", map[string]string{
+		"_patterns": `{"employee_id": "EMP-\\d{6}"}`,
+	})
+
+	out, _, err := tools.Exec(ctx, time.Now().UTC(), "EMP-004211 and TKT-99 filed it", false, &taskengine.ToolsCall{
+		Name: "redact_pii",
+		Args: map[string]string{"patterns": `{"ticket": "TKT-\\d+"}`},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "[EMPLOYEE_ID] and [TICKET] filed it", redactionOf(t, out)["text"])
+
+	_, _, err = tools.Exec(context.Background(), time.Now().UTC(), "x", false, &taskengine.ToolsCall{
+		Name: "redact_pii",
+		Args: map[string]string{"patterns": `{"bad": "("}`},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `pattern "bad"`)
+}
+
+func TestUnit_PIIRedactor_ChatHistory(t *testing.T) {
+	tools := localtools.NewPIIRedactor(libtracker.NoopTracker{})
+	hist := taskengine.ChatHistory{Messages: []taskengine.Message{
+		{Role: "user", Content: "I am bob@example.org"},
+		{Role: "assistant", Content: "Noted."},
+	}}
+
+	out, dt, err := tools.Exec(context.Background(), time.Now().UTC(), hist, false, &taskengine.ToolsCall{Name: "redact_pii"})
+	require.NoError(t, err)
+	require.Equal(t, taskengine.DataTypeChatHistory, dt)
+	redacted := out.(taskengine.ChatHistory)
+	require.Equal(t, "I am [EMAIL]", redacted.Messages[0].Content)
+	require.Equal(t, "Noted.", redacted.Messages[1].Content)
+	require.Equal(t, "I am bob@example.org", hist.Messages[0].Content, "the input history is not modified")
+}
+
+func TestUnit_PIIRedactor_RejectsUnknownArgs(t *testing.T) {
+	tools := localtools.NewPIIRedactor(libtracker.NoopTracker{})
+
+	_, _, err := tools.Exec(context.Background(), time.Now().UTC(), map[string]any{
+		"text":       "hello",
+		"unexpected": true,
+	}, false, &taskengine.ToolsCall{Name: "redact_pii"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown argument")
+}
@@ -33,6 +33,7 @@ type WebCaller struct {
 	client         *http.Client
 	defaultHeaders map[string]string
 	tracker        libtracker.ActivityTracker
+	signing        RequestSigning
 }
 
 // NewWebCaller creates a new WebCaller. Pass nil for tracker to disable
@@ -117,6 +118,21 @@ func (h *WebCaller) policyBool(args map[string]string, key string, fallback bool
 	return fallback
 }
 
+// policyStatusCodes parses a comma-separated list of HTTP status codes. An
+// entry that is not a status code is an error: a typo would otherwise
+// silently turn retries off.
+func (h *WebCaller) policyStatusCodes(args map[string]string, key string) ([]int, error) {
+	var codes []int
+	for _, s := range h.policyCSV(args, key, "") {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 100 || n > 599 {
+			return nil, fmt.Errorf("tools_policies.webtools.%s: %q is not an HTTP status code", key, s)
+		}
+		codes = append(codes, n)
+	}
+	return codes, nil
+}
+
 // ── URL / host validation ─────────────────────────────────────────────────────
 
 // validateURL parses raw and enforces scheme + host policies. Returns the
@@ -250,10 +266,10 @@ func (h *WebCaller) extractQuery(input map[string]any, toolsCall *taskengine.Too
 	return ""
 }
 
-func (h *WebCaller) extractBody(input map[string]any, maxBytes int) (io.Reader, int, error) {
+func (h *WebCaller) extractBody(input map[string]any, maxBytes int) ([]byte, error) {
 	v, ok := input["body"]
 	if !ok || v == nil {
-		return nil, 0, nil
+		return nil, nil
 	}
 	var raw []byte
 	switch x := v.(type) {
@@ -262,14 +278,14 @@ func (h *WebCaller) extractBody(input map[string]any, maxBytes int) (io.Reader,
 	default:
 		b, err := json.Marshal(v)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to marshal body: %w", err)
+			return nil, fmt.Errorf("failed to marshal body: %w", err)
 		}
 		raw = b
 	}
 	if maxBytes > 0 && len(raw) > maxBytes {
-		return nil, 0, fmt.Errorf("request body is %d bytes (max %d); raise tools_policies.webtools._max_request_body_bytes or shrink the body", len(raw), maxBytes)
+		return nil, fmt.Errorf("request body is %d bytes (max %d); raise tools_policies.webtools._max_request_body_bytes or shrink the body", len(raw), maxBytes)
 	}
-	return bytes.NewReader(raw), len(raw), nil
+	return raw, nil
 }
 
 // ── core request loop ─────────────────────────────────────────────────────────
@@ -305,6 +321,12 @@ func (h *WebCaller) doRequest(ctx context.Context, method, toolName string, inpu
 	initialBackoffMs := h.policyInt(policy, "_initial_backoff_ms", 250)
 	maxBackoffMs := h.policyInt(policy, "_max_backoff_ms", 5000)
 	disallowRedirects := h.policyBool(policy, "_disallow_redirects", false)
+	retryOn, err := h.policyStatusCodes(policy, "_retry_on")
+	if err != nil {
+		return nil, taskengine.DataTypeAny, err
+	}
+	reportDelivery := h.policyBool(policy, "_report_delivery", false)
+	signing := h.requestSigning(policy)
 
 	rawURL, err := h.extractURL(dynArgs, toolsCall)
 	if err != nil {
@@ -356,6 +378,7 @@ func (h *WebCaller) doRequest(ctx context.Context, method, toolName string, inpu
 		respBody   []byte
 		statusCode int
 		truncated  bool
+		attempts   int
 	)
 	backoff := time.Duration(initialBackoffMs) * time.Millisecond
 	maxBackoff := time.Duration(maxBackoffMs) * time.Millisecond
@@ -364,12 +387,17 @@ func (h *WebCaller) doRequest(ctx context.Context, method, toolName string, inpu
 	}
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		attempts = attempt
 		// Body must be re-built per attempt because io.Reader is single-use.
-		body, _, bodyErr := h.extractBody(dynArgs, maxBodyBytes)
+		rawBody, bodyErr := h.extractBody(dynArgs, maxBodyBytes)
 		if bodyErr != nil {
 			reportErr(bodyErr)
 			return nil, taskengine.DataTypeAny, bodyErr
 		}
+		var body io.Reader
+		if rawBody != nil {
+			body = bytes.NewReader(rawBody)
+		}
 
 		req, reqErr := http.NewRequestWithContext(ctx, method, u.String(), body)
 		if reqErr != nil {
@@ -382,6 +410,16 @@ func (h *WebCaller) doRequest(ctx context.Context, method, toolName string, inpu
 		for k, v := range headers {
 			req.Header.Set(k, v)
 		}
+		taskengine.InjectTraceContext(ctx, req.Header)
+		// Signed per attempt, after the caller's headers so a model-supplied
+		// header cannot stand in for the signature, and with a fresh
+		// timestamp so a retry is not rejected as stale.
+		if signing.Secret != "" {
+			if err := signing.sign(req, rawBody, time.Now()); err != nil {
+				reportErr(err)
+				return nil, taskengine.DataTypeAny, err
+			}
+		}
 
 		resp, doErr := client.Do(req)
 		if doErr != nil {
@@ -391,6 +429,11 @@ func (h *WebCaller) doRequest(ctx context.Context, method, toolName string, inpu
 				continue
 			}
 			reportErr(doErr)
+			if reportDelivery && ctx.Err() == nil {
+				report := deliveryReport(0, attempt, nil, false, maxRespBytes)
+				report["error"] = doErr.Error()
+				return report, taskengine.DataTypeJSON, nil
+			}
 			return nil, taskengine.DataTypeAny, fmt.Errorf("request failed after %d attempts: %w", attempt, doErr)
 		}
 
@@ -402,7 +445,7 @@ func (h *WebCaller) doRequest(ctx context.Context, method, toolName string, inpu
 			reportErr(readErr)
 			return nil, taskengine.DataTypeAny, fmt.Errorf("failed to read response: %w", readErr)
 		}
-		if statusCode >= 500 && attempt < maxAttempts {
+		if retryableStatus(statusCode, retryOn) && attempt < maxAttempts {
 			time.Sleep(jitter(backoff))
 			backoff = nextBackoff(backoff, maxBackoff)
 			continue
@@ -410,6 +453,17 @@ func (h *WebCaller) doRequest(ctx context.Context, method, toolName string, inpu
 		break
 	}
 
+	if reportDelivery {
+		// The chain branches on the report, so an HTTP failure is a result
+		// here rather than an error.
+		if statusCode >= 200 && statusCode < 300 {
+			reportChange(fmt.Sprintf("status_%d", statusCode), nil)
+		} else {
+			reportErr(fmt.Errorf("webtools %s %s: HTTP %d after %d attempts", method, u.String(), statusCode, attempts))
+		}
+		return deliveryReport(statusCode, attempts, respBody, truncated, maxRespBytes), taskengine.DataTypeJSON, nil
+	}
+
 	if statusCode >= 200 && statusCode < 300 {
 		reportChange(fmt.Sprintf("status_%d", statusCode), nil)
 		var parsed any
@@ -433,6 +487,43 @@ func (h *WebCaller) doRequest(ctx context.Context, method, toolName string, inpu
 	return nil, taskengine.DataTypeAny, failure
 }
 
+// deliveryReport is the _report_delivery result: the outcome of the last
+// attempt alongside the response body (parsed as JSON when possible).
+func deliveryReport(statusCode, attempts int, respBody []byte, truncated bool, maxRespBytes int) map[string]any {
+	var body any = string(respBody)
+	if json.Valid(respBody) {
+		var parsed any
+		if err := json.Unmarshal(respBody, &parsed); err == nil {
+			body = parsed
+		}
+	}
+	report := map[string]any{
+		"ok":       statusCode >= 200 && statusCode < 300,
+		"status":   statusCode,
+		"attempts": attempts,
+		"body":     body,
+	}
+	if truncated {
+		report["_truncated"] = true
+		report["_max_bytes"] = maxRespBytes
+	}
+	return report
+}
+
+// retryableStatus reports whether a response with status code is retried:
+// any code listed in retryOn, or any 5xx when retryOn is empty.
+func retryableStatus(code int, retryOn []int) bool {
+	if len(retryOn) == 0 {
+		return code >= 500
+	}
+	for _, c := range retryOn {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
 func wrapTruncated(parsed any, n, max int) any {
 	return map[string]any{
 		"_truncated":  true,
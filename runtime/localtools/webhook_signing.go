@@ -0,0 +1,106 @@
+package localtools
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSignatureHeader carries the request signature, "sha256=<hex>".
+	DefaultSignatureHeader = "X-Signature"
+	// DefaultSignatureTimestampHeader carries the Unix time, in seconds, the
+	// signature was made at.
+	DefaultSignatureTimestampHeader = "X-Signature-Timestamp"
+)
+
+// RequestSigning makes a WebCaller sign every request it sends so the
+// receiver can check it came from this runtime. The signature is
+// HMAC-SHA256, keyed with Secret, over "<timestamp>.<body>", sent as
+// "sha256=<hex>" in Header, with the timestamp in TimestampHeader. A
+// receiver recomputes it over the raw body and rejects timestamps outside
+// its tolerance, so a captured request cannot be replayed later.
+//
+// Secret may be "env:NAME" to read the key from the environment when the
+// request is made.
+type RequestSigning struct {
+	Secret          string
+	Header          string // DefaultSignatureHeader when empty
+	TimestampHeader string // DefaultSignatureTimestampHeader when empty
+}
+
+// WithRequestSigning signs every request this WebCaller sends. Chains can
+// override it per task with the _signing_secret, _signature_header and
+// _signature_timestamp_header keys of tools_policies.webtools, so different
+// endpoints can use different secrets.
+func WithRequestSigning(signing RequestSigning) WebtoolsOption {
+	return func(h *WebCaller) { h.signing = signing }
+}
+
+// requestSigning merges the task's tools_policies signing keys over the
+// caller's default. A zero result (no secret) means requests go unsigned.
+func (h *WebCaller) requestSigning(policy map[string]string) RequestSigning {
+	s := h.signing
+	if v := strings.TrimSpace(policy["_signing_secret"]); v != "" {
+		s.Secret = v
+	}
+	if v := strings.TrimSpace(policy["_signature_header"]); v != "" {
+		s.Header = v
+	}
+	if v := strings.TrimSpace(policy["_signature_timestamp_header"]); v != "" {
+		s.TimestampHeader = v
+	}
+	return s
+}
+
+// key resolves Secret. An "env:" reference to an unset variable is an error
+// rather than an empty key, so a misconfigured deployment fails loudly
+// instead of sending requests the receiver will reject.
+func (s RequestSigning) key() ([]byte, error) {
+	name, ok := strings.CutPrefix(s.Secret, "env:")
+	if !ok {
+		return []byte(s.Secret), nil
+	}
+	name = strings.TrimSpace(name)
+	v := os.Getenv(name)
+	if v == "" {
+		return nil, fmt.Errorf("webtools: signing secret variable %s is not set", name)
+	}
+	return []byte(v), nil
+}
+
+// sign sets the signature and timestamp headers on req for body.
+func (s RequestSigning) sign(req *http.Request, body []byte, now time.Time) error {
+	key, err := s.key()
+	if err != nil {
+		return err
+	}
+	header := s.Header
+	if header == "" {
+		header = DefaultSignatureHeader
+	}
+	tsHeader := s.TimestampHeader
+	if tsHeader == "" {
+		tsHeader = DefaultSignatureTimestampHeader
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(tsHeader, ts)
+	req.Header.Set(header, "sha256="+SignatureHex(key, ts, body))
+	return nil
+}
+
+// SignatureHex returns the hex HMAC-SHA256 of "<timestamp>.<body>" under key:
+// the value after "sha256=" in a signed request's signature header.
+func SignatureHex(key []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
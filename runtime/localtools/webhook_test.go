@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -264,6 +265,69 @@ func TestUnit_WebTools_Get_NoRetryOn4xx(t *testing.T) {
 	require.EqualValues(t, 1, hits.Load(), "4xx must not retry")
 }
 
+func TestUnit_WebTools_Get_RetryOnListedStatus(t *testing.T) {
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(429)
+			return
+		}
+		w.WriteHeader(503)
+	}))
+	defer srv.Close()
+
+	ctx := ctxWithPolicy(map[string]string{
+		"_denied_hosts":       "",
+		"_max_attempts":       "5",
+		"_retry_on":           "429",
+		"_initial_backoff_ms": "1",
+		"_max_backoff_ms":     "1",
+	})
+	_, _, err := execWeb(t, ctx, newWebTools(t, &recTracker{}), "web_get", map[string]any{"url": srv.URL})
+	require.ErrorContains(t, err, "HTTP 503")
+	require.EqualValues(t, 3, hits.Load(), "429 retries; 503 is not listed, so it ends the loop")
+
+	ctx = ctxWithPolicy(map[string]string{"_retry_on": "429,soon"})
+	_, _, err = execWeb(t, ctx, newWebTools(t, &recTracker{}), "web_get", map[string]any{"url": srv.URL})
+	require.ErrorContains(t, err, "not an HTTP status code")
+}
+
+func TestUnit_WebTools_ReportDelivery(t *testing.T) {
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(502)
+		_, _ = w.Write([]byte(`{"error":"upstream"}`))
+	}))
+	defer srv.Close()
+
+	ctx := ctxWithPolicy(map[string]string{
+		"_denied_hosts":       "",
+		"_max_attempts":       "2",
+		"_report_delivery":    "true",
+		"_initial_backoff_ms": "1",
+		"_max_backoff_ms":     "1",
+	})
+	res, dt, err := execWeb(t, ctx, newWebTools(t, &recTracker{}), "web_post", map[string]any{"url": srv.URL, "body": "x"})
+	require.NoError(t, err, "a failed delivery is reported, not raised")
+	require.Equal(t, taskengine.DataTypeJSON, dt)
+	report := res.(map[string]any)
+	require.Equal(t, false, report["ok"])
+	require.Equal(t, 502, report["status"])
+	require.Equal(t, 2, report["attempts"])
+	require.Equal(t, map[string]any{"error": "upstream"}, report["body"])
+	require.EqualValues(t, 2, hits.Load())
+
+	// A transport failure is reported too, with status 0.
+	srv.Close()
+	res, _, err = execWeb(t, ctx, newWebTools(t, &recTracker{}), "web_get", map[string]any{"url": srv.URL})
+	require.NoError(t, err)
+	report = res.(map[string]any)
+	require.Equal(t, false, report["ok"])
+	require.Equal(t, 0, report["status"])
+	require.NotEmpty(t, report["error"])
+}
+
 // ── headers shape ──────────────────────────────────────────────────────────
 
 func TestUnit_WebTools_Get_HeadersAsObject(t *testing.T) {
@@ -354,3 +418,72 @@ func TestUnit_WebTools_SchemasPerVerb(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, all, 6)
 }
+
+// ── request signing ─────────────────────────────────────────────────────────
+
+func TestUnit_WebTools_Post_SignsBodyAndTimestamp(t *testing.T) {
+	var gotSig, gotTS string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-Signature")
+		gotTS = r.Header.Get("X-Signature-Timestamp")
+		gotBody, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	tools := localtools.NewWebCaller(&recTracker{}, localtools.WithRequestSigning(localtools.RequestSigning{Secret: "s3cret"}))
+	ctx := ctxWithPolicy(map[string]string{"_denied_hosts": ""})
+
+	_, _, err := execWeb(t, ctx, tools, "web_post", map[string]any{
+		"url":     srv.URL,
+		"body":    map[string]any{"k": "v"},
+		"headers": map[string]any{"X-Signature": "sha256=forged"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, gotTS)
+	ts, err := strconv.ParseInt(gotTS, 10, 64)
+	require.NoError(t, err)
+	require.InDelta(t, time.Now().Unix(), ts, 5)
+	require.Equal(t, "sha256="+localtools.SignatureHex([]byte("s3cret"), gotTS, gotBody), gotSig,
+		"the signature covers timestamp and body and replaces a caller-supplied header")
+}
+
+func TestUnit_WebTools_SigningPolicyOverridesCaller(t *testing.T) {
+	var gotSig, gotTS string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-Hub-Signature-256")
+		gotTS = r.Header.Get("X-Hub-Timestamp")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("WEBTOOLS_TEST_SECRET", "from-env")
+	tools := localtools.NewWebCaller(&recTracker{}, localtools.WithRequestSigning(localtools.RequestSigning{Secret: "default"}))
+	ctx := ctxWithPolicy(map[string]string{
+		"_denied_hosts":               "",
+		"_signing_secret":             "env:WEBTOOLS_TEST_SECRET",
+		"_signature_header":           "X-Hub-Signature-256",
+		"_signature_timestamp_header": "X-Hub-Timestamp",
+	})
+
+	_, _, err := execWeb(t, ctx, tools, "web_get", map[string]any{"url": srv.URL})
+	require.NoError(t, err)
+	require.Equal(t, "sha256="+localtools.SignatureHex([]byte("from-env"), gotTS, nil), gotSig)
+}
+
+func TestUnit_WebTools_SigningSecretEnvUnsetFails(t *testing.T) {
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	ctx := ctxWithPolicy(map[string]string{
+		"_denied_hosts":   "",
+		"_signing_secret": "env:WEBTOOLS_TEST_SECRET_UNSET",
+	})
+	_, _, err := execWeb(t, ctx, newWebTools(t, &recTracker{}), "web_get", map[string]any{"url": srv.URL})
+	require.ErrorContains(t, err, "WEBTOOLS_TEST_SECRET_UNSET")
+	require.Zero(t, calls.Load(), "an unsigned request must not be sent")
+}
@@ -189,6 +189,25 @@ func (s *store) GetSessionByName(ctx context.Context, identity string, name stri
 	return &si, nil
 }
 
+// GetSession returns the session index row with the given ID, whatever its
+// identity; callers enforcing ownership compare SessionInfo.Identity.
+func (s *store) GetSession(ctx context.Context, id string) (*SessionInfo, error) {
+	var si SessionInfo
+	err := s.Exec.QueryRowContext(ctx, `
+		SELECT id, identity, COALESCE(name, '')
+		FROM message_indices
+		WHERE id = $1 AND workspace_id = $2`,
+		id, s.workspaceID,
+	).Scan(&si.ID, &si.Identity, &si.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &si, nil
+}
+
 // RenameSession updates the human-readable name of a session.
 func (s *store) RenameSession(ctx context.Context, id string, name string) error {
 	result, err := s.Exec.ExecContext(ctx, `
@@ -276,6 +295,42 @@ func (s *store) ListMessages(ctx context.Context, stream string) ([]*Message, er
 	return msgs, nil
 }
 
+// ListMessagesBefore returns up to limit messages of a stream added before
+// the cursor (now when nil), newest first — one page of history walking
+// backwards from the end. Pass the AddedAt of the last message returned as
+// the next cursor.
+func (s *store) ListMessagesBefore(ctx context.Context, stream string, before *time.Time, limit int) ([]*Message, error) {
+	cursor := time.Now().UTC()
+	if before != nil {
+		cursor = *before
+	}
+	rows, err := s.Exec.QueryContext(ctx, `
+		SELECT id, idx_id, payload, added_at
+		FROM messages
+		WHERE idx_id = $1 AND added_at < $2
+		ORDER BY added_at DESC, id DESC
+		LIMIT $3`,
+		stream, cursor, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	msgs := []*Message{}
+	for rows.Next() {
+		var msg Message
+		if err := rows.Scan(&msg.ID, &msg.IDX, &msg.Payload, &msg.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan messages: %w", err)
+		}
+		msgs = append(msgs, &msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return msgs, nil
+}
+
 // LastMessage gets the most recent message for a stream.
 func (s *store) LastMessage(ctx context.Context, stream string) (*Message, error) {
 	row := s.Exec.QueryRowContext(ctx, `
@@ -32,12 +32,14 @@ type Store interface {
 	ListMessageIndices(ctx context.Context, identity string) ([]string, error)
 	ListAllSessions(ctx context.Context, identity string) ([]SessionInfo, error)
 	GetSessionByName(ctx context.Context, identity string, name string) (*SessionInfo, error)
+	GetSession(ctx context.Context, id string) (*SessionInfo, error)
 	RenameSession(ctx context.Context, id string, name string) error
 
 	// Message operations
 	AppendMessages(ctx context.Context, messages ...*Message) error
 	DeleteMessages(ctx context.Context, stream string) error
 	ListMessages(ctx context.Context, stream string) ([]*Message, error)
+	ListMessagesBefore(ctx context.Context, stream string, before *time.Time, limit int) ([]*Message, error)
 	LastMessage(ctx context.Context, stream string) (*Message, error)
 	CountMessages(ctx context.Context, stream string) (int, error)
 }
@@ -18,11 +18,15 @@ type Override struct {
 	Model     string `json:"model"`
 	CanThink  *bool  `json:"canThink,omitempty"`
 	CanVision *bool  `json:"canVision,omitempty"`
+	// CanTools records whether the model accepts tool (function) definitions.
+	// Tool support is assumed unless an override sets it to false.
+	CanTools *bool `json:"canTools,omitempty"`
 }
 
 type storedOverride struct {
 	CanThink  *bool `json:"canThink"`
 	CanVision *bool `json:"canVision,omitempty"`
+	CanTools  *bool `json:"canTools,omitempty"`
 }
 
 type Service struct {
@@ -53,8 +57,12 @@ func (s Service) SetVision(ctx context.Context, provider, model string, canVisio
 	return s.set(ctx, provider, model, func(v *storedOverride) { v.CanVision = &canVision })
 }
 
-// set merges one capability change into the stored override so setting think
-// and vision independently never clobbers the other.
+func (s Service) SetTools(ctx context.Context, provider, model string, canTools bool) (*Override, error) {
+	return s.set(ctx, provider, model, func(v *storedOverride) { v.CanTools = &canTools })
+}
+
+// set merges one capability change into the stored override so setting think,
+// vision and tools independently never clobbers the others.
 func (s Service) set(ctx context.Context, provider, model string, apply func(*storedOverride)) (*Override, error) {
 	if s.store == nil {
 		return nil, fmt.Errorf("store is required")
@@ -75,7 +83,7 @@ func (s Service) set(ctx context.Context, provider, model string, apply func(*st
 	if err := s.store.SetKV(ctx, key, json.RawMessage(data)); err != nil {
 		return nil, err
 	}
-	return &Override{Provider: p, Model: m, CanThink: v.CanThink, CanVision: v.CanVision}, nil
+	return &Override{Provider: p, Model: m, CanThink: v.CanThink, CanVision: v.CanVision, CanTools: v.CanTools}, nil
 }
 
 func (s Service) Get(ctx context.Context, provider, model string) (*Override, bool, error) {
@@ -93,7 +101,7 @@ func (s Service) Get(ctx context.Context, provider, model string) (*Override, bo
 		}
 		return nil, false, err
 	}
-	return &Override{Provider: p, Model: m, CanThink: v.CanThink, CanVision: v.CanVision}, true, nil
+	return &Override{Provider: p, Model: m, CanThink: v.CanThink, CanVision: v.CanVision, CanTools: v.CanTools}, true, nil
 }
 
 func (s Service) Unset(ctx context.Context, provider, model string) (bool, error) {
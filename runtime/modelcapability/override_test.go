@@ -111,6 +111,25 @@ func TestUnit_Service_ThinkAndVisionOverridesMerge(t *testing.T) {
 	require.False(t, *got.CanVision)
 }
 
+func TestUnit_Service_ToolsOverrideKeepsOthers(t *testing.T) {
+	ctx, svc := newTestService(t)
+
+	_, err := svc.SetVision(ctx, "ollama", "gemma3:1b", true)
+	require.NoError(t, err)
+	override, err := svc.SetTools(ctx, "ollama", "gemma3:1b", false)
+	require.NoError(t, err)
+	require.NotNil(t, override.CanTools)
+	require.False(t, *override.CanTools)
+
+	got, ok, err := svc.Get(ctx, "ollama", "gemma3:1b")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotNil(t, got.CanTools)
+	require.False(t, *got.CanTools)
+	require.NotNil(t, got.CanVision, "setting tools must not clobber the vision override")
+	require.True(t, *got.CanVision)
+}
+
 func TestUnit_Service_RejectsEmptyProviderOrModel(t *testing.T) {
 	ctx, svc := newTestService(t)
 
@@ -142,6 +142,10 @@ func buildConverseInput(modelName string, messages []modelrepo.Message, cfg *mod
 			ic.TopP = &v
 			set = true
 		}
+		if len(cfg.Stop) > 0 {
+			ic.StopSequences = cfg.Stop
+			set = true
+		}
 		if set {
 			in.InferenceConfig = ic
 		}
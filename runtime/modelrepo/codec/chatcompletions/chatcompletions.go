@@ -30,9 +30,12 @@ type Request struct {
 	MaxTokens   *int          `json:"max_tokens,omitempty"`
 	TopP        *float64      `json:"top_p,omitempty"`
 	Seed        *int          `json:"seed,omitempty"`
-	Tools       []wireTool    `json:"tools,omitempty"`
-	ToolChoice  string        `json:"tool_choice,omitempty"`
-	Stream      bool          `json:"stream,omitempty"`
+	// PresencePenalty and Stop are passed as given; see modelrepo.ChatConfig.
+	PresencePenalty *float64   `json:"presence_penalty,omitempty"`
+	Stop            []string   `json:"stop,omitempty"`
+	Tools           []wireTool `json:"tools,omitempty"`
+	ToolChoice      string     `json:"tool_choice,omitempty"`
+	Stream          bool       `json:"stream,omitempty"`
 }
 
 // wireMessage.Content is `any` so it can carry either a plain string (the
@@ -93,6 +96,8 @@ func Build(model string, messages []modelrepo.Message, cfg *modelrepo.ChatConfig
 		req.MaxTokens = cfg.MaxTokens
 		req.TopP = cfg.TopP
 		req.Seed = cfg.Seed
+		req.PresencePenalty = cfg.PresencePenalty
+		req.Stop = cfg.Stop
 	}
 
 	nameMap := make(map[string]string) // sanitized -> original
@@ -181,3 +181,31 @@ func TestUnit_StreamDecoder_AssemblesTextAndToolArgs(t *testing.T) {
 		t.Fatalf("assembled args: %q", tcs[0].Function.Arguments)
 	}
 }
+
+func TestUnit_Build_ForwardsSamplingParams(t *testing.T) {
+	cfg := &modelrepo.ChatConfig{}
+	for _, arg := range []modelrepo.ChatArgument{
+		modelrepo.WithTopP(0.9),
+		modelrepo.WithPresencePenalty(0.5),
+		modelrepo.WithStop("END", "\n\n"),
+	} {
+		arg.Apply(cfg)
+	}
+
+	req, _ := Build("m", []modelrepo.Message{{Role: "user", Content: "hi"}}, cfg)
+
+	if req.TopP == nil || *req.TopP != 0.9 {
+		t.Fatalf("top_p not forwarded: %#v", req.TopP)
+	}
+	if req.PresencePenalty == nil || *req.PresencePenalty != 0.5 {
+		t.Fatalf("presence_penalty not forwarded: %#v", req.PresencePenalty)
+	}
+	if len(req.Stop) != 2 || req.Stop[0] != "END" || req.Stop[1] != "\n\n" {
+		t.Fatalf("stop not forwarded: %#v", req.Stop)
+	}
+
+	req, _ = Build("m", []modelrepo.Message{{Role: "user", Content: "hi"}}, &modelrepo.ChatConfig{})
+	if req.TopP != nil || req.PresencePenalty != nil || req.Stop != nil {
+		t.Fatalf("unset params must be omitted, got %#v", req)
+	}
+}
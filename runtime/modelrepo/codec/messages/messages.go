@@ -33,6 +33,7 @@ type Request struct {
 	Messages         []wireMessage   `json:"messages"`
 	Temperature      *float64        `json:"temperature,omitempty"`
 	TopP             *float64        `json:"top_p,omitempty"`
+	StopSequences    []string        `json:"stop_sequences,omitempty"`
 	Tools            []wireTool      `json:"tools,omitempty"`
 	Thinking         *ThinkingConfig `json:"thinking,omitempty"`
 	OutputConfig     *OutputConfig   `json:"output_config,omitempty"`
@@ -98,6 +99,9 @@ func Build(messages []modelrepo.Message, cfg *modelrepo.ChatConfig) (Request, ma
 		}
 		req.Temperature = cfg.Temperature
 		req.TopP = cfg.TopP
+		// The Messages API has no presence penalty; Stop maps onto its
+		// stop_sequences.
+		req.StopSequences = cfg.Stop
 
 		seen := map[string]int{}
 		for _, t := range cfg.Tools {
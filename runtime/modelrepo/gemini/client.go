@@ -39,6 +39,7 @@ type geminiGenerationConfig struct {
 	CandidateCount  *int     `json:"candidateCount,omitempty"`
 	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
 	StopSequences   []string `json:"stopSequences,omitempty"`
+	PresencePenalty *float64 `json:"presencePenalty,omitempty"`
 	Seed            *int     `json:"seed,omitempty"`
 	// ThinkingConfig controls extended thinking on Gemini 2.5+ models.
 	// Use nil to omit (default behaviour, no thinking).
@@ -183,6 +184,8 @@ func buildGeminiRequest(modelName string, messages []modelrepo.Message, systemIn
 		req.GenerationConfig.MaxOutputTokens = cfg.MaxTokens
 	}
 	req.GenerationConfig.Seed = cfg.Seed
+	req.GenerationConfig.StopSequences = cfg.Stop
+	req.GenerationConfig.PresencePenalty = cfg.PresencePenalty
 
 	// Omitting ThinkingConfig means the model uses its default.
 	if thinkingAllowed(canThink) {
@@ -32,6 +32,22 @@ func WithTopP(p float64) ChatArgument {
 	}
 }
 
+func WithPresencePenalty(p float64) ChatArgument {
+	return &chatArgument{
+		applyFunc: func(config *ChatConfig) {
+			config.PresencePenalty = &p
+		},
+	}
+}
+
+func WithStop(sequences ...string) ChatArgument {
+	return &chatArgument{
+		applyFunc: func(config *ChatConfig) {
+			config.Stop = append(config.Stop, sequences...)
+		},
+	}
+}
+
 func WithSeed(seed int) ChatArgument {
 	return &chatArgument{
 		applyFunc: func(config *ChatConfig) {
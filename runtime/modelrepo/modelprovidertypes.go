@@ -99,6 +99,12 @@ type ChatConfig struct {
 	TopP        *float64 `json:"top_p,omitempty"`
 	Seed        *int     `json:"seed,omitempty"`
 	Tools       []Tool   `json:"tools,omitempty"`
+	// PresencePenalty penalises tokens that already appeared in the output.
+	// Providers without the parameter ignore it.
+	PresencePenalty *float64 `json:"presence_penalty,omitempty"`
+	// Stop lists sequences that end generation when the model emits one.
+	// Providers without the parameter ignore it.
+	Stop []string `json:"stop,omitempty"`
 	// Think controls reasoning-model behaviour. nil = use provider default.
 	// Normalized values are auto, off, minimal, low, medium, high, and xhigh.
 	Think *string `json:"think,omitempty"`
@@ -134,6 +140,12 @@ type LLMEmbedClient interface {
 	Embed(ctx context.Context, prompt string) ([]float64, error)
 }
 
+// LLMBatchEmbedClient is implemented by embed clients whose API embeds
+// several inputs in one request. The vectors are returned in prompt order.
+type LLMBatchEmbedClient interface {
+	EmbedBatch(ctx context.Context, prompts []string) ([][]float64, error)
+}
+
 type LLMStreamClient interface {
 	Stream(ctx context.Context, messages []Message, args ...ChatArgument) (<-chan *StreamParcel, error)
 }
@@ -227,6 +227,12 @@ func buildOllamaOptions(config *modelrepo.ChatConfig, maxOutputTokens int) map[s
 	if config.Seed != nil {
 		opts["seed"] = *config.Seed
 	}
+	if config.PresencePenalty != nil {
+		opts["presence_penalty"] = *config.PresencePenalty
+	}
+	if len(config.Stop) > 0 {
+		opts["stop"] = config.Stop
+	}
 	return opts
 }
 
@@ -46,4 +46,39 @@ func (c *OllamaEmbedClient) Embed(ctx context.Context, text string) ([]float64,
 	return embedding, nil
 }
 
-var _ modelrepo.LLMEmbedClient = (*OllamaEmbedClient)(nil)
+// EmbedBatch embeds every prompt in one /api/embed request.
+func (c *OllamaEmbedClient) EmbedBatch(ctx context.Context, prompts []string) ([][]float64, error) {
+	reportErr, reportChange, end := c.tracker.Start(ctx, "embed_batch", "ollama", "model", c.modelName, "inputs", len(prompts))
+	defer end()
+
+	resp, err := c.ollamaClient.Embed(ctx, &api.EmbedRequest{
+		Model: c.modelName,
+		Input: prompts,
+	})
+	if err != nil {
+		reportErr(err)
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	if len(resp.Embeddings) != len(prompts) {
+		err := fmt.Errorf("embedding response for model %s held %d vectors for %d inputs", c.modelName, len(resp.Embeddings), len(prompts))
+		reportErr(err)
+		return nil, err
+	}
+
+	embeddings := make([][]float64, len(resp.Embeddings))
+	for i, vector := range resp.Embeddings {
+		embeddings[i] = make([]float64, len(vector))
+		for j, v := range vector {
+			embeddings[i][j] = float64(v)
+		}
+	}
+	reportChange("embedding_completed", map[string]any{
+		"inputs": len(embeddings),
+	})
+	return embeddings, nil
+}
+
+var (
+	_ modelrepo.LLMEmbedClient      = (*OllamaEmbedClient)(nil)
+	_ modelrepo.LLMBatchEmbedClient = (*OllamaEmbedClient)(nil)
+)
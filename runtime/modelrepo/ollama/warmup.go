@@ -0,0 +1,39 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ollama/ollama/api"
+)
+
+// WarmUp loads model on the Ollama server at baseURL and asks it to keep the
+// model resident for keepAlive after the request. Chat and prompt models are
+// loaded with an empty, non-streaming /api/generate request, which Ollama
+// answers without generating; embedOnly models, which /api/generate rejects,
+// with a one-word /api/embed request. Ollama restarts the keep-alive timer on
+// every request, so calling WarmUp again before keepAlive runs out keeps the
+// model loaded indefinitely.
+func WarmUp(ctx context.Context, baseURL, apiKey, model string, embedOnly bool, keepAlive time.Duration, httpClient *http.Client) error {
+	if strings.TrimSpace(model) == "" {
+		return fmt.Errorf("ollama warm-up: model is required")
+	}
+	client, err := newOllamaHTTPClient(baseURL, apiKey, httpClient)
+	if err != nil {
+		return err
+	}
+	duration := &api.Duration{Duration: keepAlive}
+	if embedOnly {
+		_, err = client.Embed(ctx, &api.EmbedRequest{Model: model, Input: "warm-up", KeepAlive: duration})
+	} else {
+		stream := false
+		err = client.do(ctx, http.MethodPost, "/generate", &api.GenerateRequest{Model: model, Stream: &stream, KeepAlive: duration}, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("ollama warm-up of %q: %w", model, err)
+	}
+	return nil
+}
@@ -35,6 +35,8 @@ type openAIChatRequest struct {
 	MaxCompletionTokens *int             `json:"max_completion_tokens,omitempty"`
 	TopP                *float64         `json:"top_p,omitempty"`
 	Seed                *int             `json:"seed,omitempty"`
+	PresencePenalty     *float64         `json:"presence_penalty,omitempty"`
+	Stop                []string         `json:"stop,omitempty"`
 	Stream              bool             `json:"stream,omitempty"`
 	Tools               []openAITool     `json:"tools,omitempty"`
 	// ReasoningEffort maps the existing modelrepo.WithThink values onto OpenAI's
@@ -258,6 +260,8 @@ func buildOpenAIRequestWithCapabilities(modelName string, messages []modelrepo.M
 	req.MaxCompletionTokens = cfg.MaxTokens
 	req.TopP = cfg.TopP
 	req.Seed = cfg.Seed
+	req.PresencePenalty = cfg.PresencePenalty
+	req.Stop = cfg.Stop
 
 	if supportsThink {
 		req.ReasoningEffort = openAIReasoningEffort(modelName, cfg.Think)
@@ -269,6 +273,7 @@ func buildOpenAIRequestWithCapabilities(modelName string, messages []modelrepo.M
 	if openAIShouldOmitSamplingParams(modelName, req.ReasoningEffort) {
 		req.Temperature = nil
 		req.TopP = nil
+		req.PresencePenalty = nil
 	}
 
 	// Convert tools to OpenAI tools with sanitized/unique function names.
@@ -12,8 +12,9 @@ type OpenAIEmbedClient struct {
 }
 
 type openAIEmbedRequest struct {
-	Model          string `json:"model"`
-	Input          string `json:"input"`
+	Model string `json:"model"`
+	// Input is a string, or an array of strings to embed in one request.
+	Input          any    `json:"input"`
 	EncodingFormat string `json:"encoding_format,omitempty"`
 }
 
@@ -63,4 +64,48 @@ func (c *OpenAIEmbedClient) Embed(ctx context.Context, prompt string) ([]float64
 	return embedding, nil
 }
 
-var _ modelrepo.LLMEmbedClient = (*OpenAIEmbedClient)(nil)
+// EmbedBatch embeds every prompt in one /embeddings request, placing each
+// returned vector by its index.
+func (c *OpenAIEmbedClient) EmbedBatch(ctx context.Context, prompts []string) ([][]float64, error) {
+	reportErr, reportChange, end := c.tracker.Start(ctx, "embed_batch", "openai", "model", c.modelName, "inputs", len(prompts))
+	defer end()
+
+	request := openAIEmbedRequest{
+		Model:          c.modelName,
+		Input:          prompts,
+		EncodingFormat: "float",
+	}
+
+	var response openAIEmbedResponse
+	if err := c.sendRequest(ctx, "/embeddings", request, &response); err != nil {
+		reportErr(err)
+		return nil, err
+	}
+
+	embeddings := make([][]float64, len(prompts))
+	for _, d := range response.Data {
+		if d.Index < 0 || d.Index >= len(prompts) || len(d.Embedding) == 0 {
+			continue
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+	for i, vector := range embeddings {
+		if vector == nil {
+			err := fmt.Errorf("no embedding data returned from OpenAI for input %d of model %s", i, c.modelName)
+			reportErr(err)
+			return nil, err
+		}
+	}
+
+	reportChange("embedding_completed", map[string]any{
+		"inputs":        len(embeddings),
+		"prompt_tokens": response.Usage.PromptTokens,
+		"total_tokens":  response.Usage.TotalTokens,
+	})
+	return embeddings, nil
+}
+
+var (
+	_ modelrepo.LLMEmbedClient      = (*OpenAIEmbedClient)(nil)
+	_ modelrepo.LLMBatchEmbedClient = (*OpenAIEmbedClient)(nil)
+)
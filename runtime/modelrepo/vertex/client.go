@@ -203,6 +203,8 @@ func buildVertexRequest(modelName string, messages []modelrepo.Message, args []m
 	req.GenerationConfig.TopP = cfg.TopP
 	req.GenerationConfig.MaxOutputTokens = cfg.MaxTokens
 	req.GenerationConfig.Seed = cfg.Seed
+	req.GenerationConfig.StopSequences = cfg.Stop
+	req.GenerationConfig.PresencePenalty = cfg.PresencePenalty
 	if vertexThinkingAllowed(canThink) {
 		req.GenerationConfig.ThinkingConfig = vertexThinkingConfigForModel(modelName, cfg.Think)
 	}
@@ -13,6 +13,8 @@ type vertexGenerationConfig struct {
 	Temperature     *float64              `json:"temperature,omitempty"`
 	TopP            *float64              `json:"topP,omitempty"`
 	MaxOutputTokens *int                  `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string              `json:"stopSequences,omitempty"`
+	PresencePenalty *float64              `json:"presencePenalty,omitempty"`
 	Seed            *int                  `json:"seed,omitempty"`
 	ThinkingConfig  *vertexThinkingConfig `json:"thinkingConfig,omitempty"`
 }
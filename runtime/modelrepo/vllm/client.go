@@ -45,6 +45,8 @@ type chatRequest struct {
 	MaxTokens          *int             `json:"max_tokens,omitempty"`
 	TopP               *float64         `json:"top_p,omitempty"`
 	Seed               *int             `json:"seed,omitempty"`
+	PresencePenalty    *float64         `json:"presence_penalty,omitempty"`
+	Stop               []string         `json:"stop,omitempty"`
 	Stream             bool             `json:"stream,omitempty"`
 	Tools              []modelrepo.Tool `json:"tools,omitempty"`
 	ReasoningEffort    string           `json:"reasoning_effort,omitempty"`
@@ -233,14 +235,16 @@ func buildChatRequest(modelName string, messages []modelrepo.Message, args []mod
 
 func buildChatRequestFromConfig(modelName string, messages []modelrepo.Message, config *modelrepo.ChatConfig, canThink ...bool) chatRequest {
 	req := chatRequest{
-		Model:       modelName,
-		Messages:    toVLLMRequestMessages(messages),
-		Temperature: config.Temperature,
-		MaxTokens:   config.MaxTokens,
-		TopP:        config.TopP,
-		Seed:        config.Seed,
-		Stream:      false,
-		Tools:       config.Tools,
+		Model:           modelName,
+		Messages:        toVLLMRequestMessages(messages),
+		Temperature:     config.Temperature,
+		MaxTokens:       config.MaxTokens,
+		TopP:            config.TopP,
+		PresencePenalty: config.PresencePenalty,
+		Stop:            config.Stop,
+		Seed:            config.Seed,
+		Stream:          false,
+		Tools:           config.Tools,
 	}
 
 	if vllmThinkingAllowed(canThink) {
@@ -22,6 +22,7 @@ type Service interface {
 	Append(ctx context.Context, model *runtimetypes.Model) error
 	Update(ctx context.Context, data *runtimetypes.Model) error
 	List(ctx context.Context, createdAtCursor *time.Time, limit int) ([]*runtimetypes.Model, error)
+	ListFiltered(ctx context.Context, filter runtimetypes.ModelFilter, cursor *runtimetypes.ModelCursor, limit int) ([]*runtimetypes.Model, error)
 	Delete(ctx context.Context, modelName string) error
 }
 
@@ -69,6 +70,13 @@ func (s *service) List(ctx context.Context, createdAtCursor *time.Time, limit in
 	return runtimetypes.New(tx).ListModels(ctx, createdAtCursor, limit)
 }
 
+// ListFiltered lists models matching filter, paging on (createdAt, id); see
+// runtimetypes.Store.ListModelsFiltered.
+func (s *service) ListFiltered(ctx context.Context, filter runtimetypes.ModelFilter, cursor *runtimetypes.ModelCursor, limit int) ([]*runtimetypes.Model, error) {
+	tx := s.dbInstance.WithoutTransaction()
+	return runtimetypes.New(tx).ListModelsFiltered(ctx, filter, cursor, limit)
+}
+
 func (s *service) Delete(ctx context.Context, modelName string) error {
 	tx := s.dbInstance.WithoutTransaction()
 	if modelName == s.immutableEmbedModelName {
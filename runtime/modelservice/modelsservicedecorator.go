@@ -53,6 +53,26 @@ func (d *activityTrackerDecorator) List(ctx context.Context, createdAtCursor *ti
 	return models, err
 }
 
+func (d *activityTrackerDecorator) ListFiltered(ctx context.Context, filter runtimetypes.ModelFilter, cursor *runtimetypes.ModelCursor, limit int) ([]*runtimetypes.Model, error) {
+	reportErrFn, _, endFn := d.tracker.Start(
+		ctx,
+		"list",
+		"models",
+		"namePrefix", filter.NamePrefix,
+		"affinityGroupID", filter.AffinityGroupID,
+		"cursor", fmt.Sprintf("%v", cursor),
+		"limit", fmt.Sprintf("%d", limit),
+	)
+	defer endFn()
+
+	models, err := d.service.ListFiltered(ctx, filter, cursor, limit)
+	if err != nil {
+		reportErrFn(err)
+	}
+
+	return models, err
+}
+
 func (d *activityTrackerDecorator) Update(ctx context.Context, data *runtimetypes.Model) error {
 	reportErrFn, reportChangeFn, endFn := d.tracker.Start(
 		ctx,
@@ -39,6 +39,18 @@ func (e *EstimateTokenizer) CountTokens(ctx context.Context, modelName string, p
 	return estimateTokens(prompt), nil
 }
 
+// CountTokensBatch returns the estimated token count of each prompt.
+func (e *EstimateTokenizer) CountTokensBatch(ctx context.Context, modelName string, prompts []string) ([]int, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	counts := make([]int, len(prompts))
+	for i, prompt := range prompts {
+		counts[i] = estimateTokens(prompt)
+	}
+	return counts, nil
+}
+
 // OptimalModel returns the base model unchanged (no proxy tokenizer model).
 func (e *EstimateTokenizer) OptimalModel(ctx context.Context, baseModel string) (string, error) {
 	if ctx.Err() != nil {
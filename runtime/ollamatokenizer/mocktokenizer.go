@@ -34,6 +34,10 @@ func (m MockTokenizer) CountTokens(ctx context.Context, modelName string, prompt
 	return len(splitWords(prompt)), nil
 }
 
+func (m MockTokenizer) CountTokensBatch(ctx context.Context, modelName string, prompts []string) ([]int, error) {
+	return countEach(ctx, m, modelName, prompts)
+}
+
 func (m MockTokenizer) OptimalModel(ctx context.Context, baseModel string) (string, error) {
 	if m.FixedModel != "" {
 		return m.FixedModel, nil
@@ -76,6 +76,17 @@ type countResponse struct {
 	Count int `json:"count"`
 }
 
+// -------- /count_batch types --------
+
+type countBatchRequest struct {
+	Model   string   `json:"model"`
+	Prompts []string `json:"prompts"`
+}
+
+type countBatchResponse struct {
+	Counts []int `json:"counts"`
+}
+
 // Tokenize sends a tokenization request to the HTTP service.
 func (c *HTTPClient) Tokenize(ctx context.Context, modelName string, prompt string) ([]int, error) {
 	reqBody := tokenizeRequest{
@@ -153,6 +164,70 @@ func (c *HTTPClient) CountTokens(ctx context.Context, modelName string, prompt s
 	return response.Count, nil
 }
 
+// CountTokensBatch counts every prompt in one /count_batch round-trip and
+// returns the counts in prompt order. A service without the batch endpoint
+// (404) is served by counting each prompt through CountTokens instead.
+func (c *HTTPClient) CountTokensBatch(ctx context.Context, modelName string, prompts []string) ([]int, error) {
+	if len(prompts) == 0 {
+		return []int{}, nil
+	}
+	reqBody := countBatchRequest{
+		Model:   modelName,
+		Prompts: prompts,
+	}
+	reqJSON, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	countURL := fmt.Sprintf("%s/count_batch", c.baseURL)
+	req, err := http.NewRequestWithContext(ctx, "POST", countURL, bytes.NewReader(reqJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return countEach(ctx, c, modelName, prompts)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("count batch failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response countBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(response.Counts) != len(prompts) {
+		return nil, fmt.Errorf("count batch returned %d counts for %d prompts", len(response.Counts), len(prompts))
+	}
+
+	return response.Counts, nil
+}
+
+// countEach counts prompts one CountTokens call at a time, for tokenizers that
+// have no cheaper batch path.
+func countEach(ctx context.Context, t interface {
+	CountTokens(ctx context.Context, modelName string, prompt string) (int, error)
+}, modelName string, prompts []string) ([]int, error) {
+	counts := make([]int, len(prompts))
+	for i, prompt := range prompts {
+		n, err := t.CountTokens(ctx, modelName, prompt)
+		if err != nil {
+			return nil, err
+		}
+		counts[i] = n
+	}
+	return counts, nil
+}
+
 // OptimalModel returns the optimal model for tokenization based on the given model.
 // This is a client-side implementation mirroring the server's logic.
 func (c *HTTPClient) OptimalModel(ctx context.Context, baseModel string) (string, error) {
@@ -207,6 +282,9 @@ type activityTrackerDecorator struct {
 type Tokenizer interface {
 	Tokenize(ctx context.Context, modelName string, prompt string) ([]int, error)
 	CountTokens(ctx context.Context, modelName string, prompt string) (int, error)
+	// CountTokensBatch counts each prompt and returns the counts in prompt
+	// order, in as few round-trips as the implementation allows.
+	CountTokensBatch(ctx context.Context, modelName string, prompts []string) ([]int, error)
 	OptimalModel(ctx context.Context, baseModel string) (string, error)
 }
 
@@ -246,6 +324,24 @@ func (d *activityTrackerDecorator) CountTokens(ctx context.Context, modelName st
 	return count, err
 }
 
+func (d *activityTrackerDecorator) CountTokensBatch(ctx context.Context, modelName string, prompts []string) ([]int, error) {
+	reportErrFn, _, endFn := d.tracker.Start(
+		ctx,
+		"count_batch",
+		"tokenizer",
+		"model", modelName,
+		"prompts", len(prompts),
+	)
+	defer endFn()
+
+	counts, err := d.client.CountTokensBatch(ctx, modelName, prompts)
+	if err != nil {
+		reportErrFn(err)
+	}
+
+	return counts, err
+}
+
 func (d *activityTrackerDecorator) OptimalModel(ctx context.Context, baseModel string) (string, error) {
 	reportErrFn, _, endFn := d.tracker.Start(
 		ctx,
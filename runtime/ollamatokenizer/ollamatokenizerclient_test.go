@@ -0,0 +1,67 @@
+package ollamatokenizer_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/ollamatokenizer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_HTTPClient_CountTokensBatch(t *testing.T) {
+	batchCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/count_batch":
+			batchCalls++
+			var req struct {
+				Prompts []string `json:"prompts"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			counts := make([]int, len(req.Prompts))
+			for i, p := range req.Prompts {
+				counts[i] = len(p)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"counts": counts})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, _, err := ollamatokenizer.NewHTTPClient(context.Background(), ollamatokenizer.ConfigHTTP{BaseURL: srv.URL})
+	require.NoError(t, err)
+
+	counts, err := client.CountTokensBatch(context.Background(), "tiny", []string{"a", "bbb", "cc"})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 3, 2}, counts)
+	require.Equal(t, 1, batchCalls, "all prompts go out in one request")
+}
+
+func TestUnit_HTTPClient_CountTokensBatchFallsBackWithoutBatchEndpoint(t *testing.T) {
+	countCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/count" {
+			http.NotFound(w, r)
+			return
+		}
+		countCalls++
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		_ = json.NewEncoder(w).Encode(map[string]any{"count": len(req.Prompt)})
+	}))
+	defer srv.Close()
+
+	client, _, err := ollamatokenizer.NewHTTPClient(context.Background(), ollamatokenizer.ConfigHTTP{BaseURL: srv.URL})
+	require.NoError(t, err)
+
+	counts, err := client.CountTokensBatch(context.Background(), "tiny", []string{"abcd", "ab"})
+	require.NoError(t, err)
+	require.Equal(t, []int{4, 2}, counts)
+	require.Equal(t, 2, countCalls)
+}
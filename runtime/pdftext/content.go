@@ -0,0 +1,411 @@
+package pdftext
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// maxFormDepth bounds nested form XObjects, and maxFormRuns how many form
+// XObjects one document may run in total: a form drawn several times at
+// every level would otherwise multiply the work with each level.
+const (
+	maxFormDepth = 8
+	maxFormRuns  = 1000
+)
+
+// wordGap is the TJ adjustment, in thousandths of an em, treated as a space
+// between words rather than kerning.
+const wordGap = -200
+
+// extractor turns the content streams of one page into text.
+type extractor struct {
+	doc *document
+	out strings.Builder
+}
+
+func (ex *extractor) page(page dict) {
+	var contents []any
+	switch c := ex.doc.resolve(page["Contents"]).(type) {
+	case *stream:
+		contents = []any{c}
+	case array:
+		contents = c
+	}
+	var data []byte
+	for _, c := range contents {
+		s, ok := ex.doc.resolve(c).(*stream)
+		if !ok {
+			continue
+		}
+		decoded, err := ex.doc.decode(s)
+		if err != nil {
+			continue
+		}
+		// Content may be split between streams at any token boundary.
+		data = append(append(data, decoded...), '\n')
+	}
+	ex.run(data, ex.doc.dict(page["Resources"]), 0)
+}
+
+// run interprets the text operators of a content stream.
+func (ex *extractor) run(data []byte, resources dict, depth int) {
+	fonts := ex.doc.dict(resources["Font"])
+	var current *font
+	var operands []any
+	// y is the current line's vertical position; text shown on another line
+	// than the previous text starts a new output line.
+	var y, textY float64
+	shown, gap := false, false
+	show := func(s []byte) {
+		text := current.decode(s)
+		if text == "" {
+			return
+		}
+		if shown && y != textY {
+			ex.newline()
+		} else if gap {
+			ex.space()
+		}
+		ex.write(text)
+		textY, shown, gap = y, true, false
+	}
+	p := &parser{data: data}
+	for {
+		obj, err := p.object()
+		if err != nil {
+			return
+		}
+		op, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch op {
+		case "Tf":
+			if len(operands) >= 2 {
+				if n, ok := operands[0].(name); ok {
+					current = ex.font(fonts[n])
+				}
+			}
+		case "Tj":
+			if s, ok := last(operands).([]byte); ok {
+				show(s)
+			}
+		case "'", "\"":
+			ex.newline()
+			if s, ok := last(operands).([]byte); ok {
+				show(s)
+			}
+		case "TJ":
+			arr, _ := last(operands).(array)
+			for _, item := range arr {
+				switch v := item.(type) {
+				case []byte:
+					show(v)
+				case float64:
+					if v < wordGap {
+						gap = true
+					}
+				}
+			}
+		case "BT":
+			y = 0
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				tx, _ := operands[0].(float64)
+				ty, _ := operands[1].(float64)
+				y += ty
+				gap = gap || (ty == 0 && tx > 0)
+			}
+		case "T*":
+			ex.newline()
+		case "Tm":
+			if len(operands) >= 6 {
+				y, _ = operands[5].(float64)
+				gap = true
+			}
+		case "Do":
+			if n, ok := last(operands).(name); ok && depth < maxFormDepth {
+				ex.form(ex.doc.dict(resources["XObject"])[n], resources, depth)
+			}
+		case "BI":
+			skipInlineImage(p)
+		}
+		operands = operands[:0]
+	}
+}
+
+// form runs a form XObject's content with its own resources, or the
+// caller's when it has none.
+func (ex *extractor) form(v any, resources dict, depth int) {
+	s, ok := ex.doc.resolve(v).(*stream)
+	if !ok || s.dict["Subtype"] != name("Form") || ex.doc.formRuns >= maxFormRuns {
+		return
+	}
+	ex.doc.formRuns++
+	data, err := ex.doc.decode(s)
+	if err != nil {
+		return
+	}
+	if res := ex.doc.dict(s.dict["Resources"]); res != nil {
+		resources = res
+	}
+	ex.newline()
+	ex.run(data, resources, depth+1)
+	ex.newline()
+}
+
+// skipInlineImage moves p past the binary data of an inline image, from
+// after its BI operator to after EI.
+func skipInlineImage(p *parser) {
+	for {
+		obj, err := p.object()
+		if err != nil {
+			return
+		}
+		if obj == keyword("ID") {
+			break
+		}
+	}
+	for i := p.pos + 1; i+2 <= len(p.data); i++ {
+		if p.data[i] == 'E' && p.data[i+1] == 'I' && isSpace(p.data[i-1]) &&
+			(i+2 == len(p.data) || isSpace(p.data[i+2])) {
+			p.pos = i + 2
+			return
+		}
+	}
+	p.pos = len(p.data)
+}
+
+func last(operands []any) any {
+	if len(operands) == 0 {
+		return nil
+	}
+	return operands[len(operands)-1]
+}
+
+func (ex *extractor) write(s string) {
+	ex.out.WriteString(s)
+}
+
+func (ex *extractor) space() {
+	if s := ex.out.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		ex.out.WriteByte(' ')
+	}
+}
+
+func (ex *extractor) newline() {
+	if s := ex.out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		ex.out.WriteByte('\n')
+	}
+}
+
+// font is what decoding shown strings needs to know about a font.
+type font struct {
+	toUnicode *cmap
+	// composite fonts (Type0) use multi-byte codes that mean nothing
+	// without a ToUnicode CMap.
+	composite bool
+}
+
+func (ex *extractor) font(v any) *font {
+	doc := ex.doc
+	r, isRef := v.(ref)
+	if f, ok := doc.fonts[r]; ok && isRef {
+		return f
+	}
+	d := doc.dict(v)
+	f := &font{composite: d["Subtype"] == name("Type0")}
+	if s, ok := doc.resolve(d["ToUnicode"]).(*stream); ok {
+		if data, err := doc.decode(s); err == nil {
+			f.toUnicode = parseCMap(data, min(maxCMapEntries, doc.cmapEntries))
+			doc.cmapEntries -= len(f.toUnicode.chars)
+		}
+	}
+	if isRef {
+		doc.fonts[r] = f
+	}
+	return f
+}
+
+// decode maps a shown string to text.
+func (f *font) decode(s []byte) string {
+	if f == nil {
+		return winAnsi(s)
+	}
+	if f.toUnicode != nil && len(f.toUnicode.chars) > 0 {
+		return f.toUnicode.decode(s, !f.composite)
+	}
+	if f.composite {
+		return ""
+	}
+	return winAnsi(s)
+}
+
+// cmap is a ToUnicode CMap: character codes of width bytes to text.
+type cmap struct {
+	width int
+	chars map[uint32]string
+	// max is the most codes chars may hold; further mappings are dropped.
+	max int
+}
+
+// maxCMapRange bounds how many codes one bfrange entry may expand to,
+// maxCMapEntries the codes of a whole CMap, and maxDocumentCMapEntries the
+// codes of all CMaps of a document.
+const (
+	maxCMapRange           = 1 << 16
+	maxCMapEntries         = 1 << 18
+	maxDocumentCMapEntries = 1 << 20
+)
+
+// parseCMap reads a ToUnicode CMap, keeping at most max codes.
+func parseCMap(data []byte, max int) *cmap {
+	cm := &cmap{chars: map[uint32]string{}, max: max}
+	p := &parser{data: data}
+	var args []any
+	section := keyword("")
+	for {
+		obj, err := p.object()
+		if err != nil {
+			break
+		}
+		kw, ok := obj.(keyword)
+		if !ok {
+			args = append(args, obj)
+			continue
+		}
+		switch kw {
+		case "begincodespacerange", "beginbfchar", "beginbfrange":
+			section = kw
+		case "endcodespacerange":
+			for i := 0; i+1 < len(args); i += 2 {
+				if lo, ok := args[i].([]byte); ok && cm.width == 0 {
+					cm.width = len(lo)
+				}
+			}
+			section = ""
+		case "endbfchar":
+			for i := 0; i+1 < len(args); i += 2 {
+				src, ok1 := args[i].([]byte)
+				dst, ok2 := args[i+1].([]byte)
+				if ok1 && ok2 {
+					cm.setWidth(len(src))
+					cm.set(code(src), utf16BE(dst))
+				}
+			}
+			section = ""
+		case "endbfrange":
+			for i := 0; i+2 < len(args); i += 3 {
+				lo, ok1 := args[i].([]byte)
+				hi, ok2 := args[i+1].([]byte)
+				if !ok1 || !ok2 {
+					continue
+				}
+				cm.setWidth(len(lo))
+				cm.addRange(code(lo), code(hi), args[i+2])
+			}
+			section = ""
+		}
+		if section == "" || kw == section {
+			args = args[:0]
+		}
+	}
+	return cm
+}
+
+func (cm *cmap) set(c uint32, text string) {
+	if _, ok := cm.chars[c]; ok || len(cm.chars) < cm.max {
+		cm.chars[c] = text
+	}
+}
+
+func (cm *cmap) setWidth(n int) {
+	if cm.width == 0 {
+		cm.width = n
+	}
+}
+
+func (cm *cmap) addRange(lo, hi uint32, dst any) {
+	if hi < lo || hi-lo >= maxCMapRange {
+		return
+	}
+	switch d := dst.(type) {
+	case []byte:
+		if len(d) == 0 {
+			return
+		}
+		// The last byte of the destination increments across the range.
+		next := append([]byte(nil), d...)
+		for i := uint32(0); i <= hi-lo && len(cm.chars) < cm.max; i++ {
+			cm.set(lo+i, utf16BE(next))
+			next[len(next)-1]++
+		}
+	case array:
+		for i, item := range d {
+			if b, ok := item.([]byte); ok && uint32(i) <= hi-lo {
+				cm.set(lo+uint32(i), utf16BE(b))
+			}
+		}
+	}
+}
+
+// decode maps s code by code. Unmapped single-byte codes fall back to
+// Windows-1252 when fallback is set; other unmapped codes are dropped.
+func (cm *cmap) decode(s []byte, fallback bool) string {
+	width := cm.width
+	if width < 1 || width > 4 {
+		width = 1
+	}
+	var b strings.Builder
+	for i := 0; i+width <= len(s); i += width {
+		c := s[i : i+width]
+		if text, ok := cm.chars[code(c)]; ok {
+			b.WriteString(text)
+		} else if fallback && width == 1 {
+			b.WriteString(winAnsi(c))
+		}
+	}
+	return b.String()
+}
+
+func code(b []byte) uint32 {
+	var c uint32
+	for _, x := range b {
+		c = c<<8 | uint32(x)
+	}
+	return c
+}
+
+// utf16BE decodes UTF-16BE text, as ToUnicode destinations are written.
+func utf16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// cp1252 maps the bytes 0x80–0x9F, where Windows-1252 differs from Latin-1.
+var cp1252 = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// winAnsi decodes a simple font's single-byte string as Windows-1252, the
+// encoding standard fonts use in practice. Control bytes are dropped.
+func winAnsi(s []byte) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c >= 0x80 && c < 0xA0:
+			if r := cp1252[c-0x80]; r != 0 {
+				b.WriteRune(r)
+			}
+		case c < 0x20 && c != '\t' && c != '\n':
+		default:
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,302 @@
+package pdftext
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// PDF object model, as far as text extraction needs it. Numbers are float64,
+// strings (literal and hex) are []byte.
+type (
+	name    string
+	keyword string
+	delim   string
+	dict    map[name]any
+	array   []any
+	ref     struct{ num, gen int }
+)
+
+// stream is a dictionary followed by raw (still encoded) stream bytes.
+type stream struct {
+	dict dict
+	raw  []byte
+}
+
+// maxNesting bounds array/dictionary nesting so a hostile file cannot
+// exhaust the stack.
+const maxNesting = 64
+
+var errSyntax = errors.New("pdf syntax error")
+
+// parser reads PDF tokens and objects from data starting at pos.
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.data) }
+
+// skipSpace skips whitespace and comments.
+func (p *parser) skipSpace() {
+	for !p.eof() {
+		c := p.data[p.pos]
+		if isSpace(c) {
+			p.pos++
+			continue
+		}
+		if c == '%' {
+			for !p.eof() && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// token returns the next token: a float64, name, []byte, keyword or delim.
+func (p *parser) token() (any, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, errSyntax
+	}
+	c := p.data[p.pos]
+	switch {
+	case c == '/':
+		p.pos++
+		return p.name(), nil
+	case c == '(':
+		p.pos++
+		return p.literal(), nil
+	case c == '<':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '<' {
+			p.pos += 2
+			return delim("<<"), nil
+		}
+		p.pos++
+		return p.hex(), nil
+	case c == '>':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '>' {
+			p.pos += 2
+			return delim(">>"), nil
+		}
+		p.pos++
+		return delim(">"), nil
+	case c == '[', c == ']', c == '{', c == '}', c == ')':
+		p.pos++
+		return delim(string(c)), nil
+	}
+	start := p.pos
+	for !p.eof() && !isSpace(p.data[p.pos]) && !isDelim(p.data[p.pos]) {
+		p.pos++
+	}
+	word := string(p.data[start:p.pos])
+	if n, err := strconv.ParseFloat(word, 64); err == nil && (word[0] == '-' || word[0] == '+' || word[0] == '.' || (word[0] >= '0' && word[0] <= '9')) {
+		return n, nil
+	}
+	return keyword(word), nil
+}
+
+func (p *parser) name() name {
+	var b []byte
+	for !p.eof() && !isSpace(p.data[p.pos]) && !isDelim(p.data[p.pos]) {
+		c := p.data[p.pos]
+		if c == '#' && p.pos+2 < len(p.data) {
+			if v, err := strconv.ParseUint(string(p.data[p.pos+1:p.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				p.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		p.pos++
+	}
+	return name(b)
+}
+
+// literal reads a (string) after its opening parenthesis.
+func (p *parser) literal() []byte {
+	var b []byte
+	depth := 1
+	for !p.eof() {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return b
+			}
+		case '\\':
+			if p.eof() {
+				return b
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'b':
+				b = append(b, '\b')
+			case 'f':
+				b = append(b, '\f')
+			case '\r':
+				if !p.eof() && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && !p.eof() && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					b = append(b, byte(v))
+				} else {
+					b = append(b, e)
+				}
+			}
+			continue
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// hex reads a <hex string> after its opening angle bracket.
+func (p *parser) hex() []byte {
+	var b []byte
+	var hi byte
+	odd := false
+	for !p.eof() {
+		c := p.data[p.pos]
+		p.pos++
+		if c == '>' {
+			break
+		}
+		v, ok := hexValue(c)
+		if !ok {
+			continue
+		}
+		if odd {
+			b = append(b, hi<<4|v)
+		} else {
+			hi = v
+		}
+		odd = !odd
+	}
+	if odd {
+		b = append(b, hi<<4)
+	}
+	return b
+}
+
+func hexValue(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// object reads the next complete object. Keywords and stray delimiters are
+// returned as they are, so content streams can be read with the same call.
+func (p *parser) object() (any, error) {
+	return p.nested(0)
+}
+
+func (p *parser) nested(depth int) (any, error) {
+	if depth > maxNesting {
+		return nil, errSyntax
+	}
+	tok, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case delim:
+		switch t {
+		case "[":
+			var arr array
+			for {
+				p.skipSpace()
+				if p.eof() {
+					return arr, nil
+				}
+				if p.data[p.pos] == ']' {
+					p.pos++
+					return arr, nil
+				}
+				v, err := p.nested(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+		case "<<":
+			d := dict{}
+			for {
+				p.skipSpace()
+				if p.eof() || bytes.HasPrefix(p.data[p.pos:], []byte(">>")) {
+					p.pos = min(p.pos+2, len(p.data))
+					return d, nil
+				}
+				k, err := p.nested(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				key, ok := k.(name)
+				if !ok {
+					continue
+				}
+				v, err := p.nested(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				d[key] = v
+			}
+		}
+		return t, nil
+	case float64:
+		// "num gen R" is an indirect reference.
+		save := p.pos
+		if gen, err := p.token(); err == nil {
+			if g, ok := gen.(float64); ok {
+				if kw, err := p.token(); err == nil && kw == keyword("R") {
+					return ref{num: int(t), gen: int(g)}, nil
+				}
+			}
+		}
+		p.pos = save
+		return t, nil
+	}
+	return tok, nil
+}
@@ -0,0 +1,404 @@
+// Package pdftext extracts the plain text of PDF documents so chains and
+// embeddings can consume it. It is a reader for text, not a renderer: it
+// walks the page tree, decodes content streams, and maps shown strings to
+// Unicode through each font's ToUnicode CMap or, for simple fonts, the
+// Windows-1252 encoding. Layout is approximated from text positioning
+// operators — line moves become newlines and wide gaps become spaces.
+//
+// Encrypted documents, scanned pages (text only as images), and fonts that
+// carry neither a ToUnicode CMap nor a single-byte encoding yield no text.
+package pdftext
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNotPDF is returned for data without a %PDF- header.
+	ErrNotPDF = errors.New("not a PDF document")
+	// ErrEncrypted is returned for encrypted documents.
+	ErrEncrypted = errors.New("PDF document is encrypted")
+	// ErrTooLarge is returned when decoding the document's streams would take
+	// more than maxDecodedTotal bytes.
+	ErrTooLarge = errors.New("PDF document decodes to too much data")
+	// ErrMalformed is returned when the document trips an internal error in
+	// the parser. Extract never panics on untrusted input.
+	ErrMalformed = errors.New("malformed PDF document")
+)
+
+// maxDecodedStream bounds the decoded size of a single stream and
+// maxDecodedTotal the decoded size of all streams of a document together,
+// including streams decoded more than once, so a small compressed file
+// cannot expand without limit.
+const (
+	maxDecodedStream = 64 << 20
+	maxDecodedTotal  = 128 << 20
+)
+
+// Extract returns the plain text of the PDF document in data, pages separated
+// by a blank line. A document with no extractable text returns "".
+func Extract(data []byte) (text string, err error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return "", ErrNotPDF
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = "", fmt.Errorf("%w: %v", ErrMalformed, r)
+		}
+	}()
+	doc := load(data)
+	if _, ok := doc.trailer["Encrypt"]; ok {
+		return "", ErrEncrypted
+	}
+	var pages []string
+	for _, page := range doc.pages() {
+		ex := &extractor{doc: doc}
+		ex.page(page)
+		if text := tidy(ex.out.String()); text != "" {
+			pages = append(pages, text)
+		}
+	}
+	if doc.budget < 0 {
+		return "", ErrTooLarge
+	}
+	return strings.Join(pages, "\n\n"), nil
+}
+
+// document holds the objects of a parsed file by object number.
+type document struct {
+	objects map[int]any
+	trailer dict
+	// budget is the decoded bytes left of maxDecodedTotal. It goes negative
+	// once a stream did not fit, after which nothing more is decoded.
+	budget int
+	// fonts caches decoded fonts by object; cmapEntries is the codes left
+	// of maxDocumentCMapEntries and formRuns the form XObjects run so far.
+	fonts       map[ref]*font
+	cmapEntries int
+	formRuns    int
+}
+
+var objHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// load reads every "N G obj" in data, later definitions replacing earlier
+// ones as incremental updates do, then the objects packed in object streams.
+// The cross-reference table is not consulted, so damaged tables do not
+// matter.
+func load(data []byte) *document {
+	doc := &document{
+		objects:     map[int]any{},
+		trailer:     dict{},
+		budget:      maxDecodedTotal,
+		fonts:       map[ref]*font{},
+		cmapEntries: maxDocumentCMapEntries,
+	}
+	skipUntil := 0
+	for _, m := range objHeader.FindAllSubmatchIndex(data, -1) {
+		if m[0] < skipUntil {
+			continue // inside the previous object's stream data
+		}
+		num := atoi(data[m[2]:m[3]])
+		p := &parser{data: data, pos: m[1]}
+		obj, err := p.object()
+		if err != nil {
+			continue
+		}
+		if d, ok := obj.(dict); ok {
+			if s, end, ok := readStream(data, p.pos, d); ok {
+				obj = s
+				skipUntil = end
+				if d["Type"] == name("XRef") {
+					doc.mergeTrailer(d)
+				}
+			}
+		}
+		doc.objects[num] = obj
+	}
+	for pos := 0; ; {
+		i := bytes.Index(data[pos:], []byte("trailer"))
+		if i < 0 {
+			break
+		}
+		p := &parser{data: data, pos: pos + i + len("trailer")}
+		if d, err := p.object(); err == nil {
+			if d, ok := d.(dict); ok {
+				doc.mergeTrailer(d)
+			}
+		}
+		pos += i + len("trailer")
+	}
+	doc.loadObjectStreams()
+	return doc
+}
+
+func (doc *document) mergeTrailer(d dict) {
+	for _, k := range []name{"Root", "Encrypt"} {
+		if v, ok := d[k]; ok {
+			doc.trailer[k] = v
+		}
+	}
+}
+
+// readStream reads the stream that follows the dictionary d ending at pos,
+// returning it and the offset past "endstream".
+func readStream(data []byte, pos int, d dict) (*stream, int, bool) {
+	p := &parser{data: data, pos: pos}
+	p.skipSpace()
+	if !bytes.HasPrefix(data[p.pos:], []byte("stream")) {
+		return nil, 0, false
+	}
+	start := p.pos + len("stream")
+	if bytes.HasPrefix(data[start:], []byte("\r\n")) {
+		start += 2
+	} else if start < len(data) && (data[start] == '\n' || data[start] == '\r') {
+		start++
+	}
+	// Trust /Length when it is direct and lands on "endstream"; otherwise
+	// search for the keyword.
+	if n, ok := d["Length"].(float64); ok && n >= 0 && start+int(n) <= len(data) {
+		end := start + int(n)
+		rest := bytes.TrimLeft(data[end:min(len(data), end+16)], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return &stream{dict: d, raw: data[start:end]}, end, true
+		}
+	}
+	i := bytes.Index(data[start:], []byte("endstream"))
+	if i < 0 {
+		return nil, 0, false
+	}
+	raw := bytes.TrimRight(data[start:start+i], "\r\n")
+	return &stream{dict: d, raw: raw}, start + i, true
+}
+
+// loadObjectStreams adds the objects compressed into /Type /ObjStm streams.
+// Objects already defined directly are kept.
+func (doc *document) loadObjectStreams() {
+	var containers []*stream
+	for _, obj := range doc.objects {
+		if s, ok := obj.(*stream); ok && s.dict["Type"] == name("ObjStm") {
+			containers = append(containers, s)
+		}
+	}
+	for _, s := range containers {
+		data, err := doc.decode(s)
+		if err != nil {
+			continue
+		}
+		n, _ := s.dict["N"].(float64)
+		first, _ := s.dict["First"].(float64)
+		p := &parser{data: data}
+		for i := 0; i < int(n); i++ {
+			num, err1 := p.token()
+			off, err2 := p.token()
+			if err1 != nil || err2 != nil {
+				break
+			}
+			objNum, ok1 := num.(float64)
+			objOff, ok2 := off.(float64)
+			if !ok1 || !ok2 {
+				break
+			}
+			if _, exists := doc.objects[int(objNum)]; exists {
+				continue
+			}
+			op := &parser{data: data, pos: int(first) + int(objOff)}
+			if op.pos < 0 || op.pos >= len(data) {
+				continue
+			}
+			if obj, err := op.object(); err == nil {
+				doc.objects[int(objNum)] = obj
+			}
+		}
+	}
+}
+
+// resolve follows indirect references.
+func (doc *document) resolve(v any) any {
+	for i := 0; i < 16; i++ {
+		r, ok := v.(ref)
+		if !ok {
+			return v
+		}
+		v = doc.objects[r.num]
+	}
+	return nil
+}
+
+func (doc *document) dict(v any) dict {
+	switch t := doc.resolve(v).(type) {
+	case dict:
+		return t
+	case *stream:
+		return t.dict
+	}
+	return nil
+}
+
+// pages returns the page dictionaries in document order, each carrying the
+// resources it inherits from the page tree.
+func (doc *document) pages() []dict {
+	var pages []dict
+	seen := map[int]bool{}
+	var walk func(node any, inherited any)
+	walk = func(node any, inherited any) {
+		if r, ok := node.(ref); ok {
+			if seen[r.num] {
+				return
+			}
+			seen[r.num] = true
+		}
+		d := doc.dict(node)
+		if d == nil {
+			return
+		}
+		if res, ok := d["Resources"]; ok {
+			inherited = res
+		}
+		kids, ok := doc.resolve(d["Kids"]).(array)
+		if !ok || d["Type"] == name("Page") {
+			page := dict{}
+			for k, v := range d {
+				page[k] = v
+			}
+			page["Resources"] = inherited
+			pages = append(pages, page)
+			return
+		}
+		for _, kid := range kids {
+			walk(kid, inherited)
+		}
+	}
+	if root := doc.dict(doc.trailer["Root"]); root != nil {
+		walk(root["Pages"], nil)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+	// No usable catalog: take every page object in object-number order.
+	var nums []int
+	for num, obj := range doc.objects {
+		if d := doc.dict(obj); d != nil && d["Type"] == name("Page") {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		walk(ref{num: num}, doc.dict(doc.objects[num])["Resources"])
+	}
+	return pages
+}
+
+// decode returns the stream's data with its filters applied.
+func (doc *document) decode(s *stream) ([]byte, error) {
+	if doc.budget < 0 {
+		return nil, ErrTooLarge
+	}
+	data := s.raw
+	var filters []any
+	switch f := doc.resolve(s.dict["Filter"]).(type) {
+	case name:
+		filters = []any{f}
+	case array:
+		filters = f
+	}
+	for _, f := range filters {
+		var err error
+		switch doc.resolve(f) {
+		case name("FlateDecode"), name("Fl"):
+			limit := min(maxDecodedStream, doc.budget)
+			data, err = inflate(data, limit)
+			if errors.Is(err, ErrTooLarge) && limit == doc.budget {
+				doc.budget = -1
+			}
+		case name("ASCIIHexDecode"), name("AHx"):
+			data = (&parser{data: data}).hex()
+		case name("ASCII85Decode"), name("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			err = fmt.Errorf("unsupported filter %v", f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(data) > doc.budget {
+		doc.budget = -1
+		return nil, ErrTooLarge
+	}
+	doc.budget -= len(data)
+	return data, nil
+}
+
+// inflate decompresses data, failing once the output passes limit bytes.
+func inflate(data []byte, limit int) ([]byte, error) {
+	var r io.Reader
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		r = zr
+	} else {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if len(out) > limit {
+		return nil, ErrTooLarge
+	}
+	// Many writers leave the checksum off; keep what was inflated.
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, 4*len(data)+4) // "z" expands one byte to four
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, err
+	}
+	return out[:n], nil
+}
+
+func atoi(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n*10 + int(c-'0')
+		if n > 1<<30 {
+			return -1
+		}
+	}
+	return n
+}
+
+// tidy trims trailing spaces from each line and collapses runs of blank
+// lines.
+func tidy(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
@@ -0,0 +1,29 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Decode_TotalBudget(t *testing.T) {
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	_, _ = w.Write(bytes.Repeat([]byte("x"), 600))
+	_ = w.Close()
+	s := &stream{dict: dict{"Filter": name("FlateDecode")}, raw: z.Bytes()}
+
+	doc := &document{objects: map[int]any{}, trailer: dict{}, budget: 1000}
+	data, err := doc.decode(s)
+	require.NoError(t, err)
+	require.Len(t, data, 600)
+
+	// The second copy does not fit in what is left, and once the budget is
+	// spent nothing else is decoded, however small.
+	_, err = doc.decode(s)
+	require.ErrorIs(t, err, ErrTooLarge)
+	_, err = doc.decode(&stream{dict: dict{}, raw: []byte("BT ET")})
+	require.ErrorIs(t, err, ErrTooLarge)
+}
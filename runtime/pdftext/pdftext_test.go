@@ -0,0 +1,137 @@
+package pdftext_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/pdftext"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF writes a PDF from numbered object bodies, with a cross-reference
+// table and a trailer naming object 1 as the catalog.
+func buildPDF(objects []string, trailerExtra string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R %s>>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailerExtra, xref)
+	return b.Bytes()
+}
+
+func streamObject(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flateObject(dict string, data []byte) string {
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	_, _ = w.Write(data)
+	_ = w.Close()
+	return streamObject(dict+" /Filter /FlateDecode", z.Bytes())
+}
+
+func TestUnit_Extract_SimpleFont(t *testing.T) {
+	content := []byte("BT /F1 12 Tf 72 720 Td (Hello, \\(PDF\\)!) Tj 0 -14 Td [(Wor) -10 (ld) -300 (again)] TJ ET\n" +
+		"BT 1 0 0 1 72 680 Tm (Price: 5\\200) Tj ET")
+	pdf := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		streamObject("", content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}, "")
+
+	text, err := pdftext.Extract(pdf)
+	require.NoError(t, err)
+	require.Equal(t, "Hello, (PDF)!\nWorld again\nPrice: 5€", text)
+}
+
+func TestUnit_Extract_CompressedPagesAndToUnicode(t *testing.T) {
+	// Glyph IDs: 1=h 2=i 3..5 = "xyz" by range, 9 = "ﬁ" ligature to "fi".
+	cmap := []byte(`/CIDInit /ProcSet findresource begin
+12 dict begin begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+3 beginbfchar <0001> <0068> <0002> <0069> <0009> <00660069> endbfchar
+1 beginbfrange <0003> <0005> <0078> endbfrange
+endcmap CMapName currentdict /CMap defineresource pop end end`)
+	page1 := []byte("BT /F1 10 Tf 50 700 Td <00010002> Tj ET")
+	page2 := []byte("BT /F1 10 Tf 50 700 Td <0003000400050009> Tj ET BI /W 2 /H 1 /BPC 8 /CS /G ID \x00EI\xff EI BT (ignored?) Tj ET")
+	// The font dictionary lives in a compressed object stream, as PDF 1.5+
+	// writers store it.
+	fontDict := "<< /Type /Font /Subtype /Type0 /BaseFont /Demo /Encoding /Identity-H /ToUnicode 8 0 R >>"
+	objStm := fmt.Sprintf("9 0 %s", fontDict)
+	pdf := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 9 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [6 0 R] >>",
+		flateObject("", page1),
+		flateObject("", page2),
+		flateObject("/Type /ObjStm /N 1 /First 4", []byte(objStm)),
+		flateObject("", cmap),
+	}, "")
+
+	text, err := pdftext.Extract(pdf)
+	require.NoError(t, err)
+	require.Equal(t, "hi\n\nxyzfi", text, "the composite font's raw string after the image has no ToUnicode entry")
+}
+
+func TestUnit_Extract_Errors(t *testing.T) {
+	_, err := pdftext.Extract([]byte("plain text"))
+	require.ErrorIs(t, err, pdftext.ErrNotPDF)
+
+	pdf := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 0 >>",
+		"<< /Filter /Standard /V 2 >>",
+	}, "/Encrypt 3 0 R ")
+	_, err = pdftext.Extract(pdf)
+	require.ErrorIs(t, err, pdftext.ErrEncrypted)
+
+	empty := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 0 >>",
+	}, "")
+	text, err := pdftext.Extract(empty)
+	require.NoError(t, err)
+	require.Empty(t, text)
+}
+
+func FuzzExtract(f *testing.F) {
+	f.Add(buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		streamObject("", []byte("BT /F1 12 Tf 72 720 Td (Hello) Tj [(a) -300 (b)] TJ ET BI /W 1 ID x EI")),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}, ""))
+	f.Add(buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 6 0 R >> /XObject << /X1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [4 0 R] >>",
+		flateObject("", []byte("BT /F1 10 Tf <0001> Tj ET /X1 Do")),
+		streamObject("/Type /XObject /Subtype /Form", []byte("BT (form) Tj ET /X1 Do")),
+		flateObject("/Type /ObjStm /N 1 /First 4", []byte("6 0 << /Subtype /Type0 /ToUnicode 7 0 R >>")),
+		streamObject("", []byte("1 begincodespacerange <0000> <FFFF> endcodespacerange 1 beginbfrange <0000> <FFFF> <0041> endbfrange")),
+	}, ""))
+	f.Add([]byte("%PDF-1.7\n1 0 obj << /Kids [[[[ >> endobj trailer << /Root 1 0 R >>"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, err := pdftext.Extract(data)
+		if errors.Is(err, pdftext.ErrMalformed) {
+			t.Fatalf("parser failed on input: %v", err)
+		}
+	})
+}
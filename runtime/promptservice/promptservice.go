@@ -0,0 +1,302 @@
+// Package promptservice is the prompt library: named, versioned prompt
+// templates that task chains reference from a task's prompt_template with
+// {{prompt "name"}} (the latest version) or {{prompt "name@3"}} (a pinned
+// one) instead of repeating the same text in every chain. References are
+// replaced with the template text when a chain is loaded for execution (see
+// Resolve), so the text is rendered with the task's variables like the rest
+// of the template. Stored chains keep their references, so updating a prompt
+// changes every chain that uses its latest version.
+//
+// Prompts are stored as runtimetypes KV records under one prefix, one record
+// per name holding every version, the same storage missionservice uses.
+package promptservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/contenox/runtime/apiframework"
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/contenox/runtime/runtime/taskengine"
+)
+
+// promptKVPrefix namespaces prompt records in the KV store; each name is
+// stored at promptKVPrefix+name.
+const promptKVPrefix = "prompt_library:"
+
+// scanPageSize bounds one page of the prompt prefix scan.
+const scanPageSize = 200
+
+// Prompt is one version of a named prompt template.
+type Prompt struct {
+	Name string `json:"name" example:"ticket-triage"`
+	// Version starts at 1 and grows by one with every Update.
+	Version     int       `json:"version" example:"2"`
+	Description string    `json:"description,omitempty" example:"Classifies a support ticket by urgency."`
+	Template    string    `json:"template" example:"Classify the urgency of this ticket as low, medium or high."`
+	CreatedAt   time.Time `json:"createdAt" example:"2026-01-01T00:00:00Z"`
+}
+
+// Usage lists the chains whose tasks reference a prompt.
+type Usage struct {
+	Name string `json:"name" example:"ticket-triage"`
+	// Chains are the references of the chains using the prompt, as the
+	// chain store lists them.
+	Chains []string `json:"chains" example:"[\"support.json\"]"`
+}
+
+// ChainSource is the chain store the usage report scans.
+// taskchainservice.Service satisfies it.
+type ChainSource interface {
+	List(ctx context.Context) ([]string, error)
+	Get(ctx context.Context, ref string) (*taskengine.TaskChainDefinition, error)
+}
+
+type Service interface {
+	// Create stores the first version of a prompt. A name already in the
+	// library is a conflict.
+	Create(ctx context.Context, prompt *Prompt) error
+	// Get returns version of the named prompt; version 0 is the latest.
+	Get(ctx context.Context, name string, version int) (*Prompt, error)
+	// Versions returns every version of the named prompt, oldest first.
+	Versions(ctx context.Context, name string) ([]*Prompt, error)
+	// List returns the latest version of every prompt, sorted by name.
+	List(ctx context.Context) ([]*Prompt, error)
+	// Update stores prompt as the next version of its name. Earlier
+	// versions are kept, so pinned references keep resolving.
+	Update(ctx context.Context, prompt *Prompt) error
+	// Delete removes a prompt and all its versions. It refuses with a
+	// conflict while a chain references the prompt.
+	Delete(ctx context.Context, name string) error
+	// Usage reports, for every prompt, the chains that reference it.
+	Usage(ctx context.Context) ([]Usage, error)
+	// Resolve replaces the prompt references in chain with their text.
+	Resolve(ctx context.Context, chain *taskengine.TaskChainDefinition) error
+}
+
+// promptRecord is the stored form: every version of one name.
+type promptRecord struct {
+	Versions []*Prompt `json:"versions"`
+}
+
+type service struct {
+	db     libdb.DBManager
+	chains ChainSource
+}
+
+// New returns a prompt library backed by db. chains is scanned for the usage
+// report and the in-use check on Delete; nil skips both.
+func New(db libdb.DBManager, chains ChainSource) Service {
+	return &service{db: db, chains: chains}
+}
+
+func (s *service) store() runtimetypes.Store {
+	return runtimetypes.New(s.db.WithoutTransaction())
+}
+
+func (s *service) Create(ctx context.Context, prompt *Prompt) error {
+	if err := validate(prompt); err != nil {
+		return err
+	}
+	_, err := s.load(ctx, prompt.Name)
+	if err == nil {
+		return apiframework.Conflict(fmt.Sprintf("prompt %q already exists", prompt.Name))
+	}
+	if !errors.Is(err, libdb.ErrNotFound) {
+		return err
+	}
+	prompt.Version = 1
+	prompt.CreatedAt = time.Now().UTC()
+	return s.save(ctx, prompt.Name, &promptRecord{Versions: []*Prompt{prompt}})
+}
+
+func (s *service) Get(ctx context.Context, name string, version int) (*Prompt, error) {
+	rec, err := s.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return rec.Versions[len(rec.Versions)-1], nil
+	}
+	if version < 0 || version > len(rec.Versions) {
+		return nil, fmt.Errorf("prompt %q version %d: %w", name, version, libdb.ErrNotFound)
+	}
+	return rec.Versions[version-1], nil
+}
+
+func (s *service) Versions(ctx context.Context, name string) ([]*Prompt, error) {
+	rec, err := s.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return rec.Versions, nil
+}
+
+func (s *service) List(ctx context.Context) ([]*Prompt, error) {
+	prompts := []*Prompt{}
+	var cursor *time.Time
+	for {
+		kvs, err := s.store().ListKVPrefix(ctx, promptKVPrefix, cursor, scanPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range kvs {
+			var rec promptRecord
+			if err := json.Unmarshal(kv.Value, &rec); err != nil {
+				return nil, fmt.Errorf("prompt %q: %w", strings.TrimPrefix(kv.Key, promptKVPrefix), err)
+			}
+			if len(rec.Versions) > 0 {
+				prompts = append(prompts, rec.Versions[len(rec.Versions)-1])
+			}
+		}
+		if len(kvs) < scanPageSize {
+			break
+		}
+		next := kvs[len(kvs)-1].CreatedAt
+		if cursor != nil && !next.Before(*cursor) {
+			break
+		}
+		cursor = &next
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	return prompts, nil
+}
+
+func (s *service) Update(ctx context.Context, prompt *Prompt) error {
+	if err := validate(prompt); err != nil {
+		return err
+	}
+	rec, err := s.load(ctx, prompt.Name)
+	if err != nil {
+		return err
+	}
+	prompt.Version = len(rec.Versions) + 1
+	prompt.CreatedAt = time.Now().UTC()
+	rec.Versions = append(rec.Versions, prompt)
+	return s.save(ctx, prompt.Name, rec)
+}
+
+func (s *service) Delete(ctx context.Context, name string) error {
+	if _, err := s.load(ctx, name); err != nil {
+		return err
+	}
+	usage, err := s.usage(ctx)
+	if err != nil {
+		return err
+	}
+	if chains := usage[name]; len(chains) > 0 {
+		return apiframework.Conflict(fmt.Sprintf("prompt %q is referenced by %s", name, strings.Join(chains, ", ")))
+	}
+	return s.store().DeleteKV(ctx, promptKVPrefix+name)
+}
+
+func (s *service) Usage(ctx context.Context) ([]Usage, error) {
+	prompts, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.usage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := make([]Usage, 0, len(prompts))
+	for _, p := range prompts {
+		chains := usage[p.Name]
+		if chains == nil {
+			chains = []string{}
+		}
+		report = append(report, Usage{Name: p.Name, Chains: chains})
+	}
+	return report, nil
+}
+
+// usage maps prompt names to the sorted references of the chains using
+// them. Chains that fail to load are skipped, as the chain store's List does.
+func (s *service) usage(ctx context.Context) (map[string][]string, error) {
+	usage := map[string][]string{}
+	if s.chains == nil {
+		return usage, nil
+	}
+	refs, err := s.chains.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list chains: %w", err)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		chain, err := s.chains.Get(ctx, ref)
+		if err != nil {
+			continue
+		}
+		prompts, err := taskengine.PromptRefs(chain)
+		if err != nil {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, p := range prompts {
+			if !seen[p.Name] {
+				seen[p.Name] = true
+				usage[p.Name] = append(usage[p.Name], ref)
+			}
+		}
+	}
+	return usage, nil
+}
+
+func (s *service) Resolve(ctx context.Context, chain *taskengine.TaskChainDefinition) error {
+	return taskengine.ResolvePromptRefs(chain, func(ref taskengine.PromptRef) (string, error) {
+		p, err := s.Get(ctx, ref.Name, ref.Version)
+		if err != nil {
+			return "", err
+		}
+		return p.Template, nil
+	})
+}
+
+func (s *service) load(ctx context.Context, name string) (*promptRecord, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("prompt name is required %w", errdefs.ErrBadRequest)
+	}
+	var rec promptRecord
+	if err := s.store().GetKV(ctx, promptKVPrefix+name, &rec); err != nil {
+		if errors.Is(err, libdb.ErrNotFound) {
+			return nil, fmt.Errorf("prompt %q: %w", name, libdb.ErrNotFound)
+		}
+		return nil, err
+	}
+	if len(rec.Versions) == 0 {
+		return nil, fmt.Errorf("prompt %q: %w", name, libdb.ErrNotFound)
+	}
+	return &rec, nil
+}
+
+func (s *service) save(ctx context.Context, name string, rec *promptRecord) error {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal prompt: %w", err)
+	}
+	return s.store().SetKV(ctx, promptKVPrefix+name, raw)
+}
+
+func validate(prompt *Prompt) error {
+	if prompt == nil {
+		return fmt.Errorf("prompt is required %w", errdefs.ErrBadRequest)
+	}
+	prompt.Name = strings.TrimSpace(prompt.Name)
+	if prompt.Name == "" {
+		return fmt.Errorf("prompt name is required %w", errdefs.ErrBadRequest)
+	}
+	if strings.ContainsAny(prompt.Name, `@"{}`) {
+		return fmt.Errorf("prompt name %q must not contain @, quotes or braces %w", prompt.Name, errdefs.ErrBadRequest)
+	}
+	if strings.TrimSpace(prompt.Template) == "" {
+		return fmt.Errorf("prompt template is required %w", errdefs.ErrBadRequest)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package promptservice
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/contenox/runtime/apiframework"
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/localfileservice"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/contenox/runtime/runtime/taskchainservice"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPromptDB(t *testing.T) (context.Context, libdb.DBManager) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "prompts.db"), runtimetypes.SchemaSQLite)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return ctx, db
+}
+
+type stubChains map[string]*taskengine.TaskChainDefinition
+
+func (s stubChains) List(context.Context) ([]string, error) {
+	refs := []string{}
+	for ref := range s {
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (s stubChains) Get(_ context.Context, ref string) (*taskengine.TaskChainDefinition, error) {
+	chain, ok := s[ref]
+	if !ok {
+		return nil, libdb.ErrNotFound
+	}
+	return chain, nil
+}
+
+func chainWithPrompt(template string) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID:    "chain",
+		Tasks: []taskengine.TaskDefinition{{ID: "answer", Handler: taskengine.HandleNoop, PromptTemplate: template}},
+	}
+}
+
+func TestUnit_PromptService_VersionsAndResolve(t *testing.T) {
+	ctx, db := setupPromptDB(t)
+	svc := New(db, nil)
+
+	require.NoError(t, svc.Create(ctx, &Prompt{Name: "triage", Template: "v1: {{.input}}"}))
+	err := svc.Create(ctx, &Prompt{Name: "triage", Template: "again"})
+	require.ErrorIs(t, err, apiframework.ErrConflict)
+
+	next := &Prompt{Name: "triage", Template: "v2: {{.input}}"}
+	require.NoError(t, svc.Update(ctx, next))
+	require.Equal(t, 2, next.Version)
+
+	latest, err := svc.Get(ctx, "triage", 0)
+	require.NoError(t, err)
+	require.Equal(t, "v2: {{.input}}", latest.Template)
+	versions, err := svc.Versions(ctx, "triage")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	_, err = svc.Get(ctx, "triage", 3)
+	require.ErrorIs(t, err, libdb.ErrNotFound)
+
+	chain := chainWithPrompt(`{{prompt "triage"}} / {{prompt "triage@1"}}`)
+	require.NoError(t, svc.Resolve(ctx, chain))
+	require.Equal(t, "v2: {{.input}} / v1: {{.input}}", chain.Tasks[0].PromptTemplate)
+
+	err = svc.Resolve(ctx, chainWithPrompt(`{{prompt "missing"}}`))
+	require.ErrorIs(t, err, libdb.ErrNotFound)
+}
+
+func TestUnit_PromptService_DeleteRefusedWhileInUse(t *testing.T) {
+	ctx, db := setupPromptDB(t)
+	chains := stubChains{
+		"support.json": chainWithPrompt(`{{prompt "triage@1"}} {{.input}}`),
+		"other.json":   chainWithPrompt(`{{.input}}`),
+	}
+	svc := New(db, chains)
+	require.NoError(t, svc.Create(ctx, &Prompt{Name: "triage", Template: "Classify."}))
+	require.NoError(t, svc.Create(ctx, &Prompt{Name: "unused", Template: "Unused."}))
+
+	usage, err := svc.Usage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Usage{
+		{Name: "triage", Chains: []string{"support.json"}},
+		{Name: "unused", Chains: []string{}},
+	}, usage)
+
+	err = svc.Delete(ctx, "triage")
+	require.ErrorIs(t, err, apiframework.ErrConflict)
+	require.ErrorContains(t, err, "support.json")
+
+	require.NoError(t, svc.Delete(ctx, "unused"))
+	_, err = svc.Get(ctx, "unused", 0)
+	require.ErrorIs(t, err, libdb.ErrNotFound)
+
+	delete(chains, "support.json")
+	require.NoError(t, svc.Delete(ctx, "triage"))
+}
+
+func TestUnit_PromptService_UsageScansNestedChains(t *testing.T) {
+	ctx, db := setupPromptDB(t)
+	files, err := localfileservice.New(t.TempDir())
+	require.NoError(t, err)
+	chains := taskchainservice.NewLocal(files)
+	require.NoError(t, chains.CreateAtPath(ctx, "team/x.json", chainWithPrompt(`{{prompt "triage"}} {{.input}}`)))
+	svc := New(db, chains)
+	require.NoError(t, svc.Create(ctx, &Prompt{Name: "triage", Template: "Classify."}))
+
+	usage, err := svc.Usage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Usage{{Name: "triage", Chains: []string{"team/x.json"}}}, usage)
+
+	err = svc.Delete(ctx, "triage")
+	require.ErrorIs(t, err, apiframework.ErrConflict, "a chain in a subdirectory still uses the prompt")
+	require.ErrorContains(t, err, "team/x.json")
+}
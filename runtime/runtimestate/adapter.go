@@ -13,7 +13,22 @@ import (
 )
 
 // LocalProviderAdapter creates providers for self-hosted backends (Ollama, vLLM)
+// from a runtime snapshot, resolving "env:" credential references with
+// EnvSecretResolver. Use State.ProviderAdapter to resolve through the State's
+// own SecretResolver.
 func LocalProviderAdapter(ctx context.Context, tracker libtracker.ActivityTracker, runtime map[string]statetype.BackendRuntimeState) ProviderFromRuntimeState {
+	return localProviderAdapter(ctx, tracker, runtime, EnvSecretResolver{})
+}
+
+// ProviderAdapter is LocalProviderAdapter over the current snapshot, with each
+// backend's credential resolved through the State's SecretResolver as the
+// providers are built. A backend whose credential fails to resolve is left
+// out, the same as one whose catalog cannot be constructed.
+func (s *State) ProviderAdapter(ctx context.Context, tracker libtracker.ActivityTracker) ProviderFromRuntimeState {
+	return localProviderAdapter(ctx, tracker, s.Get(ctx), SecretResolverFunc(s.resolveAPIKey))
+}
+
+func localProviderAdapter(ctx context.Context, tracker libtracker.ActivityTracker, runtime map[string]statetype.BackendRuntimeState, secrets SecretResolver) ProviderFromRuntimeState {
 	// Create a flat list of providers (one per model per backend)
 	providersByType := make(map[string][]modelrepo.Provider)
 
@@ -22,12 +37,16 @@ func LocalProviderAdapter(ctx context.Context, tracker libtracker.ActivityTracke
 			continue
 		}
 
+		apiKey, err := secrets.Resolve(ctx, state.GetAPIKey())
+		if err != nil {
+			continue
+		}
 		backendType := modelrepo.CanonicalBackendType(state.Backend.Type)
 		catalog, err := modelrepo.NewCatalogProvider(
 			modelrepo.BackendSpec{
 				Type:    backendType,
 				BaseURL: state.Backend.BaseURL,
-				APIKey:  state.GetAPIKey(),
+				APIKey:  apiKey,
 			},
 			modelrepo.WithCatalogHTTPClient(http.DefaultClient),
 			modelrepo.WithCatalogTracker(tracker),
@@ -0,0 +1,41 @@
+package runtimestate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_RunBackendCycle_TimesOutHungBackend(t *testing.T) {
+	ctx, state, db := newReconcileStateTest(t, WithBackendTimeout(100*time.Millisecond))
+
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hung.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"id": "served-model", "max_model_len": 4096}},
+		})
+	}))
+	defer healthy.Close()
+
+	store := runtimetypes.New(db.WithoutTransaction())
+	require.NoError(t, store.CreateBackend(ctx, &runtimetypes.Backend{ID: "hung", Name: "hung", Type: "ollama", BaseURL: hung.URL}))
+	require.NoError(t, store.CreateBackend(ctx, &runtimetypes.Backend{ID: "healthy", Name: "healthy", Type: "vllm", BaseURL: healthy.URL}))
+
+	start := time.Now()
+	require.NoError(t, state.RunBackendCycle(ctx))
+	require.Less(t, time.Since(start), 5*time.Second, "a hung backend must not stall the cycle")
+
+	snapshot := state.Get(ctx)
+	require.Contains(t, snapshot["hung"].Error, "backend did not respond within 100ms")
+	require.Empty(t, snapshot["healthy"].Error, "the cycle moves on to the next backend")
+	require.Equal(t, []string{"served-model"}, snapshot["healthy"].Models)
+}
@@ -47,6 +47,17 @@ func TestUnit_ApplyCapabilityOverrides_ManualFalseSuppressesProviderTrue(t *test
 	require.False(t, got.CanThink)
 }
 
+func TestUnit_ApplyCapabilityOverrides_ToolsDisabled(t *testing.T) {
+	ctx, state, store := newCapabilityOverrideTestState(t)
+	got := state.applyCapabilityOverrides(ctx, "ollama", statetype.ModelPullStatus{Model: "gemma3:1b"})
+	require.False(t, got.ToolsUnsupported, "tools are assumed supported without an override")
+
+	_, err := modelcapability.New(store).SetTools(ctx, "ollama", "gemma3:1b", false)
+	require.NoError(t, err)
+	got = state.applyCapabilityOverrides(ctx, "ollama", statetype.ModelPullStatus{Model: "gemma3:1b"})
+	require.True(t, got.ToolsUnsupported)
+}
+
 func TestUnit_RunBackendCycle_AppliesOpenAIManualThinkOverride(t *testing.T) {
 	ctx := context.Background()
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
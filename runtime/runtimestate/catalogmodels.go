@@ -52,18 +52,19 @@ func pullStatusFromObservedModel(model modelrepo.ObservedModel) statetype.ModelP
 	}
 
 	return statetype.ModelPullStatus{
-		Name:            displayName,
-		Model:           model.Name,
-		ModifiedAt:      model.ModifiedAt,
-		Size:            model.Size,
-		Digest:          model.Digest,
-		ContextLength:   model.ContextLength,
-		MaxOutputTokens: model.MaxOutputTokens,
-		CanChat:         model.CanChat,
-		CanEmbed:        model.CanEmbed,
-		CanPrompt:       model.CanPrompt,
-		CanStream:       model.CanStream,
-		CanThink:        model.CanThink,
-		CanVision:       model.CanVision,
+		Name:                  displayName,
+		Model:                 model.Name,
+		ModifiedAt:            model.ModifiedAt,
+		Size:                  model.Size,
+		Digest:                model.Digest,
+		ContextLength:         model.ContextLength,
+		DetectedContextLength: model.ContextLength,
+		MaxOutputTokens:       model.MaxOutputTokens,
+		CanChat:               model.CanChat,
+		CanEmbed:              model.CanEmbed,
+		CanPrompt:             model.CanPrompt,
+		CanStream:             model.CanStream,
+		CanThink:              model.CanThink,
+		CanVision:             model.CanVision,
 	}
 }
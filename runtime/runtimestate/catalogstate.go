@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/contenox/runtime/libkvstore"
@@ -88,6 +87,11 @@ func providerConfigKey(backendType string) (string, bool) {
 	}
 }
 
+// loadProviderAPIKey returns the provider credential configured for
+// backendType as stored — a literal key or a reference for the SecretResolver,
+// with an APIKeyEnv setting returned as an "env:" reference — so what reaches
+// the runtime snapshot is never a secret that was only held by reference.
+// Callers resolve it at the point of use through newCatalogProvider.
 func (s *State) loadProviderAPIKey(ctx context.Context, backendType string) (string, error) {
 	key, ok := providerConfigKey(backendType)
 	if !ok {
@@ -100,12 +104,19 @@ func (s *State) loadProviderAPIKey(ctx context.Context, backendType string) (str
 		return "", err
 	}
 	if cfg.APIKey == "" && strings.TrimSpace(cfg.APIKeyEnv) != "" {
-		return os.Getenv(strings.TrimSpace(cfg.APIKeyEnv)), nil
+		return EnvSecretPrefix + strings.TrimSpace(cfg.APIKeyEnv), nil
 	}
 	return cfg.APIKey, nil
 }
 
-func (s *State) newCatalogProvider(backend *runtimetypes.Backend, apiKey string) (modelrepo.CatalogProvider, error) {
+// newCatalogProvider builds backend's catalog. credential is the provider
+// credential as stored (see loadProviderAPIKey); it is resolved here, at the
+// point of use, and the resolved secret is not kept.
+func (s *State) newCatalogProvider(ctx context.Context, backend *runtimetypes.Backend, credential string) (modelrepo.CatalogProvider, error) {
+	apiKey, err := s.resolveAPIKey(ctx, credential)
+	if err != nil {
+		return nil, fmt.Errorf("resolve API key for %s: %w", backend.Type, err)
+	}
 	return modelrepo.NewCatalogProvider(
 		modelrepo.BackendSpec{
 			Type:    backend.Type,
@@ -177,6 +188,9 @@ func (s *State) applyCapabilityOverrides(ctx context.Context, provider string, m
 	if override.CanVision != nil {
 		model.CanVision = *override.CanVision
 	}
+	if override.CanTools != nil {
+		model.ToolsUnsupported = !*override.CanTools
+	}
 	return model
 }
 
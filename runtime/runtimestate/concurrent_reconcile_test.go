@@ -0,0 +1,64 @@
+package runtimestate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+// Run with -race: every worker writes the shared snapshot maps.
+func TestUnit_RunBackendCycle_ObservesBackendsConcurrently(t *testing.T) {
+	const backends, workers = 8, 4
+	ctx, state, db := newReconcileStateTest(t, WithReconcileWorkers(workers))
+
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"id": "served-model"}},
+		})
+	}))
+	defer server.Close()
+
+	store := runtimetypes.New(db.WithoutTransaction())
+	for i := range backends {
+		require.NoError(t, store.CreateBackend(ctx, &runtimetypes.Backend{
+			ID:      fmt.Sprintf("backend-%d", i),
+			Name:    fmt.Sprintf("vllm-%d", i),
+			Type:    "vllm",
+			// UNIQUE(type, base_url): give each backend its own path on the server.
+			BaseURL: fmt.Sprintf("%s/b%d", server.URL, i),
+		}))
+	}
+	require.NoError(t, state.RunBackendCycle(ctx))
+
+	snapshot := state.Get(ctx)
+	require.Len(t, snapshot, backends, "every backend observed by any worker survives cleanup")
+	for id, backend := range snapshot {
+		require.Empty(t, backend.Error, id)
+	}
+	require.Greater(t, peak.Load(), int32(1), "backends are observed in parallel")
+	require.LessOrEqual(t, peak.Load(), int32(workers), "no more than the configured workers run at once")
+
+	// A second cycle over fewer backends still cleans up the removed one.
+	require.NoError(t, store.DeleteBackend(ctx, "backend-0"))
+	require.NoError(t, state.RunBackendCycle(ctx))
+	require.NotContains(t, state.Get(ctx), "backend-0")
+	require.Len(t, state.Get(ctx), backends-1)
+}
@@ -0,0 +1,77 @@
+package runtimestate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+)
+
+// ConnectionTestTimeout bounds one TestBackendConnection probe, so a backend
+// URL that black-holes requests fails the check instead of hanging the caller.
+var ConnectionTestTimeout = 15 * time.Second
+
+// TestBackendConnection probes backend the way a reconciliation cycle would —
+// listing its models with the provider credentials already configured for its
+// type — but synchronously and without storing anything: not the backend, not
+// its runtime state, not the observed-model cache. It lets a caller check a
+// backend's URL and key before saving it rather than finding out from the
+// next cycle's error. detail is the provider's error on failure, or how many
+// models the backend reported on success.
+func (s *State) TestBackendConnection(ctx context.Context, backend runtimetypes.Backend) (bool, string) {
+	ctx, cancel := context.WithTimeout(ctx, ConnectionTestTimeout)
+	defer cancel()
+
+	backendType := modelrepo.CanonicalBackendType(backend.Type)
+	if backendType == "modeld" {
+		// modeld nodes are reached over gRPC through a per-backend connection
+		// cache keyed by the saved backend's ID; there is no stateless probe.
+		return false, "connection test is not supported for modeld backends; save the backend and check its state after the next reconciliation"
+	}
+
+	credential, err := s.loadProviderAPIKey(ctx, backend.Type)
+	if err != nil && !errors.Is(err, libdb.ErrNotFound) {
+		return false, fmt.Sprintf("failed to retrieve API key configuration: %v", err)
+	}
+	if requiresProviderAPIKey(backendType) {
+		// Check the resolved key: an APIKeyEnv setting is stored as an "env:"
+		// reference, which is never empty even when the variable is unset.
+		apiKey, err := s.resolveAPIKey(ctx, credential)
+		if err != nil {
+			return false, fmt.Sprintf("resolve API key for %s: %v", backendType, err)
+		}
+		if apiKey == "" {
+			return false, fmt.Sprintf("API key not configured for %s", backendType)
+		}
+	}
+
+	catalog, err := s.newCatalogProvider(ctx, &backend, credential)
+	if err != nil {
+		return false, err.Error()
+	}
+	models, err := catalog.ListModels(ctx)
+	if err != nil {
+		return false, err.Error()
+	}
+	if len(models) == 0 {
+		return true, "connected; the backend reported no models"
+	}
+	return true, fmt.Sprintf("connected; the backend reported %d models", len(models))
+}
+
+// requiresProviderAPIKey reports whether reconciliation refuses a backend of
+// backendType without an API key (see processGeminiBackend and
+// processOpenAIBackend). Ollama and vLLM keys are optional, and Vertex and
+// Bedrock fall back to ambient cloud credentials.
+func requiresProviderAPIKey(backendType string) bool {
+	switch backendType {
+	case "gemini", "openai", "openrouter", "anthropic", "mistral":
+		return true
+	default:
+		return false
+	}
+}
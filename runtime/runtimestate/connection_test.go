@@ -0,0 +1,74 @@
+package runtimestate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_TestBackendConnection_ProbesWithoutStoring(t *testing.T) {
+	ctx, state, db := newReconcileStateTest(t)
+
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"id": "gpt-5"}},
+		})
+	}))
+	defer server.Close()
+
+	store := runtimetypes.New(db.WithoutTransaction())
+	keyData, err := json.Marshal(ProviderConfig{APIKey: "test-key", Type: "openai"})
+	require.NoError(t, err)
+	require.NoError(t, store.SetKV(ctx, OpenaiKey, keyData))
+
+	ok, detail := state.TestBackendConnection(ctx, runtimetypes.Backend{
+		Name:    "candidate",
+		Type:    "openai",
+		BaseURL: server.URL,
+	})
+	require.True(t, ok, detail)
+	require.Contains(t, detail, "1 models")
+	require.Equal(t, "Bearer test-key", authHeader)
+
+	require.Empty(t, state.Get(ctx), "a probe must not reach the runtime state")
+	backends, err := store.ListAllBackends(ctx)
+	require.NoError(t, err)
+	require.Empty(t, backends, "a probe must not save the backend")
+}
+
+func TestUnit_TestBackendConnection_MissingKey(t *testing.T) {
+	ctx, state, _ := newReconcileStateTest(t)
+
+	ok, detail := state.TestBackendConnection(ctx, runtimetypes.Backend{
+		Name:    "candidate",
+		Type:    "mistral",
+		BaseURL: "http://127.0.0.1:1",
+	})
+	require.False(t, ok)
+	require.Contains(t, detail, "API key not configured")
+}
+
+func TestUnit_TestBackendConnection_UnsetKeyEnv(t *testing.T) {
+	ctx, state, db := newReconcileStateTest(t)
+	t.Setenv("CONTENOX_TEST_UNSET_KEY", "")
+
+	store := runtimetypes.New(db.WithoutTransaction())
+	keyData, err := json.Marshal(ProviderConfig{APIKeyEnv: "CONTENOX_TEST_UNSET_KEY", Type: "openai"})
+	require.NoError(t, err)
+	require.NoError(t, store.SetKV(ctx, OpenaiKey, keyData))
+
+	ok, detail := state.TestBackendConnection(ctx, runtimetypes.Backend{
+		Name:    "candidate",
+		Type:    "openai",
+		BaseURL: "http://127.0.0.1:1",
+	})
+	require.False(t, ok)
+	require.Contains(t, detail, "API key not configured", "an env reference to an unset variable is no key")
+}
@@ -0,0 +1,36 @@
+package runtimestate
+
+import (
+	"context"
+
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/contenox/runtime/runtime/statetype"
+)
+
+// ContextProber returns the context window backend reports for model. apiKey
+// is the resolved provider credential. llmrepo.ContextProbe.ContextLength is
+// the implementation the runtime wires in.
+type ContextProber func(ctx context.Context, backend *runtimetypes.Backend, apiKey, model string) (int, error)
+
+// resolveContextLength settles the context window of a declared model. The
+// value the backend's catalog reported (lmr.DetectedContextLength) is used
+// when present; otherwise the configured probe asks the backend directly.
+// configured, the declared context_length, applies only when neither yields
+// a window. credential is the stored provider credential, resolved here only
+// if the probe runs.
+func (s *State) resolveContextLength(ctx context.Context, backend *runtimetypes.Backend, credential string, lmr *statetype.ModelPullStatus, configured int) {
+	if lmr.DetectedContextLength <= 0 && s.contextProbe != nil {
+		lmr.DetectedContextLength = 0
+		if apiKey, err := s.resolveAPIKey(ctx, credential); err == nil {
+			if n, err := s.contextProbe(ctx, backend, apiKey, lmr.Model); err == nil && n > 0 {
+				lmr.DetectedContextLength = n
+			}
+		}
+	}
+	if lmr.DetectedContextLength > 0 {
+		lmr.ContextLength = lmr.DetectedContextLength
+		return
+	}
+	lmr.DetectedContextLength = 0
+	lmr.ContextLength = configured
+}
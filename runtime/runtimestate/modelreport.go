@@ -0,0 +1,97 @@
+package runtimestate
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/contenox/runtime/runtime/statetype"
+)
+
+func (s *State) recordDeclared(backendID string, models []*runtimetypes.Model) {
+	names := make([]string, 0, len(models))
+	for _, model := range models {
+		names = append(names, model.Model)
+	}
+	s.declared.Store(backendID, names)
+}
+
+// ModelReport returns, per backend ID, the three-way split between the models
+// the last reconcile declared for the backend, the models it reported, and
+// the difference in each direction. It reads the current snapshot and does
+// not reconcile. Names match with or without an Ollama ":latest" tag, as
+// declarations are usually written without it. Contexts lists each pulled
+// model's context window and whether the backend reported it.
+func (s *State) ModelReport(ctx context.Context) map[string]statetype.ModelReport {
+	reports := map[string]statetype.ModelReport{}
+	for id, backend := range s.Get(ctx) {
+		var declared []string
+		if v, ok := s.declared.Load(id); ok {
+			declared = v.([]string)
+		}
+		pulled := make([]string, 0, len(backend.PulledModels))
+		var contexts []statetype.ModelContextWindow
+		for _, model := range backend.PulledModels {
+			name := strings.TrimSpace(model.Model)
+			if name == "" {
+				name = strings.TrimSpace(model.Name)
+			}
+			if name == "" {
+				continue
+			}
+			pulled = append(pulled, name)
+			if model.ContextLength > 0 {
+				source := statetype.ContextSourceConfigured
+				if model.DetectedContextLength > 0 {
+					source = statetype.ContextSourceDetected
+				}
+				contexts = append(contexts, statetype.ModelContextWindow{Model: name, ContextLength: model.ContextLength, Source: source})
+			}
+		}
+		slices.SortFunc(contexts, func(a, b statetype.ModelContextWindow) int {
+			return strings.Compare(a.Model, b.Model)
+		})
+		reports[id] = statetype.ModelReport{
+			BackendID:   id,
+			BackendName: backend.Name,
+			Declared:    sortedUnique(declared),
+			Pulled:      sortedUnique(pulled),
+			Missing:     modelNamesNotIn(declared, pulled),
+			Orphaned:    modelNamesNotIn(pulled, declared),
+			Error:       backend.Error,
+			Contexts:    contexts,
+		}
+	}
+	return reports
+}
+
+// modelNamesNotIn returns the names in from that have no match in other,
+// sorted and de-duplicated.
+func modelNamesNotIn(from, other []string) []string {
+	index := make(map[string]struct{}, len(other))
+	for _, name := range other {
+		index[reportModelKey(name)] = struct{}{}
+	}
+	out := []string{}
+	for _, name := range from {
+		if _, ok := index[reportModelKey(name)]; !ok {
+			out = append(out, name)
+		}
+	}
+	return sortedUnique(out)
+}
+
+func reportModelKey(name string) string {
+	name, _ = strings.CutSuffix(strings.TrimSpace(name), ":latest")
+	return name
+}
+
+func sortedUnique(names []string) []string {
+	out := slices.Clone(names)
+	if out == nil {
+		out = []string{}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,103 @@
+package runtimestate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/contenox/runtime/runtime/statetype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ModelReport_SplitsDeclaredAndObserved(t *testing.T) {
+	ctx, state, db := newReconcileStateTest(t, WithAutoDiscoverModels())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"id": "gpt-5"}, {"id": "gpt-4o"}},
+		})
+	}))
+	defer server.Close()
+
+	store := runtimetypes.New(db.WithoutTransaction())
+	require.NoError(t, store.CreateBackend(ctx, &runtimetypes.Backend{
+		ID:      "openai-backend",
+		Name:    "openai",
+		Type:    "openai",
+		BaseURL: server.URL,
+	}))
+	keyData, err := json.Marshal(ProviderConfig{APIKey: "test-key", Type: "openai"})
+	require.NoError(t, err)
+	require.NoError(t, store.SetKV(ctx, OpenaiKey, keyData))
+	for _, name := range []string{"gpt-5", "text-embedding-3-small"} {
+		require.NoError(t, store.AppendModel(ctx, &runtimetypes.Model{ID: name, Model: name, CanChat: true}))
+	}
+
+	require.NoError(t, state.RunBackendCycle(ctx))
+
+	reports := state.ModelReport(ctx)
+	require.Contains(t, reports, "openai-backend")
+	report := reports["openai-backend"]
+	require.Equal(t, "openai", report.BackendName)
+	require.Empty(t, report.Error)
+	require.Equal(t, []string{"gpt-5", "text-embedding-3-small"}, report.Declared)
+	require.Equal(t, []string{"gpt-4o", "gpt-5"}, report.Pulled)
+	require.Equal(t, []string{"text-embedding-3-small"}, report.Missing)
+	require.Equal(t, []string{"gpt-4o"}, report.Orphaned)
+}
+
+func TestUnit_ModelNamesNotIn_IgnoresLatestTag(t *testing.T) {
+	require.Equal(t, []string{"mistral:7b"},
+		modelNamesNotIn([]string{"llama2:latest", "mistral:7b", "mistral:7b"}, []string{"llama2"}))
+	require.Equal(t, []string{}, modelNamesNotIn(nil, []string{"llama2"}))
+}
+
+func TestUnit_ModelReport_DetectedContextWinsOverConfigured(t *testing.T) {
+	var probed []string
+	probe := func(_ context.Context, backend *runtimetypes.Backend, apiKey, model string) (int, error) {
+		require.Equal(t, "openai-backend", backend.ID)
+		require.Equal(t, "test-key", apiKey)
+		probed = append(probed, model)
+		if model == "gpt-5" {
+			return 400000, nil
+		}
+		return 0, errors.New("no context window in metadata")
+	}
+	ctx, state, db := newReconcileStateTest(t, WithContextProbe(probe))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"id": "gpt-5"}, {"id": "gpt-4o"}},
+		})
+	}))
+	defer server.Close()
+
+	store := runtimetypes.New(db.WithoutTransaction())
+	require.NoError(t, store.CreateBackend(ctx, &runtimetypes.Backend{
+		ID:      "openai-backend",
+		Name:    "openai",
+		Type:    "openai",
+		BaseURL: server.URL,
+	}))
+	keyData, err := json.Marshal(ProviderConfig{APIKey: "test-key", Type: "openai"})
+	require.NoError(t, err)
+	require.NoError(t, store.SetKV(ctx, OpenaiKey, keyData))
+	for _, name := range []string{"gpt-5", "gpt-4o"} {
+		require.NoError(t, store.AppendModel(ctx, &runtimetypes.Model{ID: name, Model: name, CanChat: true, ContextLength: 16384}))
+	}
+
+	require.NoError(t, state.RunBackendCycle(ctx))
+
+	require.ElementsMatch(t, []string{"gpt-5", "gpt-4o"}, probed)
+	report := state.ModelReport(ctx)["openai-backend"]
+	require.Equal(t, []statetype.ModelContextWindow{
+		{Model: "gpt-4o", ContextLength: 16384, Source: statetype.ContextSourceConfigured},
+		{Model: "gpt-5", ContextLength: 400000, Source: statetype.ContextSourceDetected},
+	}, report.Contexts)
+}
@@ -14,6 +14,10 @@ const (
 	VertexGoogleKey   = ProviderKeyPrefix + "vertex-google"
 )
 
+// ProviderConfig is the stored credential for one provider type. APIKey is
+// either the key itself or a reference the State's SecretResolver resolves
+// when the key is needed, e.g. "env:GEMINI_API_KEY". APIKeyEnv is the older
+// spelling of an "env:" reference and is used only when APIKey is empty.
 type ProviderConfig struct {
 	APIKey    string
 	APIKeyEnv string
@@ -0,0 +1,70 @@
+package runtimestate
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvSecretPrefix marks a ProviderConfig.APIKey that names an environment
+// variable instead of holding the key itself, e.g. "env:GEMINI_API_KEY".
+const EnvSecretPrefix = "env:"
+
+// SecretResolver turns a stored provider credential into the secret a backend
+// is called with. The credential is whatever ProviderConfig.APIKey holds: a
+// literal key, or a reference such as "env:GEMINI_API_KEY" or a secret-manager
+// path. Resolve is called each time a catalog or provider is built — never
+// ahead of time — so the runtime snapshot and the observed-model cache only
+// ever hold the reference, and a rotated secret is picked up on the next
+// request without a restart.
+//
+// An implementation must return credentials it does not recognise unchanged,
+// so literal keys written before it was installed keep working.
+type SecretResolver interface {
+	Resolve(ctx context.Context, credential string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to SecretResolver.
+type SecretResolverFunc func(ctx context.Context, credential string) (string, error)
+
+func (f SecretResolverFunc) Resolve(ctx context.Context, credential string) (string, error) {
+	return f(ctx, credential)
+}
+
+// EnvSecretResolver resolves "env:NAME" references from the process
+// environment and passes every other credential through. An unset variable
+// resolves to "", the same as ProviderConfig.APIKeyEnv always has, so optional
+// credentials (Ollama, Vertex, Bedrock) still fall back to their defaults.
+// It is the resolver a State uses unless WithSecretResolver installs another.
+type EnvSecretResolver struct{}
+
+func (EnvSecretResolver) Resolve(_ context.Context, credential string) (string, error) {
+	name, ok := strings.CutPrefix(credential, EnvSecretPrefix)
+	if !ok {
+		return credential, nil
+	}
+	return os.Getenv(strings.TrimSpace(name)), nil
+}
+
+// WithSecretResolver installs the resolver provider credentials are passed
+// through before use. Wrap EnvSecretResolver to keep "env:" references
+// working alongside the resolver's own scheme.
+func WithSecretResolver(r SecretResolver) Option {
+	return func(s *State) {
+		if r != nil {
+			s.secrets = r
+		}
+	}
+}
+
+// resolveAPIKey resolves credential with the State's SecretResolver.
+func (s *State) resolveAPIKey(ctx context.Context, credential string) (string, error) {
+	if credential == "" {
+		return "", nil
+	}
+	secrets := s.secrets
+	if secrets == nil {
+		secrets = EnvSecretResolver{}
+	}
+	return secrets.Resolve(ctx, credential)
+}
@@ -0,0 +1,88 @@
+package runtimestate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_EnvSecretResolver(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("CONTENOX_TEST_PROVIDER_KEY", "from-env")
+
+	got, err := EnvSecretResolver{}.Resolve(ctx, "env:CONTENOX_TEST_PROVIDER_KEY")
+	require.NoError(t, err)
+	require.Equal(t, "from-env", got)
+
+	got, err = EnvSecretResolver{}.Resolve(ctx, "sk-literal")
+	require.NoError(t, err)
+	require.Equal(t, "sk-literal", got, "a literal key passes through")
+
+	got, err = EnvSecretResolver{}.Resolve(ctx, "env:CONTENOX_TEST_UNSET_KEY")
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
+
+// A referenced key is resolved each time a catalog or provider is built, while
+// the runtime snapshot keeps only the reference.
+func TestUnit_SecretResolver_ResolvesAtUseAndKeepsReference(t *testing.T) {
+	var revoked atomic.Bool
+	resolver := SecretResolverFunc(func(_ context.Context, credential string) (string, error) {
+		if credential != "vault:openai" {
+			return credential, nil
+		}
+		if revoked.Load() {
+			return "", errors.New("secret revoked")
+		}
+		return "resolved-key", nil
+	})
+	ctx, state, db := newReconcileStateTest(t, WithAutoDiscoverModels(), WithSecretResolver(resolver))
+
+	var authHeader atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader.Store(r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": []map[string]any{{"id": "gpt-5"}},
+		})
+	}))
+	defer server.Close()
+
+	store := runtimetypes.New(db.WithoutTransaction())
+	require.NoError(t, store.CreateBackend(ctx, &runtimetypes.Backend{
+		ID:      "openai-backend",
+		Name:    "openai",
+		Type:    "openai",
+		BaseURL: server.URL,
+	}))
+	keyData, err := json.Marshal(ProviderConfig{APIKey: "vault:openai", Type: "openai"})
+	require.NoError(t, err)
+	require.NoError(t, store.SetKV(ctx, OpenaiKey, keyData))
+
+	require.NoError(t, state.RunBackendCycle(ctx))
+	require.Equal(t, "Bearer resolved-key", authHeader.Load())
+
+	rt := state.Get(ctx)
+	require.Contains(t, rt, "openai-backend")
+	require.Empty(t, rt["openai-backend"].Error)
+	backendState := rt["openai-backend"]
+	require.Equal(t, "vault:openai", backendState.GetAPIKey(), "the snapshot holds the reference, not the secret")
+
+	providers, err := state.ProviderAdapter(ctx, nil)(ctx, "openai")
+	require.NoError(t, err)
+	require.Len(t, providers, 1)
+
+	// Resolution happens per adapter build, not at reconcile time: once the
+	// secret can no longer be resolved the backend drops out without a cycle.
+	revoked.Store(true)
+	providers, err = state.ProviderAdapter(ctx, nil)(ctx, "openai")
+	require.NoError(t, err)
+	require.Empty(t, providers)
+}
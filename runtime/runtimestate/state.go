@@ -27,6 +27,7 @@ import (
 	"github.com/contenox/runtime/runtime/runtimetypes"
 	"github.com/contenox/runtime/runtime/statetype"
 	"github.com/contenox/runtime/runtime/transport"
+	"golang.org/x/sync/errgroup"
 )
 
 // ProviderCacheDuration defines how long the state of models from an external
@@ -40,6 +41,15 @@ const ProviderCacheDuration = 1 * time.Hour
 // each time. It is a package var so tests can shorten it.
 var ReconcileDebounceInterval = 15 * time.Second
 
+// DefaultBackendTimeout bounds how long one backend may take to be observed
+// during a reconcile cycle (listing its models and any follow-up metadata
+// requests) unless WithBackendTimeout sets another limit.
+const DefaultBackendTimeout = 30 * time.Second
+
+// DefaultReconcileWorkers is how many backends a reconcile cycle observes at
+// once unless WithReconcileWorkers sets another count.
+const DefaultReconcileWorkers = 4
+
 // providerCacheEntry holds the data and metadata for a cached provider state.
 // APIKey is stored so we can detect key rotation and invalidate the cache.
 type providerCacheEntry struct {
@@ -59,10 +69,30 @@ type State struct {
 	// kvStore is used for persistent provider-model caching (nil = fall back to in-memory sync.Map)
 	kvStore       libkvstore.KVManager
 	providerCache sync.Map // fallback when kvStore is nil
+	// secrets resolves stored provider credentials just before use; nil means
+	// EnvSecretResolver.
+	secrets SecretResolver
 	// reconcileMu guards lastReconcileAt, the debounce clock shared by every
 	// reconcile (RunBackendCycle and the read-triggered ReconcileIfStale).
 	reconcileMu     sync.Mutex
 	lastReconcileAt time.Time
+	// declared holds, per backend ID, the model names the last cycle declared
+	// for it; ModelReport diffs it against the observed snapshot.
+	declared sync.Map
+	// trigger carries TriggerReconcile requests to RunReconcileLoop; its
+	// capacity of one is what coalesces them.
+	trigger chan struct{}
+	// contextProbe asks a backend for a declared model's context window when
+	// its catalog listing does not include one; nil disables probing.
+	contextProbe ContextProber
+	// backendTimeout bounds the observation of each backend; 0 means no bound.
+	backendTimeout time.Duration
+	// reconcileWorkers is how many backends a cycle observes concurrently.
+	reconcileWorkers int
+	// warmer keeps declared Ollama models resident for keepAlive; nil
+	// disables warm-up.
+	warmer    ModelWarmer
+	keepAlive time.Duration
 }
 
 type Option func(*State)
@@ -97,6 +127,37 @@ func WithAutoDiscoverModels() Option {
 	}
 }
 
+// WithContextProbe lets reconciliation ask backends for the context window
+// of declared models their catalog listing reports without one (see
+// llmrepo.ContextProbe). A detected window takes precedence over the
+// configured context_length, which remains the fallback when detection fails.
+func WithContextProbe(probe ContextProber) Option {
+	return func(s *State) {
+		s.contextProbe = probe
+	}
+}
+
+// WithBackendTimeout bounds how long each backend may take to be observed in
+// a reconcile cycle, replacing DefaultBackendTimeout. A backend that does not
+// answer in time is recorded with a timeout error and the cycle moves on to
+// the next one, so one hung backend cannot stall reconciliation. d <= 0
+// removes the bound.
+func WithBackendTimeout(d time.Duration) Option {
+	return func(s *State) {
+		s.backendTimeout = max(d, 0)
+	}
+}
+
+// WithReconcileWorkers sets how many backends a reconcile cycle observes
+// concurrently, replacing DefaultReconcileWorkers, so a slow backend only
+// delays the others once every worker is waiting on one. n <= 1 observes
+// backends one at a time.
+func WithReconcileWorkers(n int) Option {
+	return func(s *State) {
+		s.reconcileWorkers = max(n, 1)
+	}
+}
+
 // New creates and initializes a new State manager.
 // It requires a database manager (dbInstance) to load the desired configurations
 // and a messenger instance (psInstance) for event handling and progress updates.
@@ -104,9 +165,12 @@ func WithAutoDiscoverModels() Option {
 // Returns an initialized State ready for use.
 func New(ctx context.Context, dbInstance libdb.DBManager, psInstance libbus.Messenger, options ...Option) (*State, error) {
 	s := &State{
-		dbInstance: dbInstance,
-		state:      sync.Map{},
-		psInstance: psInstance,
+		dbInstance:       dbInstance,
+		state:            sync.Map{},
+		psInstance:       psInstance,
+		trigger:          make(chan struct{}, 1),
+		backendTimeout:   DefaultBackendTimeout,
+		reconcileWorkers: DefaultReconcileWorkers,
 	}
 	if psInstance == nil {
 		return nil, errors.New("psInstance cannot be nil")
@@ -223,6 +287,7 @@ func (s *State) cleanupStaleBackends(currentIDs map[string]struct{}) error {
 		}
 		if _, exists := currentIDs[id]; !exists {
 			s.state.Delete(id)
+			s.declared.Delete(id)
 		}
 		return true
 	})
@@ -283,13 +348,15 @@ func (s *State) syncBackendsWithgroups(ctx context.Context) error {
 	}
 
 	// Now, process each unique backend once with its fully aggregated list of models.
+	work := make([]backendWork, 0, len(allBackendObjects))
 	for backendID, backendObj := range allBackendObjects {
 		modelsForThisBackend := make([]*runtimetypes.Model, 0, len(backendToAggregatedModels[backendID]))
 		for _, model := range backendToAggregatedModels[backendID] {
 			modelsForThisBackend = append(modelsForThisBackend, model)
 		}
-		s.processBackend(ctx, backendObj, modelsForThisBackend)
+		work = append(work, backendWork{backend: backendObj, models: modelsForThisBackend})
 	}
+	s.processConcurrently(ctx, work)
 
 	return s.cleanupStaleBackends(activeBackendIDs)
 }
@@ -323,9 +390,60 @@ func (s *State) syncBackends(ctx context.Context) error {
 
 // Helper method to process backends and collect their IDs
 func (s *State) processBackends(ctx context.Context, backends []*runtimetypes.Backend, models []*runtimetypes.Model, currentIDs map[string]struct{}) {
+	work := make([]backendWork, 0, len(backends))
 	for _, backend := range backends {
 		currentIDs[backend.ID] = struct{}{}
-		s.processBackend(ctx, backend, models)
+		work = append(work, backendWork{backend: backend, models: models})
+	}
+	s.processConcurrently(ctx, work)
+}
+
+// backendWork is one backend a reconcile cycle observes, with the models
+// declared for it.
+type backendWork struct {
+	backend *runtimetypes.Backend
+	models  []*runtimetypes.Model
+}
+
+// processConcurrently observes the backends in work on up to
+// s.reconcileWorkers goroutines and returns once all are done. Each backend
+// writes only its own snapshot entry (s.state and s.declared are sync.Maps),
+// and callers collect the active backend IDs before calling, so
+// cleanupStaleBackends afterwards sees every backend of the cycle.
+func (s *State) processConcurrently(ctx context.Context, work []backendWork) {
+	var g errgroup.Group
+	g.SetLimit(max(s.reconcileWorkers, 1))
+	for _, w := range work {
+		g.Go(func() error {
+			s.processBackendWithTimeout(ctx, w.backend, w.models)
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
+
+// processBackendWithTimeout runs processBackend under the configured backend
+// timeout. When the timeout cut the observation short, the error it left in
+// the snapshot is replaced with one that says so; a cancelled cycle is left
+// as processBackend recorded it.
+func (s *State) processBackendWithTimeout(ctx context.Context, backend *runtimetypes.Backend, declaredModels []*runtimetypes.Model) {
+	if s.backendTimeout <= 0 {
+		s.processBackend(ctx, backend, declaredModels)
+		return
+	}
+	backendCtx, cancel := context.WithTimeout(ctx, s.backendTimeout)
+	defer cancel()
+	s.processBackend(backendCtx, backend, declaredModels)
+
+	if ctx.Err() != nil || !errors.Is(backendCtx.Err(), context.DeadlineExceeded) {
+		return
+	}
+	if v, ok := s.state.Load(backend.ID); ok {
+		if observed, ok := v.(*statetype.BackendRuntimeState); ok && observed.Error != "" {
+			timedOut := *observed
+			timedOut.Error = fmt.Sprintf("backend did not respond within %s: %s", s.backendTimeout, observed.Error)
+			s.state.Store(backend.ID, &timedOut)
+		}
 	}
 }
 
@@ -335,6 +453,7 @@ func (s *State) processBackends(ctx context.Context, backends []*runtimetypes.Ba
 // including any errors encountered for unsupported types.
 // Helper method to process backends and collect their IDs
 func (s *State) processBackend(ctx context.Context, backend *runtimetypes.Backend, declaredModels []*runtimetypes.Model) {
+	s.recordDeclared(backend.ID, declaredModels)
 	switch modelrepo.CanonicalBackendType(backend.Type) {
 	case "ollama":
 		s.processOllamaBackend(ctx, backend, declaredModels)
@@ -391,7 +510,7 @@ func (s *State) processOllamaBackend(ctx context.Context, backend *runtimetypes.
 		apiKey = key
 	}
 
-	catalog, err := s.newCatalogProvider(backend, apiKey)
+	catalog, err := s.newCatalogProvider(ctx, backend, apiKey)
 	if err != nil {
 		storeBackendError(s, backend, apiKey, err, models)
 		return
@@ -415,12 +534,15 @@ func (s *State) processOllamaBackend(ctx context.Context, backend *runtimetypes.
 	pulledModels := make([]statetype.ModelPullStatus, 0, len(observedModels))
 	for _, observed := range observedModels {
 		lmr := pullStatusFromObservedModel(observed)
+		if decl, exists := declaredModelMap[observed.Name]; exists {
+			s.resolveContextLength(ctx, backend, apiKey, &lmr, decl.ContextLength)
+		}
 
 		// If the declared model has no context_length yet (auto-detect placeholder),
 		// write the discovered value back to the DB so subsequent cycles skip re-learning.
-		if decl, exists := declaredModelMap[observed.Name]; exists && decl.ContextLength == 0 && lmr.ContextLength > 0 {
+		if decl, exists := declaredModelMap[observed.Name]; exists && decl.ContextLength == 0 && lmr.DetectedContextLength > 0 {
 			declCopy := decl
-			declCopy.ContextLength = lmr.ContextLength
+			declCopy.ContextLength = lmr.DetectedContextLength
 			declCopy.CanChat = lmr.CanChat
 			declCopy.CanEmbed = lmr.CanEmbed
 			declCopy.CanPrompt = lmr.CanPrompt
@@ -428,11 +550,10 @@ func (s *State) processOllamaBackend(ctx context.Context, backend *runtimetypes.
 			_ = runtimetypes.New(s.dbInstance.WithoutTransaction()).UpdateModel(ctx, &declCopy)
 		}
 
-		// Declared caps act as explicit overrides (admin intent wins over observed values).
+		// Declared caps act as explicit overrides (admin intent wins over observed
+		// values). The context window is the exception: resolveContextLength
+		// already preferred the detected value over the declared one.
 		if declaredModel, exists := declaredModelMap[observed.Name]; exists {
-			if declaredModel.ContextLength > 0 {
-				lmr.ContextLength = declaredModel.ContextLength
-			}
 			if declaredModel.CanChat {
 				lmr.CanChat = true
 			}
@@ -458,12 +579,13 @@ func (s *State) processOllamaBackend(ctx context.Context, backend *runtimetypes.
 		stateservice.Models = models
 	}
 	s.state.Store(backend.ID, stateservice)
+	s.warmDeclaredModels(ctx, backend, apiKey, stateservice, declaredModelMap)
 }
 
 // processLocalBackend handles state reconciliation for a llama.cpp backend.
 // It scans the model directory (stored in backend.BaseURL) for GGUF model subdirectories.
 func (s *State) processLocalBackend(ctx context.Context, backend *runtimetypes.Backend, _ []*runtimetypes.Model) {
-	catalog, err := s.newCatalogProvider(backend, "")
+	catalog, err := s.newCatalogProvider(ctx, backend, "")
 	if err != nil {
 		storeBackendError(s, backend, "", err, nil)
 		return
@@ -587,11 +709,12 @@ func (s *State) processModeldBackend(ctx context.Context, backend *runtimetypes.
 // since a single-slot, already-reconciled node serves every listed model.
 func modelPullStatusFromNodeModel(m transport.NodeModel) statetype.ModelPullStatus {
 	return statetype.ModelPullStatus{
-		Name:          m.Name,
-		Model:         m.Name,
-		Size:          m.SizeBytes,
-		Digest:        m.Digest,
-		ContextLength: m.ContextLength,
+		Name:                  m.Name,
+		Model:                 m.Name,
+		Size:                  m.SizeBytes,
+		Digest:                m.Digest,
+		ContextLength:         m.ContextLength,
+		DetectedContextLength: m.ContextLength,
 	}
 }
 
@@ -603,7 +726,7 @@ func (s *State) processVLLMBackend(ctx context.Context, backend *runtimetypes.Ba
 	for _, m := range models {
 		declaredModelMap[m.Model] = m
 	}
-	catalog, err := s.newCatalogProvider(backend, "")
+	catalog, err := s.newCatalogProvider(ctx, backend, "")
 	if err != nil {
 		storeBackendError(s, backend, "", err, nil)
 		return
@@ -629,25 +752,23 @@ func (s *State) processVLLMBackend(ctx context.Context, backend *runtimetypes.Ba
 	pulledModels := make([]statetype.ModelPullStatus, 0, len(observedModels))
 	for _, observed := range observedModels {
 		if declaredModel, exists := declaredModelMap[observed.Name]; exists {
-			effectiveContextLen := declaredModel.ContextLength
-			if effectiveContextLen == 0 && observed.ContextLength > 0 {
-				effectiveContextLen = observed.ContextLength
+			lmr := statetype.ModelPullStatus{
+				Name:                  declaredModel.ID,
+				Model:                 declaredModel.Model,
+				ModifiedAt:            declaredModel.UpdatedAt,
+				DetectedContextLength: observed.ContextLength,
+				MaxOutputTokens:       observed.MaxOutputTokens,
+				CanChat:               declaredModel.CanChat,
+				CanEmbed:              declaredModel.CanEmbed,
+				CanPrompt:             declaredModel.CanPrompt,
+				CanStream:             declaredModel.CanStream,
+			}
+			s.resolveContextLength(ctx, backend, "", &lmr, declaredModel.ContextLength)
+			if declaredModel.ContextLength == 0 && lmr.DetectedContextLength > 0 {
 				declCopy := *declaredModel
-				declCopy.ContextLength = observed.ContextLength
+				declCopy.ContextLength = lmr.DetectedContextLength
 				_ = runtimetypes.New(s.dbInstance.WithoutTransaction()).UpdateModel(ctx, &declCopy)
 			}
-
-			lmr := statetype.ModelPullStatus{
-				Name:            declaredModel.ID,
-				Model:           declaredModel.Model,
-				ModifiedAt:      declaredModel.UpdatedAt,
-				ContextLength:   effectiveContextLen,
-				MaxOutputTokens: observed.MaxOutputTokens,
-				CanChat:         declaredModel.CanChat,
-				CanEmbed:        declaredModel.CanEmbed,
-				CanPrompt:       declaredModel.CanPrompt,
-				CanStream:       declaredModel.CanStream,
-			}
 			lmr = s.applyCapabilityOverrides(ctx, backend.Type, lmr)
 			pulledModels = append(pulledModels, lmr)
 			continue
@@ -697,7 +818,7 @@ func (s *State) processGeminiBackend(ctx context.Context, backend *runtimetypes.
 		return
 	}
 
-	catalog, err := s.newCatalogProvider(backend, apiKey)
+	catalog, err := s.newCatalogProvider(ctx, backend, apiKey)
 	if err != nil {
 		stateInstance.Error = err.Error()
 		s.state.Store(backend.ID, stateInstance)
@@ -761,7 +882,7 @@ func (s *State) processOptionalCredCloudBackend(ctx context.Context, backend *ru
 		return
 	}
 
-	catalog, err := s.newCatalogProvider(backend, credJSON)
+	catalog, err := s.newCatalogProvider(ctx, backend, credJSON)
 	if err != nil {
 		stateInstance.Error = err.Error()
 		s.state.Store(backend.ID, stateInstance)
@@ -813,7 +934,7 @@ func (s *State) processOpenAIBackend(ctx context.Context, backend *runtimetypes.
 
 	observedModels, ok := s.loadObservedModelCache(ctx, backend.ID, apiKey)
 	if !ok {
-		catalog, err := s.newCatalogProvider(backend, apiKey)
+		catalog, err := s.newCatalogProvider(ctx, backend, apiKey)
 		if err != nil {
 			stateInstance.Error = err.Error()
 			s.state.Store(backend.ID, stateInstance)
@@ -834,16 +955,17 @@ func (s *State) processOpenAIBackend(ctx context.Context, backend *runtimetypes.
 	for _, observed := range observedModels {
 		if declaredModel, exists := declaredModels[observed.Name]; exists {
 			lmr := statetype.ModelPullStatus{
-				Name:            declaredModel.ID,
-				Model:           declaredModel.Model,
-				ModifiedAt:      declaredModel.UpdatedAt,
-				ContextLength:   declaredModel.ContextLength,
-				MaxOutputTokens: observed.MaxOutputTokens,
-				CanChat:         declaredModel.CanChat,
-				CanEmbed:        declaredModel.CanEmbed,
-				CanPrompt:       declaredModel.CanPrompt,
-				CanStream:       declaredModel.CanStream,
+				Name:                  declaredModel.ID,
+				Model:                 declaredModel.Model,
+				ModifiedAt:            declaredModel.UpdatedAt,
+				DetectedContextLength: observed.ContextLength,
+				MaxOutputTokens:       observed.MaxOutputTokens,
+				CanChat:               declaredModel.CanChat,
+				CanEmbed:              declaredModel.CanEmbed,
+				CanPrompt:             declaredModel.CanPrompt,
+				CanStream:             declaredModel.CanStream,
 			}
+			s.resolveContextLength(ctx, backend, apiKey, &lmr, declaredModel.ContextLength)
 			lmr = s.applyCapabilityOverrides(ctx, backend.Type, lmr)
 			pulledModels = append(pulledModels, lmr)
 			continue
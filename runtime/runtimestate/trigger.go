@@ -0,0 +1,46 @@
+package runtimestate
+
+import (
+	"context"
+	"time"
+)
+
+// TriggerReconcile asks a running RunReconcileLoop for a cycle now instead of
+// at its next tick — e.g. right after a backend is created, so it shows up in
+// the runtime state within seconds. It never blocks: the request is a flag on
+// a depth-1 channel, so any number of triggers that arrive while a cycle is
+// pending or running coalesce into a single follow-up cycle. With no loop
+// running the trigger waits for one to start.
+func (s *State) TriggerReconcile() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// RunReconcileLoop runs RunBackendCycle on every TriggerReconcile and, when
+// interval is positive, on a fixed cadence, until ctx is done. A non-positive
+// interval keeps the runtime's default of reconciling only on demand. Cycle
+// errors are not fatal to the loop; each backend's failure is already recorded
+// in its runtime state.
+//
+// This is the one place State schedules its own cycles. It stays opt-in, in
+// line with RunBackendCycle's design note: the caller decides whether the
+// loop runs, on which context, and at what interval.
+func (s *State) RunReconcileLoop(ctx context.Context, interval time.Duration) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-s.trigger:
+		}
+		_ = s.RunBackendCycle(ctx)
+	}
+}
@@ -0,0 +1,45 @@
+package runtimestate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_TriggerReconcile_CoalescesWhilePending(t *testing.T) {
+	s := &State{trigger: make(chan struct{}, 1)}
+	for range 5 {
+		s.TriggerReconcile() // must never block
+	}
+	require.Len(t, s.trigger, 1, "a burst of triggers collapses into one pending cycle")
+}
+
+func TestUnit_RunReconcileLoop_RunsOnTrigger(t *testing.T) {
+	ctx, state, _ := newReconcileStateTest(t)
+	loopCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		state.RunReconcileLoop(loopCtx, 0)
+	}()
+
+	state.reconcileMu.Lock()
+	before := state.lastReconcileAt
+	state.reconcileMu.Unlock()
+
+	state.TriggerReconcile()
+	require.Eventually(t, func() bool {
+		state.reconcileMu.Lock()
+		defer state.reconcileMu.Unlock()
+		return state.lastReconcileAt.After(before)
+	}, 5*time.Second, 10*time.Millisecond, "a trigger runs a cycle without waiting for a tick")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunReconcileLoop did not return after its context was cancelled")
+	}
+}
@@ -0,0 +1,79 @@
+package runtimestate
+
+import (
+	"context"
+	"time"
+
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/contenox/runtime/runtime/statetype"
+)
+
+// WarmUpTimeout bounds the warm-up of one model. Loading a large model takes
+// far longer than listing a backend's models, so warm-up runs outside the
+// backend timeout with this bound instead. It is a package var so tests can
+// shorten it.
+var WarmUpTimeout = 5 * time.Minute
+
+// ModelWarmer loads model on backend and asks the backend to keep it resident
+// for keepAlive. apiKey is the resolved provider credential and embedOnly
+// marks a model that can embed but neither chat nor prompt. The runtime wires
+// in a closure over ollama.WarmUp.
+type ModelWarmer func(ctx context.Context, backend *runtimetypes.Backend, apiKey, model string, embedOnly bool, keepAlive time.Duration) error
+
+// WithWarmUp makes reconciliation send a keep-alive request for every
+// declared model an Ollama backend has pulled, so the model is loaded before
+// the first request needs it and stays resident for keepAlive after each
+// cycle. With a reconcile interval shorter than keepAlive the models never
+// unload. Each outcome is recorded on the model's ModelPullStatus.WarmUp.
+// keepAlive <= 0 or a nil warm disables warm-up.
+func WithWarmUp(keepAlive time.Duration, warm ModelWarmer) Option {
+	return func(s *State) {
+		if keepAlive <= 0 || warm == nil {
+			s.keepAlive, s.warmer = 0, nil
+			return
+		}
+		s.keepAlive, s.warmer = keepAlive, warm
+	}
+}
+
+// warmDeclaredModels sends the keep-alive request for each declared model in
+// observed and stores a copy of the snapshot carrying the outcomes. It runs
+// after observed was stored, so a model that is slow to load does not hold
+// back the rest of the backend's state. It is detached from ctx's deadline,
+// which bounds observing the backend: each model gets WarmUpTimeout of its
+// own, and one that has not loaded in time is reported as failed and retried
+// on the next cycle. credential is the stored provider credential.
+func (s *State) warmDeclaredModels(ctx context.Context, backend *runtimetypes.Backend, credential string, observed *statetype.BackendRuntimeState, declared map[string]runtimetypes.Model) {
+	if s.warmer == nil || len(declared) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	apiKey, keyErr := s.resolveAPIKey(ctx, credential)
+
+	warmed := *observed
+	warmed.PulledModels = append([]statetype.ModelPullStatus(nil), observed.PulledModels...)
+	changed := false
+	for i := range warmed.PulledModels {
+		lmr := &warmed.PulledModels[i]
+		if _, ok := declared[lmr.Model]; !ok {
+			continue
+		}
+		err := keyErr
+		if err == nil {
+			embedOnly := lmr.CanEmbed && !lmr.CanChat && !lmr.CanPrompt
+			warmCtx, cancel := context.WithTimeout(ctx, WarmUpTimeout)
+			err = s.warmer(warmCtx, backend, apiKey, lmr.Model, embedOnly, s.keepAlive)
+			cancel()
+		}
+		status := &statetype.ModelWarmUp{Status: statetype.WarmUpLoaded, At: time.Now().UTC()}
+		if err != nil {
+			status.Status = statetype.WarmUpFailed
+			status.Error = err.Error()
+		}
+		lmr.WarmUp = status
+		changed = true
+	}
+	if changed {
+		s.state.Store(backend.ID, &warmed)
+	}
+}
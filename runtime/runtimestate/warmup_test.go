@@ -0,0 +1,124 @@
+package runtimestate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/runtime/modelrepo/ollama"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+	"github.com/contenox/runtime/runtime/statetype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_RunBackendCycle_WarmsDeclaredOllamaModels(t *testing.T) {
+	var mu sync.Mutex
+	warmed := map[string]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/tags":
+			_ = json.NewEncoder(w).Encode(map[string]any{"models": []map[string]any{
+				{"name": "chat-model", "model": "chat-model"},
+				{"name": "embed-model", "model": "embed-model"},
+				{"name": "broken-model", "model": "broken-model"},
+				{"name": "undeclared-model", "model": "undeclared-model"},
+			}})
+		case "/api/generate", "/api/embed":
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			mu.Lock()
+			warmed[body["model"].(string)] = map[string]any{"path": r.URL.Path, "keep_alive": body["keep_alive"]}
+			mu.Unlock()
+			if body["model"] == "broken-model" {
+				w.WriteHeader(http.StatusInternalServerError)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": "model requires more system memory"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"model": body["model"], "done": true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	warm := func(ctx context.Context, backend *runtimetypes.Backend, apiKey, model string, embedOnly bool, keepAlive time.Duration) error {
+		return ollama.WarmUp(ctx, backend.BaseURL, apiKey, model, embedOnly, keepAlive, server.Client())
+	}
+	ctx, state, db := newReconcileStateTest(t, WithWarmUp(30*time.Minute, warm))
+	store := runtimetypes.New(db.WithoutTransaction())
+	require.NoError(t, store.CreateBackend(ctx, &runtimetypes.Backend{ID: "ollama", Name: "ollama", Type: "ollama", BaseURL: server.URL}))
+	for _, m := range []*runtimetypes.Model{
+		{ID: "chat", Model: "chat-model", ContextLength: 4096, CanChat: true, CanPrompt: true},
+		{ID: "embed", Model: "embed-model", ContextLength: 512, CanEmbed: true},
+		{ID: "broken", Model: "broken-model", ContextLength: 4096, CanChat: true},
+	} {
+		require.NoError(t, store.AppendModel(ctx, m))
+	}
+
+	require.NoError(t, state.RunBackendCycle(ctx))
+
+	mu.Lock()
+	require.Equal(t, map[string]map[string]any{
+		"chat-model":   {"path": "/api/generate", "keep_alive": "30m0s"},
+		"embed-model":  {"path": "/api/embed", "keep_alive": "30m0s"},
+		"broken-model": {"path": "/api/generate", "keep_alive": "30m0s"},
+	}, warmed, "only declared models are warmed, embed-only ones through /api/embed")
+	mu.Unlock()
+
+	statuses := map[string]*statetype.ModelWarmUp{}
+	for _, lmr := range state.Get(ctx)["ollama"].PulledModels {
+		statuses[lmr.Model] = lmr.WarmUp
+	}
+	require.Equal(t, statetype.WarmUpLoaded, statuses["chat-model"].Status)
+	require.Equal(t, statetype.WarmUpLoaded, statuses["embed-model"].Status)
+	require.Equal(t, statetype.WarmUpFailed, statuses["broken-model"].Status)
+	require.Contains(t, statuses["broken-model"].Error, "model requires more system memory")
+	require.Nil(t, statuses["undeclared-model"])
+}
+
+func TestUnit_RunBackendCycle_WarmUpOutlivesBackendTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"models": []map[string]any{
+			{"name": "first-model", "model": "first-model"},
+			{"name": "second-model", "model": "second-model"},
+		}})
+	}))
+	defer server.Close()
+
+	defer func(d time.Duration) { WarmUpTimeout = d }(WarmUpTimeout)
+	WarmUpTimeout = 300 * time.Millisecond
+	// Each model loads within WarmUpTimeout, but the two together take longer,
+	// and either alone outlasts the backend timeout.
+	warm := func(ctx context.Context, _ *runtimetypes.Backend, _, _ string, _ bool, _ time.Duration) error {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	ctx, state, db := newReconcileStateTest(t, WithWarmUp(30*time.Minute, warm), WithBackendTimeout(100*time.Millisecond))
+	store := runtimetypes.New(db.WithoutTransaction())
+	require.NoError(t, store.CreateBackend(ctx, &runtimetypes.Backend{ID: "ollama", Name: "ollama", Type: "ollama", BaseURL: server.URL}))
+	for _, m := range []*runtimetypes.Model{
+		{ID: "first", Model: "first-model", ContextLength: 4096, CanChat: true},
+		{ID: "second", Model: "second-model", ContextLength: 4096, CanChat: true},
+	} {
+		require.NoError(t, store.AppendModel(ctx, m))
+	}
+
+	require.NoError(t, state.RunBackendCycle(ctx))
+
+	observed := state.Get(ctx)["ollama"]
+	require.Empty(t, observed.Error)
+	for _, lmr := range observed.PulledModels {
+		require.NotNil(t, lmr.WarmUp, lmr.Model)
+		require.Equal(t, statetype.WarmUpLoaded, lmr.WarmUp.Status, "%s: %s", lmr.Model, lmr.WarmUp.Error)
+	}
+}
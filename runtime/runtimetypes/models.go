@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	libdb "github.com/contenox/runtime/libdbexec"
@@ -226,6 +227,72 @@ func (s *store) ListModels(ctx context.Context, createdAtCursor *time.Time, limi
 	return models, nil
 }
 
+// ListModelsFiltered returns up to limit models matching filter, newest
+// first, starting after cursor (nil starts at the newest model). Pages are
+// ordered on (created_at, id), so passing the last model of one page as the
+// cursor of the next neither skips nor repeats a model that shares its
+// creation time with another.
+func (s *store) ListModelsFiltered(ctx context.Context, filter ModelFilter, cursor *ModelCursor, limit int) ([]*Model, error) {
+	after := ModelCursor{CreatedAt: time.Now().UTC()}
+	if cursor != nil {
+		after = *cursor
+	}
+	if limit > MAXLIMIT {
+		return nil, ErrLimitParamExceeded
+	}
+	namePattern := ""
+	if filter.NamePrefix != "" {
+		namePattern = likePrefixPattern(strings.ToLower(filter.NamePrefix))
+	}
+	rows, err := s.Exec.QueryContext(ctx, `
+        SELECT m.id, m.model, m.context_length, m.can_chat, m.can_embed, m.can_prompt, m.can_stream, m.created_at, m.updated_at
+        FROM ollama_models m
+        WHERE (m.created_at < $1 OR (m.created_at = $1 AND m.id < $2))
+          AND ($3 = '' OR LOWER(m.model) LIKE $3 ESCAPE '\')
+          AND ($4 = '' OR EXISTS (
+              SELECT 1 FROM ollama_model_assignments a
+              WHERE a.model_id = m.id AND a.llm_group_id = $4))
+        ORDER BY m.created_at DESC, m.id DESC
+        LIMIT $5;
+    `, after.CreatedAt, after.ID, namePattern, filter.AffinityGroupID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query models: %w", err)
+	}
+	defer rows.Close()
+
+	models := []*Model{}
+	for rows.Next() {
+		var model Model
+		if err := rows.Scan(
+			&model.ID,
+			&model.Model,
+			&model.ContextLength,
+			&model.CanChat,
+			&model.CanEmbed,
+			&model.CanPrompt,
+			&model.CanStream,
+			&model.CreatedAt,
+			&model.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan model: %w", err)
+		}
+		models = append(models, &model)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return models, nil
+}
+
+// likePrefixPattern turns prefix into a LIKE pattern matching strings that
+// start with it, escaping the wildcards it may contain with a backslash.
+func likePrefixPattern(prefix string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return r.Replace(prefix) + "%"
+}
+
 func (s *store) EstimateModelCount(ctx context.Context) (int64, error) {
 	return s.estimateCount(ctx, "ollama_models")
 }
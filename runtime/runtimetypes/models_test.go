@@ -273,3 +273,80 @@ func TestUnit_Models_ZeroContextLength(t *testing.T) {
 	err = s.AppendModel(ctx, noName)
 	require.Error(t, err)
 }
+
+func TestUnit_Models_ListFilteredByNamePrefix(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+
+	for _, name := range []string{"qwen2.5:7b", "Qwen3:8b", "qwen_x", "llama3:8b"} {
+		require.NoError(t, s.AppendModel(ctx, &runtimetypes.Model{Model: name, CanChat: true}))
+	}
+
+	models, err := s.ListModelsFiltered(ctx, runtimetypes.ModelFilter{NamePrefix: "QWEN"}, nil, 100)
+	require.NoError(t, err)
+	require.Equal(t, []string{"qwen_x", "Qwen3:8b", "qwen2.5:7b"}, modelNames(models), "prefix match is case-insensitive, newest first")
+
+	models, err = s.ListModelsFiltered(ctx, runtimetypes.ModelFilter{NamePrefix: "qwen_"}, nil, 100)
+	require.NoError(t, err)
+	require.Equal(t, []string{"qwen_x"}, modelNames(models), "wildcards in the prefix match literally")
+
+	models, err = s.ListModelsFiltered(ctx, runtimetypes.ModelFilter{}, nil, 100)
+	require.NoError(t, err)
+	require.Len(t, models, 4)
+
+	_, err = s.ListModelsFiltered(ctx, runtimetypes.ModelFilter{}, nil, runtimetypes.MAXLIMIT+1)
+	require.ErrorIs(t, err, runtimetypes.ErrLimitParamExceeded)
+}
+
+func TestUnit_Models_ListFilteredByAffinityGroup(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+
+	group := &runtimetypes.AffinityGroup{ID: uuid.NewString(), Name: "chat-pool", PurposeType: "inference"}
+	require.NoError(t, s.CreateAffinityGroup(ctx, group))
+
+	var ids []string
+	for i := range 3 {
+		model := &runtimetypes.Model{Model: fmt.Sprintf("pooled%d", i), CanChat: true}
+		require.NoError(t, s.AppendModel(ctx, model))
+		ids = append(ids, model.ID)
+	}
+	require.NoError(t, s.AssignModelToAffinityGroup(ctx, group.ID, ids[0]))
+	require.NoError(t, s.AssignModelToAffinityGroup(ctx, group.ID, ids[2]))
+
+	models, err := s.ListModelsFiltered(ctx, runtimetypes.ModelFilter{AffinityGroupID: group.ID}, nil, 100)
+	require.NoError(t, err)
+	require.Equal(t, []string{"pooled2", "pooled0"}, modelNames(models))
+
+	models, err = s.ListModelsFiltered(ctx, runtimetypes.ModelFilter{AffinityGroupID: group.ID, NamePrefix: "pooled0"}, nil, 100)
+	require.NoError(t, err)
+	require.Equal(t, []string{"pooled0"}, modelNames(models))
+}
+
+func TestUnit_Models_ListFilteredPagination(t *testing.T) {
+	ctx, s := runtimetypes.SetupStore(t)
+
+	for i := range 5 {
+		require.NoError(t, s.AppendModel(ctx, &runtimetypes.Model{Model: fmt.Sprintf("paged%d", i), CanChat: true}))
+	}
+
+	var names []string
+	var cursor *runtimetypes.ModelCursor
+	for range 10 {
+		page, err := s.ListModelsFiltered(ctx, runtimetypes.ModelFilter{NamePrefix: "paged"}, cursor, 2)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		names = append(names, modelNames(page)...)
+		last := page[len(page)-1]
+		cursor = &runtimetypes.ModelCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	require.Equal(t, []string{"paged4", "paged3", "paged2", "paged1", "paged0"}, names)
+}
+
+func modelNames(models []*runtimetypes.Model) []string {
+	names := make([]string, 0, len(models))
+	for _, m := range models {
+		names = append(names, m.Model)
+	}
+	return names
+}
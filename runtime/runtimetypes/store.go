@@ -56,6 +56,24 @@ type Model struct {
 	UpdatedAt     time.Time `json:"updatedAt" example:"2023-11-15T14:30:45Z"`
 }
 
+// ModelFilter narrows ListModelsFiltered. Zero fields match everything.
+type ModelFilter struct {
+	// NamePrefix keeps models whose name starts with it, compared
+	// case-insensitively.
+	NamePrefix string
+	// AffinityGroupID keeps models assigned to that affinity group.
+	AffinityGroupID string
+}
+
+// ModelCursor is the position after which ListModelsFiltered resumes: the
+// CreatedAt and ID of the last model of the previous page. Ordering on both
+// keeps pages stable when models are created in the same instant or while
+// the caller is paging.
+type ModelCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
 // AffinityGroup represents a logical grouping that defines preferred relationships
 // between models and backends. Entities can
 // belong to multiple affinity groups simultaneously.
@@ -153,6 +171,7 @@ type Store interface {
 	ListAllModels(ctx context.Context) ([]*Model, error)
 	UpdateModel(ctx context.Context, data *Model) error
 	ListModels(ctx context.Context, createdAtCursor *time.Time, limit int) ([]*Model, error)
+	ListModelsFiltered(ctx context.Context, filter ModelFilter, cursor *ModelCursor, limit int) ([]*Model, error)
 	EstimateModelCount(ctx context.Context) (int64, error)
 
 	CreateAffinityGroup(ctx context.Context, group *AffinityGroup) error
@@ -0,0 +1,171 @@
+package serverapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/apikeyservice"
+	"github.com/contenox/runtime/runtime/internal/apikeyapi"
+	"github.com/contenox/runtime/runtime/internal/promptapi"
+	"github.com/contenox/runtime/runtime/promptservice"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+)
+
+// keyedServer serves the real /api-keys and /prompts routes behind the API
+// gate the way serve mounts them, with the key service's live set enforced.
+func keyedServer(t *testing.T) (*httptest.Server, apikeyservice.Service) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "keys.db"), runtimetypes.SchemaSQLite)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	keys, err := apikeyservice.New(ctx, db, nil)
+	if err != nil {
+		t.Fatalf("key service: %v", err)
+	}
+
+	apiMux := http.NewServeMux()
+	apikeyapi.AddRoutes(apiMux, keys)
+	promptapi.AddRoutes(apiMux, promptservice.New(db, nil))
+	rootMux := http.NewServeMux()
+	rootMux.Handle("/api/", http.StripPrefix("/api", ProtectAPIWithKeys(testToken, keys.Keys(), "", apiMux)))
+	srv := httptest.NewServer(rootMux)
+	t.Cleanup(srv.Close)
+	return srv, keys
+}
+
+func keyedRequest(t *testing.T, srv *httptest.Server, method, path, key string) int {
+	t.Helper()
+	req, _ := http.NewRequest(method, srv.URL+path, nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func createKey(t *testing.T, keys apikeyservice.Service, identity string) *apikeyservice.CreatedKey {
+	t.Helper()
+	key, err := keys.Create(context.Background(), &apikeyservice.CreateRequest{Identity: identity})
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	return key
+}
+
+func TestServe_APIKey_RevokedKeyIs401(t *testing.T) {
+	srv, keys := keyedServer(t)
+	ci := createKey(t, keys, "ci")
+
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", ci.Secret); code != http.StatusOK {
+		t.Fatalf("GET /api/prompts with a fresh key: status = %d, want 200", code)
+	}
+	if err := keys.Revoke(context.Background(), ci.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", ci.Secret); code != http.StatusUnauthorized {
+		t.Fatalf("GET /api/prompts with a revoked key: status = %d, want 401", code)
+	}
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", testToken); code != http.StatusOK {
+		t.Fatalf("GET /api/prompts with the TOKEN: status = %d, want 200", code)
+	}
+}
+
+func TestServe_APIKey_RotatedKeyReplacesOld(t *testing.T) {
+	srv, keys := keyedServer(t)
+	old := createKey(t, keys, "ci")
+	next := createKey(t, keys, "ci")
+
+	// Until the old key is revoked both work, so clients switch over
+	// without a gap.
+	for _, key := range []string{old.Secret, next.Secret} {
+		if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", key); code != http.StatusOK {
+			t.Fatalf("GET /api/prompts during the overlap: status = %d, want 200", code)
+		}
+	}
+	if err := keys.Revoke(context.Background(), old.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", old.Secret); code != http.StatusUnauthorized {
+		t.Fatalf("old key after rotation: status = %d, want 401", code)
+	}
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", next.Secret); code != http.StatusOK {
+		t.Fatalf("new key after rotation: status = %d, want 200", code)
+	}
+}
+
+// keyedCall is keyedRequest with a JSON body, returning the response body too.
+func keyedCall(t *testing.T, srv *httptest.Server, method, path, key, body string) (int, string) {
+	t.Helper()
+	req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+key)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(raw)
+}
+
+func TestServe_APIKey_ManagedOverTheAPI(t *testing.T) {
+	srv, _ := keyedServer(t)
+	code, raw := keyedCall(t, srv, http.MethodPost, "/api/api-keys", testToken, `{"identity":"ci"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("POST /api/api-keys: status = %d, body %s", code, raw)
+	}
+	var ci apikeyservice.CreatedKey
+	if err := json.Unmarshal([]byte(raw), &ci); err != nil || ci.Secret == "" {
+		t.Fatalf("POST /api/api-keys: decode %q: %v", raw, err)
+	}
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", ci.Secret); code != http.StatusOK {
+		t.Fatalf("GET /api/prompts with the created key: status = %d, want 200", code)
+	}
+	code, raw = keyedCall(t, srv, http.MethodGet, "/api/api-keys", testToken, "")
+	if code != http.StatusOK || strings.Contains(raw, ci.Secret) {
+		t.Fatalf("GET /api/api-keys: status = %d, body %s; want 200 without secrets", code, raw)
+	}
+	if code, raw := keyedCall(t, srv, http.MethodDelete, "/api/api-keys/"+ci.ID, ci.Secret, ""); code != http.StatusOK {
+		t.Fatalf("DELETE /api/api-keys/{id}: status = %d, body %s", code, raw)
+	}
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", ci.Secret); code != http.StatusUnauthorized {
+		t.Fatalf("GET /api/prompts after revoking over the API: status = %d, want 401", code)
+	}
+}
+
+func TestServe_APIKey_ScopesLimitRouteGroups(t *testing.T) {
+	srv, _ := keyedServer(t)
+	code, raw := keyedCall(t, srv, http.MethodPost, "/api/api-keys", testToken, `{"identity":"dash","scopes":[{"resource":"prompts","access":"read"}]}`)
+	if code != http.StatusCreated {
+		t.Fatalf("POST /api/api-keys: status = %d, body %s", code, raw)
+	}
+	var reader apikeyservice.CreatedKey
+	if err := json.Unmarshal([]byte(raw), &reader); err != nil {
+		t.Fatalf("decode %q: %v", raw, err)
+	}
+
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", reader.Secret); code != http.StatusOK {
+		t.Fatalf("GET /api/prompts with read scope: status = %d, want 200", code)
+	}
+	if code, _ := keyedCall(t, srv, http.MethodPost, "/api/prompts", reader.Secret, `{"name":"p","template":"t"}`); code != http.StatusForbidden {
+		t.Fatalf("POST /api/prompts with read scope: status = %d, want 403", code)
+	}
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/api-keys", reader.Secret); code != http.StatusForbidden {
+		t.Fatalf("GET /api/api-keys without its scope: status = %d, want 403", code)
+	}
+	if code, _ := keyedCall(t, srv, http.MethodPost, "/api/prompts", testToken, `{"name":"p","template":"t"}`); code != http.StatusCreated {
+		t.Fatalf("POST /api/prompts with the TOKEN: status = %d, want 201", code)
+	}
+}
@@ -0,0 +1,171 @@
+package serverapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/contenox/runtime/libtracker"
+)
+
+// auditBodyLimit bounds how much of a create response AuditMutations reads
+// for the new resource's ID.
+const auditBodyLimit = 4 << 10
+
+// auditedRoutes are the API routes that create, update or delete a stored
+// resource, as the handlers register them. Other mutating requests, such as
+// running a task, chatting, simulating a chain or reconciling state, change
+// no configuration and are not audited.
+var auditedRoutes = []string{
+	"POST /api-keys", "DELETE /api-keys/{id}",
+	"POST /approvals/{id}",
+	"POST /backends", "POST /backends/ensure", "POST /backends/import",
+	"PUT /backends/{id}", "DELETE /backends/{id}",
+	"PUT /cli-config",
+	"POST /files", "PUT /files", "DELETE /files", "POST /files/copy", "PUT /files/move",
+	"POST /folders", "DELETE /folders",
+	"DELETE /fleet/{instanceID}",
+	"POST /hitl-policies", "PUT /hitl-policies", "DELETE /hitl-policies",
+	"POST /mcp-servers", "PUT /mcp-servers/{id}", "DELETE /mcp-servers/{id}",
+	"POST /missions", "PATCH /missions/{id}", "DELETE /missions/{id}",
+	"POST /model-registry", "PUT /model-registry/{id}", "DELETE /model-registry/{id}",
+	"POST /prompts", "PUT /prompts/{name}", "DELETE /prompts/{name}",
+	"POST /providers/{providerType}/configure", "DELETE /providers/{providerType}/config",
+	"POST /taskchains", "PUT /taskchains", "DELETE /taskchains", "POST /taskchains/import",
+	"POST /tools/remote", "PUT /tools/remote/{id}", "DELETE /tools/remote/{id}",
+	"POST /workspace/roots", "DELETE /workspace/roots",
+}
+
+// auditedMux matches a request against auditedRoutes with the same pattern
+// rules the API mux routes it by.
+var auditedMux = func() *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, pattern := range auditedRoutes {
+		mux.Handle(pattern, http.NotFoundHandler())
+	}
+	return mux
+}()
+
+func audited(r *http.Request) bool {
+	_, pattern := auditedMux.Handler(r)
+	return pattern != ""
+}
+
+// AuditMutations reports every request to an auditedRoutes route to tracker
+// as a "create" (POST), "update" (PUT, PATCH) or "delete" (DELETE) operation
+// on the route group, the first segment of the path, so an audit tracker
+// (auditservice.NewTracker) records it under the caller's identity. The
+// resource ID is the rest of the path, or for a create the "id" or "name"
+// of the resource in the response. A response of 400 or above is reported
+// as an error. Mount it inside ProtectAPIWithKeys, where the identity is in
+// the context.
+func AuditMutations(tracker libtracker.ActivityTracker, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation := auditOperation(r.Method)
+		if operation == "" || !audited(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		group, resourceID, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+		reportErr, reportChange, end := tracker.Start(r.Context(), operation, group, "id", resourceID)
+		defer end()
+
+		rec := &auditRecorder{ResponseWriter: w, keepBody: operation == "create"}
+		next.ServeHTTP(rec, r)
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status >= http.StatusBadRequest {
+			reportErr(fmt.Errorf("%s %s: %d %s", r.Method, r.URL.Path, status, http.StatusText(status)))
+			return
+		}
+		if resourceID == "" {
+			resourceID = createdResourceID(rec.body.Bytes())
+		}
+		reportChange(resourceID, nil)
+	})
+}
+
+func auditOperation(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return ""
+	}
+}
+
+// createdResourceID returns the "id", or else the "name", of the JSON object
+// in body; "" when body holds neither.
+func createdResourceID(body []byte) string {
+	var created struct {
+		ID   any    `json:"id"`
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(body, &created) != nil {
+		return ""
+	}
+	if created.ID != nil && created.ID != "" {
+		return fmt.Sprint(created.ID)
+	}
+	return created.Name
+}
+
+// auditRecorder captures the status of a response and, when keepBody is
+// set, its first auditBodyLimit bytes. Like apiframework's request logger it
+// passes Flush and Hijack through so streaming and WebSocket handlers keep
+// working.
+type auditRecorder struct {
+	http.ResponseWriter
+	status   int
+	keepBody bool
+	body     bytes.Buffer
+}
+
+func (r *auditRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *auditRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	if r.keepBody && r.body.Len() < auditBodyLimit {
+		r.body.Write(b[:min(len(b), auditBodyLimit-r.body.Len())])
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *auditRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *auditRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *auditRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
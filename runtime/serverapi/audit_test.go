@@ -0,0 +1,98 @@
+package serverapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/apikeyservice"
+	"github.com/contenox/runtime/runtime/auditservice"
+	"github.com/contenox/runtime/runtime/internal/auditapi"
+	"github.com/contenox/runtime/runtime/internal/promptapi"
+	"github.com/contenox/runtime/runtime/promptservice"
+	"github.com/contenox/runtime/runtime/runtimetypes"
+)
+
+// auditedServer serves the real /prompts and /audit routes the way serve
+// mounts them: AuditMutations inside the API gate.
+func auditedServer(t *testing.T) (*httptest.Server, apikeyservice.Service) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := libdb.NewSQLiteDBManager(ctx, filepath.Join(t.TempDir(), "audit.db"), runtimetypes.SchemaSQLite)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	keys, err := apikeyservice.New(ctx, db, nil)
+	if err != nil {
+		t.Fatalf("key service: %v", err)
+	}
+	audit := auditservice.New(db)
+
+	apiMux := http.NewServeMux()
+	promptapi.AddRoutes(apiMux, promptservice.New(db, nil))
+	auditapi.AddRoutes(apiMux, audit)
+	tracker := auditservice.NewTracker(audit, libtracker.NoopTracker{})
+	rootMux := http.NewServeMux()
+	rootMux.Handle("/api/", http.StripPrefix("/api", ProtectAPIWithKeys(testToken, keys.Keys(), "", AuditMutations(tracker, apiMux))))
+	srv := httptest.NewServer(rootMux)
+	t.Cleanup(srv.Close)
+	return srv, keys
+}
+
+func TestServe_Audit_RecordsMutationsByIdentity(t *testing.T) {
+	srv, keys := auditedServer(t)
+	ci := createKey(t, keys, "ci")
+
+	if code, raw := keyedCall(t, srv, http.MethodPost, "/api/prompts", ci.Secret, `{"name":"triage","template":"t"}`); code != http.StatusCreated {
+		t.Fatalf("POST /api/prompts: status = %d, body %s", code, raw)
+	}
+	if code, _ := keyedCall(t, srv, http.MethodDelete, "/api/prompts/missing", ci.Secret, ""); code != http.StatusNotFound {
+		t.Fatalf("DELETE /api/prompts/missing: status = %d, want 404", code)
+	}
+	if code, _ := keyedCall(t, srv, http.MethodPut, "/api/prompts/triage", testToken, `{"name":"triage","template":"t2"}`); code != http.StatusOK {
+		t.Fatalf("PUT /api/prompts/triage: status = %d, want 200", code)
+	}
+	if code := keyedRequest(t, srv, http.MethodGet, "/api/prompts", ci.Secret); code != http.StatusOK {
+		t.Fatalf("GET /api/prompts: status = %d, want 200", code)
+	}
+	// Running a task or reconciling changes no stored resource: not audited.
+	keyedCall(t, srv, http.MethodPost, "/api/tasks", ci.Secret, `{"input":"hi"}`)
+	keyedCall(t, srv, http.MethodPost, "/api/state/reconcile", ci.Secret, "")
+
+	code, raw := keyedCall(t, srv, http.MethodGet, "/api/audit?identity=ci", testToken, "")
+	if code != http.StatusOK {
+		t.Fatalf("GET /api/audit: status = %d, body %s", code, raw)
+	}
+	var page auditapi.EntryPage
+	if err := json.Unmarshal([]byte(raw), &page); err != nil {
+		t.Fatalf("decode %q: %v", raw, err)
+	}
+	got := map[string]auditservice.Entry{}
+	for _, e := range page.Data {
+		got[e.Operation] = *e
+	}
+	if len(page.Data) != 2 {
+		t.Fatalf("audit entries for ci = %s, want only the create and the failed delete", raw)
+	}
+	if e := got["create"]; e.ResourceType != "prompts" || e.ResourceID != "triage" || e.Outcome != auditservice.OutcomeSuccess {
+		t.Fatalf("create entry = %+v", e)
+	}
+	if e := got["delete"]; e.ResourceID != "missing" || e.Outcome != auditservice.OutcomeFailure {
+		t.Fatalf("delete entry = %+v", e)
+	}
+
+	_, raw = keyedCall(t, srv, http.MethodGet, "/api/audit?resourceType=prompts&resourceId=triage", testToken, "")
+	page = auditapi.EntryPage{}
+	if err := json.Unmarshal([]byte(raw), &page); err != nil {
+		t.Fatalf("decode %q: %v", raw, err)
+	}
+	if len(page.Data) != 2 || page.Data[0].Operation != "update" || page.Data[0].Identity != localOperatorIdentity {
+		t.Fatalf("audit entries for prompts/triage = %s, want the operator's update first", raw)
+	}
+}
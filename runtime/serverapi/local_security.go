@@ -1,6 +1,7 @@
 package serverapi
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -47,14 +48,37 @@ func IsLoopbackAddress(addr string) bool {
 // is 403. Non-loopback binds already require a TOKEN (ValidateLocalServeSecurity),
 // so the no-token branch only ever serves local development.
 func ProtectAPI(token, allowedOrigins string, next http.Handler) http.Handler {
+	return ProtectAPIWithKeys(token, nil, allowedOrigins, next)
+}
+
+// ProtectAPIWithKeys is ProtectAPI that also accepts the API keys in keys (nil
+// for none), enforced by apiframework.EnforceKeys. Every accepted request
+// carries its caller's identity in the context: the TOKEN and the session
+// cookie are the local operator, unrestricted, and an API key is its own
+// identity, limited to its scopes route group by route group (see
+// requireRouteScope). A revoked or expired key is 401 like any unknown one.
+//
+// Keys only take effect with a TOKEN configured; without one the API is not
+// credential-gated at all and keys are not consulted.
+func ProtectAPIWithKeys(token string, keys *apiframework.KeySet, allowedOrigins string, next http.Handler) http.Handler {
 	token = strings.TrimSpace(token)
 	allowedOrigins = strings.TrimSpace(allowedOrigins)
+	scoped := requireRouteScope(next)
+	var enforceKeys http.Handler
+	if keys != nil {
+		enforceKeys = apiframework.EnforceKeys(keys, scoped)
+	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if token != "" {
-			if !requireCredential(w, r, token) {
-				return
+			cred := extractRequestToken(r)
+			switch {
+			case AuthenticateCredential(token, cred):
+				scoped.ServeHTTP(w, r.WithContext(apiframework.WithIdentity(r.Context(), localOperatorIdentity, nil)))
+			case enforceKeys != nil:
+				enforceKeys.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiframework.ContextTokenKey, cred)))
+			default:
+				_ = apiframework.Error(w, r, apiframework.ErrUnauthorized, apiframework.GetOperation)
 			}
-			next.ServeHTTP(w, r)
 			return
 		}
 		if !isMutatingMethod(r.Method) {
@@ -81,15 +105,25 @@ func ProtectMutatingAPIWithAllowedOrigins(token, allowedOrigins string, next htt
 	return ProtectAPI(token, allowedOrigins, next)
 }
 
-// requireCredential enforces that the request carries a valid credential (cookie
-// JWT or raw token) when a TOKEN is configured, writing a 401 and returning false
-// otherwise.
-func requireCredential(w http.ResponseWriter, r *http.Request, token string) bool {
-	if !AuthenticateCredential(token, extractRequestToken(r)) {
-		_ = apiframework.Error(w, r, apiframework.ErrUnauthorized, apiframework.GetOperation)
-		return false
-	}
-	return true
+// unscopedRouteGroups are the route groups any authenticated caller may use
+// whatever its key's scopes: the liveness and version probes.
+var unscopedRouteGroups = map[string]bool{"health": true, "version": true}
+
+// requireRouteScope wraps each route group with apiframework.RequireScope for
+// the resource of the same name. A route group is the first segment of the
+// request path, so /backends/{id}/models is the "backends" resource and
+// /files/{path...} the "files" one; a key scoped to {"files", "read"} may GET
+// under /files and nothing else. Mount it where the caller's identity is
+// already in the context.
+func requireRouteScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		group, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if group == "" || unscopedRouteGroups[group] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		apiframework.RequireScope(group, next).ServeHTTP(w, r)
+	})
 }
 
 // sessionCookieName is the HttpOnly cookie the Beam login flow (see ui_auth.go)
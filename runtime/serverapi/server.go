@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"strings"
@@ -15,11 +17,16 @@ import (
 	"github.com/contenox/runtime/libtracker"
 	"github.com/contenox/runtime/runtime/agentregistryservice"
 	"github.com/contenox/runtime/runtime/agentservice"
+	"github.com/contenox/runtime/runtime/apikeyservice"
+	"github.com/contenox/runtime/runtime/auditservice"
 	"github.com/contenox/runtime/runtime/backendservice"
+	"github.com/contenox/runtime/runtime/embedservice"
 	"github.com/contenox/runtime/runtime/fleetservice"
 	"github.com/contenox/runtime/runtime/hitlservice"
 	"github.com/contenox/runtime/runtime/internal/agentregistryapi"
+	"github.com/contenox/runtime/runtime/internal/apikeyapi"
 	"github.com/contenox/runtime/runtime/internal/approvalapi"
+	"github.com/contenox/runtime/runtime/internal/auditapi"
 	"github.com/contenox/runtime/runtime/internal/backendapi"
 	"github.com/contenox/runtime/runtime/internal/compatapi"
 	"github.com/contenox/runtime/runtime/internal/fleetapi"
@@ -32,6 +39,7 @@ import (
 	"github.com/contenox/runtime/runtime/internal/modelregistryapi"
 	"github.com/contenox/runtime/runtime/internal/openapidocs"
 	"github.com/contenox/runtime/runtime/internal/operatorinboxapi"
+	"github.com/contenox/runtime/runtime/internal/promptapi"
 	"github.com/contenox/runtime/runtime/internal/providerapi"
 	"github.com/contenox/runtime/runtime/internal/setupapi"
 	"github.com/contenox/runtime/runtime/internal/taskchainapi"
@@ -45,12 +53,15 @@ import (
 	"github.com/contenox/runtime/runtime/missionservice"
 	"github.com/contenox/runtime/runtime/modelregistry"
 	"github.com/contenox/runtime/runtime/modelregistryservice"
+	"github.com/contenox/runtime/runtime/modelservice"
 	"github.com/contenox/runtime/runtime/operatorinbox"
+	"github.com/contenox/runtime/runtime/promptservice"
 	"github.com/contenox/runtime/runtime/providerservice"
 	"github.com/contenox/runtime/runtime/runtimestate"
 	"github.com/contenox/runtime/runtime/runtimetypes"
 	"github.com/contenox/runtime/runtime/stateservice"
 	"github.com/contenox/runtime/runtime/taskchainservice"
+	"github.com/contenox/runtime/runtime/taskengine"
 	"github.com/contenox/runtime/runtime/terminalservice"
 	"github.com/contenox/runtime/runtime/toolsproviderservice"
 	"github.com/contenox/runtime/runtime/version"
@@ -71,6 +82,10 @@ type Config struct {
 	TerminalShell       string `json:"terminal_shell"`
 	TerminalIdleTimeout string `json:"terminal_idle_timeout"`
 	TerminalMaxSessions string `json:"terminal_max_sessions"`
+	// APIKeys (a JSON array, see apikeyservice.ParseConfigKeys) are API keys
+	// accepted besides Token, each with its own identity and optional
+	// scopes. They need a Token to take effect. Parsed by ValidateConfig.
+	APIKeys string `json:"api_keys"`
 	// WorkspaceRoots is the operator's allowlist of directories a browser client
 	// may choose as a session workspace, separated by the OS path-list separator
 	// (":" on POSIX). The serve directory is always the default root; these
@@ -81,9 +96,89 @@ type Config struct {
 	// e.g. "1h") that bounds a pending human-in-the-loop approval when the
 	// policy rule that gated it set no TimeoutS of its own. Empty keeps
 	// hitlservice's built-in default (hitlservice.DefaultApprovalCeiling, 1
-	// hour) — see runtime/contenoxcli/serve_cmd.go's
-	// parseHITLApprovalCeiling.
+	// hour). Parsed by ValidateConfig.
 	HITLApprovalTimeout string `json:"hitl_approval_timeout"`
+	// ReconcileInterval (a Go duration string, e.g. "5m") makes serve
+	// reconcile backends on a fixed cadence in addition to on demand (startup,
+	// POST /state/reconcile, POST /setup/refresh). Empty keeps the on-demand-only
+	// default. Parsed by ValidateConfig.
+	ReconcileInterval string `json:"reconcile_interval"`
+	// BackendTimeout (a Go duration string, e.g. "30s") bounds how long one
+	// backend may take to be observed during a reconcile; a backend that does
+	// not answer in time is recorded with a timeout error. Empty keeps
+	// runtimestate.DefaultBackendTimeout. Parsed by ValidateConfig.
+	BackendTimeout string `json:"backend_timeout"`
+	// ReconcileWorkers is how many backends a reconcile observes concurrently.
+	// Empty keeps runtimestate.DefaultReconcileWorkers. Parsed by
+	// ValidateConfig.
+	ReconcileWorkers string `json:"reconcile_workers"`
+	// ModelKeepAlive (a Go duration string, e.g. "30m") makes every reconcile
+	// load the declared models of Ollama backends and keep them resident for
+	// this long, so the first request does not wait for a cold load. Empty
+	// disables warm-up. Parsed by ValidateConfig.
+	ModelKeepAlive string `json:"model_keep_alive"`
+	// ModelSelection picks the backend a request is served by when several
+	// match: "random" (the default when empty), "round-robin",
+	// "least-recently-used" or "weighted:model=weight,...". Parsed by
+	// ValidateConfig.
+	ModelSelection string `json:"model_selection"`
+	// ToolsRateLimits (a JSON object, e.g. {"slack": {"per_minute": 50}})
+	// holds calls to the named tools to a per-minute quota, queuing the
+	// excess. Empty leaves every tools unlimited. Parsed by ValidateConfig.
+	ToolsRateLimits string `json:"tools_rate_limits"`
+	// LogFormat selects the server log format: "text" (the default) or
+	// "json", for log shippers. LogLevel is debug, info (the default), warn
+	// or error. Setting either also logs one line per HTTP request carrying
+	// its request ID, tenant, identity and duration — see
+	// apiframework.RequestLogMiddleware.
+	LogFormat string `json:"log_format"`
+	LogLevel  string `json:"log_level"`
+	// MaxBodyBytes caps API request bodies, in bytes; MaxUploadBytes caps
+	// the bodies of UploadRoutes instead. Empty keeps
+	// apiframework.DefaultMaxBodyBytes and DefaultMaxUploadBytes; 0 removes
+	// the cap.
+	MaxBodyBytes   string `json:"max_body_bytes"`
+	MaxUploadBytes string `json:"max_upload_bytes"`
+	// OTelExporterOTLPEndpoint is the base URL of an OTLP/HTTP collector
+	// (e.g. http://localhost:4318) the chain and task spans are exported to;
+	// OTelExporterOTLPTracesEndpoint, when set, is the full traces URL
+	// instead. Both empty leaves tracing a no-op. See SetupTracing.
+	OTelExporterOTLPEndpoint       string `json:"otel_exporter_otlp_endpoint"`
+	OTelExporterOTLPTracesEndpoint string `json:"otel_exporter_otlp_traces_endpoint"`
+}
+
+// UploadRoutes are the routes whose bodies stream files too large for the
+// API body limit: model pushes to a modeld backend.
+var UploadRoutes = []string{"POST /backends/{id}/models/push"}
+
+// BodyLimits returns the request body limits of s for a mux whose routes
+// are mounted under prefix (e.g. "/api"; "" for none).
+func (s Settings) BodyLimits(prefix string) apiframework.BodyLimits {
+	limits := apiframework.BodyLimits{Default: s.MaxBodyBytes, Upload: s.MaxUploadBytes}
+	for _, route := range UploadRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		limits.UploadRoutes = append(limits.UploadRoutes, method+" "+prefix+path)
+	}
+	return limits
+}
+
+// StructuredLogging reports whether the operator configured the log format
+// or level, which turns on per-request logging.
+func (c *Config) StructuredLogging() bool {
+	return c != nil && (strings.TrimSpace(c.LogFormat) != "" || strings.TrimSpace(c.LogLevel) != "")
+}
+
+// NewLogger builds the logger config.LogFormat and config.LogLevel describe,
+// writing to w.
+func NewLogger(config *Config, w io.Writer) (*slog.Logger, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	handler, err := apiframework.NewLogHandler(w, config.LogFormat, config.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(handler), nil
 }
 
 // Dependencies are the services the product routes are mounted on. All fields
@@ -97,6 +192,20 @@ type Dependencies struct {
 	Auth                 middleware.AuthZReader
 	Agent                agentservice.Agent
 	Chains               taskchainservice.Service
+	// Tools is the registry chain imports are checked against, so a chain
+	// naming a tool this runtime does not provide is refused at import. nil
+	// skips that check.
+	Tools taskengine.ToolsRegistry
+	// APIKeys manages the API keys the API gate accepts besides the TOKEN;
+	// the /api-keys routes surface it. Registered only when a TOKEN is
+	// configured, since keys are not enforced without one.
+	APIKeys apikeyservice.Service
+	// Audit is the audit trail (runtime/auditservice) the /audit route
+	// queries; AuditMutations records into it.
+	Audit auditservice.Service
+	// Embedder serves the OpenAI-compatible /openai/v1/embeddings route. nil
+	// leaves the route registered but answering with a server error.
+	Embedder embedservice.Service
 	// Fleet is serve's fleet-lifecycle-policy layer (runtime/fleetservice), built
 	// on top of serve's live agent-instance Manager. The /fleet routes are a thin
 	// wrapper around it — List/Get/Dispatch/Stop/Cancel — so the orchestration
@@ -119,6 +228,10 @@ type Dependencies struct {
 	// operator fired directly, and reports whose parent session had ended (see
 	// runtime/reportrouter). Optional: nil-gated like the other route groups.
 	OperatorInbox operatorinbox.Service
+	// Prompts is the prompt library (runtime/promptservice) the /prompts
+	// routes surface. Chains reference its prompts with {{prompt "name"}};
+	// the agent resolves them whether or not the routes are registered.
+	Prompts promptservice.Service
 	// HITL is the human-in-the-loop approval service (runtime/hitlservice) whose
 	// durable pending-ask store (slice C1) the /approvals routes surface: the
 	// inbox an operator reads and answers without attaching to the session that
@@ -233,6 +346,7 @@ func registerProductRoutes(ctx context.Context, mux *http.ServeMux, config *Conf
 
 	backendapi.AddStateRoutes(mux, stateSvc)
 	backendapi.AddModelRoutes(mux, stateSvc, deps.Defaults)
+	backendapi.AddModelListRoutes(mux, modelservice.New(deps.DB, ""))
 	backendapi.AddBackendRoutes(mux, backendSvc, stateSvc)
 	modeldapi.AddRoutes(mux, modeldapi.WithStateReader(stateSvc))
 
@@ -284,6 +398,7 @@ func registerProductRoutes(ctx context.Context, mux *http.ServeMux, config *Conf
 			chains = taskchainservice.NewLocal(chainFiles)
 		}
 		taskchainapi.AddTaskChainRoutes(mux, chains)
+		taskchainapi.AddTaskChainTransferRoutes(mux, taskchainservice.NewTransfer(chains, deps.Tools))
 		hitlpolicyapi.AddRoutes(mux, chainFiles)
 	}
 
@@ -304,6 +419,7 @@ func registerProductRoutes(ctx context.Context, mux *http.ServeMux, config *Conf
 			Defaults:     deps.Defaults,
 			Auth:         deps.Auth,
 			Token:        config.Token,
+			Embedder:     deps.Embedder,
 		})
 	}
 
@@ -353,6 +469,18 @@ func registerProductRoutes(ctx context.Context, mux *http.ServeMux, config *Conf
 		operatorinboxapi.AddRoutes(mux, deps.OperatorInbox)
 	}
 
+	if deps.Prompts != nil {
+		promptapi.AddRoutes(mux, deps.Prompts)
+	}
+
+	if deps.APIKeys != nil && strings.TrimSpace(config.Token) != "" {
+		apikeyapi.AddRoutes(mux, deps.APIKeys)
+	}
+
+	if deps.Audit != nil {
+		auditapi.AddRoutes(mux, deps.Audit)
+	}
+
 	// The inbox: pending human-in-the-loop approvals an operator can read and
 	// answer without attaching to the session that raised them (slice C2 of
 	// fleet-consolidation.md, closing the loop C1's durable store opened).
@@ -371,8 +499,11 @@ func registerProductRoutes(ctx context.Context, mux *http.ServeMux, config *Conf
 }
 
 // Handler wraps a mux with the standard middleware chain: CORS, request ID,
-// tracing, and local API request protection.
-func Handler(mux *http.ServeMux, config *Config) http.Handler {
+// tracing, request logging when config.StructuredLogging, request body
+// limits, and local API request protection accepting the API keys in keys
+// (nil for none). An invalid config falls back to the default body limits;
+// callers that can fail run ValidateConfig first.
+func Handler(mux *http.ServeMux, config *Config, keys *apiframework.KeySet) http.Handler {
 	if config == nil {
 		config = &Config{}
 	}
@@ -383,8 +514,17 @@ func Handler(mux *http.ServeMux, config *Config) http.Handler {
 		ProxyOrigin:       config.ProxyOrigin,
 	}
 
+	settings, err := ValidateConfig(config)
+	if err != nil {
+		settings, _ = ValidateConfig(nil)
+	}
+
 	var h http.Handler = mux
-	h = ProtectAPI(config.Token, config.AllowedAPIOrigins, h)
+	h = ProtectAPIWithKeys(config.Token, keys, config.AllowedAPIOrigins, h)
+	h = apiframework.BodyLimitMiddleware(settings.BodyLimits(""), h)
+	if config.StructuredLogging() {
+		h = apiframework.RequestLogMiddleware(slog.Default(), runtimetypes.LocalTenantID, h)
+	}
 	h = apiframework.TracingMiddleware(h)
 	h = apiframework.RequestIDMiddleware(h)
 	h = middleware.EnableCORS(cors, h)
@@ -0,0 +1,66 @@
+package serverapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/contenox/runtime/runtime/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TracingEnabled reports whether config names an OTLP endpoint to export
+// traces to.
+func (c *Config) TracingEnabled() bool {
+	return c != nil && (strings.TrimSpace(c.OTelExporterOTLPEndpoint) != "" ||
+		strings.TrimSpace(c.OTelExporterOTLPTracesEndpoint) != "")
+}
+
+// SetupTracing installs the global OpenTelemetry TracerProvider that the
+// chain and task spans go to, exporting them over OTLP/HTTP to the endpoint
+// config names: OTEL_EXPORTER_OTLP_TRACES_ENDPOINT as is, or /v1/traces under
+// OTEL_EXPORTER_OTLP_ENDPOINT. The exporter reads the rest of its settings —
+// headers, timeout, compression, TLS — from the standard
+// OTEL_EXPORTER_OTLP_* variables, and the resource from OTEL_SERVICE_NAME and
+// OTEL_RESOURCE_ATTRIBUTES.
+//
+// Without an endpoint it installs nothing, leaving tracing a no-op. The
+// returned function flushes buffered spans and stops the exporter; it is
+// never nil.
+func SetupTracing(ctx context.Context, config *Config) (func(context.Context) error, error) {
+	if !config.TracingEnabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+	endpoint := strings.TrimSpace(config.OTelExporterOTLPTracesEndpoint)
+	if endpoint == "" {
+		endpoint = strings.TrimRight(strings.TrimSpace(config.OTelExporterOTLPEndpoint), "/") + "/v1/traces"
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP trace exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", "contenox"),
+			attribute.String("service.version", version.Get()),
+		),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		_ = exporter.Shutdown(ctx)
+		return nil, fmt.Errorf("describe trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,51 @@
+package serverapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetupTracing_NoEndpointInstallsNothing(t *testing.T) {
+	before := otel.GetTracerProvider()
+	shutdown, err := SetupTracing(context.Background(), &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() != before {
+		t.Fatal("a tracer provider was installed without an endpoint")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetupTracing_ExportsSpansOnShutdown(t *testing.T) {
+	var exported atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/traces" {
+			exported.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	before := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(before)
+	shutdown, err := SetupTracing(context.Background(), &Config{OTelExporterOTLPEndpoint: collector.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := otel.Tracer("test").Start(context.Background(), "chain test")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if exported.Load() == 0 {
+		t.Fatal("shutdown did not flush the buffered span to the collector")
+	}
+}
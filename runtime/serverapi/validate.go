@@ -0,0 +1,171 @@
+package serverapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/runtime/apikeyservice"
+	"github.com/contenox/runtime/runtime/llmrepo"
+	"github.com/contenox/runtime/runtime/localtools"
+)
+
+// ErrInvalidConfig is wrapped by every ValidateConfig failure.
+var ErrInvalidConfig = errors.New("invalid server configuration")
+
+// Settings are the Config fields that need parsing, parsed once by
+// ValidateConfig so the rest of startup works with typed values.
+type Settings struct {
+	// HITLApprovalTimeout is the HITL_APPROVAL_TIMEOUT ceiling; 0 keeps
+	// hitlservice's built-in default.
+	HITLApprovalTimeout time.Duration
+	// ReconcileInterval is the RECONCILE_INTERVAL cadence; 0 means reconcile
+	// at startup and on demand only.
+	ReconcileInterval time.Duration
+	// BackendTimeout is the BACKEND_TIMEOUT bound on observing one backend;
+	// 0 keeps runtimestate's default.
+	BackendTimeout time.Duration
+	// ReconcileWorkers is the RECONCILE_WORKERS concurrency; 0 keeps
+	// runtimestate's default.
+	ReconcileWorkers int
+	// ModelKeepAlive is the MODEL_KEEP_ALIVE residency of warmed Ollama
+	// models; 0 disables warm-up.
+	ModelKeepAlive time.Duration
+	// ModelSelection is the MODEL_SELECTION policy; nil keeps llmrepo's
+	// random default.
+	ModelSelection llmrepo.SelectionPolicy
+	// ToolsRateLimits are the TOOLS_RATE_LIMITS quotas by tools name; nil
+	// leaves every tools unlimited.
+	ToolsRateLimits map[string]localtools.RateLimit
+	// APIKeys are the API_KEYS keys; nil configures none.
+	APIKeys []apikeyservice.ConfigKey
+	// MaxBodyBytes and MaxUploadBytes are the request body caps; 0 means no
+	// cap.
+	MaxBodyBytes   int64
+	MaxUploadBytes int64
+}
+
+// ValidateConfig checks every field of config before any subsystem is
+// started, so a misconfigured server fails at once instead of deep into
+// startup. It reports all problems together, each naming the environment
+// variable to fix, and returns the parsed Settings.
+//
+// Model and provider defaults are not part of Config: they live in the CLI
+// configuration and are checked by the setup readiness evaluation.
+func ValidateConfig(config *Config) (Settings, error) {
+	settings := Settings{
+		MaxBodyBytes:   apiframework.DefaultMaxBodyBytes,
+		MaxUploadBytes: apiframework.DefaultMaxUploadBytes,
+	}
+	if config == nil {
+		return settings, nil
+	}
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if port := strings.TrimSpace(config.Port); port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			errs = append(errs, fmt.Errorf("invalid PORT %q: must be a port number between 1 and 65535", port))
+		}
+	}
+	check(validateURL("UI_BASE_URL", config.UIBaseURL))
+	check(validateURL("PROXY_ORIGIN", config.ProxyOrigin))
+	check(validateURL("BEAM_DEV_PROXY_URL", config.BeamDevProxyURL))
+	check(validateURL("OTEL_EXPORTER_OTLP_ENDPOINT", config.OTelExporterOTLPEndpoint))
+	check(validateURL("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", config.OTelExporterOTLPTracesEndpoint))
+
+	if idle := strings.TrimSpace(config.TerminalIdleTimeout); idle != "" {
+		if d, err := time.ParseDuration(idle); err != nil || d < 0 {
+			errs = append(errs, fmt.Errorf("invalid TERMINAL_IDLE_TIMEOUT %q: must be a Go duration (e.g. 30m)", idle))
+		}
+	}
+	if max := strings.TrimSpace(config.TerminalMaxSessions); max != "" {
+		if n, err := strconv.Atoi(max); err != nil || n < 0 {
+			errs = append(errs, fmt.Errorf("invalid TERMINAL_MAX_SESSIONS %q: must be a non-negative integer", max))
+		}
+	}
+
+	var err error
+	settings.HITLApprovalTimeout, err = parsePositiveDuration("HITL_APPROVAL_TIMEOUT", config.HITLApprovalTimeout, "1h")
+	check(err)
+	settings.ReconcileInterval, err = parsePositiveDuration("RECONCILE_INTERVAL", config.ReconcileInterval, "5m")
+	check(err)
+	settings.BackendTimeout, err = parsePositiveDuration("BACKEND_TIMEOUT", config.BackendTimeout, "30s")
+	check(err)
+	if workers := strings.TrimSpace(config.ReconcileWorkers); workers != "" {
+		if n, err := strconv.Atoi(workers); err != nil || n < 1 {
+			errs = append(errs, fmt.Errorf("invalid RECONCILE_WORKERS %q: must be a positive integer", workers))
+		} else {
+			settings.ReconcileWorkers = n
+		}
+	}
+	settings.ModelKeepAlive, err = parsePositiveDuration("MODEL_KEEP_ALIVE", config.ModelKeepAlive, "30m")
+	check(err)
+	if settings.ModelSelection, err = llmrepo.ParseSelectionPolicy(config.ModelSelection); err != nil {
+		errs = append(errs, fmt.Errorf("invalid MODEL_SELECTION: %w", err))
+	}
+	if settings.ToolsRateLimits, err = localtools.ParseRateLimits(config.ToolsRateLimits); err != nil {
+		errs = append(errs, fmt.Errorf("invalid TOOLS_RATE_LIMITS: %w", err))
+	}
+	if settings.APIKeys, err = apikeyservice.ParseConfigKeys(config.APIKeys); err != nil {
+		errs = append(errs, fmt.Errorf("invalid API_KEYS: %w", err))
+	}
+	settings.MaxBodyBytes, err = parseByteLimit("MAX_BODY_BYTES", config.MaxBodyBytes, settings.MaxBodyBytes)
+	check(err)
+	settings.MaxUploadBytes, err = parseByteLimit("MAX_UPLOAD_BYTES", config.MaxUploadBytes, settings.MaxUploadBytes)
+	check(err)
+
+	if _, err := apiframework.NewLogHandler(nil, config.LogFormat, config.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_FORMAT/LOG_LEVEL: %w", err))
+	}
+
+	if len(errs) > 0 {
+		return Settings{}, fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+	return settings, nil
+}
+
+// parsePositiveDuration parses a Go duration setting. Empty yields 0.
+func parsePositiveDuration(name, raw, example string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive Go duration (e.g. %s)", name, raw, example)
+	}
+	return d, nil
+}
+
+func parseByteLimit(name, raw string, fallback int64) (int64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a byte count (0 for no limit)", name, raw)
+	}
+	return n, nil
+}
+
+func validateURL(name, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid %s %q: must be an absolute URL such as http://localhost:5173", name, raw)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package serverapi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/apiframework"
+	"github.com/contenox/runtime/runtime/localtools"
+)
+
+func TestValidateConfig_ParsesSettings(t *testing.T) {
+	settings, err := ValidateConfig(&Config{
+		Port:                "32123",
+		HITLApprovalTimeout: "2h",
+		ReconcileInterval:   "5m",
+		BackendTimeout:      "45s",
+		ReconcileWorkers:    "8",
+		ModelKeepAlive:      "30m",
+		ModelSelection:      "round-robin",
+		ToolsRateLimits:     `{"slack": {"per_minute": 50, "max_queue": 4}}`,
+		MaxBodyBytes:        "1024",
+		MaxUploadBytes:      "0",
+		LogFormat:           "json",
+		APIKeys:             `[{"identity": "ci", "key": "ci-key"}]`,
+	})
+	if err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	if settings.HITLApprovalTimeout != 2*time.Hour || settings.ReconcileInterval != 5*time.Minute || settings.BackendTimeout != 45*time.Second {
+		t.Fatalf("durations = %v, %v, %v", settings.HITLApprovalTimeout, settings.ReconcileInterval, settings.BackendTimeout)
+	}
+	if settings.ReconcileWorkers != 8 {
+		t.Fatalf("reconcile workers = %d", settings.ReconcileWorkers)
+	}
+	if settings.ModelKeepAlive != 30*time.Minute {
+		t.Fatalf("model keep-alive = %v", settings.ModelKeepAlive)
+	}
+	if settings.ModelSelection == nil {
+		t.Fatal("model selection not parsed")
+	}
+	if got := settings.ToolsRateLimits["slack"]; got != (localtools.RateLimit{PerMinute: 50, Burst: 1, MaxQueue: 4}) {
+		t.Fatalf("tools rate limit = %+v", got)
+	}
+	if settings.MaxBodyBytes != 1024 || settings.MaxUploadBytes != 0 {
+		t.Fatalf("body limits = %d, %d", settings.MaxBodyBytes, settings.MaxUploadBytes)
+	}
+	if len(settings.APIKeys) != 1 || settings.APIKeys[0].Identity != "ci" || settings.APIKeys[0].Key != "ci-key" {
+		t.Fatalf("API keys = %+v", settings.APIKeys)
+	}
+	limits := settings.BodyLimits("/api")
+	if len(limits.UploadRoutes) != 1 || limits.UploadRoutes[0] != "POST /api/backends/{id}/models/push" {
+		t.Fatalf("upload routes = %v", limits.UploadRoutes)
+	}
+}
+
+func TestValidateConfig_EmptyConfigKeepsDefaults(t *testing.T) {
+	settings, err := ValidateConfig(&Config{})
+	if err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	if settings.MaxBodyBytes != apiframework.DefaultMaxBodyBytes || settings.MaxUploadBytes != apiframework.DefaultMaxUploadBytes {
+		t.Fatalf("body limits = %d, %d", settings.MaxBodyBytes, settings.MaxUploadBytes)
+	}
+	if settings.HITLApprovalTimeout != 0 || settings.ReconcileInterval != 0 || settings.BackendTimeout != 0 {
+		t.Fatalf("durations should be unset, got %+v", settings)
+	}
+}
+
+func TestValidateConfig_ReportsEveryBadVariable(t *testing.T) {
+	_, err := ValidateConfig(&Config{
+		Port:                     "http",
+		HITLApprovalTimeout:      "soon",
+		ReconcileInterval:        "-5m",
+		BackendTimeout:           "0s",
+		ReconcileWorkers:         "0",
+		ModelKeepAlive:           "forever",
+		ModelSelection:           "fastest",
+		ToolsRateLimits:          `{"slack": {"per_minute": 0}}`,
+		TerminalMaxSessions:      "many",
+		MaxBodyBytes:             "10MB",
+		LogLevel:                 "loud",
+		UIBaseURL:                "localhost",
+		OTelExporterOTLPEndpoint: "collector:4318",
+		APIKeys:                  `[{"identity": "ci"}]`,
+	})
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("err = %v, want ErrInvalidConfig", err)
+	}
+	for _, name := range []string{"PORT", "HITL_APPROVAL_TIMEOUT", "RECONCILE_INTERVAL", "BACKEND_TIMEOUT", "RECONCILE_WORKERS", "MODEL_KEEP_ALIVE", "MODEL_SELECTION", "TOOLS_RATE_LIMITS", "TERMINAL_MAX_SESSIONS", "MAX_BODY_BYTES", "LOG_LEVEL", "UI_BASE_URL", "OTEL_EXPORTER_OTLP_ENDPOINT", "API_KEYS"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error does not name %s: %v", name, err)
+		}
+	}
+}
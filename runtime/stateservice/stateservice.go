@@ -3,6 +3,7 @@ package stateservice
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -27,6 +28,17 @@ type Service interface {
 	// SetCLIConfig updates CLI default keys in SQLite KV (same as contenox config set / PUT /cli-config).
 	// Nil fields in the patch are left unchanged. Empty string fields are written and can clear a resolved setting.
 	SetCLIConfig(ctx context.Context, patch CLIConfigPatch) (CLIConfigSnapshot, error)
+	// TestBackendConnection probes an unsaved backend with the configured
+	// provider credentials and reports whether it answered, without persisting
+	// anything. See runtimestate.State.TestBackendConnection.
+	TestBackendConnection(ctx context.Context, backend runtimetypes.Backend) (ok bool, detail string)
+	// TriggerReconcile asks for a reconcile as soon as possible without
+	// waiting for it; requests made while one is pending coalesce. See
+	// runtimestate.State.TriggerReconcile.
+	TriggerReconcile(ctx context.Context)
+	// ModelReport returns each backend's declared, pulled, missing and
+	// orphaned models. See runtimestate.State.ModelReport.
+	ModelReport(ctx context.Context) ([]statetype.ModelReport, error)
 }
 
 // CLIConfigPatch selects which CLI default keys to write; nil means "do not change".
@@ -106,6 +118,31 @@ func (s *service) Refresh(ctx context.Context) (setupcheck.Result, error) {
 	return s.SetupStatus(ctx)
 }
 
+// TestBackendConnection implements Service.
+func (s *service) TestBackendConnection(ctx context.Context, backend runtimetypes.Backend) (bool, string) {
+	return s.state.TestBackendConnection(ctx, backend)
+}
+
+// ModelReport implements Service.
+func (s *service) ModelReport(ctx context.Context) ([]statetype.ModelReport, error) {
+	// Same self-heal as Get: the report is only as fresh as the snapshot.
+	_ = s.state.ReconcileIfStale(ctx)
+	m := s.state.ModelReport(ctx)
+	l := make([]statetype.ModelReport, 0, len(m))
+	for _, r := range m {
+		l = append(l, r)
+	}
+	slices.SortFunc(l, func(a, b statetype.ModelReport) int {
+		return strings.Compare(a.BackendName, b.BackendName)
+	})
+	return l, nil
+}
+
+// TriggerReconcile implements Service.
+func (s *service) TriggerReconcile(_ context.Context) {
+	s.state.TriggerReconcile()
+}
+
 // CLIConfig implements Service.
 func (s *service) CLIConfig(ctx context.Context) (CLIConfigSnapshot, error) {
 	store := runtimetypes.New(s.db.WithoutTransaction())
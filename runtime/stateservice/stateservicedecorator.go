@@ -2,9 +2,11 @@ package stateservice
 
 import (
 	"context"
+	"errors"
 
 	"github.com/contenox/runtime/libtracker"
 	"github.com/contenox/runtime/runtime/internal/setupcheck"
+	"github.com/contenox/runtime/runtime/runtimetypes"
 	"github.com/contenox/runtime/runtime/statetype"
 )
 
@@ -90,6 +92,51 @@ func (d *activityTrackerDecorator) SetCLIConfig(ctx context.Context, patch CLICo
 	return snap, err
 }
 
+func (d *activityTrackerDecorator) TestBackendConnection(ctx context.Context, backend runtimetypes.Backend) (bool, string) {
+	reportErrFn, reportChangeFn, endFn := d.tracker.Start(
+		ctx,
+		"test",
+		"backend_connection",
+		"type", backend.Type,
+		"baseURL", backend.BaseURL,
+	)
+	defer endFn()
+
+	ok, detail := d.service.TestBackendConnection(ctx, backend)
+	if !ok {
+		reportErrFn(errors.New(detail))
+	} else {
+		reportChangeFn(backend.BaseURL, map[string]any{"ok": ok, "detail": detail})
+	}
+	return ok, detail
+}
+
+func (d *activityTrackerDecorator) ModelReport(ctx context.Context) ([]statetype.ModelReport, error) {
+	reportErrFn, _, endFn := d.tracker.Start(
+		ctx,
+		"read",
+		"model_report",
+	)
+	defer endFn()
+
+	reports, err := d.service.ModelReport(ctx)
+	if err != nil {
+		reportErrFn(err)
+	}
+	return reports, err
+}
+
+func (d *activityTrackerDecorator) TriggerReconcile(ctx context.Context) {
+	_, _, endFn := d.tracker.Start(
+		ctx,
+		"trigger",
+		"reconcile",
+	)
+	defer endFn()
+
+	d.service.TriggerReconcile(ctx)
+}
+
 // WithActivityTracker wraps a StateService with activity tracking
 func WithActivityTracker(service Service, tracker libtracker.ActivityTracker) Service {
 	return &activityTrackerDecorator{
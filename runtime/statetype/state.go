@@ -25,10 +25,48 @@ type BackendRuntimeState struct {
 	ResolvedEndpoint string `json:"resolvedEndpoint,omitempty"`
 	ResolvedInstance string `json:"resolvedInstance,omitempty"`
 	LiveEngine       string `json:"liveEngine,omitempty"` // "llama" or "openvino"
-	// APIKey stores the API key used for authentication with the backend.
+	// APIKey stores the credential used for authentication with the backend
+	// as configured: a literal key, or a reference (e.g. "env:GEMINI_API_KEY")
+	// that runtimestate resolves each time it builds a provider.
 	apiKey string
 }
 
+// ModelReport is one backend's declared-versus-observed model diff. Declared
+// are the models reconciliation was told the backend should serve (its
+// affinity groups' models, or every declared model when groups are off);
+// Pulled are the models the backend reported. Missing are declared but not
+// pulled; Orphaned are pulled but not declared. Reconciliation only observes,
+// so neither set is acted on — the report is for an operator to act on.
+type ModelReport struct {
+	BackendID   string   `json:"backendId" example:"b7d9e1a3-8f0c-4a7d-9b1e-2f3a4b5c6d7e"`
+	BackendName string   `json:"backendName" example:"ollama-production"`
+	Declared    []string `json:"declared" example:"[\"mistral:instruct\", \"nomic-embed-text:latest\"]"`
+	Pulled      []string `json:"pulled" example:"[\"mistral:instruct\", \"llama2:7b\"]"`
+	Missing     []string `json:"missing" example:"[\"nomic-embed-text:latest\"]"`
+	Orphaned    []string `json:"orphaned" example:"[\"llama2:7b\"]"`
+	// Error is the backend's last reconcile error; when set, Pulled is empty
+	// because the backend could not be listed, not because it serves nothing.
+	Error string `json:"error,omitempty" example:"connection timeout: context deadline exceeded"`
+	// Contexts are the context windows of the pulled models, with where each
+	// came from.
+	Contexts []ModelContextWindow `json:"contexts,omitempty" openapi_include_type:"statetype.ModelContextWindow"`
+}
+
+// Context window sources reported in ModelContextWindow.Source.
+const (
+	ContextSourceDetected   = "detected"
+	ContextSourceConfigured = "configured"
+)
+
+// ModelContextWindow is the context window reconciliation settled on for one
+// model. Source is "detected" when the backend reported it and "configured"
+// when the declared context_length was used because detection failed.
+type ModelContextWindow struct {
+	Model         string `json:"model" example:"mistral:instruct"`
+	ContextLength int    `json:"contextLength" example:"32768"`
+	Source        string `json:"source" example:"detected"`
+}
+
 type ModelPullStatus struct {
 	Name            string       `json:"name" example:"Mistral 7B Instruct"`
 	Model           string       `json:"model" example:"mistral:instruct"`
@@ -44,6 +82,31 @@ type ModelPullStatus struct {
 	CanStream       bool         `json:"canStream" example:"true"`
 	CanThink        bool         `json:"canThink,omitempty" example:"true"`
 	CanVision       bool         `json:"canVision,omitempty" example:"true"`
+	// ToolsUnsupported marks a model that must not be sent tool definitions.
+	// Tool support is assumed by default; this is set from a capability
+	// override that disables it.
+	ToolsUnsupported bool `json:"toolsUnsupported,omitempty" example:"false"`
+	// DetectedContextLength is the context window the backend itself reported,
+	// 0 when it reported none and ContextLength is the configured value.
+	DetectedContextLength int `json:"detectedContextLength,omitempty" example:"4096"`
+	// WarmUp is the outcome of the last keep-alive request for a declared
+	// model, nil when warm-up is disabled or the model is not declared.
+	WarmUp *ModelWarmUp `json:"warmUp,omitempty" openapi_include_type:"statetype.ModelWarmUp"`
+}
+
+// Warm-up outcomes reported in ModelWarmUp.Status.
+const (
+	WarmUpLoaded = "loaded"
+	WarmUpFailed = "failed"
+)
+
+// ModelWarmUp is the outcome of the keep-alive request reconciliation sent to
+// keep a declared model resident on its backend. Status is "loaded" when the
+// backend accepted it and "failed" when it did not, with Error saying why.
+type ModelWarmUp struct {
+	Status string    `json:"status" example:"loaded"`
+	Error  string    `json:"error,omitempty" example:"ollama API returned 500: model requires more system memory"`
+	At     time.Time `json:"at" example:"2026-01-01T00:00:00Z"`
 }
 
 type ModelDetails struct {
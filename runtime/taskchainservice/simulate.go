@@ -0,0 +1,227 @@
+package taskchainservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/llmrepo"
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ErrNoMockResponse is returned, and recorded on the failing step, when a
+// simulated chain reaches a model or tools task that has no scripted
+// response.
+var ErrNoMockResponse = errors.New("no mock response scripted for task")
+
+// SimulatedModel is the model name and provider type simulated steps report.
+const SimulatedModel = "simulated"
+
+// MockResponse scripts what a task receives in a simulation instead of a real
+// model reply — or, for a tools task, instead of the tool's result.
+type MockResponse struct {
+	// Content is the reply, returned on every call.
+	Content string `json:"content,omitempty" example:"valid"`
+	// Sequence, when set, replaces Content: the n-th call for the task gets
+	// Sequence[n], and calls past the end repeat the last entry. Use it for
+	// tasks a chain revisits in a loop.
+	Sequence []string `json:"sequence,omitempty"`
+	// Error, when set, fails the call with this message, as a provider error
+	// would, so failure branches can be exercised.
+	Error string `json:"error,omitempty" example:"model overloaded"`
+}
+
+// SimulationResult is the outcome of Simulate. A chain that fails still
+// yields a result: Error holds the failure and Trace the steps up to it.
+type SimulationResult struct {
+	Output     any                            `json:"output"`
+	OutputType string                         `json:"outputType" example:"string"`
+	Error      string                         `json:"error,omitempty"`
+	Trace      []taskengine.CapturedStateUnit `json:"trace"`
+}
+
+// Simulate runs chain on input with every model call answered from
+// responses, keyed by task ID, instead of a backend. Tools tasks are answered
+// from responses as well, so nothing outside the process is called and a run
+// costs no tokens. The same responses always yield the same trace, which lets
+// chain authors assert branching behavior in CI.
+//
+// Simulate returns an error only for a chain that fails validation; a chain
+// that fails while running, including one that reaches a model or tools task
+// without a scripted response (ErrNoMockResponse), is reported in the result.
+func Simulate(ctx context.Context, chain *taskengine.TaskChainDefinition, input any, responses map[string]MockResponse) (*SimulationResult, error) {
+	if err := validateChain(chain); err != nil {
+		return nil, fmt.Errorf("%w: %w", errdefs.ErrBadRequest, err)
+	}
+	if err := taskengine.ValidateChain(chain); err != nil {
+		return nil, fmt.Errorf("chain %q: %w", chain.ID, err)
+	}
+
+	script := newMockScript(responses)
+	tools := &mockTools{script: script}
+	// Simulated steps must not reach the caller's event sink: subscribers
+	// would take them for a real run.
+	ctx = taskengine.WithTaskEventSink(ctx, taskengine.NoopTaskEventSink{})
+	exec, err := taskengine.NewExec(ctx, &mockModelRepo{script: script}, tools, libtracker.NoopTracker{})
+	if err != nil {
+		return nil, err
+	}
+	env, err := taskengine.NewEnv(ctx, libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools)
+	if err != nil {
+		return nil, err
+	}
+	env, err = taskengine.NewMacroEnv(env, tools)
+	if err != nil {
+		return nil, err
+	}
+
+	output, outputType, trace, err := env.ExecEnv(ctx, chain, input, taskengine.InferDataType(input))
+	result := &SimulationResult{Output: output, OutputType: outputType.String(), Trace: trace}
+	if result.Trace == nil {
+		result.Trace = []taskengine.CapturedStateUnit{}
+	}
+	if err != nil {
+		result.Output, result.OutputType, result.Error = nil, "", err.Error()
+	}
+	return result, nil
+}
+
+// mockScript hands out the scripted replies and counts calls per task so
+// sequences advance.
+type mockScript struct {
+	responses map[string]MockResponse
+	mu        sync.Mutex
+	calls     map[string]int
+}
+
+func newMockScript(responses map[string]MockResponse) *mockScript {
+	return &mockScript{responses: responses, calls: map[string]int{}}
+}
+
+// reply returns the next scripted reply for the task the context belongs to.
+func (s *mockScript) reply(ctx context.Context) (string, error) {
+	scope, _ := taskengine.TaskEventScopeFromContext(ctx)
+	resp, ok := s.responses[scope.TaskID]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrNoMockResponse, scope.TaskID)
+	}
+	s.mu.Lock()
+	n := s.calls[scope.TaskID]
+	s.calls[scope.TaskID] = n + 1
+	s.mu.Unlock()
+
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	if len(resp.Sequence) > 0 {
+		return resp.Sequence[min(n, len(resp.Sequence)-1)], nil
+	}
+	return resp.Content, nil
+}
+
+// mockModelRepo is the llmrepo.ModelRepo Simulate runs chains against.
+// Token counts are a rough character estimate: deterministic, and enough for
+// the engine's context-length checks.
+type mockModelRepo struct {
+	script *mockScript
+}
+
+var _ llmrepo.ModelRepo = (*mockModelRepo)(nil)
+
+func (r *mockModelRepo) meta(req llmrepo.Request) llmrepo.Meta {
+	meta := llmrepo.Meta{ModelName: SimulatedModel, ProviderType: SimulatedModel, BackendID: SimulatedModel}
+	if len(req.ModelNames) > 0 {
+		meta.ModelName = req.ModelNames[0]
+	}
+	return meta
+}
+
+func estimateTokens(text string) int {
+	return len(text)/4 + 1
+}
+
+func (r *mockModelRepo) Tokenize(ctx context.Context, modelName string, prompt string) ([]int, error) {
+	return make([]int, estimateTokens(prompt)), nil
+}
+
+func (r *mockModelRepo) CountTokens(ctx context.Context, modelName string, prompt string) (int, error) {
+	return estimateTokens(prompt), nil
+}
+
+func (r *mockModelRepo) CountTokensBatch(ctx context.Context, modelName string, texts []string) ([]int, error) {
+	counts := make([]int, len(texts))
+	for i, text := range texts {
+		counts[i] = estimateTokens(text)
+	}
+	return counts, nil
+}
+
+func (r *mockModelRepo) Capabilities(ctx context.Context, modelName string) (llmrepo.Capabilities, bool) {
+	return llmrepo.Capabilities{SupportsTools: true, SupportsStreaming: true}, true
+}
+
+func (r *mockModelRepo) PromptExecute(ctx context.Context, req llmrepo.Request, systeminstruction string, temperature float32, prompt string) (string, llmrepo.Meta, error) {
+	content, err := r.script.reply(ctx)
+	return content, r.meta(req), err
+}
+
+func (r *mockModelRepo) Chat(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+	content, err := r.script.reply(ctx)
+	if err != nil {
+		return libmodelprovider.ChatResult{}, r.meta(req), err
+	}
+	return libmodelprovider.ChatResult{Message: libmodelprovider.Message{Role: "assistant", Content: content}}, r.meta(req), nil
+}
+
+func (r *mockModelRepo) Stream(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error) {
+	content, err := r.script.reply(ctx)
+	if err != nil {
+		return nil, r.meta(req), err
+	}
+	ch := make(chan *libmodelprovider.StreamParcel, 1)
+	ch <- &libmodelprovider.StreamParcel{Data: content}
+	close(ch)
+	return ch, r.meta(req), nil
+}
+
+func (r *mockModelRepo) Embed(ctx context.Context, embedReq llmrepo.EmbedRequest, prompt string) ([]float64, llmrepo.Meta, error) {
+	return nil, llmrepo.Meta{}, fmt.Errorf("embeddings are not simulated")
+}
+
+func (r *mockModelRepo) EmbedBatch(ctx context.Context, embedReq llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error) {
+	return nil, llmrepo.Meta{}, fmt.Errorf("embeddings are not simulated")
+}
+
+// mockTools answers tools tasks from the script and registers no tools, so
+// chat tasks run without tool definitions.
+type mockTools struct {
+	script *mockScript
+}
+
+var _ taskengine.ToolsRepo = (*mockTools)(nil)
+
+func (t *mockTools) Exec(ctx context.Context, startingTime time.Time, input any, debug bool, args *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	content, err := t.script.reply(ctx)
+	if err != nil {
+		return nil, taskengine.DataTypeAny, err
+	}
+	return content, taskengine.DataTypeString, nil
+}
+
+func (t *mockTools) Supports(ctx context.Context) ([]string, error) {
+	return []string{}, nil
+}
+
+func (t *mockTools) GetSchemasForSupportedTools(ctx context.Context) (map[string]*openapi3.T, error) {
+	return map[string]*openapi3.T{}, nil
+}
+
+func (t *mockTools) GetToolsForToolsByName(ctx context.Context, name string) ([]taskengine.Tool, error) {
+	return nil, fmt.Errorf("tools %q: %w", name, taskengine.ErrToolsNotFound)
+}
@@ -0,0 +1,83 @@
+package taskchainservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/taskchainservice"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func triageChain() *taskengine.TaskChainDefinition {
+	end := taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}}}
+	return &taskengine.TaskChainDefinition{
+		ID: "triage",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:             "classify",
+				Handler:        taskengine.HandleRoute,
+				PromptTemplate: "Classify: {{.input}}",
+				Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+					{Operator: taskengine.OpEquals, When: "spam", Goto: "discard"},
+					{Operator: taskengine.OpEquals, When: "ham", Goto: "reply"},
+				}},
+			},
+			{ID: "discard", Handler: taskengine.HandleNoop, Transition: end},
+			{ID: "reply", Handler: taskengine.HandleChatCompletion, PromptTemplate: "Answer: {{.input}}", Transition: end},
+		},
+	}
+}
+
+func tracedTasks(trace []taskengine.CapturedStateUnit) []string {
+	ids := make([]string, 0, len(trace))
+	for _, step := range trace {
+		ids = append(ids, step.TaskID)
+	}
+	return ids
+}
+
+func TestUnit_Simulate_FollowsScriptedBranch(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := taskchainservice.Simulate(ctx, triageChain(), "hi there", map[string]taskchainservice.MockResponse{
+		"classify": {Content: "ham"},
+		"reply":    {Content: "hello!"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Error)
+	require.Equal(t, []string{"classify", "reply"}, tracedTasks(result.Trace))
+	require.Equal(t, "ham", result.Trace[0].Transition)
+
+	result, err = taskchainservice.Simulate(ctx, triageChain(), "buy now", map[string]taskchainservice.MockResponse{
+		"classify": {Content: "spam"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Error)
+	require.Equal(t, []string{"classify", "discard"}, tracedTasks(result.Trace))
+}
+
+func TestUnit_Simulate_ReportsUnscriptedTask(t *testing.T) {
+	result, err := taskchainservice.Simulate(context.Background(), triageChain(), "hi", map[string]taskchainservice.MockResponse{
+		"classify": {Content: "ham"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, result.Error, taskchainservice.ErrNoMockResponse.Error())
+	require.Contains(t, result.Error, `"reply"`)
+	require.Nil(t, result.Output)
+}
+
+func TestUnit_Simulate_ScriptedError(t *testing.T) {
+	result, err := taskchainservice.Simulate(context.Background(), triageChain(), "hi", map[string]taskchainservice.MockResponse{
+		"classify": {Error: "model overloaded"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, result.Error, "model overloaded")
+}
+
+func TestUnit_Simulate_RejectsInvalidChain(t *testing.T) {
+	_, err := taskchainservice.Simulate(context.Background(), &taskengine.TaskChainDefinition{ID: "empty"}, "hi", nil)
+	require.True(t, errors.Is(err, errdefs.ErrBadRequest))
+}
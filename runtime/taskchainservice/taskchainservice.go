@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	libdb "github.com/contenox/runtime/libdbexec"
@@ -52,7 +53,7 @@ func validateChain(chain *taskengine.TaskChainDefinition) error {
 	if len(chain.Tasks) == 0 {
 		return fmt.Errorf("task chain must contain at least one task")
 	}
-	return nil
+	return taskengine.ValidatePartials(chain)
 }
 
 func (s *localStore) Get(ctx context.Context, ref string) (*taskengine.TaskChainDefinition, error) {
@@ -80,22 +81,26 @@ func (s *localStore) Get(ctx context.Context, ref string) (*taskengine.TaskChain
 	return nil, fmt.Errorf("task chain %q: %w", ref, libdb.ErrNotFound)
 }
 
+// List returns the path of every chain file under the chain directory,
+// subdirectories included, sorted. A .json file that does not hold a chain
+// with an ID and tasks is left out.
 func (s *localStore) List(ctx context.Context) ([]string, error) {
-	entries, err := s.files.List(ctx, ".")
-	if err != nil {
-		return nil, err
-	}
 	paths := []string{}
-	for _, entry := range entries {
-		if entry.IsDirectory || !strings.EqualFold(filepath.Ext(entry.Path), ".json") {
-			continue
+	_, err := s.files.Find(ctx, localfileservice.FindOptions{Globs: []string{"*"}}, func(entry localfileservice.Entry) error {
+		if !strings.EqualFold(filepath.Ext(entry.Path), ".json") {
+			return nil
 		}
 		chain, err := s.loadPath(ctx, entry.Path)
 		if err != nil || chain.ID == "" || len(chain.Tasks) == 0 {
-			continue
+			return nil
 		}
 		paths = append(paths, entry.Path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	sort.Strings(paths)
 	return paths, nil
 }
 
@@ -60,3 +60,17 @@ func TestUnit_TaskChainService_RejectsInvalidPath(t *testing.T) {
 	err = svc.CreateAtPath(context.Background(), "bad.txt", testChain("bad"))
 	require.Error(t, err)
 }
+
+func TestUnit_TaskChainService_RejectsUndefinedPartial(t *testing.T) {
+	files, err := localfileservice.New(t.TempDir())
+	require.NoError(t, err)
+	svc := taskchainservice.NewLocal(files)
+
+	chain := testChain("partials")
+	chain.Tasks[0].PromptTemplate = `{{.input}} {{template "disclaimer" .}}`
+	err = svc.CreateAtPath(context.Background(), "partials.json", chain)
+	require.ErrorContains(t, err, `undefined partial "disclaimer"`)
+
+	chain.Partials = map[string]string{"disclaimer": "may be wrong"}
+	require.NoError(t, svc.CreateAtPath(context.Background(), "partials.json", chain))
+}
@@ -0,0 +1,198 @@
+package taskchainservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrUnknownTools is returned by ImportChain for a chain that names tools
+	// the tools registry does not provide.
+	ErrUnknownTools = errors.New("task chain references unknown tools")
+	// ErrChainExists is returned by ImportChain when a chain with the same ID
+	// is already stored and overwrite was not requested.
+	ErrChainExists = errors.New("task chain already exists")
+)
+
+// Transfer exports stored chains for version control and imports chains
+// kept as code, checking on the way in what the plain CRUD methods do not:
+// that the chain would pass the engine's pre-execution validation, and that
+// every tool it names is registered.
+type Transfer struct {
+	chains Service
+	tools  taskengine.ToolsRegistry
+}
+
+// NewTransfer returns a Transfer over chains. tools is the registry imported
+// chains are checked against; nil skips the tool check.
+func NewTransfer(chains Service, tools taskengine.ToolsRegistry) *Transfer {
+	return &Transfer{chains: chains, tools: tools}
+}
+
+// ChainDocument is the YAML document ExportChains writes: every stored
+// chain with the path it is stored under.
+type ChainDocument struct {
+	Chains []ExportedChain `yaml:"chains"`
+}
+
+// ExportedChain is one chain of a ChainDocument.
+type ExportedChain struct {
+	Path  string                          `yaml:"path"`
+	Chain *taskengine.TaskChainDefinition `yaml:"chain"`
+}
+
+// ExportChain returns the chain ref (a path or a chain ID, as Get accepts)
+// as YAML.
+func (t *Transfer) ExportChain(ctx context.Context, ref string) ([]byte, error) {
+	chain, err := t.chains.Get(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(chain)
+	if err != nil {
+		return nil, fmt.Errorf("encode chain %q: %w", chain.ID, err)
+	}
+	return data, nil
+}
+
+// ExportChains returns every stored chain as one ChainDocument, ordered by
+// path so the output diffs cleanly between exports.
+func (t *Transfer) ExportChains(ctx context.Context) ([]byte, error) {
+	paths, err := t.chains.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	doc := ChainDocument{Chains: make([]ExportedChain, 0, len(paths))}
+	for _, path := range paths {
+		chain, err := t.chains.Get(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("read chain %s: %w", path, err)
+		}
+		doc.Chains = append(doc.Chains, ExportedChain{Path: path, Chain: chain})
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encode chains: %w", err)
+	}
+	return data, nil
+}
+
+// ImportChain validates chain and stores it, returning the path it was
+// written to. A chain whose ID is already stored is written over its
+// existing file when overwrite is set and refused with ErrChainExists
+// otherwise; a new chain is written to "<id>.json". Nothing is written when
+// the chain fails taskengine.ValidateChain or names a tool the registry does
+// not provide — the ErrUnknownTools error lists every unknown name, not just
+// the first.
+func (t *Transfer) ImportChain(ctx context.Context, chain *taskengine.TaskChainDefinition, overwrite bool) (string, error) {
+	if err := validateChain(chain); err != nil {
+		return "", fmt.Errorf("%w: %w", errdefs.ErrBadRequest, err)
+	}
+	if err := taskengine.ValidateChain(chain); err != nil {
+		return "", fmt.Errorf("chain %q: %w", chain.ID, err)
+	}
+	if err := t.checkTools(ctx, chain); err != nil {
+		return "", err
+	}
+
+	path, err := t.pathOf(ctx, chain.ID)
+	if err != nil {
+		return "", err
+	}
+	if path != "" {
+		if !overwrite {
+			return "", fmt.Errorf("%w: chain %q is stored at %s: %w", ErrChainExists, chain.ID, path, libdb.ErrUniqueViolation)
+		}
+		return path, t.chains.UpdateAtPath(ctx, path, chain)
+	}
+	path, err = NormalizePath(chain.ID + ".json")
+	if err != nil {
+		return "", fmt.Errorf("%w: chain ID %q cannot be used as a file name: %w", errdefs.ErrBadRequest, chain.ID, err)
+	}
+	return path, t.chains.CreateAtPath(ctx, path, chain)
+}
+
+// pathOf returns the path of the stored chain with the given ID, or "" when
+// there is none.
+func (t *Transfer) pathOf(ctx context.Context, id string) (string, error) {
+	paths, err := t.chains.List(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range paths {
+		stored, err := t.chains.Get(ctx, path)
+		if err != nil {
+			continue
+		}
+		if stored.ID == id {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+func (t *Transfer) checkTools(ctx context.Context, chain *taskengine.TaskChainDefinition) error {
+	if t.tools == nil {
+		return nil
+	}
+	referenced := ReferencedTools(chain)
+	if len(referenced) == 0 {
+		return nil
+	}
+	supported, err := t.tools.Supports(ctx)
+	if err != nil {
+		return fmt.Errorf("list registered tools: %w", err)
+	}
+	known := make(map[string]bool, len(supported))
+	for _, name := range supported {
+		known[name] = true
+	}
+	var unknown []string
+	for _, name := range referenced {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w %w: chain %q: %s", errdefs.ErrUnprocessableEntity, ErrUnknownTools, chain.ID, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// ReferencedTools returns the sorted, de-duplicated names of the tools chain
+// depends on: the provider of every tools task, and every name a task's
+// execute_config.tools allowlist spells out. Allowlist patterns ("*" and
+// "!name" exclusions) name no particular tool and are skipped.
+func ReferencedTools(chain *taskengine.TaskChainDefinition) []string {
+	seen := map[string]bool{}
+	for _, task := range chain.Tasks {
+		if task.Tools != nil && task.Tools.Name != "" {
+			seen[task.Tools.Name] = true
+		}
+		if task.ExecuteConfig == nil {
+			continue
+		}
+		for _, name := range task.ExecuteConfig.Tools {
+			name = strings.TrimSpace(name)
+			if name == "" || name == "*" || strings.HasPrefix(name, "!") {
+				continue
+			}
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
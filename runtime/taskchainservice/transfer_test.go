@@ -0,0 +1,121 @@
+package taskchainservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	libdb "github.com/contenox/runtime/libdbexec"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/localfileservice"
+	"github.com/contenox/runtime/runtime/taskchainservice"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type stubRegistry []string
+
+func (r stubRegistry) Supports(context.Context) ([]string, error) { return r, nil }
+
+func toolChain(id string, tools ...string) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: id,
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "notify", Handler: taskengine.HandleTools, Tools: &taskengine.ToolsCall{Name: "webtools", ToolName: "post"}},
+			{ID: "chat", Handler: taskengine.HandleChatCompletion, ExecuteConfig: &taskengine.LLMExecutionConfig{Tools: tools}},
+		},
+	}
+}
+
+func newTransfer(t *testing.T, tools taskengine.ToolsRegistry) (taskchainservice.Service, *taskchainservice.Transfer) {
+	t.Helper()
+	files, err := localfileservice.New(t.TempDir())
+	require.NoError(t, err)
+	chains := taskchainservice.NewLocal(files)
+	return chains, taskchainservice.NewTransfer(chains, tools)
+}
+
+func TestUnit_Transfer_ReferencedTools(t *testing.T) {
+	chain := toolChain("c", "*", "!local_shell", "local_fs", "webtools", " ")
+	require.Equal(t, []string{"local_fs", "webtools"}, taskchainservice.ReferencedTools(chain))
+}
+
+func TestUnit_Transfer_ImportListsUnknownTools(t *testing.T) {
+	ctx := context.Background()
+	chains, transfer := newTransfer(t, stubRegistry{"webtools"})
+
+	_, err := transfer.ImportChain(ctx, toolChain("c", "local_fs", "nws"), false)
+	require.ErrorIs(t, err, taskchainservice.ErrUnknownTools)
+	require.ErrorIs(t, err, errdefs.ErrUnprocessableEntity)
+	require.ErrorContains(t, err, "local_fs, nws")
+
+	paths, err := chains.List(ctx)
+	require.NoError(t, err)
+	require.Empty(t, paths, "a refused chain must not be written")
+}
+
+func TestUnit_Transfer_ImportRunsEngineValidation(t *testing.T) {
+	ctx := context.Background()
+	_, transfer := newTransfer(t, nil)
+
+	chain := &taskengine.TaskChainDefinition{
+		ID:    "c",
+		Tasks: []taskengine.TaskDefinition{{ID: "a", Handler: "no_such_handler"}},
+	}
+	_, err := transfer.ImportChain(ctx, chain, false)
+	require.ErrorIs(t, err, errdefs.ErrBadRequest)
+	require.ErrorContains(t, err, "unknown handler")
+}
+
+func TestUnit_Transfer_ImportCreatesAndOverwrites(t *testing.T) {
+	ctx := context.Background()
+	chains, transfer := newTransfer(t, stubRegistry{"webtools", "local_fs"})
+
+	path, err := transfer.ImportChain(ctx, toolChain("synced", "local_fs"), false)
+	require.NoError(t, err)
+	require.Equal(t, "synced.json", path)
+
+	_, err = transfer.ImportChain(ctx, toolChain("synced"), false)
+	require.ErrorIs(t, err, taskchainservice.ErrChainExists)
+	require.ErrorIs(t, err, libdb.ErrUniqueViolation)
+
+	// A chain stored under another path is overwritten in place.
+	require.NoError(t, chains.CreateAtPath(ctx, "team/other.json", toolChain("other")))
+	updated := toolChain("other")
+	updated.Description = "v2"
+	path, err = transfer.ImportChain(ctx, updated, true)
+	require.NoError(t, err)
+	require.Equal(t, "team/other.json", path)
+
+	got, err := chains.Get(ctx, "team/other.json")
+	require.NoError(t, err)
+	require.Equal(t, "v2", got.Description)
+}
+
+func TestUnit_Transfer_ExportChains(t *testing.T) {
+	ctx := context.Background()
+	chains, transfer := newTransfer(t, nil)
+	require.NoError(t, chains.CreateAtPath(ctx, "b.json", toolChain("b")))
+	require.NoError(t, chains.CreateAtPath(ctx, "a.json", toolChain("a", "webtools")))
+	require.NoError(t, chains.CreateAtPath(ctx, "team/c.json", toolChain("c")))
+
+	data, err := transfer.ExportChains(ctx)
+	require.NoError(t, err)
+	var doc taskchainservice.ChainDocument
+	require.NoError(t, yaml.Unmarshal(data, &doc))
+	require.Len(t, doc.Chains, 3, "chains in subdirectories are exported too")
+	require.Equal(t, "team/c.json", doc.Chains[2].Path)
+	require.Equal(t, "a.json", doc.Chains[0].Path)
+	require.Equal(t, "a", doc.Chains[0].Chain.ID)
+	require.Equal(t, []string{"webtools"}, doc.Chains[0].Chain.Tasks[1].ExecuteConfig.Tools)
+
+	one, err := transfer.ExportChain(ctx, "b")
+	require.NoError(t, err)
+	var chain taskengine.TaskChainDefinition
+	require.NoError(t, yaml.Unmarshal(one, &chain))
+	require.Equal(t, "b", chain.ID)
+
+	_, err = transfer.ExportChain(ctx, "missing")
+	require.True(t, errors.Is(err, libdb.ErrNotFound))
+}
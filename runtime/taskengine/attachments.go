@@ -0,0 +1,132 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultAttachmentBudget is the bytes of file text a chat_completion task
+// inlines when LLMExecutionConfig.AttachmentBudget is unset.
+const DefaultAttachmentBudget = 32 * 1024
+
+// AttachmentContent is what an AttachmentReader found in an attached file.
+type AttachmentContent struct {
+	// Text is the file's plain text. It is empty for a file that has no text
+	// representation, such as an image, which is then described instead.
+	Text string
+	// ContentType is the file's detected media type.
+	ContentType string
+	// Size is the file's size in bytes.
+	Size int64
+}
+
+// AttachmentReader reads the file an attachment references. The runtime
+// wires in a reader over the session's workspace.
+type AttachmentReader func(ctx context.Context, att Attachment) (AttachmentContent, error)
+
+type attachmentReaderContextKey struct{}
+
+// WithAttachmentReader stores the reader NewExec uses to inline message
+// attachments. Without one, attachments are never inlined.
+func WithAttachmentReader(ctx context.Context, reader AttachmentReader) context.Context {
+	return context.WithValue(ctx, attachmentReaderContextKey{}, reader)
+}
+
+func attachmentReaderFromContext(ctx context.Context) AttachmentReader {
+	if ctx == nil {
+		return nil
+	}
+	reader, _ := ctx.Value(attachmentReaderContextKey{}).(AttachmentReader)
+	return reader
+}
+
+// modelContent is the text the model sees for m: its Content followed by
+// the Text of each inlined attachment.
+func (m Message) modelContent() string {
+	if len(m.Attachments) == 0 {
+		return m.Content
+	}
+	var b strings.Builder
+	b.WriteString(m.Content)
+	for _, att := range m.Attachments {
+		if att.Text == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(att.Text)
+	}
+	return b.String()
+}
+
+// inlineAttachments fills the Text of every attachment in messages that has
+// none yet and returns the result, or ok=false when there was nothing to
+// fill; messages itself is not modified. Up to budget bytes of file text are
+// inlined, in message order: a file that does not fit is cut off, and files
+// after the budget ran out are described instead. Attachments inlined by an
+// earlier turn keep their Text and do not count against the budget.
+func (exe *SimpleExec) inlineAttachments(ctx context.Context, messages []Message, budget int) ([]Message, bool) {
+	if exe.attachments == nil {
+		return nil, false
+	}
+	if budget <= 0 {
+		budget = DefaultAttachmentBudget
+	}
+	var out []Message
+	for i, m := range messages {
+		var atts []Attachment
+		for j, att := range m.Attachments {
+			if att.Text != "" {
+				continue
+			}
+			if atts == nil {
+				atts = append([]Attachment(nil), m.Attachments...)
+			}
+			atts[j].Text, budget = exe.renderAttachment(ctx, att, budget)
+		}
+		if atts == nil {
+			continue
+		}
+		if out == nil {
+			out = append([]Message(nil), messages...)
+		}
+		out[i].Attachments = atts
+	}
+	return out, out != nil
+}
+
+// renderAttachment reads att and returns the text the model sees for it and
+// the budget left.
+func (exe *SimpleExec) renderAttachment(ctx context.Context, att Attachment, budget int) (string, int) {
+	content, err := exe.attachments(ctx, att)
+	if err != nil {
+		return fmt.Sprintf("[Attached file %s could not be read: %v]", att.FileID, err), budget
+	}
+	contentType := att.ContentType
+	if contentType == "" {
+		contentType = content.ContentType
+	}
+	if contentType == "" {
+		contentType = "unknown type"
+	}
+	if content.Text == "" {
+		return fmt.Sprintf("[Attached file %s: %s, %d bytes, no text content]", att.FileID, contentType, content.Size), budget
+	}
+	if budget <= 0 {
+		return fmt.Sprintf("[Attached file %s: %s, %d bytes, not shown: attachment budget exhausted]", att.FileID, contentType, content.Size), budget
+	}
+	text, note := content.Text, ""
+	if len(text) > budget {
+		cut := budget
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		note = fmt.Sprintf("\n[truncated: first %d of %d bytes shown]", cut, len(text))
+		text = text[:cut]
+	}
+	budget -= len(text)
+	return fmt.Sprintf("[Attached file %s (%s)]\n%s%s\n[End of attached file %s]", att.FileID, contentType, text, note, att.FileID), budget
+}
@@ -0,0 +1,112 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/llmrepo"
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ChatCompletion_InlinesAttachments(t *testing.T) {
+	files := map[string]taskengine.AttachmentContent{
+		"notes.md": {Text: "# Notes\nship it", ContentType: "text/markdown", Size: 15},
+		"big.txt":  {Text: strings.Repeat("x", 40), ContentType: "text/plain", Size: 40},
+		"logo.png": {ContentType: "image/png", Size: 2048},
+	}
+	reads := 0
+	reader := func(_ context.Context, att taskengine.Attachment) (taskengine.AttachmentContent, error) {
+		reads++
+		content, ok := files[att.FileID]
+		if !ok {
+			return taskengine.AttachmentContent{}, errors.New("file not found")
+		}
+		return content, nil
+	}
+
+	var sent []libmodelprovider.Message
+	repo := &mockModelRepo{
+		chatFunc: func(_ context.Context, _ llmrepo.Request, messages []libmodelprovider.Message, _ ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+			sent = messages
+			return libmodelprovider.ChatResult{
+				Message: libmodelprovider.Message{Role: "assistant", Content: "read them"},
+			}, llmrepo.Meta{ModelName: "test-model"}, nil
+		},
+	}
+	ctx := taskengine.WithAttachmentReader(context.Background(), reader)
+	exec, err := taskengine.NewExec(ctx, repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+
+	history := taskengine.ChatHistory{Messages: []taskengine.Message{{
+		Role:    "user",
+		Content: "Summarise these.",
+		Attachments: []taskengine.Attachment{
+			{FileID: "notes.md"},
+			{FileID: "big.txt"},
+			{FileID: "logo.png"},
+			{FileID: "missing.txt"},
+		},
+	}}}
+	task := &taskengine.TaskDefinition{
+		ID:      "chat",
+		Handler: taskengine.HandleChatCompletion,
+		ExecuteConfig: &taskengine.LLMExecutionConfig{
+			Model:             "test-model",
+			InlineAttachments: true,
+			AttachmentBudget:  30,
+		},
+	}
+
+	out, _, _, err := exec.TaskExec(context.Background(), time.Now().UTC(), 4000, &taskengine.ChainContext{}, task, history, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	require.Equal(t, "Summarise these.\n\n"+
+		"[Attached file notes.md (text/markdown)]\n# Notes\nship it\n[End of attached file notes.md]\n\n"+
+		"[Attached file big.txt (text/plain)]\n"+strings.Repeat("x", 15)+"\n[truncated: first 15 of 40 bytes shown]\n[End of attached file big.txt]\n\n"+
+		"[Attached file logo.png: image/png, 2048 bytes, no text content]\n\n"+
+		"[Attached file missing.txt could not be read: file not found]",
+		sent[0].Content)
+	require.Empty(t, history.Messages[0].Attachments[0].Text, "the input history is not modified")
+
+	// The returned history keeps the typed content and carries the inlined
+	// text on the attachments, so the next turn does not read the files again.
+	next := out.(taskengine.ChatHistory)
+	require.Equal(t, "Summarise these.", next.Messages[0].Content)
+	require.Contains(t, next.Messages[0].Attachments[0].Text, "ship it")
+	next.Messages = append(next.Messages, taskengine.Message{Role: "user", Content: "And now?"})
+	_, _, _, err = exec.TaskExec(context.Background(), time.Now().UTC(), 4000, &taskengine.ChainContext{}, task, next, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	require.Equal(t, 4, reads)
+	require.Contains(t, sent[0].Content, "ship it")
+}
+
+func TestUnit_ChatCompletion_AttachmentsNotInlinedByDefault(t *testing.T) {
+	reader := func(context.Context, taskengine.Attachment) (taskengine.AttachmentContent, error) {
+		t.Fatal("attachments are read only with inline_attachments")
+		return taskengine.AttachmentContent{}, nil
+	}
+	var sent []libmodelprovider.Message
+	repo := &mockModelRepo{
+		chatFunc: func(_ context.Context, _ llmrepo.Request, messages []libmodelprovider.Message, _ ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+			sent = messages
+			return libmodelprovider.ChatResult{Message: libmodelprovider.Message{Role: "assistant", Content: "ok"}}, llmrepo.Meta{}, nil
+		},
+	}
+	exec, err := taskengine.NewExec(taskengine.WithAttachmentReader(context.Background(), reader), repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+
+	history := taskengine.ChatHistory{Messages: []taskengine.Message{{
+		Role: "user", Content: "hi", Attachments: []taskengine.Attachment{{FileID: "notes.md"}},
+	}}}
+	task := &taskengine.TaskDefinition{ID: "chat", Handler: taskengine.HandleChatCompletion, ExecuteConfig: &taskengine.LLMExecutionConfig{Model: "test-model"}}
+	_, _, _, err = exec.TaskExec(context.Background(), time.Now().UTC(), 4000, &taskengine.ChainContext{}, task, history, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	require.Equal(t, "hi", sent[0].Content)
+}
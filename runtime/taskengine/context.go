@@ -87,6 +87,24 @@ func RuntimeToolsAllowlistFromContext(ctx context.Context) ([]string, bool) {
 	return v.list, true
 }
 
+type clientToolsKey struct{}
+
+// WithClientTools attaches tool definitions supplied by the API client (an
+// OpenAI-style request's `tools`) to ctx. ExecEnv offers them to chat tasks
+// whose execute_config sets pass_clients_tools. The runtime never executes
+// them: execute_tool_calls hands a batch calling one back to the caller with
+// TransitionClientToolCalls.
+func WithClientTools(ctx context.Context, tools []Tool) context.Context {
+	return context.WithValue(ctx, clientToolsKey{}, tools)
+}
+
+// ClientToolsFromContext returns the tools attached with WithClientTools,
+// or nil when there are none.
+func ClientToolsFromContext(ctx context.Context) []Tool {
+	tools, _ := ctx.Value(clientToolsKey{}).([]Tool)
+	return tools
+}
+
 type contextKey string
 
 const (
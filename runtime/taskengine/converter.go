@@ -21,6 +21,8 @@ func ConvertToType(value interface{}, dataType DataType) (interface{}, error) {
 		return convertToJSON(value)
 	case DataTypeNil:
 		return nil, nil
+	case DataTypeBool:
+		return convertToBool(value)
 	case DataTypeAny:
 		if value == nil {
 			return nil, nil
@@ -136,6 +138,17 @@ func convertToInt(value interface{}) (int, error) {
 	}
 }
 
+func convertToBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	default:
+		return false, fmt.Errorf("cannot convert %T to bool", value)
+	}
+}
+
 func convertToJSON(value interface{}) (interface{}, error) {
 	switch v := value.(type) {
 	case map[string]interface{}, []interface{}:
@@ -0,0 +1,81 @@
+package taskengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ModelPrice is what one model charges per 1,000 tokens. The currency is
+// whatever the price table is written in; the engine only multiplies.
+type ModelPrice struct {
+	InputPer1K  float64 `json:"input_per_1k" example:"0.0025"`
+	OutputPer1K float64 `json:"output_per_1k" example:"0.01"`
+}
+
+// PriceTable maps model names to their prices. Lookups are exact first, then
+// case-insensitive, so "GPT-4o" in a table prices "gpt-4o".
+type PriceTable map[string]ModelPrice
+
+// ParsePriceTable decodes a JSON object of model name to ModelPrice, as stored
+// by `contenox config set model-prices`. An empty string is an empty table.
+func ParsePriceTable(raw string) (PriceTable, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var table PriceTable
+	if err := json.Unmarshal([]byte(raw), &table); err != nil {
+		return nil, fmt.Errorf("model prices must be a JSON object of model name to {\"input_per_1k\", \"output_per_1k\"}: %w", err)
+	}
+	for model, p := range table {
+		if p.InputPer1K < 0 || p.OutputPer1K < 0 {
+			return nil, fmt.Errorf("model prices: %q has a negative price", model)
+		}
+	}
+	return table, nil
+}
+
+// Cost prices usage at model's rate. It reports false when the table has no
+// price for model, so an unpriced model is not mistaken for a free one.
+func (t PriceTable) Cost(model string, usage TokenUsage) (float64, bool) {
+	p, ok := t[model]
+	if !ok {
+		for name, candidate := range t {
+			if strings.EqualFold(name, model) {
+				p, ok = candidate, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	return float64(usage.Prompt)/1000*p.InputPer1K + float64(usage.Completion)/1000*p.OutputPer1K, true
+}
+
+type priceTableKey struct{}
+
+// WithPriceTable attaches the prices ExecEnv uses to estimate the cost of each
+// model step. Without one, steps carry token usage but no cost.
+func WithPriceTable(ctx context.Context, table PriceTable) context.Context {
+	if len(table) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, priceTableKey{}, table)
+}
+
+// PriceTableFromContext returns the table attached by WithPriceTable, or nil.
+func PriceTableFromContext(ctx context.Context) PriceTable {
+	table, _ := ctx.Value(priceTableKey{}).(PriceTable)
+	return table
+}
+
+// TotalCost sums the estimated cost of an execution history.
+func TotalCost(steps []CapturedStateUnit) float64 {
+	var total float64
+	for _, s := range steps {
+		total += s.Cost
+	}
+	return total
+}
@@ -0,0 +1,58 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_ParsePriceTable(t *testing.T) {
+	table, err := taskengine.ParsePriceTable("")
+	require.NoError(t, err)
+	require.Nil(t, table)
+
+	table, err = taskengine.ParsePriceTable(`{"gpt-4o":{"input_per_1k":0.0025,"output_per_1k":0.01}}`)
+	require.NoError(t, err)
+	require.Equal(t, taskengine.ModelPrice{InputPer1K: 0.0025, OutputPer1K: 0.01}, table["gpt-4o"])
+
+	_, err = taskengine.ParsePriceTable(`[1,2]`)
+	require.Error(t, err)
+	_, err = taskengine.ParsePriceTable(`{"m":{"input_per_1k":-1}}`)
+	require.ErrorContains(t, err, "negative")
+}
+
+func TestUnit_PriceTable_Cost(t *testing.T) {
+	table := taskengine.PriceTable{"GPT-4o": {InputPer1K: 2, OutputPer1K: 10}}
+	usage := taskengine.TokenUsage{Prompt: 500, Completion: 100, Total: 600}
+
+	cost, ok := table.Cost("GPT-4o", usage)
+	require.True(t, ok)
+	require.InDelta(t, 2.0, cost, 1e-9)
+
+	cost, ok = table.Cost("gpt-4o", usage)
+	require.True(t, ok, "lookup falls back to a case-insensitive match")
+	require.InDelta(t, 2.0, cost, 1e-9)
+
+	_, ok = table.Cost("llama3", usage)
+	require.False(t, ok, "an unpriced model is not free")
+
+	var empty taskengine.PriceTable
+	_, ok = empty.Cost("gpt-4o", usage)
+	require.False(t, ok)
+}
+
+func TestUnit_PriceTable_Context(t *testing.T) {
+	ctx := context.Background()
+	require.Nil(t, taskengine.PriceTableFromContext(ctx))
+
+	table := taskengine.PriceTable{"m": {InputPer1K: 1}}
+	require.Equal(t, table, taskengine.PriceTableFromContext(taskengine.WithPriceTable(ctx, table)))
+}
+
+func TestUnit_TotalCost(t *testing.T) {
+	steps := []taskengine.CapturedStateUnit{{Cost: 0.25}, {}, {Cost: 0.5}}
+	require.InDelta(t, 0.75, taskengine.TotalCost(steps), 1e-9)
+	require.Zero(t, taskengine.TotalCost(nil))
+}
@@ -0,0 +1,68 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// detectLanguageInstruction is the system instruction a detect_language task
+// sends, after the task's own system_instruction if it has one.
+const detectLanguageInstruction = "Identify the language the text is written in. " +
+	"Respond with its two-letter ISO 639-1 code in lowercase (for example en, de or pt) and nothing else."
+
+// iso6391Codes is the set of ISO 639-1 language codes detect_language
+// accepts.
+var iso6391Codes = func() map[string]struct{} {
+	codes := map[string]struct{}{}
+	for _, code := range strings.Fields(`
+		aa ab ae af ak am an ar as av ay az ba be bg bi bm bn bo br bs ca ce ch
+		co cr cs cu cv cy da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy
+		ga gd gl gn gu gv ha he hi ho hr ht hu hy hz ia id ie ig ii ik io is it
+		iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky la lb lg li ln lo
+		lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny
+		oc oj om or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd se sg si sk sl
+		sm sn so sq sr ss st su sv sw ta te tg th ti tk tl tn to tr ts tt tw ty
+		ug uk ur uz ve vi vo wa wo xh yi yo za zh zu`) {
+		codes[code] = struct{}{}
+	}
+	return codes
+}()
+
+// detectLanguage runs a detect_language task: it asks the model which
+// language text is written in and returns the ISO 639-1 code as
+// DataTypeString, which is also the transition eval, so a chain can branch
+// to a language-specific sub-chain with an equals branch per code. A reply
+// that is not a known code fails the attempt, so the task's retry_on_failure
+// asks again.
+func (exe *SimpleExec) detectLanguage(ctx context.Context, currentTask *TaskDefinition, text string, ctxLength int) (any, DataType, string, error) {
+	sys := currentTask.SystemInstruction
+	if sys != "" {
+		sys += "\n\n"
+	}
+	sys += detectLanguageInstruction
+
+	answer, err := exe.Prompt(ctx, sys, *currentTask.ExecuteConfig, text, ctxLength)
+	if err != nil {
+		return nil, DataTypeAny, "", fmt.Errorf("detect_language task %s: %w", currentTask.ID, err)
+	}
+	code, err := parseLanguageCode(answer)
+	if err != nil {
+		return nil, DataTypeAny, "", withCategory(ErrValidation, fmt.Errorf("detect_language task %s: %w", currentTask.ID, err))
+	}
+	return code, DataTypeString, code, nil
+}
+
+// parseLanguageCode reads the ISO 639-1 code out of a model reply. Quotes,
+// code fences and trailing punctuation are ignored, and a region subtag is
+// dropped, so "`pt-BR`." reads as "pt".
+func parseLanguageCode(reply string) (string, error) {
+	code := strings.ToLower(strings.Trim(stripCodeFences(reply), " \t\r\n\"'`*.,;:!"))
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	if _, ok := iso6391Codes[code]; !ok {
+		return "", fmt.Errorf("response %q is not an ISO 639-1 language code", truncateReply(reply))
+	}
+	return code, nil
+}
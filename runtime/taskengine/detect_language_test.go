@@ -0,0 +1,71 @@
+package taskengine_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/llmrepo"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func detectLanguageEnv(t *testing.T, replies ...string) (taskengine.EnvExecutor, *[]string) {
+	t.Helper()
+	var systems []string
+	repo := &mockModelRepo{
+		promptFunc: func(_ context.Context, _ llmrepo.Request, sys string, _ float32, _ string) (string, llmrepo.Meta, error) {
+			systems = append(systems, sys)
+			reply := replies[0]
+			if len(replies) > 1 {
+				replies = replies[1:]
+			}
+			return reply, llmrepo.Meta{ModelName: "test-model", ProviderType: "llama"}, nil
+		},
+	}
+	exec, err := taskengine.NewExec(context.Background(), repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env, err := taskengine.NewEnv(context.Background(), libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+	return env, &systems
+}
+
+func detectLanguageChain(retries int) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID: "support",
+		Tasks: []taskengine.TaskDefinition{{
+			ID:             "language",
+			Handler:        taskengine.HandleDetectLanguage,
+			ExecuteConfig:  &taskengine.LLMExecutionConfig{Model: "test-model"},
+			RetryOnFailure: retries,
+			Transition: taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{
+				{Operator: taskengine.OpEquals, When: "de", Goto: taskengine.TermEnd},
+			}},
+		}},
+	}
+}
+
+func TestUnit_DetectLanguage_BranchesOnCode(t *testing.T) {
+	env, systems := detectLanguageEnv(t, "`DE-at`.")
+	out, dt, trace, err := env.ExecEnv(context.Background(), detectLanguageChain(0), "Mein Drucker druckt nicht.", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, "de", out)
+	require.Equal(t, taskengine.DataTypeString, dt)
+	require.Equal(t, "de", trace[len(trace)-1].Transition)
+	require.True(t, strings.Contains((*systems)[0], "ISO 639-1"))
+}
+
+func TestUnit_DetectLanguage_RetriesInvalidCodes(t *testing.T) {
+	env, systems := detectLanguageEnv(t, "German", "xx", "de")
+	out, _, _, err := env.ExecEnv(context.Background(), detectLanguageChain(2), "Mein Drucker druckt nicht.", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, "de", out)
+	require.Len(t, *systems, 3, "each invalid reply is retried")
+
+	env, _ = detectLanguageEnv(t, "German")
+	_, _, _, err = env.ExecEnv(context.Background(), detectLanguageChain(1), "Mein Drucker druckt nicht.", taskengine.DataTypeString)
+	require.ErrorContains(t, err, `"German" is not an ISO 639-1 language code`)
+	require.ErrorIs(t, err, taskengine.ErrValidation)
+}
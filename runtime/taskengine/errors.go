@@ -1,6 +1,75 @@
 package taskengine
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 // ErrContextLengthExceeded is returned when the input or chat history exceeds the allowed context length.
 var ErrContextLengthExceeded = errors.New("exceeds context length")
+
+// Error categories. Task failures are tagged with one of these so callers
+// can tell a bad request or bad model output from a broken dependency
+// without matching messages: test with errors.Is, or ask ErrorCategory.
+// Tagging leaves the error's message unchanged.
+var (
+	// ErrValidation is a failure caused by the chain or its input: a
+	// malformed task definition, a template that does not render, model
+	// output that does not parse, an input over the context length.
+	// Retrying the same request fails the same way.
+	ErrValidation = errors.New("validation failed")
+	// ErrProvider is a failure of the model provider: the backend is down,
+	// rejected the call or broke off its stream.
+	ErrProvider = errors.New("model provider failed")
+	// ErrTimeout is a task or request that ran out of time.
+	ErrTimeout = errors.New("timed out")
+	// ErrHook is a failure of a tool a tools task called.
+	ErrHook = errors.New("hook failed")
+)
+
+// categorizedError tags err with a category while keeping err's message.
+type categorizedError struct {
+	err      error
+	category error
+}
+
+func (e *categorizedError) Error() string   { return e.err.Error() }
+func (e *categorizedError) Unwrap() []error { return []error{e.err, e.category} }
+
+// withCategory tags err with category. A nil err stays nil, and an err
+// already in category is returned as is.
+func withCategory(category, err error) error {
+	if err == nil || errors.Is(err, category) {
+		return err
+	}
+	return &categorizedError{err: err, category: category}
+}
+
+// ErrorCategory returns the category err is tagged with, or nil for an
+// uncategorized error. When an error carries more than one — a provider call
+// cut off by the task's deadline — ErrTimeout wins over ErrProvider and
+// ErrHook, which win over ErrValidation.
+func ErrorCategory(err error) error {
+	for _, category := range []error{ErrTimeout, ErrProvider, ErrHook, ErrValidation} {
+		if errors.Is(err, category) {
+			return category
+		}
+	}
+	return nil
+}
+
+// categorizeTaskError tags the failures TaskExec can classify from the error
+// alone; the call sites tag provider, hook and validation failures as they
+// happen.
+func categorizeTaskError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return withCategory(ErrTimeout, err)
+	}
+	if errors.Is(err, ErrContextLengthExceeded) || errors.Is(err, ErrUnsupportedTaskType) {
+		return withCategory(ErrValidation, err)
+	}
+	return err
+}
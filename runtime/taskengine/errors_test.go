@@ -0,0 +1,61 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingToolsRepo holds every call until its context ends.
+type blockingToolsRepo struct{ scopedExecToolsRepo }
+
+func (b *blockingToolsRepo) Exec(ctx context.Context, _ time.Time, _ any, _ bool, _ *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	<-ctx.Done()
+	return nil, taskengine.DataTypeAny, ctx.Err()
+}
+
+func TestUnit_ErrorCategory(t *testing.T) {
+	require.Nil(t, taskengine.ErrorCategory(errors.New("plain")))
+	require.Nil(t, taskengine.ErrorCategory(nil))
+	require.Equal(t, taskengine.ErrValidation, taskengine.ErrorCategory(fmt.Errorf("task x: %w", taskengine.ErrValidation)))
+	require.Equal(t, taskengine.ErrTimeout,
+		taskengine.ErrorCategory(fmt.Errorf("%w: %w", taskengine.ErrProvider, taskengine.ErrTimeout)),
+		"a provider call cut off by a deadline is a timeout")
+}
+
+func TestUnit_TaskExec_CategorizesFailures(t *testing.T) {
+	tools := &scopedExecToolsRepo{supported: []string{"mail"}}
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools, libtracker.NoopTracker{})
+	require.NoError(t, err)
+	now := time.Now().UTC()
+
+	extract := &taskengine.TaskDefinition{ID: "pick", Handler: taskengine.HandleJSONExtract, Extract: &taskengine.JSONExtractConfig{Path: "$.x"}}
+	_, _, _, err = exec.TaskExec(context.Background(), now, 0, &taskengine.ChainContext{}, extract, "not json", taskengine.DataTypeString)
+	require.ErrorIs(t, err, taskengine.ErrValidation, "model output that does not parse is a validation failure")
+
+	unknown := &taskengine.TaskDefinition{ID: "odd", Handler: "no_such_handler"}
+	_, _, _, err = exec.TaskExec(context.Background(), now, 0, &taskengine.ChainContext{}, unknown, "x", taskengine.DataTypeString)
+	require.ErrorIs(t, err, taskengine.ErrUnsupportedTaskType)
+	require.Equal(t, taskengine.ErrValidation, taskengine.ErrorCategory(err))
+
+	failing := &countingToolsRepo{scopedExecToolsRepo: scopedExecToolsRepo{supported: []string{"mail"}}, failNext: true}
+	exec, err = taskengine.NewExec(context.Background(), &mockModelRepo{}, failing, libtracker.NoopTracker{})
+	require.NoError(t, err)
+	send := &taskengine.TaskDefinition{ID: "send", Handler: taskengine.HandleTools, Tools: &taskengine.ToolsCall{Name: "mail", ToolName: "send_email"}}
+	_, _, _, err = exec.TaskExec(context.Background(), now, 0, &taskengine.ChainContext{}, send, "hi", taskengine.DataTypeString)
+	require.ErrorIs(t, err, taskengine.ErrHook)
+	require.EqualError(t, err, "smtp unavailable", "categorizing does not change the message")
+
+	exec, err = taskengine.NewExec(context.Background(), &mockModelRepo{}, &blockingToolsRepo{}, libtracker.NoopTracker{})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, _, err = exec.TaskExec(ctx, now, 0, &taskengine.ChainContext{}, send, "hi", taskengine.DataTypeString)
+	require.Equal(t, taskengine.ErrTimeout, taskengine.ErrorCategory(err))
+}
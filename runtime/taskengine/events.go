@@ -174,6 +174,13 @@ func taskEventScopeFromContext(ctx context.Context) (TaskEventScope, bool) {
 	return scope, ok
 }
 
+// TaskEventScopeFromContext returns the scope ExecEnv attaches to the context
+// of every task attempt, which the model and tools repos are called with. It
+// lets a repo tell which task of which chain a call belongs to.
+func TaskEventScopeFromContext(ctx context.Context) (TaskEventScope, bool) {
+	return taskEventScopeFromContext(ctx)
+}
+
 func NewTaskEvent(ctx context.Context, kind TaskEventKind) TaskEvent {
 	event := TaskEvent{
 		Kind:      kind,
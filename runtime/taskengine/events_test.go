@@ -41,6 +41,18 @@ func (m *mockModelRepo) CountTokens(ctx context.Context, modelName string, promp
 	return 1, nil
 }
 
+func (m *mockModelRepo) CountTokensBatch(ctx context.Context, modelName string, texts []string) ([]int, error) {
+	counts := make([]int, len(texts))
+	for i := range counts {
+		counts[i] = 1
+	}
+	return counts, nil
+}
+
+func (m *mockModelRepo) Capabilities(ctx context.Context, modelName string) (llmrepo.Capabilities, bool) {
+	return llmrepo.Capabilities{}, false
+}
+
 func (m *mockModelRepo) PromptExecute(ctx context.Context, req llmrepo.Request, systeminstruction string, temperature float32, prompt string) (string, llmrepo.Meta, error) {
 	if m.promptFunc != nil {
 		return m.promptFunc(ctx, req, systeminstruction, temperature, prompt)
@@ -59,6 +71,10 @@ func (m *mockModelRepo) Embed(ctx context.Context, embedReq llmrepo.EmbedRequest
 	return nil, llmrepo.Meta{}, errors.New("Embed should not be called")
 }
 
+func (m *mockModelRepo) EmbedBatch(ctx context.Context, embedReq llmrepo.EmbedRequest, prompts []string) ([][]float64, llmrepo.Meta, error) {
+	return nil, llmrepo.Meta{}, errors.New("EmbedBatch should not be called")
+}
+
 func (m *mockModelRepo) Stream(ctx context.Context, req llmrepo.Request, messages []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (<-chan *libmodelprovider.StreamParcel, llmrepo.Meta, error) {
 	if m.streamFunc == nil {
 		return nil, llmrepo.Meta{}, errors.New("streamFunc not configured")
@@ -158,3 +158,38 @@ func TestUnit_ExecuteToolCalls_NilToolsScope_PreservesLegacyExecution(t *testing
 	require.Equal(t, "local_shell", repo.calls[0].Name)
 	require.Equal(t, "local_shell", repo.calls[0].ToolName)
 }
+
+func TestUnit_ExecuteToolCalls_HandsClientToolCallsBack(t *testing.T) {
+	repo := &scopedExecToolsRepo{supported: []string{"local_fs"}}
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, repo, libtracker.NoopTracker{})
+	require.NoError(t, err)
+
+	chainCtx := &taskengine.ChainContext{
+		Tools: map[string]taskengine.ToolWithResolution{
+			"local_fs.read_file": {
+				Tool:      taskengine.Tool{Type: "function", Function: taskengine.FunctionTool{Name: "local_fs.read_file"}},
+				ToolsName: "local_fs",
+			},
+		},
+		ClientTools: []taskengine.Tool{{Type: "function", Function: taskengine.FunctionTool{Name: "get_weather"}}},
+	}
+	history := taskengine.ChatHistory{Messages: []taskengine.Message{{
+		Role: "assistant",
+		CallTools: []taskengine.ToolCall{
+			{ID: "call-1", Type: "function", Function: taskengine.FunctionCall{Name: "local_fs.read_file", Arguments: `{}`}},
+			{ID: "call-2", Type: "function", Function: taskengine.FunctionCall{Name: "get_weather", Arguments: `{}`}},
+		},
+	}}}
+
+	out, _, transition, err := exec.TaskExec(
+		context.Background(), time.Now().UTC(), 4000,
+		chainCtx,
+		&taskengine.TaskDefinition{ID: "exec", Handler: taskengine.HandleExecuteToolCalls},
+		history,
+		taskengine.DataTypeChatHistory,
+	)
+	require.NoError(t, err)
+	require.Equal(t, taskengine.TransitionClientToolCalls, transition)
+	require.Empty(t, repo.calls, "a batch with a client call is handed back whole")
+	require.Len(t, out.(taskengine.ChatHistory).Messages, 1)
+}
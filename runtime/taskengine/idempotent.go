@@ -0,0 +1,140 @@
+package taskengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+)
+
+// idempotentToolsKey marks a context whose tool calls come from a task with
+// Idempotent set.
+type idempotentToolsKey struct{}
+
+// WithIdempotentToolCalls marks the tool calls made under ctx as safe to
+// answer from an IdempotentTools cache. The task engine sets it for tasks
+// with TaskDefinition.Idempotent.
+func WithIdempotentToolCalls(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentToolsKey{}, true)
+}
+
+// IdempotentToolCallsFromContext reports whether ctx was marked by
+// WithIdempotentToolCalls.
+func IdempotentToolCallsFromContext(ctx context.Context) bool {
+	on, _ := ctx.Value(idempotentToolsKey{}).(bool)
+	return on
+}
+
+// IdempotencyKeyFunc derives the key that recognises a repeat of a tool
+// call. ok=false runs the call uncached.
+type IdempotencyKeyFunc func(ctx context.Context, input any, call *ToolsCall) (key string, ok bool)
+
+// DefaultIdempotencyKey keys a call by the chain run it belongs to (the
+// request ID on ctx), the provider and tool it targets, its args and its
+// input. Calls made outside a request, or whose input cannot be encoded,
+// are not keyed.
+func DefaultIdempotencyKey(ctx context.Context, input any, call *ToolsCall) (string, bool) {
+	runID, _ := ctx.Value(libtracker.ContextKeyRequestID).(string)
+	if runID == "" || call == nil {
+		return "", false
+	}
+	// encoding/json sorts map keys, so equal args encode equally.
+	args, err := json.Marshal(call.Args)
+	if err != nil {
+		return "", false
+	}
+	in, err := json.Marshal(input)
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(runID), []byte(call.Name), []byte(call.ToolName), args, in} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// idempotentToolsRetention bounds how many completed calls an IdempotentTools
+// wrapper remembers; the oldest are forgotten first.
+const idempotentToolsRetention = 1024
+
+// IdempotentTools wraps inner so a tool call from an Idempotent task that
+// already completed is not executed again: a repeat with the same key — a
+// task retried after a downstream failure, or a chain looping back through a
+// side-effecting step such as send_email — returns the first call's result.
+// A concurrent repeat waits for the call in flight. Failed calls are not
+// remembered, so a retry after a failure runs the call again.
+//
+// Calls from tasks without the flag pass straight through. Results are held
+// in memory, so protection lasts for the process's lifetime and the most
+// recent idempotentToolsRetention calls. A nil keyFn uses
+// DefaultIdempotencyKey.
+func IdempotentTools(inner ToolsRepo, keyFn IdempotencyKeyFunc) ToolsRepo {
+	if keyFn == nil {
+		keyFn = DefaultIdempotencyKey
+	}
+	return &idempotentTools{ToolsRepo: inner, keyFn: keyFn, calls: map[string]*idempotentCall{}}
+}
+
+type idempotentTools struct {
+	ToolsRepo
+	keyFn IdempotencyKeyFunc
+
+	mu        sync.Mutex
+	calls     map[string]*idempotentCall
+	completed []string // keys of completed calls, oldest first
+}
+
+type idempotentCall struct {
+	done     chan struct{}
+	output   any
+	dataType DataType
+	err      error
+}
+
+func (t *idempotentTools) Exec(ctx context.Context, startingTime time.Time, input any, debug bool, args *ToolsCall) (any, DataType, error) {
+	if !IdempotentToolCallsFromContext(ctx) {
+		return t.ToolsRepo.Exec(ctx, startingTime, input, debug, args)
+	}
+	key, ok := t.keyFn(ctx, input, args)
+	if !ok {
+		return t.ToolsRepo.Exec(ctx, startingTime, input, debug, args)
+	}
+
+	t.mu.Lock()
+	if call, found := t.calls[key]; found {
+		t.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.output, call.dataType, call.err
+		case <-ctx.Done():
+			return nil, DataTypeAny, ctx.Err()
+		}
+	}
+	call := &idempotentCall{done: make(chan struct{})}
+	t.calls[key] = call
+	t.mu.Unlock()
+
+	call.output, call.dataType, call.err = t.ToolsRepo.Exec(ctx, startingTime, input, debug, args)
+
+	t.mu.Lock()
+	if call.err != nil {
+		delete(t.calls, key)
+	} else {
+		t.completed = append(t.completed, key)
+		for len(t.completed) > idempotentToolsRetention {
+			delete(t.calls, t.completed[0])
+			t.completed = t.completed[1:]
+		}
+	}
+	t.mu.Unlock()
+	close(call.done)
+	return call.output, call.dataType, call.err
+}
+
+var _ ToolsRepo = (*idempotentTools)(nil)
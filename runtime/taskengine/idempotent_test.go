@@ -0,0 +1,87 @@
+package taskengine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+// countingToolsRepo counts Exec calls and fails while failNext is set.
+type countingToolsRepo struct {
+	scopedExecToolsRepo
+	execs    int
+	failNext bool
+}
+
+func (c *countingToolsRepo) Exec(_ context.Context, _ time.Time, _ any, _ bool, _ *taskengine.ToolsCall) (any, taskengine.DataType, error) {
+	c.execs++
+	if c.failNext {
+		c.failNext = false
+		return nil, taskengine.DataTypeAny, errors.New("smtp unavailable")
+	}
+	return map[string]any{"sent": c.execs}, taskengine.DataTypeJSON, nil
+}
+
+func runCtx(runID string) context.Context {
+	return context.WithValue(context.Background(), libtracker.ContextKeyRequestID, runID)
+}
+
+func TestUnit_IdempotentTools_RepeatReturnsFirstResult(t *testing.T) {
+	inner := &countingToolsRepo{}
+	repo := taskengine.IdempotentTools(inner, nil)
+	call := &taskengine.ToolsCall{Name: "mail", ToolName: "send_email", Args: map[string]string{"to": "a@example.com"}}
+	ctx := taskengine.WithIdempotentToolCalls(runCtx("run-1"))
+
+	first, _, err := repo.Exec(ctx, time.Now(), "hello", false, call)
+	require.NoError(t, err)
+	again, dt, err := repo.Exec(ctx, time.Now(), "hello", false, call)
+	require.NoError(t, err)
+	require.Equal(t, taskengine.DataTypeJSON, dt)
+	require.Equal(t, first, again)
+	require.Equal(t, 1, inner.execs, "the repeat must not reach the tool")
+
+	other := &taskengine.ToolsCall{Name: "mail", ToolName: "send_email", Args: map[string]string{"to": "b@example.com"}}
+	_, _, err = repo.Exec(ctx, time.Now(), "hello", false, other)
+	require.NoError(t, err)
+	require.Equal(t, 2, inner.execs, "different args are a different call")
+
+	_, _, err = repo.Exec(taskengine.WithIdempotentToolCalls(runCtx("run-2")), time.Now(), "hello", false, call)
+	require.NoError(t, err)
+	require.Equal(t, 3, inner.execs, "another run is not deduplicated")
+}
+
+func TestUnit_IdempotentTools_OptInAndFailures(t *testing.T) {
+	inner := &countingToolsRepo{}
+	repo := taskengine.IdempotentTools(inner, nil)
+	call := &taskengine.ToolsCall{Name: "mail", ToolName: "send_email"}
+
+	for range 2 {
+		_, _, err := repo.Exec(runCtx("run-1"), time.Now(), nil, false, call)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, inner.execs, "tasks without the flag are not cached")
+
+	inner.execs = 0
+	marked := taskengine.WithIdempotentToolCalls(runCtx("run-1"))
+	inner.failNext = true
+	_, _, err := repo.Exec(marked, time.Now(), nil, false, call)
+	require.Error(t, err)
+	_, _, err = repo.Exec(marked, time.Now(), nil, false, call)
+	require.NoError(t, err)
+	_, _, err = repo.Exec(marked, time.Now(), nil, false, call)
+	require.NoError(t, err)
+	require.Equal(t, 2, inner.execs, "a failed call is retried; the success after it is not repeated")
+
+	inner.execs = 0
+	noRun := taskengine.WithIdempotentToolCalls(context.Background())
+	for range 2 {
+		_, _, err := repo.Exec(noRun, time.Now(), nil, false, call)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, inner.execs, "without a run ID the default key does not apply")
+}
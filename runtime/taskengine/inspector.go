@@ -33,6 +33,11 @@ type CapturedStateUnit struct {
 	ModelName    string      `json:"modelName,omitempty"`
 	ToolNames    []string    `json:"toolNames,omitempty"`
 	TokenUsage   *TokenUsage `json:"tokenUsage,omitempty"`
+	// DroppedMessages is how many messages a truncate_history step removed.
+	DroppedMessages int `json:"droppedMessages,omitempty"`
+	// Cost is the estimated price of the step's model call, from the price
+	// table attached with WithPriceTable. Zero when the model is unpriced.
+	Cost float64 `json:"cost,omitempty" example:"0.0042"`
 }
 
 type TokenUsage struct {
@@ -0,0 +1,119 @@
+package taskengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/yalp/jsonpath"
+)
+
+// jsonExtract runs a json_extract task: it evaluates the task's JSONPath
+// expression against the input and emits the matched value. The input may be
+// DataTypeJSON in any form the engine carries it — raw bytes, a JSON string,
+// or an already-decoded map or slice — or a DataTypeString holding JSON, such
+// as a model reply, with code fences stripped.
+//
+// The output type follows the value: a string is DataTypeString, a whole
+// number DataTypeInt, and anything else — objects, arrays, booleans and
+// fractional numbers, for which the engine has no narrower type — DataTypeJSON.
+// A JSON null is DataTypeNil. The transition eval is the value as text, so a
+// chain can branch on an extracted field directly.
+func jsonExtract(currentTask *TaskDefinition, input any, dataType DataType) (any, DataType, string, error) {
+	cfg := currentTask.Extract
+	if cfg == nil || cfg.Path == "" {
+		return nil, DataTypeAny, "", fmt.Errorf("json_extract task %s: extract.path is required", currentTask.ID)
+	}
+	filter, err := jsonpath.Prepare(cfg.Path)
+	if err != nil {
+		return nil, DataTypeAny, "", fmt.Errorf("json_extract task %s: invalid path %q: %w", currentTask.ID, cfg.Path, err)
+	}
+	doc, err := decodeJSONInput(input, dataType)
+	if err != nil {
+		return nil, DataTypeAny, "", fmt.Errorf("json_extract task %s: %w", currentTask.ID, err)
+	}
+
+	value, err := filter(doc)
+	if err != nil {
+		if !cfg.UseDefault {
+			return nil, DataTypeAny, "", fmt.Errorf("json_extract task %s: path %q: %w", currentTask.ID, cfg.Path, err)
+		}
+		value = cfg.Default
+	}
+	out, outType := typeExtractedValue(value)
+	eval, err := extractedText(out)
+	if err != nil {
+		return nil, DataTypeAny, "", fmt.Errorf("json_extract task %s: %w", currentTask.ID, err)
+	}
+	return out, outType, eval, nil
+}
+
+// decodeJSONInput turns a task input into the generic form (map[string]any,
+// []any, string, float64, bool, nil) JSONPath evaluates against.
+func decodeJSONInput(input any, dataType DataType) (any, error) {
+	var raw []byte
+	switch v := input.(type) {
+	case []byte:
+		raw = v
+	case json.RawMessage:
+		raw = v
+	case string:
+		if dataType != DataTypeJSON && dataType != DataTypeString && dataType != DataTypeAny {
+			return nil, fmt.Errorf("input must be JSON, got %s", dataType.String())
+		}
+		raw = []byte(stripCodeFences(v))
+	case ChatHistory:
+		return nil, fmt.Errorf("input must be JSON, got a chat history")
+	default:
+		// Decoded values — maps, slices, structs — are normalised through a
+		// JSON round trip so typed Go values match the same paths their JSON
+		// form would.
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("input is not JSON-encodable: %w", err)
+		}
+		raw = b
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("input is not valid JSON: %w", err)
+	}
+	return doc, nil
+}
+
+func typeExtractedValue(v any) (any, DataType) {
+	switch t := v.(type) {
+	case nil:
+		return nil, DataTypeNil
+	case string:
+		return t, DataTypeString
+	case int:
+		return t, DataTypeInt
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) <= 1<<53 {
+			return int(t), DataTypeInt
+		}
+		return t, DataTypeJSON
+	default:
+		return t, DataTypeJSON
+	}
+}
+
+func extractedText(v any) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case int:
+		return strconv.Itoa(t), nil
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return "", fmt.Errorf("extracted value is not JSON-encodable: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+}
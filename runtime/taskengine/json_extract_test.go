@@ -0,0 +1,95 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func runExtract(t *testing.T, cfg *taskengine.JSONExtractConfig, input any, dt taskengine.DataType) (any, taskengine.DataType, string, error) {
+	t.Helper()
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	task := &taskengine.TaskDefinition{ID: "pick", Handler: taskengine.HandleJSONExtract, Extract: cfg}
+	return exec.TaskExec(context.Background(), time.Now().UTC(), 0, &taskengine.ChainContext{}, task, input, dt)
+}
+
+func TestUnit_JSONExtract_TypesTheExtractedValue(t *testing.T) {
+	doc := map[string]any{"result": map[string]any{"status": "ok", "score": 0.5, "items": []any{"a", "b"}}}
+
+	out, dt, eval, err := runExtract(t, &taskengine.JSONExtractConfig{Path: "$.result.status"}, doc, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	require.Equal(t, "ok", out)
+	require.Equal(t, taskengine.DataTypeString, dt)
+	require.Equal(t, "ok", eval, "the eval is the value, so chains can branch on it")
+
+	out, dt, _, err = runExtract(t, &taskengine.JSONExtractConfig{Path: "$.count"}, []byte(`{"count": 3}`), taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	require.Equal(t, 3, out)
+	require.Equal(t, taskengine.DataTypeInt, dt)
+
+	out, dt, eval, err = runExtract(t, &taskengine.JSONExtractConfig{Path: "$.result.score"}, doc, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	require.Equal(t, 0.5, out)
+	require.Equal(t, taskengine.DataTypeJSON, dt)
+	require.Equal(t, "0.5", eval)
+
+	out, dt, eval, err = runExtract(t, &taskengine.JSONExtractConfig{Path: "$.result.items"}, doc, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	require.Equal(t, []any{"a", "b"}, out)
+	require.Equal(t, taskengine.DataTypeJSON, dt)
+	require.Equal(t, `["a","b"]`, eval)
+}
+
+func TestUnit_JSONExtract_ReadsFencedModelReply(t *testing.T) {
+	reply := "```json\n{\"answer\": {\"city\": \"Paris\"}}\n```"
+	out, dt, _, err := runExtract(t, &taskengine.JSONExtractConfig{Path: "$.answer.city"}, reply, taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, "Paris", out)
+	require.Equal(t, taskengine.DataTypeString, dt)
+
+	_, _, _, err = runExtract(t, &taskengine.JSONExtractConfig{Path: "$.answer"}, "not json", taskengine.DataTypeString)
+	require.ErrorContains(t, err, "not valid JSON")
+}
+
+func TestUnit_JSONExtract_MissingPathErrorsOrDefaults(t *testing.T) {
+	doc := map[string]any{"result": map[string]any{}}
+
+	_, _, _, err := runExtract(t, &taskengine.JSONExtractConfig{Path: "$.result.status"}, doc, taskengine.DataTypeJSON)
+	require.ErrorContains(t, err, "not found")
+
+	out, dt, eval, err := runExtract(t, &taskengine.JSONExtractConfig{Path: "$.result.status", UseDefault: true, Default: "unknown"}, doc, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	require.Equal(t, "unknown", out)
+	require.Equal(t, taskengine.DataTypeString, dt)
+	require.Equal(t, "unknown", eval)
+}
+
+func TestUnit_JSONExtract_ChainValidationRequiresValidPath(t *testing.T) {
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env, err := taskengine.NewEnv(context.Background(), libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+
+	for _, cfg := range []*taskengine.JSONExtractConfig{nil, {Path: "result.status"}} {
+		chain := &taskengine.TaskChainDefinition{
+			ID: "extract",
+			Tasks: []taskengine.TaskDefinition{{
+				ID:      "pick",
+				Handler: taskengine.HandleJSONExtract,
+				Extract: cfg,
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+				},
+			}},
+		}
+		_, _, _, err = env.ExecEnv(context.Background(), chain, map[string]any{}, taskengine.DataTypeJSON)
+		require.ErrorIs(t, err, errdefs.ErrBadRequest)
+	}
+}
@@ -31,7 +31,8 @@ const (
 	// ClassRateLimit is HTTP 429 / 529 (Anthropic overload). Retried with a
 	// longer floor (RateLimitMinWait).
 	ClassRateLimit ErrorClass = "rate_limit"
-	// ClassServerError is HTTP 5xx. Retried with normal backoff.
+	// ClassServerError is HTTP 5xx or a backend that refused or dropped the
+	// connection. Retried with normal backoff.
 	ClassServerError ErrorClass = "server_error"
 	// ClassTimeout is context.DeadlineExceeded or i/o timeout. Retried.
 	ClassTimeout ErrorClass = "timeout"
@@ -78,7 +79,8 @@ func ClassifyError(err error) ErrorClass {
 	case containsAny(s, "context length", "context window", "maximum context", "exceeds context", "tokens exceed", "token count "):
 		return ClassCapacity
 	case containsAny(s, "500", "502", "503", "504", "internal server error", "bad gateway", "service unavailable", "gateway timeout",
-		"empty content from model", "empty content from vertex", "empty candidate parts"):
+		"empty content from model", "empty content from vertex", "empty candidate parts",
+		"connection refused", "connection reset", "no such host"):
 		return ClassServerError
 	case containsAny(s, "i/o timeout", "deadline exceeded", "timed out"):
 		return ClassTimeout
@@ -23,6 +23,7 @@ func TestUnit_ClassifyError(t *testing.T) {
 		{"openai 429", fmt.Errorf("OpenAI API returned non-200 status: 429, body: rate limited for model gpt-4"), llmretry.ClassRateLimit},
 		{"anthropic 529", fmt.Errorf("anthropic 529 overloaded"), llmretry.ClassRateLimit},
 		{"openai 503", fmt.Errorf("OpenAI API returned non-200 status: 503 service unavailable"), llmretry.ClassServerError},
+		{"connection refused", fmt.Errorf("Post \"http://127.0.0.1:11434/api/chat\": dial tcp 127.0.0.1:11434: connect: connection refused"), llmretry.ClassServerError},
 		{"401 unauthorized", fmt.Errorf("status: 401 unauthorized"), llmretry.ClassAuth},
 		{"invalid api key", fmt.Errorf("invalid api key supplied"), llmretry.ClassAuth},
 		{"capacity exceeded", fmt.Errorf("input token count 200000 exceeds context length 128000"), llmretry.ClassCapacity},
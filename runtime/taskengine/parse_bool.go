@@ -0,0 +1,84 @@
+package taskengine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// boolAnswers are the answers parse_bool accepts as the first word of a
+// reply, compared case-insensitively.
+var boolAnswers = map[string]bool{
+	"yes": true, "y": true, "true": true, "1": true,
+	"no": false, "n": false, "false": false, "0": false,
+}
+
+// contradictingWords are the answers that make a reply ambiguous when they
+// appear after a first word of the opposite meaning, as in "yes, or rather
+// no". Single letters and digits are left out: they occur in ordinary prose.
+var contradictingWords = map[string]bool{
+	"yes": true, "true": true,
+	"no": false, "false": false,
+}
+
+// parseBool runs a parse_bool task: it reads a yes/no model reply as a
+// boolean. The reply may be a string or the last message of a chat history;
+// input that is already a bool passes through. The first word decides —
+// yes/y/true/1 or no/n/false/0, in any case, so "Yes, it is urgent." is true
+// — and a reply whose first word is none of those, or that later says the
+// opposite, fails the task instead of guessing.
+//
+// The output is DataTypeBool and the transition eval is "true" or "false".
+func parseBool(currentTask *TaskDefinition, input any, dataType DataType) (any, DataType, string, error) {
+	var reply string
+	switch v := input.(type) {
+	case bool:
+		return v, DataTypeBool, strconv.FormatBool(v), nil
+	case string:
+		reply = v
+	case int:
+		reply = strconv.Itoa(v)
+	case ChatHistory:
+		if len(v.Messages) == 0 {
+			return nil, DataTypeAny, "", fmt.Errorf("parse_bool task %s: chat history is empty", currentTask.ID)
+		}
+		reply = v.Messages[len(v.Messages)-1].Content
+	default:
+		return nil, DataTypeAny, "", fmt.Errorf("parse_bool task %s: unsupported input type %s", currentTask.ID, dataType.String())
+	}
+	answer, err := parseBoolReply(reply)
+	if err != nil {
+		return nil, DataTypeAny, "", fmt.Errorf("parse_bool task %s: %w", currentTask.ID, err)
+	}
+	return answer, DataTypeBool, strconv.FormatBool(answer), nil
+}
+
+// parseBoolReply interprets reply as described on parseBool.
+func parseBoolReply(reply string) (bool, error) {
+	words := strings.FieldsFunc(strings.ToLower(stripCodeFences(reply)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return false, fmt.Errorf("response is empty, want yes or no")
+	}
+	answer, ok := boolAnswers[words[0]]
+	if !ok {
+		return false, fmt.Errorf("response %q is not a yes/no answer", truncateReply(reply))
+	}
+	for _, w := range words[1:] {
+		if other, ok := contradictingWords[w]; ok && other != answer {
+			return false, fmt.Errorf("response %q is ambiguous: it says both %q and %q", truncateReply(reply), words[0], w)
+		}
+	}
+	return answer, nil
+}
+
+// truncateReply shortens a reply quoted in an error message.
+func truncateReply(reply string) string {
+	reply = strings.TrimSpace(reply)
+	if r := []rune(reply); len(r) > 80 {
+		return string(r[:80]) + "..."
+	}
+	return reply
+}
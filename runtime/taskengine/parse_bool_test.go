@@ -0,0 +1,53 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func runParseBool(t *testing.T, input any, dt taskengine.DataType) (any, taskengine.DataType, string, error) {
+	t.Helper()
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	task := &taskengine.TaskDefinition{ID: "is_urgent", Handler: taskengine.HandleParseBool}
+	return exec.TaskExec(context.Background(), time.Now().UTC(), 0, &taskengine.ChainContext{}, task, input, dt)
+}
+
+func TestUnit_ParseBool_ReadsCommonAnswers(t *testing.T) {
+	for reply, want := range map[string]bool{
+		"yes": true, "YES": true, " True\n": true, "1": true, "y": true, "Yes, it is urgent.": true, "**Yes**": true,
+		"no": false, "No.": false, "FALSE": false, "0": false, "n": false, "No, it can wait.": false,
+	} {
+		out, dt, eval, err := runParseBool(t, reply, taskengine.DataTypeString)
+		require.NoError(t, err, reply)
+		require.Equal(t, want, out, reply)
+		require.Equal(t, taskengine.DataTypeBool, dt)
+		require.Equal(t, map[bool]string{true: "true", false: "false"}[want], eval, reply)
+	}
+
+	hist := taskengine.ChatHistory{Messages: []taskengine.Message{
+		{Role: "user", Content: "Is this ticket urgent?"},
+		{Role: "assistant", Content: "No"},
+	}}
+	out, _, eval, err := runParseBool(t, hist, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	require.Equal(t, false, out)
+	require.Equal(t, "false", eval)
+}
+
+func TestUnit_ParseBool_RejectsAmbiguousReplies(t *testing.T) {
+	_, _, _, err := runParseBool(t, "maybe", taskengine.DataTypeString)
+	require.ErrorContains(t, err, "not a yes/no answer")
+
+	_, _, _, err = runParseBool(t, "Yes, or rather no.", taskengine.DataTypeString)
+	require.ErrorContains(t, err, "ambiguous")
+
+	_, _, _, err = runParseBool(t, "  ", taskengine.DataTypeString)
+	require.ErrorContains(t, err, "empty")
+}
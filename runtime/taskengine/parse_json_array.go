@@ -0,0 +1,80 @@
+package taskengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// parseJSONArray runs a parse_json_array task: it turns a model reply that
+// should hold a JSON array into that array, so later tasks can iterate over
+// it. The reply may be a string or the last message of a chat history, and
+// may wrap the array in code fences or a sentence of preamble (see
+// ExtractJSONArray). Input that is already JSON must itself be an array.
+//
+// The output is the array as DataTypeJSON and the transition eval is its
+// element count, so a chain can branch on "0" for an empty result.
+func parseJSONArray(currentTask *TaskDefinition, input any, dataType DataType) (any, DataType, string, error) {
+	var doc any
+	switch v := input.(type) {
+	case string:
+		parsed, err := decodeReply(v)
+		if err != nil {
+			return nil, DataTypeAny, "", fmt.Errorf("parse_json_array task %s: response is not a JSON array: %w", currentTask.ID, err)
+		}
+		doc = parsed
+	case ChatHistory:
+		if len(v.Messages) == 0 {
+			return nil, DataTypeAny, "", fmt.Errorf("parse_json_array task %s: chat history is empty", currentTask.ID)
+		}
+		parsed, err := decodeReply(v.Messages[len(v.Messages)-1].Content)
+		if err != nil {
+			return nil, DataTypeAny, "", fmt.Errorf("parse_json_array task %s: response is not a JSON array: %w", currentTask.ID, err)
+		}
+		doc = parsed
+	default:
+		decoded, err := decodeJSONInput(input, dataType)
+		if err != nil {
+			return nil, DataTypeAny, "", fmt.Errorf("parse_json_array task %s: %w", currentTask.ID, err)
+		}
+		doc = decoded
+	}
+	items, ok := doc.([]any)
+	if !ok {
+		return nil, DataTypeAny, "", fmt.Errorf("parse_json_array task %s: response is not a JSON array, got %s", currentTask.ID, jsonKind(doc))
+	}
+	return items, DataTypeJSON, strconv.Itoa(len(items)), nil
+}
+
+// decodeReply decodes a model reply. A reply that is JSON once its fences are
+// stripped is taken as is, so an object wrapping an array is reported as an
+// object rather than silently unwrapped; only otherwise is the array searched
+// for past any preamble.
+func decodeReply(reply string) (any, error) {
+	var doc any
+	if err := json.Unmarshal([]byte(stripCodeFences(reply)), &doc); err == nil {
+		return doc, nil
+	}
+	if err := json.Unmarshal([]byte(ExtractJSONArray(reply)), &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// jsonKind names the JSON type of a decoded value for error messages.
+func jsonKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
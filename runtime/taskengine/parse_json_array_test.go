@@ -0,0 +1,56 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func runParseArray(t *testing.T, input any, dt taskengine.DataType) (any, taskengine.DataType, string, error) {
+	t.Helper()
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	task := &taskengine.TaskDefinition{ID: "topics", Handler: taskengine.HandleParseJSONArray}
+	return exec.TaskExec(context.Background(), time.Now().UTC(), 0, &taskengine.ChainContext{}, task, input, dt)
+}
+
+func TestUnit_ParseJSONArray_ParsesFencedReply(t *testing.T) {
+	reply := "Here are the topics:\n```json\n[\"go\", \"rust\"]\n```"
+	out, dt, eval, err := runParseArray(t, reply, taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, []any{"go", "rust"}, out)
+	require.Equal(t, taskengine.DataTypeJSON, dt)
+	require.Equal(t, "2", eval, "the eval is the element count")
+
+	_, _, eval, err = runParseArray(t, "[]", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, "0", eval)
+}
+
+func TestUnit_ParseJSONArray_ReadsLastMessageOfHistory(t *testing.T) {
+	hist := taskengine.ChatHistory{Messages: []taskengine.Message{
+		{Role: "user", Content: "list topics"},
+		{Role: "assistant", Content: `[{"name": "go"}]`},
+	}}
+	out, _, eval, err := runParseArray(t, hist, taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	require.Equal(t, []any{map[string]any{"name": "go"}}, out)
+	require.Equal(t, "1", eval)
+
+	out, _, _, err = runParseArray(t, []any{"a"}, taskengine.DataTypeJSON)
+	require.NoError(t, err)
+	require.Equal(t, []any{"a"}, out)
+}
+
+func TestUnit_ParseJSONArray_RejectsNonArrays(t *testing.T) {
+	_, _, _, err := runParseArray(t, `{"topics": ["go"]}`, taskengine.DataTypeString)
+	require.ErrorContains(t, err, "not a JSON array, got an object")
+
+	_, _, _, err = runParseArray(t, "no topics today", taskengine.DataTypeString)
+	require.ErrorContains(t, err, "not a JSON array")
+}
@@ -0,0 +1,143 @@
+package taskengine
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/contenox/runtime/runtime/errdefs"
+)
+
+// newPromptTemplate parses tmplStr with the sprig helpers and the chain's
+// partials registered as named templates, so the body can pull one in with
+// {{template "name" .}}. Partials are parsed before the body; a partial may
+// reference another partial but not the body itself. The prompt function is
+// registered so templates holding prompt library references still parse; see
+// ResolvePromptRefs.
+func newPromptTemplate(tmplStr string, partials map[string]string) (*template.Template, error) {
+	tmpl := template.New("prompt").Funcs(sprig.TxtFuncMap()).Funcs(template.FuncMap{"prompt": unresolvedPrompt})
+	for _, name := range sortedPartialNames(partials) {
+		if _, err := tmpl.New(name).Parse(partials[name]); err != nil {
+			return nil, fmt.Errorf("partial %q: %w", name, err)
+		}
+	}
+	if _, err := tmpl.Parse(tmplStr); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// renderTemplateWithPartials is renderTemplate with the chain's partials
+// available to the template.
+func renderTemplateWithPartials(tmplStr string, partials map[string]string, vars any) (string, error) {
+	tmpl, err := newPromptTemplate(tmplStr, partials)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ValidatePartials checks the chain's named partials and every template that
+// can reference them — each task's PromptTemplate and Print — and reports the
+// first reference to a partial that is not defined. text/template only fails
+// on an undefined {{template}} when it executes, which for a task deep in a
+// chain is long after the chain was accepted; this moves the failure to load
+// time. Templates defined inline with {{define}} count as defined.
+func ValidatePartials(chain *TaskChainDefinition) error {
+	if chain == nil {
+		return nil
+	}
+	for _, name := range sortedPartialNames(chain.Partials) {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("partial name cannot be empty %w", errdefs.ErrBadRequest)
+		}
+	}
+	tmpl, err := newPromptTemplate("", chain.Partials)
+	if err != nil {
+		return fmt.Errorf("%v %w", err, errdefs.ErrBadRequest)
+	}
+	for _, name := range sortedPartialNames(chain.Partials) {
+		if missing := undefinedTemplateRef(tmpl, tmpl.Lookup(name)); missing != "" {
+			return fmt.Errorf("partial %q references undefined partial %q %w", name, missing, errdefs.ErrBadRequest)
+		}
+	}
+	for _, ct := range chain.Tasks {
+		for _, f := range []struct{ field, src string }{
+			{"prompt_template", ct.PromptTemplate},
+			{"print", ct.Print},
+		} {
+			field, src := f.field, f.src
+			if src == "" {
+				continue
+			}
+			// Step macros are not template syntax; expand them the way
+			// execution does before parsing.
+			tmpl, err := newPromptTemplate(expandStepMacros(src, nil), chain.Partials)
+			if err != nil {
+				return fmt.Errorf("task %q: %s: %v %w", ct.ID, field, err, errdefs.ErrBadRequest)
+			}
+			for _, t := range tmpl.Templates() {
+				if missing := undefinedTemplateRef(tmpl, t); missing != "" {
+					return fmt.Errorf("task %q: %s references undefined partial %q %w", ct.ID, field, missing, errdefs.ErrBadRequest)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// undefinedTemplateRef returns the name of the first {{template}} call in t
+// that set cannot resolve, or "" when every reference is defined.
+func undefinedTemplateRef(set, t *template.Template) string {
+	if t == nil || t.Tree == nil {
+		return ""
+	}
+	var missing string
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		if n == nil || missing != "" {
+			return
+		}
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.TemplateNode:
+			if set.Lookup(n.Name) == nil {
+				missing = n.Name
+			}
+		case *parse.IfNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.List)
+			walk(n.ElseList)
+		}
+	}
+	walk(t.Tree.Root)
+	return missing
+}
+
+func sortedPartialNames(partials map[string]string) []string {
+	names := make([]string, 0, len(partials))
+	for name := range partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,76 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func partialsChain(prompt string, partials map[string]string) *taskengine.TaskChainDefinition {
+	return &taskengine.TaskChainDefinition{
+		ID:       "partials",
+		Partials: partials,
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:             "answer",
+				Handler:        taskengine.HandleNoop,
+				PromptTemplate: prompt,
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{
+						{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestUnit_SimpleEnv_ExecEnv_RendersPartials(t *testing.T) {
+	exec := &scriptedExecutor{
+		outputs:     []any{"ok"},
+		outputTypes: []taskengine.DataType{taskengine.DataTypeString},
+	}
+	env, err := taskengine.NewEnv(context.Background(), libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+
+	chain := partialsChain(`Q: {{.input}} {{template "disclaimer" .}}`, map[string]string{
+		"disclaimer": `({{template "brand"}} may be wrong about {{.input}})`,
+		"brand":      "Contenox",
+	})
+	_, _, _, err = env.ExecEnv(libtracker.WithNewRequestID(context.Background()), chain, "tides", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Len(t, exec.calls, 1)
+	require.Equal(t, "Q: tides (Contenox may be wrong about tides)", exec.calls[0].input)
+}
+
+func TestUnit_ValidatePartials(t *testing.T) {
+	cases := []struct {
+		name     string
+		prompt   string
+		partials map[string]string
+		wantErr  string
+	}{
+		{name: "defined", prompt: `{{template "disclaimer"}}`, partials: map[string]string{"disclaimer": "d"}},
+		{name: "inline define", prompt: `{{define "local"}}x{{end}}{{template "local"}}`},
+		{name: "step macro", prompt: `round {{edge_count:answer->answer}} {{template "d"}}`, partials: map[string]string{"d": "d"}},
+		{name: "missing in prompt", prompt: `{{if .input}}{{template "disclaimer"}}{{end}}`, wantErr: `prompt_template references undefined partial "disclaimer"`},
+		{name: "missing in partial", prompt: `{{template "a"}}`, partials: map[string]string{"a": `{{template "b"}}`}, wantErr: `partial "a" references undefined partial "b"`},
+		{name: "bad partial syntax", partials: map[string]string{"a": `{{.x`}, wantErr: `partial "a"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := taskengine.ValidatePartials(partialsChain(tc.prompt, tc.partials))
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errdefs.ErrBadRequest)
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
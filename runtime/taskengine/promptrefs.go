@@ -0,0 +1,110 @@
+package taskengine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/contenox/runtime/runtime/errdefs"
+)
+
+// promptRefRe matches a prompt library reference, {{prompt "name"}} or
+// {{prompt "name@3"}} for a pinned version, with optional trim markers.
+var promptRefRe = regexp.MustCompile(`\{\{-?\s*prompt\s+"([^"]+)"\s*-?\}\}`)
+
+// PromptRef is one {{prompt "..."}} reference in a task's PromptTemplate.
+// Version 0 means the latest version.
+type PromptRef struct {
+	Name    string
+	Version int
+}
+
+func (r PromptRef) String() string {
+	if r.Version == 0 {
+		return r.Name
+	}
+	return fmt.Sprintf("%s@%d", r.Name, r.Version)
+}
+
+// ParsePromptRef parses the quoted argument of {{prompt "..."}}: a prompt
+// name, optionally followed by @version.
+func ParsePromptRef(raw string) (PromptRef, error) {
+	ref := PromptRef{Name: strings.TrimSpace(raw)}
+	if i := strings.LastIndex(ref.Name, "@"); i >= 0 {
+		v, err := strconv.Atoi(ref.Name[i+1:])
+		if err != nil || v < 1 {
+			return PromptRef{}, fmt.Errorf("prompt reference %q: version must be a positive integer %w", raw, errdefs.ErrBadRequest)
+		}
+		ref.Name, ref.Version = strings.TrimSpace(ref.Name[:i]), v
+	}
+	if ref.Name == "" {
+		return PromptRef{}, fmt.Errorf("prompt reference %q: name is required %w", raw, errdefs.ErrBadRequest)
+	}
+	return ref, nil
+}
+
+// PromptRefs returns the distinct prompt library references in the chain's
+// task PromptTemplates, sorted by name and version.
+func PromptRefs(chain *TaskChainDefinition) ([]PromptRef, error) {
+	if chain == nil {
+		return nil, nil
+	}
+	seen := map[PromptRef]struct{}{}
+	refs := []PromptRef{}
+	for _, ct := range chain.Tasks {
+		for _, m := range promptRefRe.FindAllStringSubmatch(ct.PromptTemplate, -1) {
+			ref, err := ParsePromptRef(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("task %q: %w", ct.ID, err)
+			}
+			if _, ok := seen[ref]; ok {
+				continue
+			}
+			seen[ref] = struct{}{}
+			refs = append(refs, ref)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].Version < refs[j].Version
+	})
+	return refs, nil
+}
+
+// ResolvePromptRefs replaces every {{prompt "..."}} reference in the chain's
+// task PromptTemplates with the template text lookup returns for it. The
+// inserted text is template source, rendered with the task's variables like
+// the rest of the PromptTemplate. A reference lookup fails on leaves the
+// chain unchanged.
+func ResolvePromptRefs(chain *TaskChainDefinition, lookup func(PromptRef) (string, error)) error {
+	refs, err := PromptRefs(chain)
+	if err != nil || len(refs) == 0 {
+		return err
+	}
+	texts := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		text, err := lookup(ref)
+		if err != nil {
+			return fmt.Errorf("prompt %q: %w", ref, err)
+		}
+		texts[ref.String()] = text
+	}
+	for i := range chain.Tasks {
+		chain.Tasks[i].PromptTemplate = promptRefRe.ReplaceAllStringFunc(chain.Tasks[i].PromptTemplate, func(m string) string {
+			ref, _ := ParsePromptRef(promptRefRe.FindStringSubmatch(m)[1])
+			return texts[ref.String()]
+		})
+	}
+	return nil
+}
+
+// unresolvedPrompt backs the prompt template function. References are
+// replaced before a chain runs, so one that reaches rendering was loaded
+// without the prompt library.
+func unresolvedPrompt(name string) (string, error) {
+	return "", fmt.Errorf("prompt %q was not resolved: the chain was loaded without the prompt library", name)
+}
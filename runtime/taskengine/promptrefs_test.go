@@ -0,0 +1,46 @@
+package taskengine_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_PromptRefs_ResolveReplacesReferences(t *testing.T) {
+	chain := partialsChain(`{{- prompt "triage" -}} Ticket: {{.input}} {{prompt "footer@2"}}`, nil)
+
+	refs, err := taskengine.PromptRefs(chain)
+	require.NoError(t, err)
+	require.Equal(t, []taskengine.PromptRef{{Name: "footer", Version: 2}, {Name: "triage"}}, refs)
+
+	err = taskengine.ResolvePromptRefs(chain, func(ref taskengine.PromptRef) (string, error) {
+		return map[string]string{"triage": "Classify this.", "footer@2": "Be brief."}[ref.String()], nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Classify this. Ticket: {{.input}} Be brief.", chain.Tasks[0].PromptTemplate)
+	require.NoError(t, taskengine.ValidatePartials(chain))
+}
+
+func TestUnit_PromptRefs_FailedLookupLeavesChainUnchanged(t *testing.T) {
+	prompt := `{{prompt "a"}} {{prompt "b"}}`
+	chain := partialsChain(prompt, nil)
+
+	err := taskengine.ResolvePromptRefs(chain, func(ref taskengine.PromptRef) (string, error) {
+		if ref.Name == "b" {
+			return "", errors.New("not found")
+		}
+		return "A", nil
+	})
+	require.ErrorContains(t, err, `prompt "b"`)
+	require.Equal(t, prompt, chain.Tasks[0].PromptTemplate)
+}
+
+func TestUnit_PromptRefs_UnresolvedReferencesStillValidate(t *testing.T) {
+	require.NoError(t, taskengine.ValidatePartials(partialsChain(`{{prompt "triage"}} {{.input}}`, nil)))
+
+	_, err := taskengine.PromptRefs(partialsChain(`{{prompt "triage@latest"}}`, nil))
+	require.ErrorIs(t, err, errdefs.ErrBadRequest)
+}
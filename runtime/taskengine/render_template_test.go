@@ -0,0 +1,71 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/errdefs"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+func newRenderEnv(t *testing.T) taskengine.EnvExecutor {
+	t.Helper()
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env, err := taskengine.NewEnv(context.Background(), libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+	return env
+}
+
+func TestUnit_RenderTemplate_EmitsRenderedTextAsOutput(t *testing.T) {
+	env := newRenderEnv(t)
+	chain := &taskengine.TaskChainDefinition{
+		ID: "render",
+		Tasks: []taskengine.TaskDefinition{
+			{
+				ID:             "greet",
+				Handler:        taskengine.HandleRenderTemplate,
+				PromptTemplate: "Hello {{.input}}",
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{
+						{Operator: taskengine.OpEquals, When: "Hello world", Goto: "shout"},
+						{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd},
+					},
+				},
+			},
+			{
+				ID:             "shout",
+				Handler:        taskengine.HandleRenderTemplate,
+				PromptTemplate: "{{upper .greet}}!",
+				Transition: taskengine.TaskTransition{
+					Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+				},
+			},
+		},
+	}
+	out, dt, steps, err := env.ExecEnv(libtracker.WithNewRequestID(context.Background()), chain, "world", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, "HELLO WORLD!", out)
+	require.Equal(t, taskengine.DataTypeString, dt)
+	require.Len(t, steps, 2)
+	require.Equal(t, "Hello world", steps[0].Transition)
+}
+
+func TestUnit_RenderTemplate_RequiresPromptTemplate(t *testing.T) {
+	env := newRenderEnv(t)
+	chain := &taskengine.TaskChainDefinition{
+		ID: "render",
+		Tasks: []taskengine.TaskDefinition{{
+			ID:      "greet",
+			Handler: taskengine.HandleRenderTemplate,
+			Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+			},
+		}},
+	}
+	_, _, _, err := env.ExecEnv(context.Background(), chain, "world", taskengine.DataTypeString)
+	require.ErrorIs(t, err, errdefs.ErrBadRequest)
+}
@@ -1,20 +1,17 @@
 package taskengine
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
-	"github.com/Masterminds/sprig/v3"
-
 	"github.com/contenox/runtime/libtracker"
 	"github.com/contenox/runtime/runtime/errdefs"
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/yalp/jsonpath"
 )
 
 // DataType represents the type of data passed between tasks.
@@ -27,6 +24,8 @@ const (
 	DataTypeJSON
 	DataTypeChatHistory
 	DataTypeNil
+	// DataTypeBool carries a Go bool, produced by parse_bool.
+	DataTypeBool
 )
 
 // String returns the string representation of the data type.
@@ -44,6 +43,8 @@ func (d *DataType) String() string {
 		return "chat_history"
 	case DataTypeNil:
 		return "nil"
+	case DataTypeBool:
+		return "bool"
 	default:
 		return "unknown"
 	}
@@ -64,6 +65,8 @@ func DataTypeFromString(s string) (DataType, error) {
 		return DataTypeChatHistory, nil
 	case "nil":
 		return DataTypeNil, nil
+	case "bool":
+		return DataTypeBool, nil
 	default:
 		return DataTypeAny, fmt.Errorf("unknown data type: %s", s)
 	}
@@ -177,6 +180,16 @@ type ToolWithResolution struct {
 func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, input any, dataType DataType) (result any, resultType DataType, history []CapturedStateUnit, retErr error) {
 	_, reportChangeChain, endChain := env.tracker.Start(ctx, "chain_exec", chain.ID, "chain_id", chain.ID)
 	defer endChain()
+	ctx, chainSpan := startChainSpan(ctx, chain)
+	defer func() { endSpan(chainSpan, retErr) }()
+
+	prices := PriceTableFromContext(ctx)
+	var chainCost float64
+	defer func() {
+		if chainCost > 0 {
+			reportChangeChain("chain_cost", map[string]any{"cost": chainCost})
+		}
+	}()
 
 	stack := env.inspector.Start(ctx)
 
@@ -205,6 +218,9 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 	if err := validateChain(chain.Tasks); err != nil {
 		return nil, DataTypeAny, stack.GetExecutionHistory(), err
 	}
+	if err := ValidatePartials(chain); err != nil {
+		return nil, DataTypeAny, stack.GetExecutionHistory(), err
+	}
 
 	currentTask, err := findTaskByID(chain.Tasks, chain.Tasks[0].ID)
 	if err != nil {
@@ -225,7 +241,7 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 
 	chainContext := &ChainContext{
 		Tools:       map[string]ToolWithResolution{},
-		ClientTools: []Tool{},
+		ClientTools: append([]Tool{}, ClientToolsFromContext(ctx)...),
 		Debug:       chain.Debug,
 	}
 	filter := map[string]ToolWithResolution{}
@@ -300,7 +316,7 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 
 			taskInput, ok = vars[inputVar]
 			if !ok {
-				return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: input variable %q not found", currentTask.ID, currentTask.InputVar)
+				return nil, DataTypeAny, stack.GetExecutionHistory(), withCategory(ErrValidation, fmt.Errorf("task %s: input variable %q not found", currentTask.ID, currentTask.InputVar))
 			}
 			taskInputType, ok = varTypes[inputVar]
 			if !ok {
@@ -310,9 +326,9 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 
 		// Render prompt template if exists
 		if currentTask.PromptTemplate != "" {
-			rendered, err := renderTemplate(expandStepMacros(currentTask.PromptTemplate, edgeCounts), vars)
+			rendered, err := renderTemplateWithPartials(expandStepMacros(currentTask.PromptTemplate, edgeCounts), chain.Partials, vars)
 			if err != nil {
-				return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: template error: %v", currentTask.ID, err)
+				return nil, DataTypeAny, stack.GetExecutionHistory(), withCategory(ErrValidation, fmt.Errorf("task %s: template error: %v", currentTask.ID, err))
 			}
 			taskInput = rendered
 			taskInputType = DataTypeString
@@ -329,10 +345,13 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 			if currentTask.Timeout != "" {
 				timeout, err := time.ParseDuration(currentTask.Timeout)
 				if err != nil {
-					return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: invalid timeout: %v", currentTask.ID, err)
+					return nil, DataTypeAny, stack.GetExecutionHistory(), withCategory(ErrValidation, fmt.Errorf("task %s: invalid timeout: %v", currentTask.ID, err))
 				}
 				taskCtx, cancel = context.WithTimeout(taskCtx, timeout)
 			}
+			if currentTask.Idempotent {
+				taskCtx = WithIdempotentToolCalls(taskCtx)
+			}
 			taskCtx = WithTaskEventScope(taskCtx, TaskEventScope{
 				ChainID:     chain.ID,
 				TaskID:      currentTask.ID,
@@ -356,7 +375,9 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 			stepTask.PromptTemplate = expandStepMacros(currentTask.PromptTemplate, edgeCounts)
 			stepTask.OutputTemplate = expandStepMacros(currentTask.OutputTemplate, edgeCounts)
 			stepTask.Print = expandStepMacros(currentTask.Print, edgeCounts)
+			stepTask.ExecuteConfig = withProviderPreference(currentTask.ExecuteConfig, chain.ProviderPreference)
 			taskCtx = WithEdgeCounts(taskCtx, edgeCounts)
+			taskCtx, taskSpan := startTaskSpan(taskCtx, chain.ID, currentTask, retry)
 
 			// Use the chain's TokenLimit as the base context window budget.
 			// If a per-request context length was attached (e.g. from PromptRequest.ContextLength
@@ -410,18 +431,46 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 				step.ProviderType = currentTask.ExecuteConfig.Provider
 				step.ModelName = GetPrimaryModel(currentTask.ExecuteConfig)
 			}
-			if currentTask.Handler == HandleExecuteToolCalls {
+			if currentTask.Handler == HandleExecuteToolCalls || currentTask.Handler == HandleToolLoop {
 				if names := extractToolNamesFromOutput(output, outputType); len(names) > 0 {
 					step.ToolNames = names
 				}
 			}
-			if hist, ok := output.(ChatHistory); ok && (hist.InputTokens > 0 || hist.OutputTokens > 0) {
+			if currentTask.Handler == HandleTruncateHistory && taskErr == nil {
+				in, inOK := taskInput.(ChatHistory)
+				out, outOK := output.(ChatHistory)
+				if inOK && outOK {
+					step.DroppedMessages = len(in.Messages) - len(out.Messages)
+				}
+			}
+			// A model handler's own usage includes the prompt and, for
+			// tool_loop, every turn.
+			modelCall := currentTask.Handler == HandleChatCompletion || currentTask.Handler == HandleToolLoop
+			if hist, ok := output.(ChatHistory); ok && (hist.InputTokens > 0 || hist.OutputTokens > 0 || (modelCall && hist.Usage != nil)) {
 				step.TokenUsage = &TokenUsage{
 					Prompt:     hist.InputTokens,
 					Completion: hist.OutputTokens,
 					Total:      hist.InputTokens + hist.OutputTokens,
 				}
+				if modelCall && hist.Usage != nil {
+					usage := *hist.Usage
+					step.TokenUsage = &usage
+				}
+				// Only handlers that called a model are priced: other handlers
+				// pass a history on with the token counts of the call that
+				// produced it, which would be charged twice.
+				if modelCall {
+					model := step.ModelName
+					if hist.Model != "" {
+						model = hist.Model
+					}
+					if cost, ok := prices.Cost(model, *step.TokenUsage); ok {
+						step.Cost = cost
+						chainCost += cost
+					}
+				}
 			}
+			endTaskSpan(taskSpan, step)
 			stack.RecordStep(step)
 
 			stepEvent := NewTaskEvent(taskCtx, TaskEventStepCompleted)
@@ -495,7 +544,7 @@ func (env SimpleEnv) ExecEnv(ctx context.Context, chain *TaskChainDefinition, in
 
 		// Handle print statement
 		if currentTask.Print != "" {
-			printMsg, err := renderTemplate(expandStepMacros(currentTask.Print, edgeCounts), vars)
+			printMsg, err := renderTemplateWithPartials(expandStepMacros(currentTask.Print, edgeCounts), chain.Partials, vars)
 			if err != nil {
 				return nil, DataTypeAny, stack.GetExecutionHistory(), fmt.Errorf("task %s: print template error: %v", currentTask.ID, err)
 			}
@@ -561,15 +610,7 @@ func renderTemplate(tmplStr string, vars any) (string, error) {
 	// legitimately reference not-yet-populated keys (e.g. a task's Print that
 	// references its own id, which is stored after Print renders), so erroring
 	// would break valid chains. Authors must reference only already-set vars.
-	tmpl, err := template.New("prompt").Funcs(sprig.TxtFuncMap()).Parse(tmplStr)
-	if err != nil {
-		return "", err
-	}
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, vars); err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return renderTemplateWithPartials(tmplStr, nil, vars)
 }
 
 func (exe SimpleEnv) evaluateTransitions(_ context.Context, _ string, transition TaskTransition, eval string, edgeCounts map[string]int) (string, *TransitionBranch, error) {
@@ -648,12 +689,27 @@ func findTaskByID(tasks []TaskDefinition, id string) (*TaskDefinition, error) {
 
 func isKnownHandler(h TaskHandler) bool {
 	switch h {
-	case HandleRaiseError, HandleRoute, HandleChatCompletion, HandleExecuteToolCalls, HandleNoop, HandleTools:
+	case HandleRaiseError, HandleRoute, HandleChatCompletion, HandleExecuteToolCalls, HandleNoop, HandleTools, HandleToolLoop, HandleTruncateHistory, HandleJSONExtract, HandleRenderTemplate, HandleParseJSONArray, HandleParseBool, HandleDetectLanguage:
 		return true
 	}
 	return false
 }
 
+// ValidateChain runs the checks ExecEnv applies to a chain before it executes
+// the first task — task IDs, handlers, transitions and partials — without
+// running anything. Tools that save chains use it as a dry run, so a chain
+// that could never start is refused when it is written rather than when it
+// is first executed.
+func ValidateChain(chain *TaskChainDefinition) error {
+	if chain == nil {
+		return fmt.Errorf("task chain is required %w", errdefs.ErrBadRequest)
+	}
+	if err := validateChain(chain.Tasks); err != nil {
+		return err
+	}
+	return ValidatePartials(chain)
+}
+
 func validateChain(tasks []TaskDefinition) error {
 	if len(tasks) == 0 {
 		return fmt.Errorf("chain has no tasks %w", errdefs.ErrBadRequest)
@@ -681,6 +737,17 @@ func validateChain(tasks []TaskDefinition) error {
 		if ct.Handler == HandleTools && (ct.Tools == nil || ct.Tools.Name == "") {
 			return fmt.Errorf("task %q: 'tools' handler requires a tools block with a name %w", ct.ID, errdefs.ErrBadRequest)
 		}
+		if ct.Handler == HandleRenderTemplate && ct.PromptTemplate == "" {
+			return fmt.Errorf("task %q: 'render_template' handler requires a prompt_template %w", ct.ID, errdefs.ErrBadRequest)
+		}
+		if ct.Handler == HandleJSONExtract {
+			if ct.Extract == nil || ct.Extract.Path == "" {
+				return fmt.Errorf("task %q: 'json_extract' handler requires an extract block with a path %w", ct.ID, errdefs.ErrBadRequest)
+			}
+			if _, err := jsonpath.Prepare(ct.Extract.Path); err != nil {
+				return fmt.Errorf("task %q: invalid extract path %q: %v %w", ct.ID, ct.Extract.Path, err, errdefs.ErrBadRequest)
+			}
+		}
 		// on_failure must reference a real task ('end' is not resolvable at runtime).
 		if ct.Transition.OnFailure != "" {
 			if _, ok := taskIDs[ct.Transition.OnFailure]; !ok {
@@ -755,3 +822,19 @@ func extractToolNamesFromOutput(output any, outputType DataType) []string {
 	}
 	return nil
 }
+
+// withProviderPreference returns the execute config a task runs with under
+// the chain's provider preference: cfg itself when the task names a provider
+// or the chain has no preference, otherwise a copy (a new config when cfg is
+// nil) whose Providers are the preference.
+func withProviderPreference(cfg *LLMExecutionConfig, preference []string) *LLMExecutionConfig {
+	if len(preference) == 0 || (cfg != nil && (cfg.Provider != "" || len(cfg.Providers) > 0)) {
+		return cfg
+	}
+	merged := LLMExecutionConfig{}
+	if cfg != nil {
+		merged = *cfg
+	}
+	merged.Providers = append([]string(nil), preference...)
+	return &merged
+}
@@ -574,3 +574,52 @@ func TestUnit_SimpleEnv_ExecEnv_InputVar_DefaultBehavior(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "second", result)
 }
+
+type configRecorder struct {
+	configs map[string]*taskengine.LLMExecutionConfig
+}
+
+func (r *configRecorder) TaskExec(
+	_ context.Context,
+	_ time.Time,
+	_ int,
+	_ *taskengine.ChainContext,
+	currentTask *taskengine.TaskDefinition,
+	input any,
+	dataType taskengine.DataType,
+) (any, taskengine.DataType, string, error) {
+	r.configs[currentTask.ID] = currentTask.ExecuteConfig
+	return input, dataType, "", nil
+}
+
+func TestUnit_SimpleEnv_ExecEnv_ChainProviderPreference(t *testing.T) {
+	recorder := &configRecorder{configs: map[string]*taskengine.LLMExecutionConfig{}}
+	env, err := taskengine.NewEnv(context.Background(), libtracker.NoopTracker{}, recorder, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+
+	next := func(goTo string) taskengine.TaskTransition {
+		return taskengine.TaskTransition{Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: goTo}}}
+	}
+	chain := &taskengine.TaskChainDefinition{
+		ProviderPreference: []string{"ollama", "openai"},
+		Tasks: []taskengine.TaskDefinition{
+			{ID: "no_config", Handler: taskengine.HandleNoop, Transition: next("model_only")},
+			{ID: "model_only", Handler: taskengine.HandleNoop, ExecuteConfig: &taskengine.LLMExecutionConfig{Model: "qwen2.5:7b"}, Transition: next("own_provider")},
+			{ID: "own_provider", Handler: taskengine.HandleNoop, ExecuteConfig: &taskengine.LLMExecutionConfig{Provider: "gemini"}, Transition: next("own_providers")},
+			{ID: "own_providers", Handler: taskengine.HandleNoop, ExecuteConfig: &taskengine.LLMExecutionConfig{Providers: []string{"vllm"}}, Transition: next(taskengine.TermEnd)},
+		},
+	}
+
+	_, _, _, err = env.ExecEnv(libtracker.WithNewRequestID(context.Background()), chain, "hi", taskengine.DataTypeString)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"ollama", "openai"}, recorder.configs["no_config"].Providers)
+	require.Equal(t, []string{"ollama", "openai"}, recorder.configs["model_only"].Providers)
+	require.Equal(t, "qwen2.5:7b", recorder.configs["model_only"].Model)
+	require.Equal(t, "gemini", recorder.configs["own_provider"].Provider)
+	require.Empty(t, recorder.configs["own_provider"].Providers, "a task's own provider overrides the chain preference")
+	require.Equal(t, []string{"vllm"}, recorder.configs["own_providers"].Providers)
+
+	require.Nil(t, chain.Tasks[0].ExecuteConfig, "the chain definition is not modified")
+	require.Empty(t, chain.Tasks[1].ExecuteConfig.Providers)
+}
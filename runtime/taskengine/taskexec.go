@@ -55,6 +55,7 @@ type SimpleExec struct {
 	toolsProvider ToolsRepo
 	tracker       libtracker.ActivityTracker
 	eventSink     TaskEventSink
+	attachments   AttachmentReader
 }
 
 // NewExec creates a new SimpleExec instance
@@ -75,6 +76,7 @@ func NewExec(
 		repo:          repo,
 		tracker:       tracker,
 		eventSink:     taskEventSinkFromContext(ctx),
+		attachments:   attachmentReaderFromContext(ctx),
 	}, nil
 }
 
@@ -139,7 +141,7 @@ func (exe *SimpleExec) countChatHistoryTokens(ctx context.Context, modelName str
 	// Count tokens for each message
 	totalTokens := 0
 	for _, msg := range history.Messages {
-		tokenCount, err := exe.repo.CountTokens(ctx, modelName, msg.Content)
+		tokenCount, err := exe.repo.CountTokens(ctx, modelName, msg.modelContent())
 		if err != nil {
 			return 0, fmt.Errorf("token count failed for message: %w", err)
 		}
@@ -173,7 +175,7 @@ func requestedContextRequirement(ctx context.Context, actualTokens int) int {
 func (exe *SimpleExec) shiftMessagesToFit(ctx context.Context, modelName string, msgs []Message, budget int) ([]Message, int, error) {
 	toks := make([]int, len(msgs))
 	for i, m := range msgs {
-		n, err := exe.repo.CountTokens(ctx, modelName, m.Content)
+		n, err := exe.repo.CountTokens(ctx, modelName, m.modelContent())
 		if err != nil {
 			return nil, 0, fmt.Errorf("token count failed: %w", err)
 		}
@@ -254,7 +256,7 @@ func (exe *SimpleExec) shiftMessagesToFit(ctx context.Context, modelName string,
 
 	total := 0
 	for _, m := range out {
-		n, err := exe.repo.CountTokens(ctx, modelName, m.Content)
+		n, err := exe.repo.CountTokens(ctx, modelName, m.modelContent())
 		if err != nil {
 			return nil, 0, fmt.Errorf("token count failed: %w", err)
 		}
@@ -284,6 +286,26 @@ func GetPrimaryModel(llmCall *LLMExecutionConfig) string {
 	return "default" // Fallback model name for token counting
 }
 
+// noCandidateSupportsTools reports whether every model llmCall names, or the
+// primary model when it names none, is served and marked as unable to take
+// tool definitions.
+func (exe *SimpleExec) noCandidateSupportsTools(ctx context.Context, llmCall *LLMExecutionConfig) bool {
+	candidates := llmCall.Models
+	if llmCall.Model != "" {
+		candidates = append([]string{llmCall.Model}, candidates...)
+	}
+	if len(candidates) == 0 {
+		candidates = []string{GetPrimaryModel(llmCall)}
+	}
+	for _, name := range candidates {
+		caps, ok := exe.repo.Capabilities(ctx, name)
+		if !ok || caps.SupportsTools {
+			return false
+		}
+	}
+	return true
+}
+
 func routeHistoryPrompt(history ChatHistory) string {
 	lines := make([]string, 0, len(history.Messages))
 	for _, msg := range history.Messages {
@@ -369,7 +391,7 @@ func (exe *SimpleExec) Prompt(ctx context.Context, systemInstruction string, llm
 			var fullResponse strings.Builder
 			for parcel := range stream {
 				if parcel.Error != nil {
-					err := fmt.Errorf("prompt stream failed: %w", parcel.Error)
+					err := withCategory(ErrProvider, fmt.Errorf("prompt stream failed: %w", parcel.Error))
 					reportErr(err)
 					return "", err
 				}
@@ -449,7 +471,7 @@ func (exe *SimpleExec) promptWithRetry(
 		})
 	}
 	if err != nil {
-		return "", llmrepo.Meta{}, err
+		return "", llmrepo.Meta{}, withCategory(ErrProvider, err)
 	}
 	pr := result.(promptResult)
 	return pr.response, pr.meta, nil
@@ -481,7 +503,14 @@ func selectRoute(answer string, routes []string) string {
 	return chosen
 }
 
+// TaskExec runs one task and tags its failure with an error category (see
+// ErrorCategory).
 func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time, ctxLength int, chainContext *ChainContext, currentTask *TaskDefinition, input any, dataType DataType) (any, DataType, string, error) {
+	output, outputType, transitionEval, err := exe.taskExec(taskCtx, startingTime, ctxLength, chainContext, currentTask, input, dataType)
+	return output, outputType, transitionEval, categorizeTaskError(taskCtx, err)
+}
+
+func (exe *SimpleExec) taskExec(taskCtx context.Context, startingTime time.Time, ctxLength int, chainContext *ChainContext, currentTask *TaskDefinition, input any, dataType DataType) (any, DataType, string, error) {
 	var transitionEval string
 	var taskErr error
 	var output any = input
@@ -506,6 +535,8 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 			return prompt, nil
 		case DataTypeInt:
 			return fmt.Sprintf("%d", input), nil
+		case DataTypeBool:
+			return fmt.Sprintf("%t", input), nil
 		case DataTypeChatHistory:
 			history, ok := input.(ChatHistory)
 			if !ok {
@@ -536,7 +567,7 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 		}
 		routes := declaredRoutes(currentTask.Transition.Branches)
 		if len(routes) == 0 {
-			return nil, DataTypeAny, "", fmt.Errorf("route task %s has no equals branches to route between", currentTask.ID)
+			return nil, DataTypeAny, "", withCategory(ErrValidation, fmt.Errorf("route task %s has no equals branches to route between", currentTask.ID))
 		}
 		sys := currentTask.SystemInstruction
 		if sys != "" {
@@ -658,6 +689,14 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 			return nil, DataTypeAny, "", fmt.Errorf("handler '%s' requires input of type 'chat_history' or 'string', used var: %s but got '%s'", currentTask.Handler, currentTask.InputVar, dataType.String())
 		}
 
+		if finalExecConfig.InlineAttachments {
+			if inlined, ok := exe.inlineAttachments(taskCtx, chatHistory.Messages, finalExecConfig.AttachmentBudget); ok {
+				chatHistory.Messages = inlined
+				// The inlined text is not in the old InputTokens value.
+				chatHistory.InputTokens = 0
+			}
+		}
+
 		// Count tokens and check limits for chat completion
 		modelName := GetPrimaryModel(finalExecConfig)
 		if !finalExecConfig.Shift {
@@ -695,8 +734,8 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 
 	case HandleExecuteToolCalls:
 		if dataType != DataTypeChatHistory {
-			taskErr = fmt.Errorf("handler '%s' requires 'chat_history' input, but got '%s'",
-				currentTask.Handler, dataType.String())
+			taskErr = withCategory(ErrValidation, fmt.Errorf("handler '%s' requires 'chat_history' input, but got '%s'",
+				currentTask.Handler, dataType.String()))
 			break
 		}
 
@@ -716,6 +755,10 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 			transitionEval = TransitionNoCallsFound
 			break
 		}
+		if callsClientTool(chainContext.ClientTools, lastMessage.CallTools) {
+			transitionEval = TransitionClientToolCalls
+			break
+		}
 
 		allowedTools, explicitToolsScope, err := exe.executionToolsScope(taskCtx, currentTask)
 		if err != nil {
@@ -913,9 +956,49 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 			transitionEval = TransitionNoCallsFound
 		}
 
+	case HandleToolLoop:
+		output, outputType, transitionEval, taskErr = exe.toolLoop(taskCtx, startingTime, ctxLength, chainContext, currentTask, input, dataType)
+
+	case HandleTruncateHistory:
+		output, outputType, transitionEval, taskErr = exe.truncateHistory(taskCtx, ctxLength, currentTask, input, dataType)
+
+	case HandleJSONExtract:
+		output, outputType, transitionEval, taskErr = jsonExtract(currentTask, input, dataType)
+		taskErr = withCategory(ErrValidation, taskErr)
+
+	case HandleParseJSONArray:
+		output, outputType, transitionEval, taskErr = parseJSONArray(currentTask, input, dataType)
+		taskErr = withCategory(ErrValidation, taskErr)
+
+	case HandleParseBool:
+		output, outputType, transitionEval, taskErr = parseBool(currentTask, input, dataType)
+		taskErr = withCategory(ErrValidation, taskErr)
+
+	case HandleDetectLanguage:
+		if currentTask.ExecuteConfig == nil {
+			currentTask.ExecuteConfig = &LLMExecutionConfig{}
+		}
+		text, err := getPrompt()
+		if err != nil {
+			taskErr = withCategory(ErrValidation, fmt.Errorf("detect_language task %s: %w", currentTask.ID, err))
+			break
+		}
+		output, outputType, transitionEval, taskErr = exe.detectLanguage(taskCtx, currentTask, text, ctxLength)
+
+	case HandleRenderTemplate:
+		// ExecEnv has already rendered PromptTemplate over the chain's
+		// variables into the input; this handler makes that text the
+		// output, where noop would have done the same but evaluated "noop".
+		rendered, ok := input.(string)
+		if !ok || dataType != DataTypeString {
+			taskErr = withCategory(ErrValidation, fmt.Errorf("render_template task %s: expected the rendered prompt_template as input, got %s", currentTask.ID, dataType.String()))
+			break
+		}
+		output, outputType, transitionEval = rendered, DataTypeString, rendered
+
 	case HandleTools:
 		if currentTask.Tools == nil {
-			taskErr = fmt.Errorf("tools task missing tools definition")
+			taskErr = withCategory(ErrValidation, fmt.Errorf("tools task missing tools definition"))
 		} else {
 			if currentTask.Tools.Args == nil {
 				currentTask.Tools.Args = make(map[string]string)
@@ -993,6 +1076,85 @@ func (exe *SimpleExec) TaskExec(taskCtx context.Context, startingTime time.Time,
 	return output, outputType, transitionEval, taskErr
 }
 
+// toolLoop runs a tool_loop task: a chat_completion turn, then, when the model
+// requested tools, an execute_tool_calls pass that appends the results as
+// tool-role messages, repeated until the model answers without tool calls or
+// the turn cap is hit. Both halves run through TaskExec with the task's handler
+// swapped, so they keep their own guards, tool scoping, tracking and events
+// exactly as a two-task chat_completion/execute_tool_calls cycle would.
+func (exe *SimpleExec) toolLoop(taskCtx context.Context, startingTime time.Time, ctxLength int, chainContext *ChainContext, currentTask *TaskDefinition, input any, dataType DataType) (any, DataType, string, error) {
+	maxTurns := DefaultMaxToolIterations
+	if currentTask.ExecuteConfig != nil && currentTask.ExecuteConfig.MaxToolIterations > 0 {
+		maxTurns = currentTask.ExecuteConfig.MaxToolIterations
+	}
+	reportErr, reportChange, end := exe.tracker.Start(taskCtx, "tool_loop", currentTask.ID,
+		"max_turns", maxTurns)
+	defer end()
+
+	savedHandler := currentTask.Handler
+	defer func() { currentTask.Handler = savedHandler }()
+
+	// Each turn's history carries that turn's usage; the loop's usage is the
+	// sum over all of them.
+	var usage TokenUsage
+	output, outputType := input, dataType
+	for turn := 1; turn <= maxTurns; turn++ {
+		currentTask.Handler = HandleChatCompletion
+		reply, replyType, eval, err := exe.TaskExec(taskCtx, startingTime, ctxLength, chainContext, currentTask, output, outputType)
+		if err != nil {
+			reportErr(err)
+			return nil, DataTypeAny, "", fmt.Errorf("tool loop turn %d: %w", turn, err)
+		}
+		if hist, ok := reply.(ChatHistory); ok && hist.Usage != nil {
+			usage.Prompt += hist.Usage.Prompt
+			usage.Completion += hist.Usage.Completion
+			usage.Total += hist.Usage.Total
+		}
+		output, outputType = reply, replyType
+		if eval != TransitionToolCall {
+			reportChange("turns", turn)
+			return withUsage(output, usage), outputType, TransitionExecuted, nil
+		}
+
+		currentTask.Handler = HandleExecuteToolCalls
+		results, resultsType, eval, err := exe.TaskExec(taskCtx, startingTime, ctxLength, chainContext, currentTask, output, outputType)
+		if err != nil {
+			reportErr(err)
+			return results, resultsType, TransitionFailed, fmt.Errorf("tool loop turn %d: %w", turn, err)
+		}
+		if eval == TransitionClientToolCalls {
+			reportChange("turns", turn)
+			return withUsage(results, usage), resultsType, TransitionClientToolCalls, nil
+		}
+		output, outputType = results, resultsType
+	}
+	reportChange("turns", maxTurns)
+	return withUsage(output, usage), outputType, TransitionMaxIterations, nil
+}
+
+// withUsage sets usage on output when it is a chat history.
+func withUsage(output any, usage TokenUsage) any {
+	if hist, ok := output.(ChatHistory); ok {
+		hist.Usage = &usage
+		return hist
+	}
+	return output
+}
+
+// callsClientTool reports whether any of calls names one of the client's
+// tools. A batch that does is handed back to the client whole: running the
+// server-side calls alone would leave the transcript half answered.
+func callsClientTool(clientTools []Tool, calls []ToolCall) bool {
+	for _, call := range calls {
+		for _, tool := range clientTools {
+			if tool.Function.Name == call.Function.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 const noResolvedToolsInstruction = "No tools are available in this turn. Do not claim to have inspected files, run commands, opened URLs, or used tools. Answer only from the provided conversation and context; if tool access or external inspection is needed, say so explicitly."
 
 func llmCallRequestedTools(llmCall *LLMExecutionConfig) bool {
@@ -1071,6 +1233,22 @@ func (exe *SimpleExec) executeLLM(
 			}
 		}
 	}
+
+	modelName := GetPrimaryModel(llmCall)
+
+	// A model marked as unable to take tool definitions would reject or
+	// garble the request. When every model the call may resolve to is marked,
+	// drop them here and let the guard below tell the model it has no tools
+	// this turn; otherwise the model repo drops them if resolution lands on a
+	// marked one.
+	if len(tools) > 0 && exe.noCandidateSupportsTools(ctx, llmCall) {
+		reportChange("tools_unsupported_warning", map[string]any{
+			"model_name":    modelName,
+			"dropped_tools": len(tools),
+		})
+		tools = nil
+	}
+
 	if len(tools) == 0 && llmCallRequestedTools(llmCall) {
 		prelude = append(prelude, Message{
 			Role:    "system",
@@ -1083,34 +1261,34 @@ func (exe *SimpleExec) executeLLM(
 	}
 
 	// Token counting
-	modelName := GetPrimaryModel(llmCall)
-
 	var messagesTokens int
 
-	// Count messages tokens if not already set
+	// Count the history (unless already known) and the prelude in one
+	// tokenizer call; the prelude's counts come last in the batch.
+	var texts []string
 	if input.InputTokens > 0 {
 		messagesTokens = input.InputTokens
 	} else {
-		var total int
 		for _, m := range input.Messages {
-			cnt, err := exe.repo.CountTokens(ctx, modelName, m.Content)
-			if err != nil {
-				reportErr(fmt.Errorf("token count failed: %w", err))
-				return nil, DataTypeAny, "", fmt.Errorf("token count failed: %w", err)
-			}
-			total += cnt
+			texts = append(texts, m.modelContent())
 		}
-		messagesTokens = total
 	}
-
-	preludeTokens := 0
+	historyTexts := len(texts)
 	for _, m := range prelude {
-		cnt, err := exe.repo.CountTokens(ctx, modelName, m.Content)
-		if err != nil {
-			reportErr(fmt.Errorf("token count failed: %w", err))
-			return nil, DataTypeAny, "", fmt.Errorf("token count failed: %w", err)
+		texts = append(texts, m.Content)
+	}
+	counts, err := exe.repo.CountTokensBatch(ctx, modelName, texts)
+	if err != nil {
+		reportErr(fmt.Errorf("token count failed: %w", err))
+		return nil, DataTypeAny, "", fmt.Errorf("token count failed: %w", err)
+	}
+	preludeTokens := 0
+	for i, cnt := range counts {
+		if i < historyTexts {
+			messagesTokens += cnt
+		} else {
+			preludeTokens += cnt
 		}
-		preludeTokens += cnt
 	}
 	messagesTokens += preludeTokens
 
@@ -1244,7 +1422,7 @@ func (exe *SimpleExec) executeLLM(
 			var streamedToolCalls []libmodelprovider.ToolCall
 			for parcel := range stream {
 				if parcel.Error != nil {
-					return nil, DataTypeAny, "", fmt.Errorf("chat stream failed: %w", parcel.Error)
+					return nil, DataTypeAny, "", withCategory(ErrProvider, fmt.Errorf("chat stream failed: %w", parcel.Error))
 				}
 				streamedContent.WriteString(parcel.Data)
 				streamedThinking.WriteString(parcel.Thinking)
@@ -1284,6 +1462,7 @@ func (exe *SimpleExec) executeLLM(
 			// Count output tokens (content only, not tool calls) to match the
 			// non-streaming path so usage indicators and follow-up budgeting stay
 			// consistent across the two code paths.
+			input.OutputTokens = 0
 			if len(content) != 0 {
 				outputTokensCount, err := exe.repo.CountTokens(ctx, meta.ModelName, content)
 				if err != nil {
@@ -1293,6 +1472,8 @@ func (exe *SimpleExec) executeLLM(
 				}
 				input.OutputTokens = outputTokensCount
 			}
+			input.Model = meta.ModelName
+			input.Usage = &TokenUsage{Prompt: totalTokens, Completion: input.OutputTokens, Total: totalTokens + input.OutputTokens}
 
 			if len(callTools) > 0 {
 				return input, DataTypeChatHistory, TransitionToolCall, nil
@@ -1361,6 +1542,10 @@ func (exe *SimpleExec) executeLLM(
 		}
 	}
 	input.OutputTokens = outputTokensCount
+	// Record the model the resolver picked, which prices the call when the
+	// task named none or failed over.
+	input.Model = meta.ModelName
+	input.Usage = &TokenUsage{Prompt: totalTokens, Completion: outputTokensCount, Total: totalTokens + outputTokensCount}
 
 	if len(callTools) > 0 {
 		return input, DataTypeChatHistory, TransitionToolCall, nil
@@ -1386,6 +1571,15 @@ func chatArgsForLLMCall(llmCall *LLMExecutionConfig, tools []libmodelprovider.To
 	if llmCall.MaxTokens != nil && *llmCall.MaxTokens > 0 {
 		chatArgs = append(chatArgs, libmodelprovider.WithMaxTokens(*llmCall.MaxTokens))
 	}
+	if llmCall.TopP != nil {
+		chatArgs = append(chatArgs, libmodelprovider.WithTopP(*llmCall.TopP))
+	}
+	if llmCall.PresencePenalty != nil {
+		chatArgs = append(chatArgs, libmodelprovider.WithPresencePenalty(*llmCall.PresencePenalty))
+	}
+	if len(llmCall.Stop) > 0 {
+		chatArgs = append(chatArgs, libmodelprovider.WithStop(llmCall.Stop...))
+	}
 	if llmCall.Shift {
 		chatArgs = append(chatArgs, libmodelprovider.WithShift{})
 	}
@@ -1442,7 +1636,7 @@ func providerMessagesFromEngine(prelude, messages []Message) []libmodelprovider.
 		}
 		out = append(out, libmodelprovider.Message{
 			Role:       m.Role,
-			Content:    m.Content,
+			Content:    m.modelContent(),
 			Images:     images,
 			ToolCalls:  toolCalls,
 			ToolCallID: m.ToolCallID,
@@ -1484,12 +1678,12 @@ func (exe *SimpleExec) toolsengine(
 	// Call the provider with the new, simple signature.
 	toolsOutput, dataType, err := exe.toolsProvider.Exec(ctx, startingTime, input, debug, tools)
 	if err != nil {
-		return nil, dataType, TransitionFailed, err
+		return nil, dataType, TransitionFailed, withCategory(ErrHook, err)
 	}
 
 	toolsOutput, dataType, normErr := NormalizeDataType(toolsOutput, dataType)
 	if normErr != nil {
-		return nil, DataTypeAny, TransitionFailed, normErr
+		return nil, DataTypeAny, TransitionFailed, withCategory(ErrHook, normErr)
 	}
 
 	// On success, process the output. Default eval matches execute_tool_calls'
@@ -1503,7 +1697,7 @@ func (exe *SimpleExec) toolsengine(
 		rendered, tplErr := renderTemplate(outputTemplate, finalOutput)
 		if tplErr != nil {
 			// Return a descriptive error if templating fails.
-			return nil, DataTypeAny, TransitionFailed, fmt.Errorf("failed to render tools output template: %w", tplErr)
+			return nil, DataTypeAny, TransitionFailed, withCategory(ErrValidation, fmt.Errorf("failed to render tools output template: %w", tplErr))
 		}
 		finalOutput = rendered
 		finalOutputType = DataTypeString
@@ -1880,7 +2074,7 @@ func (exe *SimpleExec) chatWithRetry(
 		})
 	}
 	if err != nil {
-		return libmodelprovider.ChatResult{}, llmrepo.Meta{}, err
+		return libmodelprovider.ChatResult{}, llmrepo.Meta{}, withCategory(ErrProvider, err)
 	}
 	cr := result.(chatResult)
 	return cr.resp, cr.meta, nil
@@ -107,6 +107,45 @@ func TestUnit_TaskExec_ChatCompletionUsesRequestedContextLengthMinimum(t *testin
 	require.Equal(t, 4096, seenReq.ContextLength)
 }
 
+func TestUnit_TaskExec_ChatCompletionForwardsSamplingParams(t *testing.T) {
+	var seen []*libmodelprovider.ChatConfig
+	repo := &mockModelRepo{
+		chatFunc: func(_ context.Context, _ llmrepo.Request, _ []libmodelprovider.Message, opts ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+			cfg := &libmodelprovider.ChatConfig{}
+			for _, opt := range opts {
+				opt.Apply(cfg)
+			}
+			seen = append(seen, cfg)
+			return libmodelprovider.ChatResult{
+				Message: libmodelprovider.Message{Role: "assistant", Content: "ok"},
+			}, llmrepo.Meta{ModelName: "test-model", ProviderType: "llama"}, nil
+		},
+	}
+	exec, err := taskengine.NewExec(context.Background(), repo, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+
+	topP, presence, maxTokens := 0.9, 0.5, 128
+	input := taskengine.ChatHistory{Messages: []taskengine.Message{{Role: "user", Content: "hello"}}}
+	for _, cfg := range []*taskengine.LLMExecutionConfig{
+		{Model: "test-model", TopP: &topP, PresencePenalty: &presence, Stop: []string{"END"}, MaxTokens: &maxTokens},
+		{Model: "test-model"},
+	} {
+		task := &taskengine.TaskDefinition{ID: "chat", Handler: taskengine.HandleChatCompletion, ExecuteConfig: cfg}
+		_, _, _, err = exec.TaskExec(context.Background(), time.Now().UTC(), 4096, &taskengine.ChainContext{}, task, input, taskengine.DataTypeChatHistory)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, seen, 2)
+	require.Equal(t, &topP, seen[0].TopP)
+	require.Equal(t, &presence, seen[0].PresencePenalty)
+	require.Equal(t, []string{"END"}, seen[0].Stop)
+	require.Equal(t, &maxTokens, seen[0].MaxTokens)
+	require.Nil(t, seen[1].TopP, "unset parameters leave the provider default")
+	require.Nil(t, seen[1].PresencePenalty)
+	require.Nil(t, seen[1].Stop)
+	require.Nil(t, seen[1].MaxTokens)
+}
+
 func TestUnit_TaskExec_RouteUsesRequestedContextLengthMinimum(t *testing.T) {
 	var seenReq llmrepo.Request
 	repo := &mockModelRepo{
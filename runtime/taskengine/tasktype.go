@@ -21,6 +21,28 @@ const (
 	HandleExecuteToolCalls TaskHandler = "execute_tool_calls"
 	HandleNoop             TaskHandler = "noop"
 	HandleTools            TaskHandler = "tools"
+	// HandleToolLoop runs chat_completion and execute_tool_calls in turn
+	// inside one task until the model answers without tool calls or
+	// LLMExecutionConfig.MaxToolIterations model turns have run.
+	HandleToolLoop TaskHandler = "tool_loop"
+	// HandleTruncateHistory drops the oldest non-system messages of a chat
+	// history until it fits a token budget; see TruncateHistoryConfig.
+	HandleTruncateHistory TaskHandler = "truncate_history"
+	// HandleJSONExtract pulls one value out of a JSON input with a JSONPath
+	// expression; see JSONExtractConfig.
+	HandleJSONExtract TaskHandler = "json_extract"
+	// HandleRenderTemplate emits its rendered prompt_template as the task
+	// output without calling a model.
+	HandleRenderTemplate TaskHandler = "render_template"
+	// HandleParseJSONArray parses a model reply holding a JSON array into
+	// that array, so later tasks can iterate over it.
+	HandleParseJSONArray TaskHandler = "parse_json_array"
+	// HandleParseBool reads a yes/no model reply as a boolean, so a chain
+	// can branch on it without a route task.
+	HandleParseBool TaskHandler = "parse_bool"
+	// HandleDetectLanguage asks the model for the ISO 639-1 code of the
+	// input's language, so a chain can branch per language.
+	HandleDetectLanguage TaskHandler = "detect_language"
 )
 
 func (t TaskHandler) String() string {
@@ -60,12 +82,12 @@ func IsAssistantProseHandler(handler string) bool {
 // vscodeagent emits when it is false — which is the same judgement spelled two
 // ways, not a divergence.
 //
-// chat_completion is included because its tool calls surface as tool events of
-// their own, and route because its step is a routing decision no surface renders
-// as a step.
+// chat_completion and tool_loop are included because their tool calls surface as
+// tool events of their own, and route because its step is a routing decision no
+// surface renders as a step.
 func IsToolBearingHandler(handler string) bool {
 	switch TaskHandler(handler) {
-	case HandleExecuteToolCalls, HandleTools, HandleChatCompletion, HandleRoute:
+	case HandleExecuteToolCalls, HandleTools, HandleChatCompletion, HandleRoute, HandleToolLoop:
 		return true
 	default:
 		return false
@@ -78,8 +100,15 @@ func IsToolBearingHandler(handler string) bool {
 // contract — branch on these constants, not the model's free text:
 //
 //   - chat_completion        → TransitionToolCall (model requested tools) | TransitionExecuted (finished, no tool calls)
-//   - execute_tool_calls     → TransitionNoop (empty history) | TransitionNoCallsFound (model produced no tool calls) | TransitionToolsExecuted | TransitionFailed
+//   - execute_tool_calls     → TransitionNoop (empty history) | TransitionNoCallsFound (model produced no tool calls) | TransitionToolsExecuted | TransitionFailed | TransitionClientToolCalls (calls for the client to run)
 //   - tools                  → TransitionToolsExecuted | TransitionFailed (or, when OutputTemplate is set, its rendered text)
+//   - tool_loop              → TransitionExecuted (final answer) | TransitionMaxIterations (cap hit) | TransitionClientToolCalls
+//   - truncate_history       → TransitionTruncated (messages dropped) | TransitionNoop (already fits)
+//   - json_extract           → the extracted value as text (strings verbatim, other values as JSON)
+//   - render_template        → the rendered text
+//   - parse_json_array       → the element count, e.g. "0" for an empty array
+//   - parse_bool             → "true" | "false"
+//   - detect_language        → the ISO 639-1 code, e.g. "de"
 //   - noop                   → TransitionNoop
 //
 // To branch on the model's actual text, use the `route` handler, whose eval IS
@@ -99,6 +128,18 @@ const (
 	TransitionToolsExecuted = "tools_executed"
 	// TransitionFailed: a tools task failed.
 	TransitionFailed = "failed"
+	// TransitionMaxIterations: a tool_loop task ran its last allowed model turn
+	// and the model still requested tools. Those calls are executed, so the
+	// output history ends with their results, ready for a follow-up task.
+	TransitionMaxIterations = "max_iterations"
+	// TransitionTruncated: a truncate_history task dropped messages to fit its
+	// budget. When the history already fit it emits TransitionNoop instead.
+	TransitionTruncated = "truncated"
+	// TransitionClientToolCalls: the model called a tool the API client
+	// supplied (see WithClientTools). execute_tool_calls and tool_loop leave
+	// the batch unanswered — the history ends with the assistant's calls — so
+	// the chain can end and return them for the client to run.
+	TransitionClientToolCalls = "client_tool_calls"
 )
 
 // DataType (un)marshals as its lowercase string name in both JSON and YAML.
@@ -161,7 +202,7 @@ type TransitionBranch struct {
 	// ends_with is byte-exact and CASE-SENSITIVE with no trimming — a trailing
 	// newline (common in multiline template literals) will not match. Only the
 	// `route` handler normalizes its answer.
-	Operator OperatorTerm `yaml:"operator,omitempty" json:"operator,omitempty" example:"equals" openapi_include_type:"string"`
+	Operator OperatorTerm `yaml:"operator,omitempty" json:"operator,omitempty" example:"equals" openapi_include_type:"taskengine.OperatorTerm"`
 
 	// When is the value this branch matches against the task's transition eval.
 	// What the eval is depends on the handler:
@@ -301,14 +342,34 @@ type LLMExecutionConfig struct {
 	// MaxTokensTemplate stores a string max_tokens macro from chain JSON until
 	// MacroEnv expands it into MaxTokens. It is not emitted as a separate field.
 	MaxTokensTemplate string `yaml:"-" json:"-"`
+	// TopP, PresencePenalty and Stop are passed to the provider as given; nil
+	// or empty leaves each at the provider default. Providers without a
+	// parameter ignore it (e.g. Anthropic has no presence penalty).
+	TopP            *float64 `yaml:"top_p,omitempty" json:"top_p,omitempty" example:"0.9"`
+	PresencePenalty *float64 `yaml:"presence_penalty,omitempty" json:"presence_penalty,omitempty" example:"0.5"`
+	Stop            []string `yaml:"stop,omitempty" json:"stop,omitempty" example:"[\"</answer>\"]"`
 	// Shift allows the context window to slide on overflow instead of erroring.
 	Shift bool `yaml:"shift,omitempty" json:"shift,omitempty"`
 	// RetryPolicy wraps the underlying chat/prompt call with classified retry
 	// (rate-limit / server-error / timeout) and an optional model fallback.
 	// Nil or zero-value disables retry — current default. See [llmretry.Do].
 	RetryPolicy *llmretry.RetryPolicy `yaml:"retry_policy,omitempty" json:"retry_policy,omitempty"`
+	// MaxToolIterations caps the model turns a tool_loop task runs before it
+	// stops with TransitionMaxIterations. 0 uses DefaultMaxToolIterations;
+	// other handlers ignore it.
+	MaxToolIterations int `yaml:"max_tool_iterations,omitempty" json:"max_tool_iterations,omitempty" example:"10"`
+	// InlineAttachments makes a chat_completion task read the files attached
+	// to its messages and show the model their text. AttachmentBudget caps
+	// the bytes of file text one task inlines; 0 uses
+	// DefaultAttachmentBudget. Other handlers ignore both.
+	InlineAttachments bool `yaml:"inline_attachments,omitempty" json:"inline_attachments,omitempty"`
+	AttachmentBudget  int  `yaml:"attachment_budget,omitempty" json:"attachment_budget,omitempty" example:"32768"`
 }
 
+// DefaultMaxToolIterations is the tool_loop model-turn cap when
+// LLMExecutionConfig.MaxToolIterations is unset.
+const DefaultMaxToolIterations = 10
+
 func (c *LLMExecutionConfig) UnmarshalJSON(data []byte) error {
 	type noMethods LLMExecutionConfig
 	fields := map[string]json.RawMessage{}
@@ -475,6 +536,36 @@ type ToolsCall struct {
 	Args map[string]string `yaml:"args,omitempty" json:"args,omitempty" example:"{\"channel\": \"#alerts\", \"message\": \"Task completed successfully\"}"`
 }
 
+// TruncateHistoryConfig configures a `truncate_history` task. Tokens are
+// counted with the tokenizer of the task's execute_config model, so point it at
+// the model the trimmed history is meant for.
+type TruncateHistoryConfig struct {
+	// MaxTokens is the budget the history is trimmed to. 0 uses the chain's
+	// token_limit.
+	MaxTokens int `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty" example:"8192"`
+	// KeepTurns is how many of the most recent turns (a user message and
+	// everything after it up to the next user message) are never dropped.
+	// 0 uses DefaultTruncateKeepTurns.
+	KeepTurns int `yaml:"keep_turns,omitempty" json:"keep_turns,omitempty" example:"2"`
+}
+
+// JSONExtractConfig configures a `json_extract` task.
+type JSONExtractConfig struct {
+	// Path is a JSONPath expression rooted at "$", e.g. "$.items[0].name".
+	// Required.
+	Path string `yaml:"path" json:"path" example:"$.result.status"`
+	// UseDefault makes a path that matches nothing yield Default instead of
+	// failing the task.
+	UseDefault bool `yaml:"use_default,omitempty" json:"use_default,omitempty"`
+	// Default is the value emitted when the path matches nothing and
+	// UseDefault is set.
+	Default any `yaml:"default,omitempty" json:"default,omitempty"`
+}
+
+// DefaultTruncateKeepTurns keeps the current turn when
+// TruncateHistoryConfig.KeepTurns is unset.
+const DefaultTruncateKeepTurns = 1
+
 type TaskDefinition struct {
 	// ID uniquely identifies the task within the chain.
 	ID string `yaml:"id" json:"id" example:"validate_input"`
@@ -483,7 +574,7 @@ type TaskDefinition struct {
 	Description string `yaml:"description" json:"description" example:"Validates user input meets quality requirements"`
 
 	// Handler determines how the LLM output (or tools) will be interpreted.
-	Handler TaskHandler `yaml:"handler" json:"handler" example:"chat_completion" openapi_include_type:"string"`
+	Handler TaskHandler `yaml:"handler" json:"handler" example:"chat_completion" openapi_include_type:"taskengine.TaskHandler"`
 
 	// SystemInstruction provides additional instructions to the LLM, if applicable system level will be used.
 	SystemInstruction string `yaml:"system_instruction,omitempty" json:"system_instruction,omitempty" example:"You are a quality control assistant. Respond only with 'valid' or 'invalid'."`
@@ -496,6 +587,12 @@ type TaskDefinition struct {
 	// Example: {type: "send_email", args: {"to": "user@example.com"}}
 	Tools *ToolsCall `yaml:"tools,omitempty" json:"tools,omitempty" openapi_include_type:"taskengine.ToolsCall"`
 
+	// Truncate configures a truncate_history task; other handlers ignore it.
+	Truncate *TruncateHistoryConfig `yaml:"truncate,omitempty" json:"truncate,omitempty" openapi_include_type:"taskengine.TruncateHistoryConfig"`
+
+	// Extract configures a json_extract task; other handlers ignore it.
+	Extract *JSONExtractConfig `yaml:"extract,omitempty" json:"extract,omitempty" openapi_include_type:"taskengine.JSONExtractConfig"`
+
 	// Print optionally formats the output for display/logging.
 	// Supports template variables from previous task outputs.
 	// Optional for all task types except Tools where it's rarely used.
@@ -536,6 +633,14 @@ type TaskDefinition struct {
 	// Applies to all task types including Tools.
 	// Default: 0 (no retries)
 	RetryOnFailure int `yaml:"retry_on_failure,omitempty" json:"retry_on_failure,omitempty" example:"2"`
+
+	// Idempotent marks this task's tool calls as side effects that must run
+	// once per chain run. A call repeated with the same args and input — on a
+	// retry, or when a transition loops back to this task — returns the first
+	// call's result instead of running again, when the engine's tools are
+	// wrapped with IdempotentTools.
+	// Default: false
+	Idempotent bool `yaml:"idempotent,omitempty" json:"idempotent,omitempty" example:"true"`
 }
 
 type ChainTerms string
@@ -564,6 +669,18 @@ type TaskChainDefinition struct {
 
 	// TokenLimit is the token limit for the context window (used during execution).
 	TokenLimit int64 `yaml:"token_limit" json:"token_limit"`
+
+	// Partials are named template snippets shared by the chain's tasks. Each is
+	// registered as a named template, so any task's prompt_template or print can
+	// include one with {{template "disclaimer" .}}. References to undefined
+	// partials are rejected when the chain is loaded.
+	Partials map[string]string `yaml:"partials,omitempty" json:"partials,omitempty"`
+
+	// ProviderPreference is the chain-wide provider order, e.g. local Ollama
+	// first with OpenAI as the fallback. It becomes the execute_config
+	// providers of every task that names no provider of its own; a task's
+	// provider or providers always take precedence over it.
+	ProviderPreference []string `yaml:"provider_preference,omitempty" json:"provider_preference,omitempty" example:"[\"ollama\", \"openai\"]"`
 }
 
 // ChatHistory represents a conversation history with an LLM.
@@ -576,6 +693,10 @@ type ChatHistory struct {
 	InputTokens int `json:"inputTokens" example:"15"`
 	// OutputTokens will be filled by the engine and will hold the number of tokens used for the output.
 	OutputTokens int `json:"outputTokens" example:"10"`
+	// Usage is filled by the engine with the tokens of the model call that
+	// produced this history: the prompt as sent, including tool definitions,
+	// and the reply. A tool_loop task sums it over all of its model turns.
+	Usage *TokenUsage `json:"usage,omitempty"`
 }
 
 // Message represents a single message in a chat conversation.
@@ -592,6 +713,10 @@ type Message struct {
 	// the message to the model; requests with images resolve only to
 	// vision-capable models and fail with a typed error when none is available.
 	Images []ImagePart `json:"images,omitempty" openapi_include_type:"taskengine.ImagePart"`
+	// Attachments reference workspace files beside Content. A chat_completion
+	// task with inline_attachments set reads them and shows the model their
+	// text, or a description of files that have none.
+	Attachments []Attachment `json:"attachments,omitempty" openapi_include_type:"taskengine.Attachment"`
 	// Thinking is the model's internal reasoning trace.
 	// Only populated when thinking is enabled; never sent back to the model as history.
 	Thinking string `json:"thinking,omitempty"`
@@ -618,6 +743,20 @@ type ImagePart struct {
 	MimeType string `json:"mime_type" example:"image/png"`
 }
 
+// Attachment references a file beside a Message's Content.
+type Attachment struct {
+	// FileID is the workspace-relative path of the file, as the /files API
+	// names it.
+	FileID string `json:"file_id" example:"docs/spec.md"`
+	// ContentType is the file's media type. Empty lets the reader detect it.
+	ContentType string `json:"content_type,omitempty" example:"text/markdown"`
+	// Text is what the model sees for the attachment: the file's text, or a
+	// description of a file that has none. The engine fills it the first time
+	// the attachment is inlined, so later turns show the model the same
+	// snapshot without reading the file again.
+	Text string `json:"text,omitempty"`
+}
+
 // Tool represents a tool that can be called by the model.
 type Tool struct {
 	Type     string       `json:"type"`
@@ -0,0 +1,111 @@
+package taskengine_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/llmrepo"
+	libmodelprovider "github.com/contenox/runtime/runtime/modelrepo"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+// toolLoopHarness runs one tool_loop task against a model that requests the
+// echo tool on its first toolTurns turns and then answers in plain text.
+func toolLoopHarness(t *testing.T, toolTurns, maxIterations int) (taskengine.ChatHistory, string, int, *tools.MockToolsRepo) {
+	t.Helper()
+	toolsRepo := tools.NewMockToolsRegistry().
+		WithResponse("echo", tools.ToolsResponse{Output: "ECHOED"})
+
+	turns := 0
+	repo := &mockModelRepo{
+		chatFunc: func(_ context.Context, _ llmrepo.Request, messages []libmodelprovider.Message, _ ...libmodelprovider.ChatArgument) (libmodelprovider.ChatResult, llmrepo.Meta, error) {
+			turns++
+			if turns > 1 {
+				require.Equal(t, "tool", messages[len(messages)-1].Role, "each re-prompt must carry the previous turn's tool result")
+			}
+			if turns <= toolTurns {
+				call := libmodelprovider.ToolCall{ID: fmt.Sprintf("call-%d", turns), Type: "function"}
+				call.Function.Name = "echo.echo"
+				call.Function.Arguments = `{"input":"hi"}`
+				return libmodelprovider.ChatResult{
+					Message:   libmodelprovider.Message{Role: "assistant"},
+					ToolCalls: []libmodelprovider.ToolCall{call},
+				}, llmrepo.Meta{ModelName: "test-model"}, nil
+			}
+			return libmodelprovider.ChatResult{
+				Message: libmodelprovider.Message{Role: "assistant", Content: "done"},
+			}, llmrepo.Meta{ModelName: "test-model"}, nil
+		},
+	}
+
+	exec, err := taskengine.NewExec(context.Background(), repo, toolsRepo, libtracker.NoopTracker{})
+	require.NoError(t, err)
+
+	chainCtx := &taskengine.ChainContext{
+		Tools: map[string]taskengine.ToolWithResolution{
+			"echo.echo": {
+				Tool:      taskengine.Tool{Type: "function", Function: taskengine.FunctionTool{Name: "echo.echo"}},
+				ToolsName: "echo",
+			},
+		},
+	}
+	task := &taskengine.TaskDefinition{
+		ID:      "agent",
+		Handler: taskengine.HandleToolLoop,
+		ExecuteConfig: &taskengine.LLMExecutionConfig{
+			Model:             "test-model",
+			Tools:             []string{"echo"},
+			MaxToolIterations: maxIterations,
+		},
+	}
+
+	out, dt, eval, err := exec.TaskExec(
+		context.Background(), time.Now().UTC(), 4000,
+		chainCtx, task, "please echo", taskengine.DataTypeString)
+	require.NoError(t, err)
+	require.Equal(t, taskengine.DataTypeChatHistory, dt)
+	require.Equal(t, taskengine.HandleToolLoop, task.Handler, "the task's handler must be restored after the loop")
+	hist, ok := out.(taskengine.ChatHistory)
+	require.True(t, ok)
+	return hist, eval, turns, toolsRepo
+}
+
+func TestUnit_ToolLoop_RunsToolsUntilFinalAnswer(t *testing.T) {
+	hist, eval, turns, toolsRepo := toolLoopHarness(t, 2, 0)
+	require.Equal(t, taskengine.TransitionExecuted, eval)
+	require.Equal(t, 3, turns)
+	require.Equal(t, 2, toolsRepo.CallCount())
+
+	var roles []string
+	for _, m := range hist.Messages {
+		roles = append(roles, m.Role)
+	}
+	require.Equal(t, []string{"user", "assistant", "tool", "assistant", "tool", "assistant"}, roles)
+	require.Equal(t, "done", hist.Messages[len(hist.Messages)-1].Content)
+}
+
+func TestUnit_ToolLoop_StopsAtMaxIterations(t *testing.T) {
+	hist, eval, turns, toolsRepo := toolLoopHarness(t, 100, 2)
+	require.Equal(t, taskengine.TransitionMaxIterations, eval)
+	require.Equal(t, 2, turns, "the model must not be re-invoked past the cap")
+	require.Equal(t, 2, toolsRepo.CallCount(), "the last turn's tool calls are still answered")
+
+	last := hist.Messages[len(hist.Messages)-1]
+	require.Equal(t, "tool", last.Role, "the history must not end on an unanswered tool call")
+}
+
+func TestUnit_ToolLoop_SumsUsageAcrossTurns(t *testing.T) {
+	hist, _, turns, _ := toolLoopHarness(t, 2, 0)
+	require.Equal(t, 3, turns)
+	require.NotNil(t, hist.Usage)
+	// The mock counts one token per message and one for the tool list. The
+	// three prompts hold 1, 3 and 5 messages, and only the last reply has
+	// content.
+	require.Equal(t, taskengine.TokenUsage{Prompt: 2 + 4 + 6, Completion: 1, Total: 13}, *hist.Usage)
+	require.Equal(t, 1, hist.OutputTokens, "the history's own counts stay those of the last turn")
+}
@@ -0,0 +1,81 @@
+package taskengine
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer emits the chain and task spans. It resolves through the global
+// OpenTelemetry TracerProvider, which is a no-op until the embedding process
+// installs one with otel.SetTracerProvider (contenox serve does when an OTLP
+// endpoint is configured, see serverapi.SetupTracing) — so tracing costs
+// nothing unless an exporter is configured, and picks one up even when it is
+// installed after this package is initialised.
+var tracer = otel.Tracer("github.com/contenox/runtime/runtime/taskengine")
+
+// traceContext is the W3C traceparent propagator. It is used directly rather
+// than through otel.GetTextMapPropagator so trace context reaches remote tools
+// even when the embedding process never configured a global propagator.
+var traceContext = propagation.TraceContext{}
+
+// startChainSpan opens the span covering one ExecEnv run. Task spans and any
+// nested chain runs become its children through the returned context.
+func startChainSpan(ctx context.Context, chain *TaskChainDefinition) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "chain "+chain.ID,
+		trace.WithAttributes(
+			attribute.String("contenox.chain.id", chain.ID),
+			attribute.Int("contenox.chain.tasks", len(chain.Tasks)),
+		))
+}
+
+// startTaskSpan opens the span covering one attempt of a task.
+func startTaskSpan(ctx context.Context, chainID string, task *TaskDefinition, retry int) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("contenox.chain.id", chainID),
+		attribute.String("contenox.task.id", task.ID),
+		attribute.String("contenox.task.handler", task.Handler.String()),
+		attribute.Int("contenox.task.retry", retry),
+	}
+	if task.ExecuteConfig != nil {
+		attrs = append(attrs, attribute.String("contenox.task.model", GetPrimaryModel(task.ExecuteConfig)))
+	}
+	return tracer.Start(ctx, "task "+task.ID, trace.WithAttributes(attrs...))
+}
+
+// endTaskSpan records the attempt's outcome on span and ends it.
+func endTaskSpan(span trace.Span, step CapturedStateUnit) {
+	span.SetAttributes(attribute.String("contenox.task.transition", step.Transition))
+	if step.TokenUsage != nil {
+		span.SetAttributes(
+			attribute.Int("contenox.tokens.prompt", step.TokenUsage.Prompt),
+			attribute.Int("contenox.tokens.completion", step.TokenUsage.Completion),
+		)
+	}
+	if err := step.Error.ErrorInternal; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InjectTraceContext writes the trace context of ctx into header as a W3C
+// traceparent, so a remote tool called during a task joins the chain's trace.
+// It writes nothing when ctx carries no span.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	traceContext.Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,148 @@
+package taskengine_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingProvider is a minimal TracerProvider that keeps every span it
+// starts, standing in for an SDK exporter.
+type recordingProvider struct {
+	embedded.TracerProvider
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordingTracer struct {
+	embedded.Tracer
+	p *recordingProvider
+}
+
+type recordedSpan struct {
+	noop.Span
+	name   string
+	parent string
+	mu     sync.Mutex
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	ended  bool
+}
+
+func (p *recordingProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{p: p}
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	s := &recordedSpan{name: name, attrs: map[attribute.Key]attribute.Value{}}
+	if parent, ok := trace.SpanFromContext(ctx).(*recordedSpan); ok {
+		s.parent = parent.name
+	}
+	cfg := trace.NewSpanStartConfig(opts...)
+	s.SetAttributes(cfg.Attributes()...)
+	t.p.mu.Lock()
+	t.p.spans = append(t.p.spans, s)
+	t.p.mu.Unlock()
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = code
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended = true
+}
+
+func (p *recordingProvider) byName(name string) *recordedSpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := len(p.spans) - 1; i >= 0; i-- {
+		if p.spans[i].name == name {
+			return p.spans[i]
+		}
+	}
+	return nil
+}
+
+func TestUnit_SimpleEnv_ExecEnv_EmitsChainAndTaskSpans(t *testing.T) {
+	provider := &recordingProvider{}
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env, err := taskengine.NewEnv(context.Background(), libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+
+	end := taskengine.TaskTransition{
+		Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+	}
+	chain := &taskengine.TaskChainDefinition{
+		ID:    "traced",
+		Tasks: []taskengine.TaskDefinition{{ID: "pass", Handler: taskengine.HandleNoop, Transition: end}},
+	}
+	_, _, _, err = env.ExecEnv(libtracker.WithNewRequestID(context.Background()), chain, "hi", taskengine.DataTypeString)
+	require.NoError(t, err)
+
+	chainSpan := provider.byName("chain traced")
+	require.NotNil(t, chainSpan)
+	require.True(t, chainSpan.ended)
+	require.Equal(t, codes.Unset, chainSpan.status)
+
+	taskSpan := provider.byName("task pass")
+	require.NotNil(t, taskSpan)
+	require.True(t, taskSpan.ended)
+	require.Equal(t, "chain traced", taskSpan.parent)
+	require.Equal(t, "noop", taskSpan.attrs["contenox.task.handler"].AsString())
+	require.Equal(t, int64(0), taskSpan.attrs["contenox.task.retry"].AsInt64())
+
+	failing := &taskengine.TaskChainDefinition{
+		ID: "failing",
+		Tasks: []taskengine.TaskDefinition{{
+			ID: "boom", Handler: taskengine.HandleRaiseError, PromptTemplate: "boom", Transition: end,
+		}},
+	}
+	_, _, _, err = env.ExecEnv(libtracker.WithNewRequestID(context.Background()), failing, "hi", taskengine.DataTypeString)
+	require.Error(t, err)
+	require.Equal(t, codes.Error, provider.byName("task boom").status)
+	require.Equal(t, codes.Error, provider.byName("chain failing").status)
+}
+
+func TestUnit_InjectTraceContext_WritesTraceparent(t *testing.T) {
+	header := http.Header{}
+	taskengine.InjectTraceContext(context.Background(), header)
+	require.Empty(t, header.Get("traceparent"), "no span, nothing to propagate")
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	taskengine.InjectTraceContext(trace.ContextWithSpanContext(context.Background(), sc), header)
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", header.Get("traceparent"))
+}
@@ -0,0 +1,149 @@
+package taskengine
+
+import (
+	"context"
+	"fmt"
+)
+
+// truncateHistory runs a truncate_history task: it drops the oldest
+// non-system messages of the input chat history until the history fits the
+// task's token budget. System messages and the last KeepTurns turns are never
+// dropped, and an assistant tool call is dropped together with its results, so
+// the output is always a well-formed history. When what must be kept alone
+// exceeds the budget the task fails with ErrContextLengthExceeded rather than
+// returning a history the next model call would reject anyway.
+//
+// Unlike LLMExecutionConfig.Shift, which slides the window inside one model
+// call and keeps the original history in the chain, this makes the trimmed
+// history the task's output, so every later task — and the persisted session —
+// sees the shorter conversation.
+func (exe *SimpleExec) truncateHistory(ctx context.Context, ctxLength int, currentTask *TaskDefinition, input any, dataType DataType) (any, DataType, string, error) {
+	if dataType != DataTypeChatHistory {
+		return nil, DataTypeAny, "", fmt.Errorf("truncate_history task %s: input must be a chat history, got %s", currentTask.ID, dataType.String())
+	}
+	history, ok := input.(ChatHistory)
+	if !ok {
+		return nil, DataTypeAny, "", fmt.Errorf("SEVERBUG: input is not a chat history")
+	}
+
+	cfg := TruncateHistoryConfig{}
+	if currentTask.Truncate != nil {
+		cfg = *currentTask.Truncate
+	}
+	budget := cfg.MaxTokens
+	if budget <= 0 {
+		budget = ctxLength
+	}
+	if budget <= 0 {
+		return nil, DataTypeAny, "", fmt.Errorf("truncate_history task %s: no token budget: set truncate.max_tokens or the chain's token_limit", currentTask.ID)
+	}
+	keepTurns := cfg.KeepTurns
+	if keepTurns <= 0 {
+		keepTurns = DefaultTruncateKeepTurns
+	}
+	modelName := "default"
+	if currentTask.ExecuteConfig != nil {
+		modelName = GetPrimaryModel(currentTask.ExecuteConfig)
+	}
+
+	reportErr, reportChange, end := exe.tracker.Start(ctx, "truncate_history", currentTask.ID,
+		"budget", budget, "keep_turns", keepTurns)
+	defer end()
+
+	kept, tokens, err := exe.truncateMessages(ctx, modelName, history.Messages, budget, keepTurns)
+	if err != nil {
+		reportErr(err)
+		return nil, DataTypeAny, "", fmt.Errorf("truncate_history task %s: %w", currentTask.ID, err)
+	}
+	dropped := len(history.Messages) - len(kept)
+	reportChange("dropped_messages", map[string]any{
+		"dropped": dropped,
+		"kept":    len(kept),
+		"tokens":  tokens,
+	})
+	if dropped == 0 {
+		return history, DataTypeChatHistory, TransitionNoop, nil
+	}
+	history.Messages = kept
+	// The token counts belonged to the untrimmed history.
+	history.InputTokens, history.OutputTokens = 0, 0
+	return history, DataTypeChatHistory, TransitionTruncated, nil
+}
+
+// truncateMessages keeps system messages, the last keepTurns turns (a turn
+// starts at a user message) and then as many of the remaining newest messages
+// as fit budget. It returns the kept messages in their original order and
+// their token count.
+func (exe *SimpleExec) truncateMessages(ctx context.Context, modelName string, msgs []Message, budget, keepTurns int) ([]Message, int, error) {
+	texts := make([]string, len(msgs))
+	for i, m := range msgs {
+		texts[i] = m.Content
+	}
+	toks, err := exe.repo.CountTokensBatch(ctx, modelName, texts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("token count failed: %w", err)
+	}
+
+	// Group messages into units that are kept or dropped as a whole: an
+	// assistant tool call with the tool results that follow it, or one
+	// message. Orphan tool results are left for repairToolCallPairing.
+	type unit struct {
+		idx    []int
+		tokens int
+		pinned bool
+	}
+	var units []unit
+	for i := 0; i < len(msgs); i++ {
+		u := unit{idx: []int{i}, tokens: toks[i], pinned: msgs[i].Role == "system"}
+		if msgs[i].Role == "assistant" && len(msgs[i].CallTools) > 0 {
+			for j := i + 1; j < len(msgs) && msgs[j].Role == "tool"; j++ {
+				u.idx = append(u.idx, j)
+				u.tokens += toks[j]
+				i = j
+			}
+		}
+		units = append(units, u)
+	}
+
+	// Pin the most recent keepTurns turns.
+	turns := 0
+	for k := len(units) - 1; k >= 0 && turns < keepTurns; k-- {
+		units[k].pinned = true
+		if msgs[units[k].idx[0]].Role == "user" {
+			turns++
+		}
+	}
+
+	used := 0
+	keep := make([]bool, len(units))
+	for k, u := range units {
+		if u.pinned {
+			keep[k] = true
+			used += u.tokens
+		}
+	}
+	if used > budget {
+		return nil, 0, fmt.Errorf("%w: system messages and the last %d turns need %d tokens > %d", ErrContextLengthExceeded, keepTurns, used, budget)
+	}
+	for k := len(units) - 1; k >= 0; k-- {
+		if keep[k] {
+			continue
+		}
+		if used+units[k].tokens > budget {
+			break
+		}
+		keep[k] = true
+		used += units[k].tokens
+	}
+
+	out := make([]Message, 0, len(msgs))
+	for k, u := range units {
+		if !keep[k] {
+			continue
+		}
+		for _, ix := range u.idx {
+			out = append(out, msgs[ix])
+		}
+	}
+	return repairToolCallPairing(out), used, nil
+}
@@ -0,0 +1,106 @@
+package taskengine_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/contenox/runtime/libtracker"
+	"github.com/contenox/runtime/runtime/internal/tools"
+	"github.com/contenox/runtime/runtime/taskengine"
+	"github.com/stretchr/testify/require"
+)
+
+// truncateInput is a history whose every message counts as one token with
+// mockModelRepo, so budgets below are message counts.
+func truncateInput() taskengine.ChatHistory {
+	call := taskengine.ToolCall{ID: "c1", Type: "function"}
+	call.Function.Name = "echo.echo"
+	return taskengine.ChatHistory{
+		Messages: []taskengine.Message{
+			{Role: "system", Content: "be brief"},
+			{Role: "user", Content: "first"},
+			{Role: "assistant", Content: "first answer"},
+			{Role: "user", Content: "second"},
+			{Role: "assistant", CallTools: []taskengine.ToolCall{call}},
+			{Role: "tool", ToolCallID: "c1", Content: "ECHOED"},
+			{Role: "assistant", Content: "second answer"},
+			{Role: "user", Content: "third"},
+		},
+		InputTokens:  40,
+		OutputTokens: 2,
+	}
+}
+
+func runTruncate(t *testing.T, cfg *taskengine.TruncateHistoryConfig, ctxLength int) (taskengine.ChatHistory, string, error) {
+	t.Helper()
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	task := &taskengine.TaskDefinition{ID: "trim", Handler: taskengine.HandleTruncateHistory, Truncate: cfg}
+	out, dt, eval, err := exec.TaskExec(context.Background(), time.Now().UTC(), ctxLength, &taskengine.ChainContext{}, task, truncateInput(), taskengine.DataTypeChatHistory)
+	if err != nil {
+		return taskengine.ChatHistory{}, eval, err
+	}
+	require.Equal(t, taskengine.DataTypeChatHistory, dt)
+	return out.(taskengine.ChatHistory), eval, nil
+}
+
+func contents(h taskengine.ChatHistory) []string {
+	var out []string
+	for _, m := range h.Messages {
+		out = append(out, m.Role+":"+m.Content)
+	}
+	return out
+}
+
+func TestUnit_TruncateHistory_DropsOldestKeepsSystemAndToolGroups(t *testing.T) {
+	hist, eval, err := runTruncate(t, &taskengine.TruncateHistoryConfig{MaxTokens: 4}, 0)
+	require.NoError(t, err)
+	require.Equal(t, taskengine.TransitionTruncated, eval)
+	// The tool call and its result would overflow together, so both go.
+	require.Equal(t, []string{"system:be brief", "assistant:second answer", "user:third"}, contents(hist))
+	require.Zero(t, hist.InputTokens, "token counts of the untrimmed history must not survive")
+}
+
+func TestUnit_TruncateHistory_KeepsRecentTurnsOverBudget(t *testing.T) {
+	_, _, err := runTruncate(t, &taskengine.TruncateHistoryConfig{MaxTokens: 3, KeepTurns: 2}, 0)
+	require.ErrorIs(t, err, taskengine.ErrContextLengthExceeded)
+
+	hist, _, err := runTruncate(t, &taskengine.TruncateHistoryConfig{MaxTokens: 6, KeepTurns: 2}, 0)
+	require.NoError(t, err)
+	require.Equal(t, "user:second", contents(hist)[1])
+	require.Len(t, hist.Messages, 6)
+}
+
+func TestUnit_TruncateHistory_FitsUsesChainTokenLimit(t *testing.T) {
+	hist, eval, err := runTruncate(t, nil, 100)
+	require.NoError(t, err)
+	require.Equal(t, taskengine.TransitionNoop, eval)
+	require.Len(t, hist.Messages, 8)
+
+	_, _, err = runTruncate(t, nil, 0)
+	require.ErrorContains(t, err, "no token budget")
+}
+
+func TestUnit_SimpleEnv_ExecEnv_RecordsDroppedMessages(t *testing.T) {
+	exec, err := taskengine.NewExec(context.Background(), &mockModelRepo{}, tools.NewMockToolsRegistry(), libtracker.NoopTracker{})
+	require.NoError(t, err)
+	env, err := taskengine.NewEnv(context.Background(), libtracker.NoopTracker{}, exec, taskengine.NewSimpleInspector(), tools.NewMockToolsRegistry())
+	require.NoError(t, err)
+
+	chain := &taskengine.TaskChainDefinition{
+		ID: "trim",
+		Tasks: []taskengine.TaskDefinition{{
+			ID:       "trim",
+			Handler:  taskengine.HandleTruncateHistory,
+			Truncate: &taskengine.TruncateHistoryConfig{MaxTokens: 4},
+			Transition: taskengine.TaskTransition{
+				Branches: []taskengine.TransitionBranch{{Operator: taskengine.OpDefault, Goto: taskengine.TermEnd}},
+			},
+		}},
+	}
+	_, _, steps, err := env.ExecEnv(libtracker.WithNewRequestID(context.Background()), chain, truncateInput(), taskengine.DataTypeChatHistory)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	require.Equal(t, 5, steps[0].DroppedMessages)
+}